@@ -342,6 +342,37 @@ const expectedGraphqlConfig = `{
     },
     "introspection": {
         "disabled": false
+    },
+    "cache": {
+        "enabled": false,
+        "timeout": 0,
+        "cache_by_claims": null,
+        "use_cache_control_directives": false,
+        "shared": false
+    },
+    "cost_analysis": {
+        "enabled": false,
+        "type_costs": null,
+        "field_costs": null,
+        "default_list_size": 0,
+        "rate_limit_by_cost": false
+    },
+    "persisted_queries": {
+        "enabled": false,
+        "ttl": 0,
+        "trusted_documents_only": false
+    },
+    "schema_registry": {
+        "enabled": false,
+        "max_versions": 0,
+        "block_breaking_changes": false
+    },
+    "batching": {
+        "enabled": false,
+        "max_operations": 0
+    },
+    "deduplication": {
+        "enabled": false
     }
 }`
 
@@ -173,7 +173,8 @@ func TestSupergraph_supergraphDataSourceConfigs(t *testing.T) {
 		ApiDefinition:             apiDef,
 		subscriptionClientFactory: &MockSubscriptionClientFactory{},
 	}
-	actualGraphQLConfigs := adapter.subgraphDataSourceConfigs()
+	actualGraphQLConfigs, err := adapter.subgraphDataSourceConfigs()
+	require.NoError(t, err)
 	assert.Equal(t, expectedDataSourceConfigs, actualGraphQLConfigs)
 }
 
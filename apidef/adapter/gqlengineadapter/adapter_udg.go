@@ -11,6 +11,8 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+	udgdatasource "github.com/TykTechnologies/tyk/internal/graphql/datasource"
 )
 
 type UniversalDataGraph struct {
@@ -142,6 +144,40 @@ func (u *UniversalDataGraph) engineConfigV2DataSources() (planDataSources []plan
 				graphqlConfig.SubscriptionType,
 			))
 
+		case apidef.GraphQLEngineDataSourceKindGRPC:
+			var grpcConfig apidef.GraphQLEngineDataSourceConfigGRPC
+			err = json.Unmarshal(ds.Config, &grpcConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			client, fetch, err := udgdatasource.GRPC(grpcConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			planDataSource.Factory = &restdatasource.Factory{
+				Client: &http.Client{Transport: graphqlinternal.NewDataSourceTransport(ds.Name, client.Transport)},
+			}
+			planDataSource.Custom = restDataSourceConfig(fetch)
+
+		case apidef.GraphQLEngineDataSourceKindSQL:
+			var sqlConfig apidef.GraphQLEngineDataSourceConfigSQL
+			err = json.Unmarshal(ds.Config, &sqlConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			client, fetch, err := udgdatasource.SQL(sqlConfig, udgdatasource.SingleRow(u.ApiDefinition.GraphQL.Schema, ds.RootFields))
+			if err != nil {
+				return nil, err
+			}
+
+			planDataSource.Factory = &restdatasource.Factory{
+				Client: &http.Client{Transport: graphqlinternal.NewDataSourceTransport(ds.Name, client.Transport)},
+			}
+			planDataSource.Custom = restDataSourceConfig(fetch)
+
 		case apidef.GraphQLEngineDataSourceKindKafka:
 			var kafkaConfig apidef.GraphQLEngineDataSourceConfigKafka
 			err = json.Unmarshal(ds.Config, &kafkaConfig)
@@ -8,6 +8,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/adapter/gqlengineadapter"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 )
 
 type Supergraph struct {
@@ -19,7 +20,11 @@ type Supergraph struct {
 }
 
 func (s *Supergraph) EngineConfigV3() (*graphql.EngineV2Configuration, error) {
-	dataSourceConfs := s.subgraphDataSourceConfigs()
+	dataSourceConfs, err := s.subgraphDataSourceConfigs()
+	if err != nil {
+		return nil, err
+	}
+
 	var federationConfigV2Factory *graphql.FederationEngineConfigFactory
 	federationConfigV2Factory = graphql.NewFederationEngineConfigFactory(
 		dataSourceConfs,
@@ -28,7 +33,7 @@ func (s *Supergraph) EngineConfigV3() (*graphql.EngineV2Configuration, error) {
 		graphql.WithFederationSubscriptionClientFactory(subscriptionClientFactoryOrDefault(s.subscriptionClientFactory)),
 	)
 
-	err := federationConfigV2Factory.SetMergedSchemaFromString(s.ApiDefinition.GraphQL.Supergraph.MergedSDL)
+	err = federationConfigV2Factory.SetMergedSchemaFromString(s.ApiDefinition.GraphQL.Supergraph.MergedSDL)
 	if err != nil {
 		return nil, err
 	}
@@ -43,13 +48,19 @@ func (s *Supergraph) EngineConfigV3() (*graphql.EngineV2Configuration, error) {
 	return &conf, nil
 }
 
-func (s *Supergraph) subgraphDataSourceConfigs() []graphqldatasource.Configuration {
+func (s *Supergraph) subgraphDataSourceConfigs() ([]graphqldatasource.Configuration, error) {
 	confs := make([]graphqldatasource.Configuration, 0)
 	if len(s.ApiDefinition.GraphQL.Supergraph.Subgraphs) == 0 {
-		return confs
+		return confs, nil
 	}
 
-	for _, apiDefSubgraphConf := range s.ApiDefinition.GraphQL.Supergraph.Subgraphs {
+	// Federation 2 subgraphs are planned with their SDLs rewritten to the Federation 1 form
+	composition, err := graphqlinternal.ComposeSupergraph(s.ApiDefinition.GraphQL.Supergraph)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, apiDefSubgraphConf := range s.ApiDefinition.GraphQL.Supergraph.Subgraphs {
 		if len(apiDefSubgraphConf.SDL) == 0 {
 			continue
 		}
@@ -59,15 +70,19 @@ func (s *Supergraph) subgraphDataSourceConfigs() []graphqldatasource.Configurati
 			http.MethodPost,
 			hdr,
 			apiDefSubgraphConf.SubscriptionType)
+		serviceSDL := apiDefSubgraphConf.SDL
+		if composition != nil {
+			serviceSDL = composition.SubgraphSDLs[i]
+		}
 		conf.Federation = graphqldatasource.FederationConfiguration{
 			Enabled:    true,
-			ServiceSDL: apiDefSubgraphConf.SDL,
+			ServiceSDL: serviceSDL,
 		}
 
 		confs = append(confs, conf)
 	}
 
-	return confs
+	return confs, nil
 }
 
 func graphqlDataSourceConfiguration(url string, method string, headers map[string]string, subscriptionType apidef.SubscriptionType) graphqldatasource.Configuration {
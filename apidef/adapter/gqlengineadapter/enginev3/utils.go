@@ -14,6 +14,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/adapter/gqlengineadapter"
+	udgdatasource "github.com/TykTechnologies/tyk/internal/graphql/datasource"
 )
 
 func extractURLQueryParamsForEngineV2(url string, providedApiDefQueries []apidef.QueryVariable) (urlWithoutParams string, engineV2Queries []restdatasource.QueryConfiguration, err error) {
@@ -83,6 +84,18 @@ func ConvertApiDefinitionHeadersToHttpHeaders(apiDefHeaders map[string]string) h
 
 }
 
+// restDataSourceConfig configures the REST data source sending the requests of gRPC and SQL data sources.
+func restDataSourceConfig(fetch udgdatasource.Fetch) json.RawMessage {
+	return restdatasource.ConfigJSON(restdatasource.Configuration{
+		Fetch: restdatasource.FetchConfiguration{
+			URL:    fetch.URL,
+			Method: fetch.Method,
+			Body:   fetch.Body,
+			Header: ConvertApiDefinitionHeadersToHttpHeaders(fetch.Header),
+		},
+	})
+}
+
 func generateRestDataSourceFromGraphql(config apidef.GraphQLEngineDataSourceConfigGraphQL) (json.RawMessage, error) {
 	if !config.HasOperation {
 		return nil, gqlengineadapter.ErrGraphQLConfigIsMissingOperation
@@ -10,6 +10,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	udgdatasource "github.com/TykTechnologies/tyk/internal/graphql/datasource"
 )
 
 var (
@@ -84,6 +85,18 @@ func RemoveDuplicateApiDefinitionHeaders(headers ...map[string]string) map[strin
 	return hdr
 }
 
+// restDataSourceConfig configures the REST data source sending the requests of gRPC and SQL data sources.
+func restDataSourceConfig(fetch udgdatasource.Fetch) json.RawMessage {
+	return restdatasource.ConfigJSON(restdatasource.Configuration{
+		Fetch: restdatasource.FetchConfiguration{
+			URL:    fetch.URL,
+			Method: fetch.Method,
+			Body:   fetch.Body,
+			Header: ConvertApiDefinitionHeadersToHttpHeaders(fetch.Header),
+		},
+	})
+}
+
 func generateRestDataSourceFromGraphql(config apidef.GraphQLEngineDataSourceConfigGraphQL) (json.RawMessage, error) {
 	if !config.HasOperation {
 		return nil, ErrGraphQLConfigIsMissingOperation
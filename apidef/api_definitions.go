@@ -44,6 +44,7 @@ type EndpointMethodAction string
 type SourceMode string
 
 type MiddlewareDriver string
+type JSVMEngine string
 type IdExtractorSource string
 type IdExtractorType string
 type AuthTypeEnum string
@@ -68,6 +69,12 @@ const (
 	LuaDriver      MiddlewareDriver = "lua"
 	GrpcDriver     MiddlewareDriver = "grpc"
 	GoPluginDriver MiddlewareDriver = "goplugin"
+	WasmDriver     MiddlewareDriver = "wasm"
+
+	// OttoJSVMEngine runs JSVM middleware and virtual endpoints on otto, supporting ES5.
+	OttoJSVMEngine JSVMEngine = "otto"
+	// GojaJSVMEngine runs JSVM middleware and virtual endpoints on goja, supporting ES2020+.
+	GojaJSVMEngine JSVMEngine = "goja"
 
 	BodySource        IdExtractorSource = "body"
 	HeaderSource      IdExtractorSource = "header"
@@ -175,6 +182,28 @@ type TemplateData struct {
 	Mode           SourceMode       `bson:"template_mode" json:"template_mode"`
 	EnableSession  bool             `bson:"enable_session" json:"enable_session"`
 	TemplateSource string           `bson:"template_source" json:"template_source"`
+	Conversion     BodyConversion   `bson:"conversion" json:"conversion"`
+}
+
+// BodyConversion configures a built-in conversion between JSON and XML bodies that is
+// used instead of a template. The direction follows the input type: a json input is
+// converted to XML and an xml input is converted to JSON.
+type BodyConversion struct {
+	// Enabled activates the conversion, the template settings are ignored when set.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RootElement is the name of the XML root element when converting JSON to XML, e.g. "soap:Envelope".
+	RootElement string `bson:"root_element" json:"root_element"`
+	// Namespaces maps XML namespace prefixes to URIs. They are declared on the root element when
+	// converting to XML and their declarations are dropped when converting to JSON.
+	// An empty prefix declares the default namespace.
+	Namespaces map[string]string `bson:"namespaces" json:"namespaces"`
+	// AttributePrefix marks JSON keys that map to XML attributes, defaults to "-".
+	AttributePrefix string `bson:"attribute_prefix" json:"attribute_prefix"`
+	// ArrayPaths lists dot separated element paths that are always converted to JSON arrays,
+	// even when the XML contains a single element.
+	ArrayPaths []string `bson:"array_paths" json:"array_paths"`
+	// CastValues converts numeric and boolean XML values to JSON numbers and booleans.
+	CastValues bool `bson:"cast_values" json:"cast_values"`
 }
 
 type TemplateMeta struct {
@@ -190,6 +219,16 @@ type TransformJQMeta struct {
 	Method string `bson:"method" json:"method"`
 }
 
+// TransformExpressionMeta configures a CEL expression that rewrites the body of matching requests or responses.
+// The expression has access to the `body`, `headers`, `context`, `session` and `status` variables,
+// and its result replaces the body as JSON.
+type TransformExpressionMeta struct {
+	Disabled   bool   `bson:"disabled" json:"disabled"`
+	Expression string `bson:"expression" json:"expression"`
+	Path       string `bson:"path" json:"path"`
+	Method     string `bson:"method" json:"method"`
+}
+
 type HeaderInjectionMeta struct {
 	Disabled      bool              `bson:"disabled" json:"disabled"`
 	DeleteHeaders []string          `bson:"delete_headers" json:"delete_headers"`
@@ -262,6 +301,17 @@ type RequestSizeMeta struct {
 	SizeLimit int64  `bson:"size_limit" json:"size_limit"`
 }
 
+// ResponseSizeMeta limits the size and the content types of upstream responses for an endpoint.
+type ResponseSizeMeta struct {
+	Disabled bool   `bson:"disabled" json:"disabled"`
+	Path     string `bson:"path" json:"path"`
+	Method   string `bson:"method" json:"method"`
+	// SizeLimit is the maximum size of the response body in bytes. Zero means no limit.
+	SizeLimit int64 `bson:"size_limit" json:"size_limit"`
+	// AllowedContentTypes lists the media types the upstream may respond with. Empty means any.
+	AllowedContentTypes []string `bson:"allowed_content_types" json:"allowed_content_types"`
+}
+
 type CircuitBreakerMeta struct {
 	Disabled             bool    `bson:"disabled" json:"disabled"`
 	Path                 string  `bson:"path" json:"path"`
@@ -373,32 +423,35 @@ type GoPluginMeta struct {
 }
 
 type ExtendedPathsSet struct {
-	Ignored                 []EndPointMeta        `bson:"ignored" json:"ignored,omitempty"`
-	WhiteList               []EndPointMeta        `bson:"white_list" json:"white_list,omitempty"`
-	BlackList               []EndPointMeta        `bson:"black_list" json:"black_list,omitempty"`
-	MockResponse            []MockResponseMeta    `bson:"mock_response" json:"mock_response,omitempty"`
-	Cached                  []string              `bson:"cache" json:"cache,omitempty"`
-	AdvanceCacheConfig      []CacheMeta           `bson:"advance_cache_config" json:"advance_cache_config,omitempty"`
-	Transform               []TemplateMeta        `bson:"transform" json:"transform,omitempty"`
-	TransformResponse       []TemplateMeta        `bson:"transform_response" json:"transform_response,omitempty"`
-	TransformJQ             []TransformJQMeta     `bson:"transform_jq" json:"transform_jq,omitempty"`
-	TransformJQResponse     []TransformJQMeta     `bson:"transform_jq_response" json:"transform_jq_response,omitempty"`
-	TransformHeader         []HeaderInjectionMeta `bson:"transform_headers" json:"transform_headers,omitempty"`
-	TransformResponseHeader []HeaderInjectionMeta `bson:"transform_response_headers" json:"transform_response_headers,omitempty"`
-	HardTimeouts            []HardTimeoutMeta     `bson:"hard_timeouts" json:"hard_timeouts,omitempty"`
-	CircuitBreaker          []CircuitBreakerMeta  `bson:"circuit_breakers" json:"circuit_breakers,omitempty"`
-	URLRewrite              []URLRewriteMeta      `bson:"url_rewrites" json:"url_rewrites,omitempty"`
-	Virtual                 []VirtualMeta         `bson:"virtual" json:"virtual,omitempty"`
-	SizeLimit               []RequestSizeMeta     `bson:"size_limits" json:"size_limits,omitempty"`
-	MethodTransforms        []MethodTransformMeta `bson:"method_transforms" json:"method_transforms,omitempty"`
-	TrackEndpoints          []TrackEndpointMeta   `bson:"track_endpoints" json:"track_endpoints,omitempty"`
-	DoNotTrackEndpoints     []TrackEndpointMeta   `bson:"do_not_track_endpoints" json:"do_not_track_endpoints,omitempty"`
-	ValidateJSON            []ValidatePathMeta    `bson:"validate_json" json:"validate_json,omitempty"`
-	ValidateRequest         []ValidateRequestMeta `bson:"validate_request" json:"validate_request,omitempty"`
-	Internal                []InternalMeta        `bson:"internal" json:"internal,omitempty"`
-	GoPlugin                []GoPluginMeta        `bson:"go_plugin" json:"go_plugin,omitempty"`
-	PersistGraphQL          []PersistGraphQLMeta  `bson:"persist_graphql" json:"persist_graphql"`
-	RateLimit               []RateLimitMeta       `bson:"rate_limit" json:"rate_limit"`
+	Ignored                     []EndPointMeta            `bson:"ignored" json:"ignored,omitempty"`
+	WhiteList                   []EndPointMeta            `bson:"white_list" json:"white_list,omitempty"`
+	BlackList                   []EndPointMeta            `bson:"black_list" json:"black_list,omitempty"`
+	MockResponse                []MockResponseMeta        `bson:"mock_response" json:"mock_response,omitempty"`
+	Cached                      []string                  `bson:"cache" json:"cache,omitempty"`
+	AdvanceCacheConfig          []CacheMeta               `bson:"advance_cache_config" json:"advance_cache_config,omitempty"`
+	Transform                   []TemplateMeta            `bson:"transform" json:"transform,omitempty"`
+	TransformResponse           []TemplateMeta            `bson:"transform_response" json:"transform_response,omitempty"`
+	TransformJQ                 []TransformJQMeta         `bson:"transform_jq" json:"transform_jq,omitempty"`
+	TransformJQResponse         []TransformJQMeta         `bson:"transform_jq_response" json:"transform_jq_response,omitempty"`
+	TransformExpression         []TransformExpressionMeta `bson:"transform_expression" json:"transform_expression,omitempty"`
+	TransformExpressionResponse []TransformExpressionMeta `bson:"transform_expression_response" json:"transform_expression_response,omitempty"`
+	TransformHeader             []HeaderInjectionMeta     `bson:"transform_headers" json:"transform_headers,omitempty"`
+	TransformResponseHeader     []HeaderInjectionMeta     `bson:"transform_response_headers" json:"transform_response_headers,omitempty"`
+	HardTimeouts                []HardTimeoutMeta         `bson:"hard_timeouts" json:"hard_timeouts,omitempty"`
+	CircuitBreaker              []CircuitBreakerMeta      `bson:"circuit_breakers" json:"circuit_breakers,omitempty"`
+	URLRewrite                  []URLRewriteMeta          `bson:"url_rewrites" json:"url_rewrites,omitempty"`
+	Virtual                     []VirtualMeta             `bson:"virtual" json:"virtual,omitempty"`
+	SizeLimit                   []RequestSizeMeta         `bson:"size_limits" json:"size_limits,omitempty"`
+	ResponseSizeLimit           []ResponseSizeMeta        `bson:"response_size_limits" json:"response_size_limits,omitempty"`
+	MethodTransforms            []MethodTransformMeta     `bson:"method_transforms" json:"method_transforms,omitempty"`
+	TrackEndpoints              []TrackEndpointMeta       `bson:"track_endpoints" json:"track_endpoints,omitempty"`
+	DoNotTrackEndpoints         []TrackEndpointMeta       `bson:"do_not_track_endpoints" json:"do_not_track_endpoints,omitempty"`
+	ValidateJSON                []ValidatePathMeta        `bson:"validate_json" json:"validate_json,omitempty"`
+	ValidateRequest             []ValidateRequestMeta     `bson:"validate_request" json:"validate_request,omitempty"`
+	Internal                    []InternalMeta            `bson:"internal" json:"internal,omitempty"`
+	GoPlugin                    []GoPluginMeta            `bson:"go_plugin" json:"go_plugin,omitempty"`
+	PersistGraphQL              []PersistGraphQLMeta      `bson:"persist_graphql" json:"persist_graphql"`
+	RateLimit                   []RateLimitMeta           `bson:"rate_limit" json:"rate_limit"`
 }
 
 // Clear omits values that have OAS API definition conversions in place.
@@ -406,9 +459,12 @@ func (e *ExtendedPathsSet) Clear() {
 	// The values listed within don't have a conversion from OAS in place.
 	// When the conversion is added, delete the individual field to clear it.
 	*e = ExtendedPathsSet{
-		TransformJQ:         e.TransformJQ,
-		TransformJQResponse: e.TransformJQResponse,
-		PersistGraphQL:      e.PersistGraphQL,
+		TransformJQ:                 e.TransformJQ,
+		TransformJQResponse:         e.TransformJQResponse,
+		TransformExpression:         e.TransformExpression,
+		TransformExpressionResponse: e.TransformExpressionResponse,
+		ResponseSizeLimit:           e.ResponseSizeLimit,
+		PersistGraphQL:              e.PersistGraphQL,
 	}
 }
 
@@ -539,6 +595,8 @@ type MiddlewareDefinition struct {
 	Path           string `bson:"path" json:"path"`
 	RequireSession bool   `bson:"require_session" json:"require_session"`
 	RawBodyOnly    bool   `bson:"raw_body_only" json:"raw_body_only"`
+	// StreamBody makes response plugins process the upstream body in chunks as it's proxied, instead of buffering it.
+	StreamBody bool `bson:"stream_body" json:"stream_body"`
 }
 
 // IDExtractorConfig specifies the configuration for ID extractor
@@ -571,6 +629,8 @@ type MiddlewareSection struct {
 	Response    []MiddlewareDefinition `bson:"response" json:"response"`
 	Driver      MiddlewareDriver       `bson:"driver" json:"driver"`
 	IdExtractor MiddlewareIdExtractor  `bson:"id_extractor" json:"id_extractor"`
+	// FailOpen lets requests through the hooks, except auth checks, when the gRPC plugin server is unreachable.
+	FailOpen bool `bson:"fail_open" json:"fail_open"`
 }
 
 type CacheOptions struct {
@@ -724,6 +784,7 @@ type APIDefinition struct {
 	CustomMiddleware                     MiddlewareSection      `bson:"custom_middleware" json:"custom_middleware"`
 	CustomMiddlewareBundle               string                 `bson:"custom_middleware_bundle" json:"custom_middleware_bundle"`
 	CustomMiddlewareBundleDisabled       bool                   `bson:"custom_middleware_bundle_disabled" json:"custom_middleware_bundle_disabled"`
+	JSVMEngine                           JSVMEngine             `bson:"jsvm_engine" json:"jsvm_engine,omitempty"`
 	CacheOptions                         CacheOptions           `bson:"cache_options" json:"cache_options"`
 	SessionLifetimeRespectsKeyExpiration bool                   `bson:"session_lifetime_respects_key_expiration" json:"session_lifetime_respects_key_expiration,omitempty"`
 	SessionLifetime                      int64                  `bson:"session_lifetime" json:"session_lifetime"`
@@ -753,6 +814,7 @@ type APIDefinition struct {
 	StripAuthData                        bool                   `bson:"strip_auth_data" json:"strip_auth_data"`
 	EnableDetailedRecording              bool                   `bson:"enable_detailed_recording" json:"enable_detailed_recording"`
 	GraphQL                              GraphQLConfig          `bson:"graphql" json:"graphql"`
+	GRPC                                 GRPCConfig             `bson:"grpc" json:"grpc"`
 	AnalyticsPlugin                      AnalyticsPluginConfig  `bson:"analytics_plugin" json:"analytics_plugin,omitempty"`
 
 	// Gateway segment tags
@@ -931,6 +993,48 @@ type CORSConfig struct {
 	Debug              bool     `bson:"debug" json:"debug"`
 }
 
+// GRPCConfig configures a native gRPC API. Requests are matched by gRPC method, errors are returned
+// as gRPC status codes and analytics record the grpc-status of each call.
+type GRPCConfig struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// AllowedMethods lists the methods clients can call, as `/package.Service/Method`, or `/package.Service/*`
+	// for all the methods of a service. All methods are allowed when empty.
+	AllowedMethods []string `bson:"allowed_methods" json:"allowed_methods"`
+	// MethodRateLimits limits the calls to gRPC methods across all clients, like per-endpoint rate limits.
+	MethodRateLimits []GRPCMethodRateLimit `bson:"method_rate_limits" json:"method_rate_limits"`
+	// Transcoding exposes the gRPC methods as REST/JSON endpoints.
+	Transcoding GRPCTranscoding `bson:"transcoding" json:"transcoding"`
+	// Web bridges gRPC-Web requests from browsers to the native gRPC upstream.
+	Web GRPCWeb `bson:"web" json:"web"`
+}
+
+// GRPCWeb configures the gRPC-Web bridge. Requests sent as `application/grpc-web(+proto)` or
+// `application/grpc-web-text` are proxied as native gRPC, with the trailers returned in the response body.
+// CORS preflight requests are answered with the API CORS config, allowing the gRPC-Web headers.
+type GRPCWeb struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+}
+
+// GRPCTranscoding configures the transcoding of HTTP/JSON requests to gRPC calls. Routes are built from the
+// `google.api.http` annotations of the methods, methods without annotations are served on `POST /package.Service/Method`.
+type GRPCTranscoding struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// DescriptorSet is a base64 encoded `FileDescriptorSet`, as generated by `protoc --include_imports --descriptor_set_out`.
+	DescriptorSet string `bson:"descriptor_set" json:"descriptor_set"`
+	// DescriptorSetPath is the path of a `FileDescriptorSet` file, relative to the custom middleware bundle when the API has one.
+	DescriptorSetPath string `bson:"descriptor_set_path" json:"descriptor_set_path"`
+	// Services limits the transcoded services, as `package.Service`. All the services of the descriptors are transcoded when empty.
+	Services []string `bson:"services" json:"services"`
+}
+
+// GRPCMethodRateLimit configures the rate limit of a gRPC method, or of all the methods of a service.
+type GRPCMethodRateLimit struct {
+	Disabled bool    `bson:"disabled" json:"disabled"`
+	Method   string  `bson:"method" json:"method"`
+	Rate     float64 `bson:"rate" json:"rate"`
+	Per      float64 `bson:"per" json:"per"`
+}
+
 // GraphQLConfig is the root config object for a GraphQL API.
 type GraphQLConfig struct {
 	// Enabled indicates if GraphQL should be enabled.
@@ -957,6 +1061,18 @@ type GraphQLConfig struct {
 	Supergraph GraphQLSupergraphConfig `bson:"supergraph" json:"supergraph"`
 	// Introspection holds the configuration for GraphQL Introspection
 	Introspection GraphQLIntrospectionConfig `bson:"introspection" json:"introspection"`
+	// Cache holds the configuration for GraphQL aware response caching.
+	Cache GraphQLCacheConfig `bson:"cache" json:"cache"`
+	// CostAnalysis holds the configuration for static cost analysis of GraphQL operations.
+	CostAnalysis GraphQLCostAnalysisConfig `bson:"cost_analysis" json:"cost_analysis"`
+	// PersistedQueries holds the configuration for automatic persisted queries and trusted documents.
+	PersistedQueries GraphQLPersistedQueriesConfig `bson:"persisted_queries" json:"persisted_queries"`
+	// SchemaRegistry holds the configuration for the schema version history and breaking change detection.
+	SchemaRegistry GraphQLSchemaRegistryConfig `bson:"schema_registry" json:"schema_registry"`
+	// Batching holds the configuration for requests carrying an array of operations.
+	Batching GraphQLBatchingConfig `bson:"batching" json:"batching"`
+	// Deduplication holds the configuration for deduplicating identical in-flight requests to the upstreams.
+	Deduplication GraphQLDeduplicationConfig `bson:"deduplication" json:"deduplication"`
 }
 
 type GraphQLConfigVersion string
@@ -972,6 +1088,93 @@ type GraphQLIntrospectionConfig struct {
 	Disabled bool `bson:"disabled" json:"disabled"`
 }
 
+// GraphQLCacheConfig configures caching of GraphQL query responses.
+// Cache keys are built from the normalised operation, its variables and the
+// configured claims instead of the raw request body.
+type GraphQLCacheConfig struct {
+	// Enabled activates the GraphQL response cache.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Timeout is the default cache TTL in seconds. When zero, cache_options.cache_timeout is used.
+	Timeout int64 `bson:"timeout" json:"timeout"`
+	// CacheByClaims lists JWT claims or session metadata keys that are added to the cache key.
+	CacheByClaims []string `bson:"cache_by_claims" json:"cache_by_claims"`
+	// UseCacheControlDirectives enables per type and per field TTLs from @cacheControl(maxAge) hints in the schema.
+	UseCacheControlDirectives bool `bson:"use_cache_control_directives" json:"use_cache_control_directives"`
+	// Shared caches responses per organisation instead of per key. Only enable it when the responses don't depend
+	// on the key making the request.
+	Shared bool `bson:"shared" json:"shared"`
+}
+
+// GraphQLCostAnalysisConfig configures the static cost analysis of GraphQL operations.
+// Field weights and list sizes can also be declared in the schema with @cost(weight) and
+// @listSize(assumedSize, slicingArguments) directives, the values configured here take precedence.
+type GraphQLCostAnalysisConfig struct {
+	// Enabled activates cost analysis, rejecting operations above the max_query_cost of the key.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// TypeCosts is the weight of fields returning the type. Fields returning objects, interfaces
+	// and unions weigh 1 by default, fields returning scalars and enums 0.
+	TypeCosts map[string]int `bson:"type_costs" json:"type_costs"`
+	// FieldCosts holds the weight and list size configuration of fields, keyed by "Type.field".
+	FieldCosts map[string]GraphQLFieldCost `bson:"field_costs" json:"field_costs"`
+	// DefaultListSize is the assumed size of lists without a slicing argument or assumed size.
+	// When zero, such lists are counted once.
+	DefaultListSize int `bson:"default_list_size" json:"default_list_size"`
+	// RateLimitByCost makes operations consume rate limit allowance equal to their cost,
+	// on top of the rate limit of requests.
+	RateLimitByCost bool `bson:"rate_limit_by_cost" json:"rate_limit_by_cost"`
+}
+
+// GraphQLFieldCost is the cost configuration of a single field.
+type GraphQLFieldCost struct {
+	// Weight overrides the weight of the field's return type when not zero.
+	Weight int `bson:"weight" json:"weight"`
+	// AssumedSize is the size of the list returned by the field, if no slicing argument is set.
+	AssumedSize int `bson:"assumed_size" json:"assumed_size"`
+	// SlicingArguments are the arguments setting the size of the list returned by the field, like first or last.
+	SlicingArguments []string `bson:"slicing_arguments" json:"slicing_arguments"`
+}
+
+// GraphQLPersistedQueriesConfig configures automatic persisted queries (APQ) and trusted documents.
+// Clients using APQ send the SHA-256 hash of the query in extensions.persistedQuery instead of the query,
+// the gateway stores the query the first time a client sends both.
+type GraphQLPersistedQueriesConfig struct {
+	// Enabled activates the APQ protocol.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// TTL is the time in seconds queries registered by clients are kept. When zero, they don't expire.
+	TTL int64 `bson:"ttl" json:"ttl"`
+	// TrustedDocumentsOnly rejects operations which are not in the trusted documents uploaded via the
+	// Control API. Clients can't register queries through APQ in this mode.
+	TrustedDocumentsOnly bool `bson:"trusted_documents_only" json:"trusted_documents_only"`
+}
+
+// GraphQLSchemaRegistryConfig configures the schema registry of a GraphQL API. The gateway keeps the versions of
+// the schema the API was loaded with, each diffed against the previous one, and serves them via the Control API.
+type GraphQLSchemaRegistryConfig struct {
+	// Enabled activates recording the schema versions.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxVersions is the number of schema versions kept, older versions are dropped. When zero, 10 versions are kept.
+	MaxVersions int `bson:"max_versions" json:"max_versions"`
+	// BlockBreakingChanges rejects API updates through the Control API that make breaking changes to the schema.
+	BlockBreakingChanges bool `bson:"block_breaking_changes" json:"block_breaking_changes"`
+}
+
+// GraphQLBatchingConfig configures array batching. Clients send an array of operations in one HTTP request, each
+// operation goes through the API like a request of its own and the response is the array of their results.
+type GraphQLBatchingConfig struct {
+	// Enabled accepts requests carrying an array of operations.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxOperations is the maximum number of operations of a batch. When zero, it defaults to 10.
+	MaxOperations int `bson:"max_operations" json:"max_operations"`
+}
+
+// GraphQLDeduplicationConfig configures the deduplication of upstream requests. While a request made to resolve a
+// query is in flight, identical requests to the same upstream wait for its response instead of being sent.
+// Mutations and subscriptions are never deduplicated.
+type GraphQLDeduplicationConfig struct {
+	// Enabled activates the deduplication of upstream requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+}
+
 type GraphQLResponseExtensions struct {
 	OnErrorForwarding bool `bson:"on_error_forwarding" json:"on_error_forwarding"`
 }
@@ -1040,6 +1243,8 @@ const (
 	GraphQLEngineDataSourceKindREST    = "REST"
 	GraphQLEngineDataSourceKindGraphQL = "GraphQL"
 	GraphQLEngineDataSourceKindKafka   = "Kafka"
+	GraphQLEngineDataSourceKindGRPC    = "gRPC"
+	GraphQLEngineDataSourceKindSQL     = "SQL"
 )
 
 type GraphQLEngineDataSource struct {
@@ -1085,6 +1290,39 @@ type GraphQLEngineDataSourceConfigKafka struct {
 	SASL                 GraphQLEngineKafkaSASL `json:"sasl"`
 }
 
+// GraphQLEngineDataSourceConfigGRPC resolves fields by calling a unary gRPC method. The request message is
+// built from Request, a JSON template which can reference field arguments, e.g. {"id": "{{ .arguments.id }}"}.
+// The response message is returned as JSON with the field names in lowerCamelCase.
+type GraphQLEngineDataSourceConfigGRPC struct {
+	// Target is the address of the gRPC server, e.g. users.internal:50051.
+	Target string `bson:"target" json:"target"`
+	// Method is the full name of the method, e.g. users.v1.UserService/GetUser.
+	Method string `bson:"method" json:"method"`
+	// Request is the JSON template of the request message.
+	Request string `bson:"request" json:"request"`
+	// Headers are sent as metadata with every call.
+	Headers map[string]string `bson:"headers" json:"headers"`
+	// UseTLS connects to the server with TLS, otherwise the connection is plaintext.
+	UseTLS bool `bson:"use_tls" json:"use_tls"`
+	// Descriptors is a base64 encoded protobuf FileDescriptorSet describing the method, e.g. generated with
+	// protoc --include_imports --descriptor_set_out. When empty, the method is resolved with server reflection.
+	Descriptors string `bson:"descriptors" json:"descriptors"`
+}
+
+// GraphQLEngineDataSourceConfigSQL resolves fields with a read-only SQL query. Queries run in a read-only
+// transaction which is always rolled back.
+type GraphQLEngineDataSourceConfigSQL struct {
+	// Driver is the database driver, postgres or sqlite.
+	Driver string `bson:"driver" json:"driver"`
+	// ConnectionString is the data source name passed to the driver.
+	ConnectionString string `bson:"connection_string" json:"connection_string"`
+	// Query is a SELECT statement, with placeholders in the syntax of the driver, e.g. $1 for postgres.
+	Query string `bson:"query" json:"query"`
+	// Arguments are the templates of the values bound to the placeholders of the query, in order,
+	// e.g. {{ .arguments.id }}.
+	Arguments []string `bson:"arguments" json:"arguments"`
+}
+
 type GraphQLEngineKafkaSASL struct {
 	Enable   bool   `json:"enable"`
 	User     string `json:"user"`
@@ -33,6 +33,9 @@ func TestXTykGateway_Lint(t *testing.T) {
 			if op.TransformResponseBody != nil {
 				op.TransformResponseBody.Format = "json"
 			}
+			if op.ValidateResponse != nil {
+				op.ValidateResponse.Mode = ValidateResponseModeBlock
+			}
 			if op.RateLimit != nil {
 				op.RateLimit.Per = ReadableDuration(time.Minute)
 			}
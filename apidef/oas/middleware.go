@@ -454,7 +454,8 @@ type PluginConfig struct {
 	// - `python`,
 	// - `lua`,
 	// - `grpc`,
-	// - `goplugin`.
+	// - `goplugin`,
+	// - `wasm`.
 	//
 	// Tyk classic API definition: `custom_middleware.driver`.
 	Driver apidef.MiddlewareDriver `bson:"driver,omitempty" json:"driver,omitempty"`
@@ -464,11 +465,18 @@ type PluginConfig struct {
 
 	// Data configures custom plugin data.
 	Data *PluginConfigData `bson:"data,omitempty" json:"data,omitempty"`
+
+	// FailOpen lets requests continue without running the plugins when the gRPC plugin server
+	// is unreachable or times out. Authentication plugins always fail closed.
+	//
+	// Tyk classic API definition: `custom_middleware.fail_open`.
+	FailOpen bool `bson:"failOpen,omitempty" json:"failOpen,omitempty"`
 }
 
 // Fill fills PluginConfig from apidef.
 func (p *PluginConfig) Fill(api apidef.APIDefinition) {
 	p.Driver = api.CustomMiddleware.Driver
+	p.FailOpen = api.CustomMiddleware.FailOpen
 
 	if p.Bundle == nil {
 		p.Bundle = &PluginBundle{}
@@ -492,6 +500,7 @@ func (p *PluginConfig) Fill(api apidef.APIDefinition) {
 // ExtractTo extracts *PluginConfig into *apidef.
 func (p *PluginConfig) ExtractTo(api *apidef.APIDefinition) {
 	api.CustomMiddleware.Driver = p.Driver
+	api.CustomMiddleware.FailOpen = p.FailOpen
 
 	if p.Bundle == nil {
 		p.Bundle = &PluginBundle{}
@@ -1182,13 +1191,16 @@ type CustomPlugin struct {
 	Enabled bool `bson:"enabled" json:"enabled"` // required.
 	// FunctionName is the name of authentication method.
 	FunctionName string `bson:"functionName" json:"functionName"` // required.
-	// Path is the path to shared object file in case of goplugin mode or path to JS code in case of otto auth plugin.
+	// Path is the path to shared object file in case of goplugin mode, WebAssembly module in case of wasm mode or path to JS code in case of otto auth plugin.
 	Path string `bson:"path" json:"path"`
 	// RawBodyOnly if set to true, do not fill body in request or response object.
 	RawBodyOnly bool `bson:"rawBodyOnly,omitempty" json:"rawBodyOnly,omitempty"`
 	// RequireSession if set to true passes down the session information for plugins after authentication.
 	// RequireSession is used only with JSVM custom middleware.
 	RequireSession bool `bson:"requireSession,omitempty" json:"requireSession,omitempty"`
+	// StreamBody if set to true, response plugins receive the upstream body in chunks as it's proxied, instead of buffered.
+	// StreamBody is used only with gRPC, Python and Go plugin response middleware.
+	StreamBody bool `bson:"streamBody,omitempty" json:"streamBody,omitempty"`
 }
 
 // CustomPlugins is a list of CustomPlugin objects.
@@ -1208,6 +1220,7 @@ func (c *CustomPlugins) Fill(mwDefs []apidef.MiddlewareDefinition) {
 			FunctionName:   mwDef.Name,
 			RawBodyOnly:    mwDef.RawBodyOnly,
 			RequireSession: mwDef.RequireSession,
+			StreamBody:     mwDef.StreamBody,
 		}
 	}
 
@@ -1227,6 +1240,7 @@ func (c *CustomPlugins) ExtractTo(mwDefs []apidef.MiddlewareDefinition) {
 			Path:           plugin.Path,
 			RawBodyOnly:    plugin.RawBodyOnly,
 			RequireSession: plugin.RequireSession,
+			StreamBody:     plugin.StreamBody,
 		}
 	}
 }
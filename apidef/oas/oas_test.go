@@ -219,6 +219,19 @@ func TestOAS_ExtractTo_ResetAPIDefinition(t *testing.T) {
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformJQResponse[0].Filter",
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformJQResponse[0].Path",
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformJQResponse[0].Method",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpression[0].Disabled",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpression[0].Expression",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpression[0].Path",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpression[0].Method",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpressionResponse[0].Disabled",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpressionResponse[0].Expression",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpressionResponse[0].Path",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.TransformExpressionResponse[0].Method",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.ResponseSizeLimit[0].Disabled",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.ResponseSizeLimit[0].Path",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.ResponseSizeLimit[0].Method",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.ResponseSizeLimit[0].SizeLimit",
+		"APIDefinition.VersionData.Versions[0].ExtendedPaths.ResponseSizeLimit[0].AllowedContentTypes[0]",
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.PersistGraphQL[0].Path",
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.PersistGraphQL[0].Method",
 		"APIDefinition.VersionData.Versions[0].ExtendedPaths.PersistGraphQL[0].Operation",
@@ -249,6 +262,7 @@ func TestOAS_ExtractTo_ResetAPIDefinition(t *testing.T) {
 		"APIDefinition.Proxy.Transport.SSLForceCommonNameCheck",
 		"APIDefinition.Proxy.Transport.ProxyURL",
 		"APIDefinition.DisableQuota",
+		"APIDefinition.JSVMEngine",
 		"APIDefinition.SessionLifetimeRespectsKeyExpiration",
 		"APIDefinition.SessionLifetime",
 		"APIDefinition.AuthProvider.Name",
@@ -309,6 +323,38 @@ func TestOAS_ExtractTo_ResetAPIDefinition(t *testing.T) {
 		"APIDefinition.GraphQL.Supergraph.GlobalHeaders[0]",
 		"APIDefinition.GraphQL.Supergraph.DisableQueryBatching",
 		"APIDefinition.GraphQL.Introspection.Disabled",
+		"APIDefinition.GraphQL.Cache.Enabled",
+		"APIDefinition.GraphQL.Cache.Timeout",
+		"APIDefinition.GraphQL.Cache.CacheByClaims[0]",
+		"APIDefinition.GraphQL.Cache.UseCacheControlDirectives",
+		"APIDefinition.GraphQL.Cache.Shared",
+		"APIDefinition.GraphQL.CostAnalysis.Enabled",
+		"APIDefinition.GraphQL.CostAnalysis.TypeCosts[0]",
+		"APIDefinition.GraphQL.CostAnalysis.FieldCosts[0].Weight",
+		"APIDefinition.GraphQL.CostAnalysis.FieldCosts[0].AssumedSize",
+		"APIDefinition.GraphQL.CostAnalysis.FieldCosts[0].SlicingArguments[0]",
+		"APIDefinition.GraphQL.CostAnalysis.DefaultListSize",
+		"APIDefinition.GraphQL.CostAnalysis.RateLimitByCost",
+		"APIDefinition.GraphQL.PersistedQueries.Enabled",
+		"APIDefinition.GraphQL.PersistedQueries.TTL",
+		"APIDefinition.GraphQL.PersistedQueries.TrustedDocumentsOnly",
+		"APIDefinition.GraphQL.SchemaRegistry.Enabled",
+		"APIDefinition.GraphQL.SchemaRegistry.MaxVersions",
+		"APIDefinition.GraphQL.SchemaRegistry.BlockBreakingChanges",
+		"APIDefinition.GraphQL.Batching.Enabled",
+		"APIDefinition.GraphQL.Batching.MaxOperations",
+		"APIDefinition.GraphQL.Deduplication.Enabled",
+		"APIDefinition.GRPC.Enabled",
+		"APIDefinition.GRPC.AllowedMethods[0]",
+		"APIDefinition.GRPC.MethodRateLimits[0].Disabled",
+		"APIDefinition.GRPC.MethodRateLimits[0].Method",
+		"APIDefinition.GRPC.MethodRateLimits[0].Rate",
+		"APIDefinition.GRPC.MethodRateLimits[0].Per",
+		"APIDefinition.GRPC.Transcoding.Enabled",
+		"APIDefinition.GRPC.Transcoding.DescriptorSet",
+		"APIDefinition.GRPC.Transcoding.DescriptorSetPath",
+		"APIDefinition.GRPC.Transcoding.Services[0]",
+		"APIDefinition.GRPC.Web.Enabled",
 		"APIDefinition.AnalyticsPlugin.Enabled",
 		"APIDefinition.AnalyticsPlugin.PluginPath",
 		"APIDefinition.AnalyticsPlugin.FuncName",
@@ -58,6 +58,9 @@ type Operation struct {
 	// ValidateRequest contains the request validation configuration.
 	ValidateRequest *ValidateRequest `bson:"validateRequest,omitempty" json:"validateRequest,omitempty"`
 
+	// ValidateResponse contains the upstream response validation configuration.
+	ValidateResponse *ValidateResponse `bson:"validateResponse,omitempty" json:"validateResponse,omitempty"`
+
 	// MockResponse contains the mock response configuration.
 	MockResponse *MockResponse `bson:"mockResponse,omitempty" json:"mockResponse,omitempty"`
 
@@ -664,6 +667,30 @@ func (v *ValidateRequest) Import(enabled bool) {
 	v.ErrorResponseCode = http.StatusUnprocessableEntity
 }
 
+const (
+	// ValidateResponseModeLog logs responses that fail validation and sends them to the client unchanged.
+	ValidateResponseModeLog = "log"
+	// ValidateResponseModeEvent fires a ResponseValidationFailed event for responses that fail validation
+	// and sends them to the client unchanged.
+	ValidateResponseModeEvent = "event"
+	// ValidateResponseModeBlock replaces responses that fail validation with a 502 Bad Gateway.
+	ValidateResponseModeBlock = "block"
+)
+
+// ValidateResponse holds configuration required for validating upstream responses
+// against the response definitions of the OAS operation.
+type ValidateResponse struct {
+	// Enabled is a boolean flag, if set to `true`, it enables response validation.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Mode is the action taken when a response fails validation. Valid values are:
+	// - `log`: the failure is logged and the response is sent unchanged,
+	// - `event`: the failure is logged, a `ResponseValidationFailed` event is fired and the response is sent unchanged,
+	// - `block`: the failure is logged, a `ResponseValidationFailed` event is fired and the response is replaced with a 502.
+	// If unset, defaults to `log`.
+	Mode string `bson:"mode,omitempty" json:"mode,omitempty"`
+}
+
 func convertSchema(mapSchema map[string]interface{}) (*openapi3.Schema, error) {
 	bytes, err := json.Marshal(mapSchema)
 	if err != nil {
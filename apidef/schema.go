@@ -84,6 +84,10 @@ const Schema = `{
 		"custom_middleware_bundle_disabled": {
            	"type": "boolean"
         },
+        "jsvm_engine": {
+            "type": "string",
+            "enum": ["", "otto", "goja"]
+        },
         "jwt_policy_field_name": {
             "type": "string"
         },
@@ -493,6 +497,71 @@ const Schema = `{
             "is_enabled"
         ]
         },
+        "grpc": {
+            "type": ["object", "null"],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "allowed_methods": {
+                    "type": ["array", "null"],
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "method_rate_limits": {
+                    "type": ["array", "null"],
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "disabled": {
+                                "type": "boolean"
+                            },
+                            "method": {
+                                "type": "string"
+                            },
+                            "rate": {
+                                "type": "number"
+                            },
+                            "per": {
+                                "type": "number"
+                            }
+                        },
+                        "required": [
+                            "method"
+                        ]
+                    }
+                },
+                "transcoding": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "descriptor_set": {
+                            "type": "string"
+                        },
+                        "descriptor_set_path": {
+                            "type": "string"
+                        },
+                        "services": {
+                            "type": ["array", "null"],
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "web": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        }
+                    }
+                }
+            }
+        },
         "graphql": {
             "type": ["object", "null"],
             "properties": {
@@ -590,6 +659,8 @@ const Schema = `{
                                     "enum": [
                                         "REST",
                                         "GraphQL",
+                                        "gRPC",
+                                        "SQL",
                                         ""
                                     ]
                                 },
@@ -723,6 +794,121 @@ const Schema = `{
                         }
                     }
                 },
+                "cache": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "timeout": {
+                            "type": "integer"
+                        },
+                        "cache_by_claims": {
+                            "type": ["array", "null"],
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "use_cache_control_directives": {
+                            "type": "boolean"
+                        },
+                        "shared": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "cost_analysis": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "type_costs": {
+                            "type": ["object", "null"],
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        },
+                        "field_costs": {
+                            "type": ["object", "null"],
+                            "additionalProperties": {
+                                "type": "object",
+                                "properties": {
+                                    "weight": {
+                                        "type": "integer"
+                                    },
+                                    "assumed_size": {
+                                        "type": "integer",
+                                        "minimum": 0
+                                    },
+                                    "slicing_arguments": {
+                                        "type": ["array", "null"],
+                                        "items": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            }
+                        },
+                        "default_list_size": {
+                            "type": "integer",
+                            "minimum": 0
+                        },
+                        "rate_limit_by_cost": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "persisted_queries": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "ttl": {
+                            "type": "integer",
+                            "minimum": 0
+                        },
+                        "trusted_documents_only": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "schema_registry": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "max_versions": {
+                            "type": "integer",
+                            "minimum": 0
+                        },
+                        "block_breaking_changes": {
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "batching": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        },
+                        "max_operations": {
+                            "type": "integer",
+                            "minimum": 0
+                        }
+                    }
+                },
+                "deduplication": {
+                    "type": ["object", "null"],
+                    "properties": {
+                        "enabled": {
+                            "type": "boolean"
+                        }
+                    }
+                },
                 "playground": {
                     "type": ["object", "null"],
                     "properties": {
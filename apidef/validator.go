@@ -6,6 +6,8 @@ import (
 	"net"
 	"sort"
 	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/expression"
 )
 
 type ValidationResult struct {
@@ -57,6 +59,7 @@ var DefaultValidationRuleSet = ValidationRuleSet{
 	&RuleAtLeastEnableOneAuthSource{},
 	&RuleValidateIPList{},
 	&RuleValidateEnforceTimeout{},
+	&RuleValidateTransformExpressions{},
 }
 
 func Validate(definition *APIDefinition, ruleSet ValidationRuleSet) ValidationResult {
@@ -199,3 +202,25 @@ func (r *RuleValidateEnforceTimeout) Validate(apiDef *APIDefinition, validationR
 		}
 	}
 }
+
+var ErrInvalidTransformExpression = "invalid transform expression for %s %s: %v"
+
+type RuleValidateTransformExpressions struct{}
+
+func (r *RuleValidateTransformExpressions) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		metas := append([]TransformExpressionMeta{}, vInfo.ExtendedPaths.TransformExpression...)
+		metas = append(metas, vInfo.ExtendedPaths.TransformExpressionResponse...)
+
+		for _, meta := range metas {
+			if meta.Disabled {
+				continue
+			}
+
+			if _, err := expression.Compile(meta.Expression); err != nil {
+				validationResult.IsValid = false
+				validationResult.AppendError(fmt.Errorf(ErrInvalidTransformExpression, meta.Method, meta.Path, err))
+			}
+		}
+	}
+}
@@ -416,3 +416,47 @@ func TestRuleValidateEnforceTimeout_Validate(t *testing.T) {
 		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
 	}
 }
+
+func TestRuleValidateTransformExpressions_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateTransformExpressions{},
+	}
+
+	getAPIDef := func(metas []TransformExpressionMeta) *APIDefinition {
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name: "Default",
+						ExtendedPaths: ExtendedPathsSet{
+							TransformExpressionResponse: metas,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("valid expression", func(t *testing.T) {
+		result := Validate(getAPIDef([]TransformExpressionMeta{
+			{Path: "/get", Method: http.MethodGet, Expression: `{"name": body.name}`},
+		}), ruleSet)
+		assert.True(t, result.IsValid)
+	})
+
+	t.Run("disabled invalid expression", func(t *testing.T) {
+		result := Validate(getAPIDef([]TransformExpressionMeta{
+			{Disabled: true, Path: "/get", Method: http.MethodGet, Expression: `{`},
+		}), ruleSet)
+		assert.True(t, result.IsValid)
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		result := Validate(getAPIDef([]TransformExpressionMeta{
+			{Path: "/get", Method: http.MethodGet, Expression: `unknown.name`},
+		}), ruleSet)
+		assert.False(t, result.IsValid)
+		assert.ErrorContains(t, result.FirstError(), "invalid transform expression for GET /get")
+		assert.ErrorContains(t, result.FirstError(), "undeclared reference to 'unknown'")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -13,8 +14,8 @@ import (
 	"io/ioutil"
 	"os"
 
-	"github.com/TykTechnologies/goverify"
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/crypto"
 	logger "github.com/TykTechnologies/tyk/log"
 
 	kingpin "github.com/alecthomas/kingpin/v2"
@@ -47,6 +48,7 @@ type Bundler struct {
 	bundlePath   *string
 	skipSigning  *bool
 	manifestPath *string
+	sha256       *bool
 }
 
 // Bundle is the entrypoint function for this subcommand.
@@ -59,6 +61,7 @@ func (b *Bundler) Build(ctx *kingpin.ParseContext) error {
 	manifestPath := *b.manifestPath
 	bundlePath := *b.bundlePath
 	skipSigning := *b.skipSigning
+	useSHA256 := *b.sha256
 	key := *b.keyPath
 
 	log.Infof("Building bundle using '%s'", manifestPath)
@@ -84,8 +87,13 @@ func (b *Bundler) Build(ctx *kingpin.ParseContext) error {
 		return err
 	}
 
-	// Compute the checksum and append it to the manifest data structure:
-	manifest.Checksum = fmt.Sprintf("%x", md5.Sum(bundleBuf.Bytes()))
+	// Compute the checksum and append it to the manifest data structure, SHA-256 checksums
+	// are only accepted by gateways supporting them:
+	if useSHA256 {
+		manifest.Checksum = fmt.Sprintf("sha256:%x", sha256.Sum256(bundleBuf.Bytes()))
+	} else {
+		manifest.Checksum = fmt.Sprintf("%x", md5.Sum(bundleBuf.Bytes()))
+	}
 
 	if key == "" {
 		if skipSigning {
@@ -146,7 +154,7 @@ func (b *Bundler) Build(ctx *kingpin.ParseContext) error {
 }
 
 func (b *Bundler) sign(key string, manifest *apidef.BundleManifest, bundle *bytes.Buffer) (err error) {
-	signer, err := goverify.LoadPrivateKeyFromFile(key)
+	signer, err := crypto.LoadSigner(key)
 	if err != nil {
 		return err
 	}
@@ -208,9 +216,10 @@ func AddTo(app *kingpin.Application) {
 	cmd := app.Command(cmdName, cmdDesc)
 
 	buildCmd := cmd.Command("build", "Build a new plugin bundle using a manifest file and its specified files")
-	bundler.keyPath = buildCmd.Flag("key", "Key for bundle signature").Short('k').String()
+	bundler.keyPath = buildCmd.Flag("key", "RSA, ECDSA or Ed25519 private key for bundle signature").Short('k').String()
 	bundler.bundlePath = buildCmd.Flag("output", "Output file").Short('o').Default(defaultBundlePath).String()
 	bundler.skipSigning = buildCmd.Flag("skip-signing", "Skip bundle signing").Short('y').Bool()
 	bundler.manifestPath = buildCmd.Flag("manifest", "Path to manifest file").Default(defaultManifestPath).Short('m').String()
+	bundler.sha256 = buildCmd.Flag("sha256", "Use a SHA-256 bundle checksum instead of MD5, requires a gateway supporting it").Bool()
 	buildCmd.Action(bundler.Build)
 }
@@ -248,6 +248,10 @@ type AnalyticsConfigConfig struct {
 
 	// Determines the serialization engine for analytics. Available options: msgpack, and protobuf. By default, msgpack.
 	SerializerType string `json:"serializer_type"`
+
+	// Set this to `true` to store a record of each GraphQL operation, with its fields and data source calls, as JSON in the `tyk-graphql-analytics` Redis list.
+	// The list is expired like the analytics records, after `storage_expiration_time` seconds unless it is processed first.
+	EnableGraphQLOperationRecords bool `json:"enable_graphql_operation_records"`
 }
 
 type HealthCheckConfig struct {
@@ -552,11 +556,65 @@ type CoProcessConfig struct {
 	// Authority used in GRPC connection
 	GRPCAuthority string `json:"grpc_authority"`
 
+	// Additional gRPC plugin server addresses. Together with `coprocess_grpc_server` they form a pool
+	// requests are balanced across.
+	CoProcessGRPCServers []string `json:"coprocess_grpc_servers"`
+
+	// Selects the gRPC plugin server for each request: `round_robin` (default) or `least_loaded`,
+	// which picks the server with the fewest requests in flight.
+	GRPCLoadBalancing string `json:"grpc_load_balancing"`
+
+	// Interval, in seconds, between gRPC health checks (`grpc.health.v1.Health/Check`) of the plugin servers.
+	// Servers reporting they aren't serving, or failing the check, get no requests until they're healthy again.
+	// Servers not implementing the health service are considered healthy. Set to 0 to disable health checks.
+	GRPCHealthCheckInterval int `json:"grpc_health_check_interval"`
+
+	// Deadline, in milliseconds, of calls to the gRPC plugin server. Defaults to no deadline.
+	GRPCTimeout int `json:"grpc_timeout"`
+
+	// Deadlines, in milliseconds, of calls for specific hook types, overriding `grpc_timeout`.
+	// Keys are `pre`, `auth_check`, `post_key_auth`, `post` and `response`.
+	GRPCHookTimeouts map[string]int `json:"grpc_hook_timeouts"`
+
 	// Sets the path to built-in Tyk modules. This will be part of the Python module lookup path. The value used here is the default one for most installations.
 	PythonPathPrefix string `json:"python_path_prefix"`
 
 	// If you have multiple Python versions installed you can specify your version.
 	PythonVersion string `json:"python_version"`
+
+	// Maximum memory, in megabytes, a single invocation of a WebAssembly plugin can use. Defaults to 16.
+	WasmMemoryLimit int `json:"wasm_memory_limit"`
+
+	// Maximum time, in milliseconds, a single invocation of a WebAssembly plugin can run for. Defaults to 1000.
+	// Plugins running for longer are interrupted and the request fails.
+	WasmExecutionTimeout int `json:"wasm_execution_timeout"`
+}
+
+// BundleS3Config configures the getter for bundles stored in S3-compatible object stores.
+type BundleS3Config struct {
+	// Endpoint of the object store, e.g. `https://minio.example.com:9000`. Defaults to AWS S3.
+	Endpoint string `json:"endpoint"`
+
+	// Region of the bucket. Defaults to `us-east-1`.
+	Region string `json:"region"`
+
+	// Access key ID and secret access key used to sign requests. When empty, the AWS default
+	// credential chain (environment, shared config, instance roles) is used.
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+
+	// Use path-style addressing (`endpoint/bucket/key`), required by most S3-compatible stores.
+	UsePathStyle bool `json:"use_path_style"`
+}
+
+// BundleOCIConfig configures the getter for bundles stored as OCI artifacts.
+type BundleOCIConfig struct {
+	// Username and password for the registry. Anonymous access is used when empty.
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Connect to the registry over plain HTTP.
+	PlainHTTP bool `json:"plain_http"`
 }
 
 type CertificatesConfig struct {
@@ -969,12 +1027,38 @@ type Config struct {
 	// Disable TLS validation for bundle URLs
 	BundleInsecureSkipVerify bool `bson:"bundle_insecure_skip_verify" json:"bundle_insecure_skip_verify"`
 
+	// Path to a PEM encoded RSA, ECDSA or Ed25519 public key used to verify bundle signatures. Defaults to `public_key_path`.
+	// RSA and ECDSA signatures are made over the SHA-256 digest of the bundle files, Ed25519 signatures over the files themselves.
+	BundlePublicKeyPath string `bson:"bundle_public_key_path" json:"bundle_public_key_path"`
+
+	// Requires bundle manifests to use SHA-256 checksums (`sha256:<hex>`), rejecting legacy MD5 checksums.
+	BundleRequireSHA256 bool `bson:"bundle_require_sha256" json:"bundle_require_sha256"`
+
+	// Enables cosign-style verification of bundle archives. The base64 encoded signature, as produced by
+	// `cosign sign-blob --output-signature`, is fetched from the bundle location with a `.sig` suffix and verified
+	// with `bundle_public_key_path` before the bundle is extracted. For OCI bundles, the signature is read from the `<tag>.sig` artifact,
+	// or `sha256-<digest>.sig` when the bundle is referenced by digest.
+	BundleCosignVerification bool `bson:"bundle_cosign_verification" json:"bundle_cosign_verification"`
+
+	// Configures access to S3-compatible object stores, used when `bundle_base_url` has the `s3://bucket/prefix` form.
+	BundleS3 BundleS3Config `bson:"bundle_s3" json:"bundle_s3"`
+
+	// Configures access to OCI registries, used when `bundle_base_url` has the `oci://registry/repository` form.
+	// Bundles are then referenced by tag or digest, e.g. `bundle:v1` or `bundle@sha256:<hex>`.
+	BundleOCI BundleOCIConfig `bson:"bundle_oci" json:"bundle_oci"`
+
 	// Set to true if you are using JSVM custom middleware or virtual endpoints.
 	EnableJSVM bool `json:"enable_jsvm"`
 
 	// Set the execution timeout for JSVM plugins and virtal endpoints
 	JSVMTimeout int `json:"jsvm_timeout"`
 
+	// Sets the default JavaScript engine for JSVM plugins and virtual endpoints, APIs can override it with `jsvm_engine`.
+	// Valid values are `otto` (default, ES5) and `goja` (ES2020+). With goja, request bodies are decoded as UTF-8
+	// and runtimes are reused across requests: global variables are reset after each request, while changes made
+	// to the objects they reference stay visible to later requests.
+	JSVMEngine apidef.JSVMEngine `json:"jsvm_engine"`
+
 	// Disable virtual endpoints and the code will not be loaded into the VM when the API definition initialises.
 	// This is useful for systems where you want to avoid having third-party code run.
 	DisableVirtualPathBlobs bool `json:"disable_virtual_path_blobs"`
@@ -53,6 +53,22 @@ const (
 	// CacheOptions holds cache options required for cache writer middleware.
 	CacheOptions
 	OASDefinition
+
+	// ResponseLimitExceeded holds the analytics tag of the response limit an upstream response broke.
+	ResponseLimitExceeded
+
+	// GRPCTranscodedCall holds the gRPC call a REST/JSON request was transcoded to.
+	GRPCTranscodedCall
+	// GRPCWebCall holds the gRPC-Web request bridged to a native gRPC call.
+	GRPCWebCall
+	// GraphQLQueryCost holds the estimated cost of the GraphQL operation.
+	GraphQLQueryCost
+	// GraphQLMaskedFields holds the restricted fields removed from the GraphQL operation.
+	GraphQLMaskedFields
+	// GraphQLOperationStats holds the stats extracted from the GraphQL operation and its response.
+	GraphQLOperationStats
+	// GraphQLSubscriptionConn holds the tracker of the subscriptions of a GraphQL websocket connection.
+	GraphQLSubscriptionConn
 )
 
 func ctxSetSession(r *http.Request, s *user.SessionState, scheduleUpdate bool, hashKey bool) {
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"encoding/json"
 	"fmt"
 	mathrand "math/rand"
 	"strings"
@@ -14,12 +15,16 @@ import (
 	maxminddb "github.com/oschwald/maxminddb-golang"
 
 	"github.com/TykTechnologies/tyk/config"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
 const analyticsKeyName = "tyk-system-analytics"
 
+// graphQLAnalyticsKeyName is the list holding the operation records of GraphQL APIs.
+const graphQLAnalyticsKeyName = "tyk-graphql-analytics"
+
 const (
 	recordsBufferFlushInterval       = 200 * time.Millisecond
 	recordsBufferForcedFlushInterval = 1 * time.Second
@@ -47,6 +52,7 @@ type RedisAnalyticsHandler struct {
 	GeoIPDB                     *maxminddb.Reader
 	globalConf                  config.Config
 	recordsChan                 chan *analytics.AnalyticsRecord
+	graphQLRecordsChan          chan *graphqlinternal.OperationRecord
 	workerBufferSize            uint64
 	shouldStop                  uint32
 	poolWg                      sync.WaitGroup
@@ -59,6 +65,8 @@ type RedisAnalyticsHandler struct {
 	// testing purposes
 	mockEnabled   bool
 	mockRecordHit func(record *analytics.AnalyticsRecord)
+
+	mockRecordGraphQLOperation func(record *graphqlinternal.OperationRecord)
 }
 
 func (r *RedisAnalyticsHandler) Init() {
@@ -86,11 +94,17 @@ func (r *RedisAnalyticsHandler) Init() {
 // Start initialize the records channel and spawn the record workers
 func (r *RedisAnalyticsHandler) Start() {
 	r.recordsChan = make(chan *analytics.AnalyticsRecord, r.globalConf.AnalyticsConfig.RecordsBufferSize)
+	r.graphQLRecordsChan = make(chan *graphqlinternal.OperationRecord, r.globalConf.AnalyticsConfig.RecordsBufferSize)
 	atomic.SwapUint32(&r.shouldStop, 0)
 	for i := 0; i < r.Gw.GetConfig().AnalyticsConfig.PoolSize; i++ {
 		r.poolWg.Add(1)
 		go r.recordWorker()
 	}
+
+	if r.globalConf.AnalyticsConfig.EnableGraphQLOperationRecords {
+		r.poolWg.Add(1)
+		go r.graphQLRecordWorker()
+	}
 }
 
 // Stop stops the analytics processing
@@ -101,6 +115,7 @@ func (r *RedisAnalyticsHandler) Stop() {
 	// close channel to stop workers
 	r.mu.Lock()
 	close(r.recordsChan)
+	close(r.graphQLRecordsChan)
 	r.mu.Unlock()
 
 	// wait for all workers to be done
@@ -135,6 +150,67 @@ func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) err
 	return nil
 }
 
+// RecordGraphQLOperation will store the operation record of a GraphQL request in Redis, if enabled
+func (r *RedisAnalyticsHandler) RecordGraphQLOperation(record *graphqlinternal.OperationRecord) {
+	if !r.globalConf.AnalyticsConfig.EnableGraphQLOperationRecords {
+		return
+	}
+
+	if r.mockEnabled {
+		if r.mockRecordGraphQLOperation != nil {
+			r.mockRecordGraphQLOperation(record)
+		}
+		return
+	}
+
+	if atomic.LoadUint32(&r.shouldStop) > 0 {
+		return
+	}
+
+	r.mu.Lock()
+	r.graphQLRecordsChan <- record
+	r.mu.Unlock()
+}
+
+// graphQLRecordWorker stores the GraphQL operation records as JSON, in batches like the analytics records.
+func (r *RedisAnalyticsHandler) graphQLRecordWorker() {
+	defer r.poolWg.Done()
+
+	recordsBuffer := make([][]byte, 0, r.workerBufferSize)
+	flushTicker := time.NewTicker(recordsBufferFlushInterval)
+	defer flushTicker.Stop()
+
+	flush := func() {
+		if len(recordsBuffer) > 0 {
+			r.Store.AppendToSetPipelined(graphQLAnalyticsKeyName, recordsBuffer)
+			recordsBuffer = recordsBuffer[:0]
+		}
+	}
+
+	for {
+		select {
+		case record, ok := <-r.graphQLRecordsChan:
+			if !ok {
+				flush()
+				return
+			}
+
+			encoded, err := json.Marshal(record)
+			if err != nil {
+				log.WithError(err).Error("Error encoding GraphQL analytics data")
+				continue
+			}
+
+			recordsBuffer = append(recordsBuffer, encoded)
+			if uint64(len(recordsBuffer)) >= r.workerBufferSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		}
+	}
+}
+
 func (r *RedisAnalyticsHandler) recordWorker() {
 	defer r.poolWg.Done()
 
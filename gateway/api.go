@@ -49,6 +49,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/config"
 
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/redis"
 	"github.com/TykTechnologies/tyk/internal/uuid"
@@ -1003,7 +1004,17 @@ func (gw *Gateway) handleGetAPIListOAS(modePublic bool) (interface{}, int) {
 	apisList := []oas.OAS{}
 
 	for _, apiSpec := range gw.apisByID {
-		if apiSpec.IsOAS {
+		if apiSpec.grpcTranscoder != nil {
+			apiOAS, err := apiSpec.grpcTranscodedOAS()
+			if err != nil {
+				log.WithError(err).Error("Couldn't generate OAS of gRPC transcoding API")
+				continue
+			}
+			if modePublic {
+				apiOAS.RemoveTykExtension()
+			}
+			apisList = append(apisList, *apiOAS)
+		} else if apiSpec.IsOAS {
 			apiSpec.OAS.Fill(*apiSpec.APIDefinition)
 			if modePublic {
 				apiSpec.OAS.RemoveTykExtension()
@@ -1017,7 +1028,14 @@ func (gw *Gateway) handleGetAPIListOAS(modePublic bool) (interface{}, int) {
 
 func (gw *Gateway) handleGetAPI(apiID string, oasEndpoint bool) (interface{}, int) {
 	if spec := gw.getApiSpec(apiID); spec != nil {
-		if oasEndpoint && spec.IsOAS {
+		if oasEndpoint && spec.grpcTranscoder != nil {
+			apiOAS, err := spec.grpcTranscodedOAS()
+			if err != nil {
+				log.WithError(err).Error("Couldn't generate OAS of gRPC transcoding API")
+				return apiError("Couldn't generate OAS"), http.StatusInternalServerError
+			}
+			return apiOAS, http.StatusOK
+		} else if oasEndpoint && spec.IsOAS {
 			spec.OAS.Fill(*spec.APIDefinition)
 			return &spec.OAS, http.StatusOK
 		} else if oasEndpoint && !spec.IsOAS {
@@ -1209,6 +1227,20 @@ func (gw *Gateway) handleUpdateApi(apiID string, r *http.Request, fs afero.Fs, o
 		return *validationErr, http.StatusBadRequest
 	}
 
+	if spec.GraphQL.Enabled && spec.GraphQL.SchemaRegistry.BlockBreakingChanges && newDef.GraphQL.Enabled {
+		changes, err := graphQLSchemaChanges(spec, &newDef)
+		if err != nil {
+			return apiError("Invalid GraphQL schema: " + err.Error()), http.StatusBadRequest
+		}
+
+		if graphqlinternal.HasBreakingChanges(changes) {
+			return graphQLSchemaBreakingChangesError{
+				apiStatusMessage: apiError("GraphQL schema has breaking changes"),
+				Changes:          changes,
+			}, http.StatusBadRequest
+		}
+	}
+
 	if oasEndpoint && spec.IsOAS {
 		updateOASServers(spec, gw.GetConfig(), &newDef, &oasObj)
 		newDef.IsOAS = true
@@ -3135,6 +3167,39 @@ func ctxSetDoNotTrack(r *http.Request, b bool) {
 	setCtxValue(r, ctx.DoNotTrackThisEndpoint, b)
 }
 
+func ctxGetResponseLimitExceeded(r *http.Request) string {
+	if v := r.Context().Value(ctx.ResponseLimitExceeded); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+func ctxSetResponseLimitExceeded(r *http.Request, tag string) {
+	setCtxValue(r, ctx.ResponseLimitExceeded, tag)
+}
+
+func ctxGetGRPCTranscodedCall(r *http.Request) *grpcTranscodedCall {
+	if v := r.Context().Value(ctx.GRPCTranscodedCall); v != nil {
+		return v.(*grpcTranscodedCall)
+	}
+	return nil
+}
+
+func ctxSetGRPCTranscodedCall(r *http.Request, call *grpcTranscodedCall) {
+	setCtxValue(r, ctx.GRPCTranscodedCall, call)
+}
+
+func ctxGetGRPCWebCall(r *http.Request) *grpcWebCall {
+	if v := r.Context().Value(ctx.GRPCWebCall); v != nil {
+		return v.(*grpcWebCall)
+	}
+	return nil
+}
+
+func ctxSetGRPCWebCall(r *http.Request, call *grpcWebCall) {
+	setCtxValue(r, ctx.GRPCWebCall, call)
+}
+
 func ctxGetVersionInfo(r *http.Request) *apidef.VersionInfo {
 	if v := r.Context().Value(ctx.VersionData); v != nil {
 		return v.(*apidef.VersionInfo)
@@ -3269,6 +3334,22 @@ func ctxGetGraphQLRequestV2(r *http.Request) (gqlRequest *gqlv2.Request) {
 	return nil
 }
 
+// ctxGetGraphQLOperation returns the GraphQL request of any engine version as a v1 request.
+func ctxGetGraphQLOperation(r *http.Request) *gql.Request {
+	if gqlRequest := ctxGetGraphQLRequest(r); gqlRequest != nil {
+		return gqlRequest
+	}
+
+	if gqlRequest := ctxGetGraphQLRequestV2(r); gqlRequest != nil {
+		return &gql.Request{
+			OperationName: gqlRequest.OperationName,
+			Variables:     gqlRequest.Variables,
+			Query:         gqlRequest.Query,
+		}
+	}
+	return nil
+}
+
 func ctxSetGraphQLIsWebSocketUpgrade(r *http.Request, isWebSocketUpgrade bool) {
 	setCtxValue(r, ctx.GraphQLIsWebSocketUpgrade, isWebSocketUpgrade)
 }
@@ -3283,6 +3364,36 @@ func ctxGetGraphQLIsWebSocketUpgrade(r *http.Request) (isWebSocketUpgrade bool)
 	return false
 }
 
+func ctxSetGraphQLQueryCost(r *http.Request, cost int) {
+	setCtxValue(r, ctx.GraphQLQueryCost, cost)
+}
+
+// ctxGetGraphQLQueryCost returns the estimated cost of the GraphQL operation, if cost analysis is enabled.
+func ctxGetGraphQLQueryCost(r *http.Request) (cost int, ok bool) {
+	cost, ok = r.Context().Value(ctx.GraphQLQueryCost).(int)
+	return cost, ok
+}
+
+func ctxSetGraphQLMaskedFields(r *http.Request, fields []graphqlinternal.RestrictedField) {
+	setCtxValue(r, ctx.GraphQLMaskedFields, fields)
+}
+
+// ctxGetGraphQLMaskedFields returns the restricted fields removed from the GraphQL operation, to be resolved to null.
+func ctxGetGraphQLMaskedFields(r *http.Request) []graphqlinternal.RestrictedField {
+	fields, _ := r.Context().Value(ctx.GraphQLMaskedFields).([]graphqlinternal.RestrictedField)
+	return fields
+}
+
+func ctxSetGraphQLOperationStats(r *http.Request, stats *graphQLOperationStats) {
+	setCtxValue(r, ctx.GraphQLOperationStats, stats)
+}
+
+// ctxGetGraphQLOperationStats returns the stats of the GraphQL operation, if they were already extracted.
+func ctxGetGraphQLOperationStats(r *http.Request) *graphQLOperationStats {
+	stats, _ := r.Context().Value(ctx.GraphQLOperationStats).(*graphQLOperationStats)
+	return stats
+}
+
 func ctxGetDefaultVersion(r *http.Request) bool {
 	return r.Context().Value(ctx.VersionDefault) != nil
 }
@@ -3477,3 +3588,13 @@ func updateOASServers(spec *APISpec, conf config.Config, apiDef *apidef.APIDefin
 	newAPIURL := getAPIURL(*apiDef, conf)
 	oasObj.UpdateServers(newAPIURL, oldAPIURL)
 }
+
+func ctxSetGraphQLSubscriptionConn(r *http.Request, conn *graphQLSubscriptionConn) {
+	setCtxValue(r, ctx.GraphQLSubscriptionConn, conn)
+}
+
+// ctxGetGraphQLSubscriptionConn returns the subscription tracker of a GraphQL websocket connection.
+func ctxGetGraphQLSubscriptionConn(c context.Context) *graphQLSubscriptionConn {
+	conn, _ := c.Value(ctx.GraphQLSubscriptionConn).(*graphQLSubscriptionConn)
+	return conn
+}
@@ -23,7 +23,10 @@ import (
 
 	"github.com/getkin/kin-openapi/routers"
 
+	"github.com/TykTechnologies/tyk/internal/cache"
+	"github.com/TykTechnologies/tyk/internal/expression"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 
 	"github.com/getkin/kin-openapi/routers/gorillamux"
@@ -92,6 +95,9 @@ const (
 	GoPlugin
 	PersistGraphQL
 	RateLimit
+	TransformedExpression
+	TransformedExpressionResponse
+	ResponseSizeLimit
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -99,36 +105,39 @@ type RequestStatus string
 
 // Statuses of the request, all are false-y except StatusOk and StatusOkAndIgnore
 const (
-	VersionNotFound                RequestStatus = "Version information not found"
-	VersionDoesNotExist            RequestStatus = "This API version does not seem to exist"
-	VersionWhiteListStatusNotFound RequestStatus = "WhiteListStatus for path not found"
-	VersionExpired                 RequestStatus = "Api Version has expired, please check documentation or contact administrator"
-	APIExpired                     RequestStatus = "API has expired, please check documentation or contact administrator"
-	EndPointNotAllowed             RequestStatus = "Requested endpoint is forbidden"
-	StatusOkAndIgnore              RequestStatus = "Everything OK, passing and not filtering"
-	StatusOk                       RequestStatus = "Everything OK, passing"
-	StatusCached                   RequestStatus = "Cached path"
-	StatusTransform                RequestStatus = "Transformed path"
-	StatusTransformResponse        RequestStatus = "Transformed response"
-	StatusTransformJQ              RequestStatus = "Transformed path with JQ"
-	StatusTransformJQResponse      RequestStatus = "Transformed response with JQ"
-	StatusHeaderInjected           RequestStatus = "Header injected"
-	StatusMethodTransformed        RequestStatus = "Method Transformed"
-	StatusHeaderInjectedResponse   RequestStatus = "Header injected on response"
-	StatusRedirectFlowByReply      RequestStatus = "Exceptional action requested, redirecting flow!"
-	StatusHardTimeout              RequestStatus = "Hard Timeout enforced on path"
-	StatusCircuitBreaker           RequestStatus = "Circuit breaker enforced"
-	StatusURLRewrite               RequestStatus = "URL Rewritten"
-	StatusVirtualPath              RequestStatus = "Virtual Endpoint"
-	StatusRequestSizeControlled    RequestStatus = "Request Size Limited"
-	StatusRequestTracked           RequestStatus = "Request Tracked"
-	StatusRequestNotTracked        RequestStatus = "Request Not Tracked"
-	StatusValidateJSON             RequestStatus = "Validate JSON"
-	StatusValidateRequest          RequestStatus = "Validate Request"
-	StatusInternal                 RequestStatus = "Internal path"
-	StatusGoPlugin                 RequestStatus = "Go plugin"
-	StatusPersistGraphQL           RequestStatus = "Persist GraphQL"
-	StatusRateLimit                RequestStatus = "Rate Limited"
+	VersionNotFound                   RequestStatus = "Version information not found"
+	VersionDoesNotExist               RequestStatus = "This API version does not seem to exist"
+	VersionWhiteListStatusNotFound    RequestStatus = "WhiteListStatus for path not found"
+	VersionExpired                    RequestStatus = "Api Version has expired, please check documentation or contact administrator"
+	APIExpired                        RequestStatus = "API has expired, please check documentation or contact administrator"
+	EndPointNotAllowed                RequestStatus = "Requested endpoint is forbidden"
+	StatusOkAndIgnore                 RequestStatus = "Everything OK, passing and not filtering"
+	StatusOk                          RequestStatus = "Everything OK, passing"
+	StatusCached                      RequestStatus = "Cached path"
+	StatusTransform                   RequestStatus = "Transformed path"
+	StatusTransformResponse           RequestStatus = "Transformed response"
+	StatusTransformJQ                 RequestStatus = "Transformed path with JQ"
+	StatusTransformJQResponse         RequestStatus = "Transformed response with JQ"
+	StatusHeaderInjected              RequestStatus = "Header injected"
+	StatusMethodTransformed           RequestStatus = "Method Transformed"
+	StatusHeaderInjectedResponse      RequestStatus = "Header injected on response"
+	StatusRedirectFlowByReply         RequestStatus = "Exceptional action requested, redirecting flow!"
+	StatusHardTimeout                 RequestStatus = "Hard Timeout enforced on path"
+	StatusCircuitBreaker              RequestStatus = "Circuit breaker enforced"
+	StatusURLRewrite                  RequestStatus = "URL Rewritten"
+	StatusVirtualPath                 RequestStatus = "Virtual Endpoint"
+	StatusRequestSizeControlled       RequestStatus = "Request Size Limited"
+	StatusRequestTracked              RequestStatus = "Request Tracked"
+	StatusRequestNotTracked           RequestStatus = "Request Not Tracked"
+	StatusValidateJSON                RequestStatus = "Validate JSON"
+	StatusValidateRequest             RequestStatus = "Validate Request"
+	StatusInternal                    RequestStatus = "Internal path"
+	StatusGoPlugin                    RequestStatus = "Go plugin"
+	StatusPersistGraphQL              RequestStatus = "Persist GraphQL"
+	StatusRateLimit                   RequestStatus = "Rate Limited"
+	StatusTransformExpression         RequestStatus = "Transformed path with expression"
+	StatusTransformExpressionResponse RequestStatus = "Transformed response with expression"
+	StatusResponseSizeControlled      RequestStatus = "Response Size Limited"
 )
 
 // URLSpec represents a flattened specification for URLs, used to check if a proxy URL
@@ -137,32 +146,35 @@ const (
 type URLSpec struct {
 	spec *regexp.Regexp
 
-	Status                    URLStatus
-	MethodActions             map[string]apidef.EndpointMethodMeta
-	Whitelist                 apidef.EndPointMeta
-	Blacklist                 apidef.EndPointMeta
-	Ignored                   apidef.EndPointMeta
-	MockResponse              apidef.MockResponseMeta
-	CacheConfig               EndPointCacheMeta
-	TransformAction           TransformSpec
-	TransformResponseAction   TransformSpec
-	TransformJQAction         TransformJQSpec
-	TransformJQResponseAction TransformJQSpec
-	InjectHeaders             apidef.HeaderInjectionMeta
-	InjectHeadersResponse     apidef.HeaderInjectionMeta
-	HardTimeout               apidef.HardTimeoutMeta
-	CircuitBreaker            ExtendedCircuitBreakerMeta
-	URLRewrite                *apidef.URLRewriteMeta
-	VirtualPathSpec           apidef.VirtualMeta
-	RequestSize               apidef.RequestSizeMeta
-	MethodTransform           apidef.MethodTransformMeta
-	TrackEndpoint             apidef.TrackEndpointMeta
-	DoNotTrackEndpoint        apidef.TrackEndpointMeta
-	ValidatePathMeta          apidef.ValidatePathMeta
-	Internal                  apidef.InternalMeta
-	GoPluginMeta              GoPluginMiddleware
-	PersistGraphQL            apidef.PersistGraphQLMeta
-	RateLimit                 apidef.RateLimitMeta
+	Status                            URLStatus
+	MethodActions                     map[string]apidef.EndpointMethodMeta
+	Whitelist                         apidef.EndPointMeta
+	Blacklist                         apidef.EndPointMeta
+	Ignored                           apidef.EndPointMeta
+	MockResponse                      apidef.MockResponseMeta
+	CacheConfig                       EndPointCacheMeta
+	TransformAction                   TransformSpec
+	TransformResponseAction           TransformSpec
+	TransformJQAction                 TransformJQSpec
+	TransformJQResponseAction         TransformJQSpec
+	TransformExpressionAction         TransformExpressionSpec
+	TransformExpressionResponseAction TransformExpressionSpec
+	InjectHeaders                     apidef.HeaderInjectionMeta
+	InjectHeadersResponse             apidef.HeaderInjectionMeta
+	HardTimeout                       apidef.HardTimeoutMeta
+	CircuitBreaker                    ExtendedCircuitBreakerMeta
+	URLRewrite                        *apidef.URLRewriteMeta
+	VirtualPathSpec                   apidef.VirtualMeta
+	RequestSize                       apidef.RequestSizeMeta
+	ResponseSize                      apidef.ResponseSizeMeta
+	MethodTransform                   apidef.MethodTransformMeta
+	TrackEndpoint                     apidef.TrackEndpointMeta
+	DoNotTrackEndpoint                apidef.TrackEndpointMeta
+	ValidatePathMeta                  apidef.ValidatePathMeta
+	Internal                          apidef.InternalMeta
+	GoPluginMeta                      GoPluginMiddleware
+	PersistGraphQL                    apidef.PersistGraphQLMeta
+	RateLimit                         apidef.RateLimitMeta
 
 	IgnoreCase bool
 }
@@ -224,9 +236,30 @@ type APISpec struct {
 
 	GraphEngine graphengine.Engine
 
-	HasMock            bool
-	HasValidateRequest bool
-	OASRouter          routers.Router
+	HasMock             bool
+	HasValidateRequest  bool
+	HasValidateResponse bool
+	OASRouter           routers.Router
+
+	grpcTranscoder        *grpcTranscoder
+	graphqlCostCalculator *graphqlinternal.QueryCostCalculator
+
+	// responseFieldRules caches the compiled response field rules of the keys of the API by rule set,
+	// expiring the rule sets no longer in use.
+	responseFieldRules cache.Repository
+
+	// composedSupergraphSDL is the SDL of the supergraph composed at load time. It's kept
+	// out of the API definition, which holds the configuration as it was loaded.
+	composedSupergraphSDL string
+}
+
+// graphQLSchema returns the schema the GraphQL API is executed with.
+func (a *APISpec) graphQLSchema() string {
+	if a.composedSupergraphSDL != "" {
+		return a.composedSupergraphSDL
+	}
+
+	return a.GraphQL.Schema
 }
 
 // GetSessionLifetimeRespectsKeyExpiration returns a boolean to tell whether session lifetime should respect to key expiration or not.
@@ -267,7 +300,7 @@ func (s *APISpec) Unload() {
 	// release all other resources associated with spec
 
 	// JSVM object is a circular dependecy hell, but we can check if it initialized like this
-	if s.JSVM.VM != nil {
+	if s.JSVM.Ready() {
 		s.JSVM.DeInit()
 	}
 
@@ -947,11 +980,13 @@ func (a APIDefinitionLoader) compileTransformPathSpec(paths []apidef.TemplateMet
 		// Load the templates
 		var err error
 
-		switch stringSpec.TemplateData.Mode {
-		case apidef.UseFile:
+		switch {
+		case stringSpec.TemplateData.Conversion.Enabled:
+			log.Debug("-- Using body conversion, no template needed")
+		case stringSpec.TemplateData.Mode == apidef.UseFile:
 			log.Debug("-- Using File mode")
 			newTransformSpec.Template, err = a.loadFileTemplate(stringSpec.TemplateData.TemplateSource)
-		case apidef.UseBlob:
+		case stringSpec.TemplateData.Mode == apidef.UseBlob:
 			log.Debug("-- Blob mode")
 			newTransformSpec.Template, err = a.loadBlobTemplate(stringSpec.TemplateData.TemplateSource)
 		default:
@@ -1064,6 +1099,24 @@ func (a APIDefinitionLoader) compileRequestSizePathSpec(paths []apidef.RequestSi
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileResponseSizePathSpec(paths []apidef.ResponseSizeMeta, stat URLStatus, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled {
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		newSpec.ResponseSize = stringSpec
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.CircuitBreakerMeta, stat URLStatus, apiSpec *APISpec, conf config.Config) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
@@ -1343,6 +1396,41 @@ func (a APIDefinitionLoader) compileRateLimitPathsSpec(paths []apidef.RateLimitM
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileTransformExpressionPathSpec(paths []apidef.TransformExpressionMeta, stat URLStatus, apiSpec *APISpec, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	log.Debug("Checking for expression transform paths ...")
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled {
+			continue
+		}
+
+		program, err := expression.Compile(stringSpec.Expression)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"api_id": apiSpec.APIID,
+				"path":   stringSpec.Path,
+				"method": stringSpec.Method,
+			}).WithError(err).Error("Transform expression load failure! Skipping transformation")
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		newTransformSpec := TransformExpressionSpec{TransformExpressionMeta: stringSpec, Program: program}
+
+		if stat == TransformedExpression {
+			newSpec.TransformExpressionAction = newTransformSpec
+		} else {
+			newSpec.TransformExpressionResponseAction = newTransformSpec
+		}
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionInfo, apiSpec *APISpec, conf config.Config) ([]URLSpec, bool) {
 	// TODO: New compiler here, needs to put data into a different structure
 
@@ -1355,6 +1443,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	transformResponsePaths := a.compileTransformPathSpec(apiVersionDef.ExtendedPaths.TransformResponse, TransformedResponse, conf)
 	transformJQPaths := a.compileTransformJQPathSpec(apiVersionDef.ExtendedPaths.TransformJQ, TransformedJQ)
 	transformJQResponsePaths := a.compileTransformJQPathSpec(apiVersionDef.ExtendedPaths.TransformJQResponse, TransformedJQResponse)
+	transformExpressionPaths := a.compileTransformExpressionPathSpec(apiVersionDef.ExtendedPaths.TransformExpression, TransformedExpression, apiSpec, conf)
+	transformExpressionResponsePaths := a.compileTransformExpressionPathSpec(apiVersionDef.ExtendedPaths.TransformExpressionResponse, TransformedExpressionResponse, apiSpec, conf)
 	headerTransformPaths := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformHeader, HeaderInjected, conf)
 	headerTransformPathsOnResponse := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformResponseHeader, HeaderInjectedResponse, conf)
 	hardTimeouts := a.compileTimeoutPathSpec(apiVersionDef.ExtendedPaths.HardTimeouts, HardTimeout, conf)
@@ -1362,6 +1452,7 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	urlRewrites := a.compileURLRewritesPathSpec(apiVersionDef.ExtendedPaths.URLRewrite, URLRewrite, conf)
 	virtualPaths := a.compileVirtualPathsSpec(apiVersionDef.ExtendedPaths.Virtual, VirtualPath, apiSpec, conf)
 	requestSizes := a.compileRequestSizePathSpec(apiVersionDef.ExtendedPaths.SizeLimit, RequestSizeLimit, conf)
+	responseSizes := a.compileResponseSizePathSpec(apiVersionDef.ExtendedPaths.ResponseSizeLimit, ResponseSizeLimit, conf)
 	methodTransforms := a.compileMethodTransformSpec(apiVersionDef.ExtendedPaths.MethodTransforms, MethodTransformed, conf)
 	trackedPaths := a.compileTrackedEndpointPathsSpec(apiVersionDef.ExtendedPaths.TrackEndpoints, RequestTracked, conf)
 	unTrackedPaths := a.compileUnTrackedEndpointPathsSpec(apiVersionDef.ExtendedPaths.DoNotTrackEndpoints, RequestNotTracked, conf)
@@ -1381,12 +1472,15 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, transformResponsePaths...)
 	combinedPath = append(combinedPath, transformJQPaths...)
 	combinedPath = append(combinedPath, transformJQResponsePaths...)
+	combinedPath = append(combinedPath, transformExpressionPaths...)
+	combinedPath = append(combinedPath, transformExpressionResponsePaths...)
 	combinedPath = append(combinedPath, headerTransformPaths...)
 	combinedPath = append(combinedPath, headerTransformPathsOnResponse...)
 	combinedPath = append(combinedPath, hardTimeouts...)
 	combinedPath = append(combinedPath, circuitBreakers...)
 	combinedPath = append(combinedPath, urlRewrites...)
 	combinedPath = append(combinedPath, requestSizes...)
+	combinedPath = append(combinedPath, responseSizes...)
 	combinedPath = append(combinedPath, goPlugins...)
 	combinedPath = append(combinedPath, persistGraphQL...)
 	combinedPath = append(combinedPath, virtualPaths...)
@@ -1458,6 +1552,12 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusPersistGraphQL
 	case RateLimit:
 		return StatusRateLimit
+	case TransformedExpression:
+		return StatusTransformExpression
+	case TransformedExpressionResponse:
+		return StatusTransformExpressionResponse
+	case ResponseSizeLimit:
+		return StatusResponseSizeControlled
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
 		return EndPointNotAllowed
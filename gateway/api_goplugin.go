@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/goplugin"
+)
+
+// GoPluginsResponse reports the Go plugin versions loaded by the gateway and the memory they retain.
+type GoPluginsResponse struct {
+	Plugins []goplugin.LoadedPlugin `json:"plugins"`
+	// FileSize and HeapDelta are the totals of all versions, as previous versions can't be unloaded.
+	FileSize  int64 `json:"file_size"`
+	HeapDelta int64 `json:"heap_delta"`
+}
+
+func (gw *Gateway) goPluginsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := GoPluginsResponse{
+		Plugins: goplugin.LoadedPlugins(),
+	}
+
+	for _, p := range resp.Plugins {
+		resp.FileSize += p.FileSize
+		resp.HeapDelta += p.HeapDelta
+	}
+
+	doJSONWrite(w, http.StatusOK, resp)
+}
@@ -0,0 +1,19 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGoPluginsApi(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodGet, Path: "/tyk/plugins/go", Code: http.StatusForbidden},
+		{AdminAuth: true, Method: http.MethodGet, Path: "/tyk/plugins/go", Code: http.StatusOK,
+			BodyMatch: `"plugins":\[\]`},
+	}...)
+}
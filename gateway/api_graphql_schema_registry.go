@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+)
+
+func (gw *Gateway) graphQLSchemaVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	versions, ok := gw.graphQLSchemaRegistryVersions(w, r)
+	if !ok {
+		return
+	}
+
+	for i := range versions {
+		versions[i].SDL = ""
+	}
+
+	doJSONWrite(w, http.StatusOK, versions)
+}
+
+func (gw *Gateway) graphQLSchemaVersionHandler(w http.ResponseWriter, r *http.Request) {
+	versions, ok := gw.graphQLSchemaRegistryVersions(w, r)
+	if !ok {
+		return
+	}
+
+	version, found := findGraphQLSchemaVersion(versions, mux.Vars(r)["version"])
+	if !found {
+		doJSONWrite(w, http.StatusNotFound, apiError("Schema version not found"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, version)
+}
+
+// graphQLSchemaDiffHandler diffs two schema versions of an API, given by the from and to query parameters.
+// By default, the latest version is diffed against the one before it.
+func (gw *Gateway) graphQLSchemaDiffHandler(w http.ResponseWriter, r *http.Request) {
+	versions, ok := gw.graphQLSchemaRegistryVersions(w, r)
+	if !ok {
+		return
+	}
+
+	if len(versions) == 0 {
+		doJSONWrite(w, http.StatusNotFound, apiError("Schema version not found"))
+		return
+	}
+
+	to := versions[len(versions)-1]
+	if param := r.URL.Query().Get("to"); param != "" {
+		var found bool
+		if to, found = findGraphQLSchemaVersion(versions, param); !found {
+			doJSONWrite(w, http.StatusNotFound, apiError("Schema version not found"))
+			return
+		}
+	}
+
+	from := GraphQLSchemaVersion{}
+	found := false
+	if param := r.URL.Query().Get("from"); param != "" {
+		from, found = findGraphQLSchemaVersion(versions, param)
+	} else {
+		for _, version := range versions {
+			if version.Version < to.Version {
+				from, found = version, true
+			}
+		}
+	}
+
+	if !found {
+		doJSONWrite(w, http.StatusNotFound, apiError("Schema version not found"))
+		return
+	}
+
+	changes, err := graphqlinternal.DiffSchemas(from.SDL, to.SDL)
+	if err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to diff schema versions"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, GraphQLSchemaDiff{
+		APIID:    mux.Vars(r)["apiID"],
+		From:     from.Version,
+		To:       to.Version,
+		Breaking: graphqlinternal.HasBreakingChanges(changes),
+		Changes:  changes,
+	})
+}
+
+func (gw *Gateway) graphQLSchemaRegistryVersions(w http.ResponseWriter, r *http.Request) ([]GraphQLSchemaVersion, bool) {
+	apiID := mux.Vars(r)["apiID"]
+
+	spec := gw.getApiSpec(apiID)
+	if spec == nil || !spec.GraphQL.Enabled {
+		doJSONWrite(w, http.StatusNotFound, apiError("GraphQL API not found"))
+		return nil, false
+	}
+
+	versions, err := gw.graphQLSchemaVersions(apiID)
+	if err != nil {
+		log.WithError(err).WithField("api_id", apiID).Error("Failed to read GraphQL schema versions")
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to read schema versions"))
+		return nil, false
+	}
+
+	return versions, true
+}
+
+func findGraphQLSchemaVersion(versions []GraphQLSchemaVersion, param string) (GraphQLSchemaVersion, bool) {
+	number, err := strconv.Atoi(param)
+	if err != nil {
+		return GraphQLSchemaVersion{}, false
+	}
+
+	for _, version := range versions {
+		if version.Version == number {
+			return version, true
+		}
+	}
+	return GraphQLSchemaVersion{}, false
+}
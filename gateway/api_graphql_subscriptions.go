@@ -0,0 +1,25 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// graphQLSubscriptionsHandler lists the active GraphQL subscriptions of this node, optionally filtered by the
+// api_id query parameter.
+func (gw *Gateway) graphQLSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	doJSONWrite(w, http.StatusOK, gw.graphQLSubscriptions.List(r.URL.Query().Get("api_id")))
+}
+
+// graphQLSubscriptionHandler terminates an active GraphQL subscription of this node.
+func (gw *Gateway) graphQLSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["subscriptionID"]
+
+	if !gw.graphQLSubscriptions.Terminate(id) {
+		doJSONWrite(w, http.StatusNotFound, apiError("Subscription not found"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, apiOk("subscription terminated"))
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// TrustedDocument is an operation clients are allowed to run against a GraphQL API.
+type TrustedDocument struct {
+	// ID is the hex encoded SHA-256 hash of Body, it's computed when empty.
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	Body string `json:"body"`
+}
+
+// TrustedDocumentsManifest lists the trusted documents of an API. The format is compatible with
+// persisted query manifests generated by GraphQL clients.
+type TrustedDocumentsManifest struct {
+	Format     string            `json:"format,omitempty"`
+	Version    int               `json:"version,omitempty"`
+	Operations []TrustedDocument `json:"operations"`
+}
+
+func (gw *Gateway) trustedDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+
+	spec := gw.getApiSpec(apiID)
+	if spec == nil || !spec.GraphQL.Enabled {
+		doJSONWrite(w, http.StatusNotFound, apiError("GraphQL API not found"))
+		return
+	}
+
+	store := gw.graphQLPersistedQueryStore()
+
+	switch r.Method {
+	case http.MethodGet:
+		manifest := TrustedDocumentsManifest{Operations: []TrustedDocument{}}
+		prefix := trustedDocumentStorageKey(apiID, "")
+		for key, body := range store.GetKeysAndValuesWithFilter(trustedDocumentsPattern(apiID)) {
+			manifest.Operations = append(manifest.Operations, TrustedDocument{ID: strings.TrimPrefix(key, prefix), Body: body})
+		}
+
+		doJSONWrite(w, http.StatusOK, manifest)
+	case http.MethodPost:
+		var manifest TrustedDocumentsManifest
+		if err := json.NewDecoder(r.Body).Decode(&manifest); err != nil {
+			doJSONWrite(w, http.StatusBadRequest, apiError("Request malformed"))
+			return
+		}
+
+		for i, doc := range manifest.Operations {
+			hash := graphQLDocumentHash(doc.Body)
+			if doc.ID != "" && strings.ToLower(doc.ID) != hash {
+				doJSONWrite(w, http.StatusBadRequest, apiError(fmt.Sprintf("operation %d: id does not match the hash of the body", i)))
+				return
+			}
+			manifest.Operations[i].ID = hash
+		}
+
+		for _, doc := range manifest.Operations {
+			if err := store.SetKey(trustedDocumentStorageKey(apiID, doc.ID), doc.Body, 0); err != nil {
+				log.WithError(err).WithField("api_id", apiID).Error("Failed to store trusted document")
+				doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to store trusted documents"))
+				return
+			}
+		}
+
+		doJSONWrite(w, http.StatusOK, apiOk(fmt.Sprintf("%d trusted documents added", len(manifest.Operations))))
+	case http.MethodDelete:
+		if !store.DeleteScanMatch(store.KeyPrefix + trustedDocumentsPattern(apiID)) {
+			doJSONWrite(w, http.StatusInternalServerError, apiError("Failed to delete trusted documents"))
+			return
+		}
+
+		doJSONWrite(w, http.StatusOK, apiOk("trusted documents deleted"))
+	}
+}
+
+// trustedDocumentsPattern returns the key pattern matching the trusted documents of the API. It only matches
+// hash shaped suffixes, so the documents of an API whose ID extends apiID with a dash (my-api and my-api-v2) don't match.
+func trustedDocumentsPattern(apiID string) string {
+	return redisGlobEscaper.Replace(trustedDocumentStorageKey(apiID, "")) + strings.Repeat("[0-9a-f]", sha256.Size*2)
+}
+
+// redisGlobEscaper escapes the characters with a special meaning in Redis key patterns.
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -26,6 +27,8 @@ import (
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
 
+	"github.com/TykTechnologies/tyk/internal/cache"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/internal/otel"
 )
 
@@ -123,11 +126,16 @@ func (gw *Gateway) generateSubRoutes(spec *APISpec, router *mux.Router, logger *
 	}
 
 	if spec.CORS.Enable {
+		allowedHeaders, exposedHeaders := spec.CORS.AllowedHeaders, spec.CORS.ExposedHeaders
+		if spec.GRPC.Enabled && spec.GRPC.Web.Enabled {
+			allowedHeaders, exposedHeaders = grpcWebCORSHeaders(allowedHeaders, exposedHeaders)
+		}
+
 		c := cors.New(cors.Options{
 			AllowedOrigins:     spec.CORS.AllowedOrigins,
 			AllowedMethods:     spec.CORS.AllowedMethods,
-			AllowedHeaders:     spec.CORS.AllowedHeaders,
-			ExposedHeaders:     spec.CORS.ExposedHeaders,
+			AllowedHeaders:     allowedHeaders,
+			ExposedHeaders:     exposedHeaders,
 			AllowCredentials:   spec.CORS.AllowCredentials,
 			MaxAge:             spec.CORS.MaxAge,
 			OptionsPassthrough: spec.CORS.OptionsPassthrough,
@@ -177,6 +185,32 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		return &chainDef
 	}
 
+	if spec.GraphQL.Enabled && spec.GraphQL.ExecutionMode == apidef.GraphQLExecutionModeSupergraph {
+		composition, err := graphqlinternal.ComposeSupergraph(spec.GraphQL.Supergraph)
+		if err != nil {
+			var compositionErrs graphqlinternal.CompositionErrors
+			if errors.As(err, &compositionErrs) {
+				for _, compositionErr := range compositionErrs {
+					logger.WithFields(logrus.Fields{
+						"code":     compositionErr.Code,
+						"subgraph": compositionErr.Subgraph,
+					}).Error("Supergraph composition error: ", compositionErr.Message)
+				}
+			}
+			logger.Warning("Supergraph composition failed, skipped!")
+			chainDef.Skip = true
+			return &chainDef
+		}
+
+		if composition != nil {
+			spec.composedSupergraphSDL = composition.SupergraphSDL
+		}
+	}
+
+	if spec.GraphQL.Enabled && spec.GraphQL.SchemaRegistry.Enabled {
+		gw.recordGraphQLSchemaVersion(spec, logger)
+	}
+
 	// Expose API only to looping
 	if spec.Internal {
 		chainDef.Skip = true
@@ -235,6 +269,26 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		prefix = gw.getBundleDestPath(spec)
 	}
 
+	if spec.GRPC.Enabled && spec.GRPC.Transcoding.Enabled {
+		transcoder, err := newGRPCTranscoder(spec.GRPC.Transcoding, prefix)
+		if err != nil {
+			logger.WithError(err).Error("Couldn't load gRPC transcoding descriptors")
+		}
+		spec.grpcTranscoder = transcoder
+	}
+
+	if spec.GraphQL.Enabled && spec.GraphQL.CostAnalysis.Enabled {
+		calculator, err := graphqlinternal.NewQueryCostCalculator(spec.graphQLSchema(), spec.GraphQL.CostAnalysis)
+		if err != nil {
+			logger.WithError(err).Error("Couldn't parse GraphQL schema for cost analysis")
+		}
+		spec.graphqlCostCalculator = calculator
+	}
+
+	if !spec.UseKeylessAccess {
+		spec.responseFieldRules = cache.New(responseFieldRulesCacheTTL, responseFieldRulesCacheCleanup)
+	}
+
 	logger.Debug("Initializing API")
 	var mwPaths []string
 
@@ -244,8 +298,8 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		spec.JSVM.LoadJSPaths(mwPaths, prefix)
 	}
 
-	//  if bundle was used - fix paths for goplugin-type custom middle-wares
-	if mwDriver == apidef.GoPluginDriver && prefix != "" {
+	//  if bundle was used - fix paths for goplugin and wasm custom middle-wares
+	if (mwDriver == apidef.GoPluginDriver || mwDriver == apidef.WasmDriver) && prefix != "" {
 		mwAuthCheckFunc.Path = filepath.Join(prefix, mwAuthCheckFunc.Path)
 		fixFuncPath(prefix, mwPreFuncs)
 		fixFuncPath(prefix, mwPostFuncs)
@@ -253,6 +307,19 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		fixFuncPath(prefix, mwResponseFuncs)
 	}
 
+	if mwDriver == apidef.WasmDriver {
+		wasmHooks := map[coprocess.HookType][]apidef.MiddlewareDefinition{
+			coprocess.HookType_Pre:         mwPreFuncs,
+			coprocess.HookType_PostKeyAuth: mwPostAuthCheckFuncs,
+			coprocess.HookType_Post:        mwPostFuncs,
+			coprocess.HookType_Response:    mwResponseFuncs,
+		}
+		if mwAuthCheckFunc.Name != "" {
+			wasmHooks[coprocess.HookType_CustomKeyCheck] = []apidef.MiddlewareDefinition{mwAuthCheckFunc}
+		}
+		gw.loadWasmPlugins(spec, wasmHooks)
+	}
+
 	enableVersionOverrides := false
 	for _, versionData := range spec.VersionData.Versions {
 		if versionData.OverrideTarget != "" && !spec.VersionData.NotVersioned {
@@ -299,7 +366,12 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		logger.Info("Checking security policy: Open")
 	}
 
+	// the operations of a batch go through the whole chain on their own
+	gw.mwAppendEnabled(&chainArray, &GraphQLBatchMiddleware{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &VersionCheck{BaseMiddleware: baseMid})
+	gw.mwAppendEnabled(&chainArray, &GRPCWebMiddleware{BaseMiddleware: baseMid})
+	gw.mwAppendEnabled(&chainArray, &GRPCTranscodingMiddleware{BaseMiddleware: baseMid})
+	gw.mwAppendEnabled(&chainArray, &GRPCMiddleware{BaseMiddleware: baseMid})
 
 	for _, obj := range mwPreFuncs {
 		if mwDriver == apidef.GoPluginDriver {
@@ -314,7 +386,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 			)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, false, nil})
 		} else {
 			chainArray = append(chainArray, gw.createDynamicMiddleware(obj.Name, true, obj.RequireSession, baseMid))
 		}
@@ -381,7 +453,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", mwAuthCheckFunc.Name, "hook type: CustomKeyCheck", ", driver: ", mwDriver)
 
 				newExtractor(spec, baseMid)
-				gw.mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, nil})
+				gw.mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, false, nil})
 			}
 		}
 
@@ -413,7 +485,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 				)
 			} else {
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-				gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+				gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, false, nil})
 			}
 		}
 
@@ -425,6 +497,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 	}
 
 	gw.mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid})
+	gw.mwAppendEnabled(&chainArray, &GraphQLPersistedQueryMiddleware{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &GraphQLMiddleware{BaseMiddleware: baseMid})
 
 	if streamMw := getStreamingMiddleware(baseMid); streamMw != nil {
@@ -436,11 +509,14 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 		gw.mwAppendEnabled(&chainArray, &GraphQLGranularAccessMiddleware{BaseMiddleware: baseMid})
 	}
 
+	gw.mwAppendEnabled(&chainArray, &GraphQLCacheMiddleware{RedisCacheMiddleware: &RedisCacheMiddleware{BaseMiddleware: baseMid, store: &cacheStore}})
+
 	gw.mwAppendEnabled(&chainArray, &ValidateJSON{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &ValidateRequest{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &PersistGraphQLOperationMiddleware{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &TransformMiddleware{baseMid})
 	gw.mwAppendEnabled(&chainArray, &TransformJQMiddleware{baseMid})
+	gw.mwAppendEnabled(&chainArray, &TransformExpressionMiddleware{baseMid})
 	gw.mwAppendEnabled(&chainArray, &TransformHeaders{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &URLRewriteMiddleware{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &TransformMethod{BaseMiddleware: baseMid})
@@ -465,7 +541,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 			)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Post", ", driver: ", mwDriver)
-			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			gw.mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, false, nil})
 		} else {
 			chainArray = append(chainArray, gw.createDynamicMiddleware(obj.Name, false, obj.RequireSession, baseMid))
 		}
@@ -473,6 +549,7 @@ func (gw *Gateway) processSpec(spec *APISpec, apisByListen map[string]int,
 
 	gw.mwAppendEnabled(&chainArray, &UpstreamBasicAuth{BaseMiddleware: baseMid})
 	gw.mwAppendEnabled(&chainArray, &UpstreamOAuth{BaseMiddleware: baseMid})
+	gw.mwAppendEnabled(&chainArray, &GRPCTranscodeRequestMiddleware{BaseMiddleware: baseMid})
 
 	chain = alice.New(chainArray...).Then(&DummyProxyHandler{SH: SuccessHandler{baseMid}, Gw: gw})
 
@@ -604,3 +604,60 @@ func TestConfigureAuthAndOrgStores(t *testing.T) {
 		})
 	}
 }
+
+func TestFederationV2Supergraph(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	const link = `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key", "@shareable"]) `
+
+	accounts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"me":{"__typename":"User","id":"1","username":"Me"}}}`))
+	}))
+	defer accounts.Close()
+
+	reviews := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"_entities":[{"__typename":"User","reviews":[{"body":"Great"}]}]}}`))
+	}))
+	defer reviews.Close()
+
+	supergraph := func(reviewsSDL string) func(spec *APISpec) {
+		return func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/supergraph"
+			spec.GraphQL = apidef.GraphQLConfig{
+				Enabled:       true,
+				Version:       apidef.GraphQLConfigVersion2,
+				ExecutionMode: apidef.GraphQLExecutionModeSupergraph,
+				Supergraph: apidef.GraphQLSupergraphConfig{
+					Subgraphs: []apidef.GraphQLSubgraphEntity{
+						{Name: "accounts", URL: accounts.URL, SDL: link + `type Query { me: User } type User @key(fields: "id") { id: ID! username: String! }`},
+						{Name: "reviews", URL: reviews.URL, SDL: reviewsSDL},
+					},
+				},
+			}
+		}
+	}
+
+	t.Run("composes the supergraph", func(t *testing.T) {
+		spec := g.Gw.BuildAndLoadAPI(supergraph(link + `type User @key(fields: "id") { id: ID! reviews: [Review] } type Review { body: String }`))[0]
+
+		loaded := g.Gw.getApiSpec(spec.APIID)
+		assert.NotEmpty(t, loaded.composedSupergraphSDL)
+		assert.Empty(t, loaded.GraphQL.Schema)
+		assert.Empty(t, loaded.GraphQL.Supergraph.MergedSDL)
+
+		_, _ = g.Run(t, test.TestCase{
+			Path:      "/supergraph",
+			Method:    http.MethodPost,
+			Data:      `{"query":"{ me { username reviews { body } } }"}`,
+			BodyMatch: `{"data":{"me":{"username":"Me","reviews":\[{"body":"Great"}\]}}}`,
+			Code:      http.StatusOK,
+		})
+	})
+
+	t.Run("skips the API on composition errors", func(t *testing.T) {
+		g.Gw.BuildAndLoadAPI(supergraph(link + `type User @key(fields: "id") { id: ID! username: String! }`))
+
+		_, _ = g.Run(t, test.TestCase{Path: "/supergraph", Method: http.MethodPost, Code: http.StatusNotFound})
+	})
+}
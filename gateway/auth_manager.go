@@ -111,6 +111,7 @@ func (b *DefaultSessionManager) clearCacheForKey(keyName string, hashed bool) {
 	}
 	// Delete gateway's cache immediately
 	b.Gw.SessionCache.Delete(cacheKey)
+	b.Gw.graphQLSubscriptions.TerminateKey(cacheKey)
 
 	// Notify gateways in cluster to flush cache
 	n := Notification{
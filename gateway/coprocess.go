@@ -23,7 +23,7 @@ import (
 )
 
 var (
-	supportedDrivers = []apidef.MiddlewareDriver{apidef.PythonDriver, apidef.LuaDriver, apidef.GrpcDriver}
+	supportedDrivers = []apidef.MiddlewareDriver{apidef.PythonDriver, apidef.LuaDriver, apidef.GrpcDriver, apidef.WasmDriver}
 	loadedDrivers    = map[apidef.MiddlewareDriver]coprocess.Dispatcher{}
 )
 
@@ -35,6 +35,7 @@ type CoProcessMiddleware struct {
 	HookName         string
 	MiddlewareDriver apidef.MiddlewareDriver
 	RawBodyOnly      bool
+	StreamBody       bool
 
 	successHandler *SuccessHandler
 }
@@ -175,14 +176,17 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response, spec *A
 			resObj.MultivalueHeaders = append(resObj.MultivalueHeaders, &currentHeader)
 		}
 		resObj.StatusCode = int32(res.StatusCode)
-		rawBody, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-		resObj.RawBody = rawBody
-		res.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
-		if utf8.Valid(rawBody) && !c.Middleware.RawBodyOnly {
-			resObj.Body = string(rawBody)
+		// streaming hooks receive the body in chunks, see CustomMiddlewareResponseHook.streamResponse
+		if !c.Middleware.StreamBody {
+			rawBody, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				return nil, err
+			}
+			resObj.RawBody = rawBody
+			res.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
+			if utf8.Valid(rawBody) && !c.Middleware.RawBodyOnly {
+				resObj.Body = string(rawBody)
+			}
 		}
 		object.Response = resObj
 	}
@@ -259,6 +263,10 @@ func (gw *Gateway) CoProcessInit() {
 
 	// Load gRPC dispatcher:
 	if gw.GetConfig().CoProcessOptions.CoProcessGRPCServer != "" {
+		if dispatcher, ok := loadedDrivers[apidef.GrpcDriver].(*GRPCDispatcher); ok {
+			dispatcher.Close()
+		}
+
 		var err error
 		loadedDrivers[apidef.GrpcDriver], err = gw.NewGRPCDispatcher()
 		if err == nil {
@@ -272,6 +280,21 @@ func (gw *Gateway) CoProcessInit() {
 		}
 	}
 
+	// Load WebAssembly dispatcher:
+	if loadedDrivers[apidef.WasmDriver] == nil {
+		var err error
+		loadedDrivers[apidef.WasmDriver], err = NewWasmDispatcher(gw.GetConfig().CoProcessOptions)
+		if err == nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).Info("WebAssembly dispatcher was initialized")
+		} else {
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).WithError(err).Error("Couldn't load WebAssembly dispatcher")
+		}
+	}
+
 }
 
 // EnabledForSpec checks if this middleware should be enabled for a given API.
@@ -369,6 +392,11 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	ms := DurationToMillisecond(time.Since(t1))
 
 	if err != nil {
+		if m.failOpen(err) {
+			logger.WithError(err).Warning("Plugin server is unavailable, skipping hook")
+			return nil, http.StatusOK
+		}
+
 		logger.WithError(err).Error("Dispatch error")
 		if m.HookType == coprocess.HookType_CustomKeyCheck {
 			return errors.New("Key not authorised"), 403
@@ -512,6 +540,20 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 	return nil, http.StatusOK
 }
 
+// Streaming response hooks get the phase of each call in object.Spec[coprocessStreamKey].
+const (
+	coprocessStreamKey = "stream"
+	// coprocessStreamHeaders is the first call, with the status code and headers but no body. Returning
+	// coprocessStreamBuffer as the phase makes the gateway buffer the body and send it in a single call.
+	coprocessStreamHeaders = "headers"
+	// coprocessStreamChunk calls hold the next chunk of the body, the returned body replaces it.
+	coprocessStreamChunk = "chunk"
+	// coprocessStreamEnd is the last call, without body, to flush any data held back by the plugin.
+	coprocessStreamEnd = "end"
+	// coprocessStreamBuffer calls hold the whole body, as requested by the plugin.
+	coprocessStreamBuffer = "buffer"
+)
+
 type CustomMiddlewareResponseHook struct {
 	BaseTykResponseHandler
 	mw *CoProcessMiddleware
@@ -532,11 +574,20 @@ func (h *CustomMiddlewareResponseHook) Init(mwDef interface{}, spec *APISpec) er
 		HookName:         mwDefinition.Name,
 		HookType:         coprocess.HookType_Response,
 		RawBodyOnly:      mwDefinition.RawBodyOnly,
+		StreamBody:       mwDefinition.StreamBody,
 		MiddlewareDriver: spec.CustomMiddleware.Driver,
 	}
 	return nil
 }
 
+// failOpen returns true if the hook should be skipped after the dispatch error, as the plugin server
+// is unavailable and the API fails open. Auth checks always fail closed.
+func (m *CoProcessMiddleware) failOpen(err error) bool {
+	return m.Spec.CustomMiddleware.FailOpen &&
+		m.HookType != coprocess.HookType_CustomKeyCheck &&
+		errors.Is(err, errCoProcessUnavailable)
+}
+
 // getAuthType overrides BaseMiddleware.getAuthType.
 func (m *CoProcessMiddleware) getAuthType() string {
 	return apidef.CoprocessType
@@ -566,40 +617,129 @@ func (h *CustomMiddlewareResponseHook) HandleResponse(rw http.ResponseWriter, re
 	}
 	object.Session = ProtoSessionState(ses)
 
+	if h.mw.StreamBody {
+		object.Spec[coprocessStreamKey] = coprocessStreamHeaders
+	}
+
+	retObject, err := h.dispatch(object)
+	if err != nil || retObject == nil {
+		return err
+	}
+
+	h.setResponse(res, object.Response, retObject.Response)
+
+	if !h.mw.StreamBody {
+		// Set response body:
+		bodyBuf := bytes.NewBuffer(retObject.Response.RawBody)
+		res.Body = ioutil.NopCloser(bodyBuf)
+		return nil
+	}
+
+	object.Response = retObject.Response
+	if retObject.Spec[coprocessStreamKey] != coprocessStreamBuffer {
+		h.streamResponse(res, object)
+		return nil
+	}
+
+	// The plugin requested the whole body:
+	rawBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		log.WithError(err).Debug("Couldn't read response body")
+		return errors.New("Middleware error")
+	}
+
+	object.Spec[coprocessStreamKey] = coprocessStreamBuffer
+	h.setObjectBody(object, rawBody)
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
+	retObject, err = h.dispatch(object)
+	if err != nil || retObject == nil {
+		return err
+	}
+
+	h.setResponse(res, object.Response, retObject.Response)
+	res.Body = ioutil.NopCloser(bytes.NewReader(retObject.Response.RawBody))
+	res.ContentLength = int64(len(retObject.Response.RawBody))
+	return nil
+}
+
+// dispatch sends the object to the response hook. It returns no object when the plugin server
+// is unavailable and the API fails open, leaving the response unchanged.
+func (h *CustomMiddlewareResponseHook) dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	coProcessor := CoProcessor{
+		Middleware: h.mw,
+	}
+
 	retObject, err := coProcessor.Dispatch(object)
 	if err != nil {
+		if h.mw.failOpen(err) {
+			log.WithError(err).Warning("Plugin server is unavailable, skipping response hook")
+			return nil, nil
+		}
+
 		log.WithError(err).Debug("Couldn't dispatch request object")
-		return errors.New("Middleware error")
+		return nil, errors.New("Middleware error")
 	}
 
 	if retObject.Response == nil {
 		log.WithError(err).Debug("No response object returned by response hook")
-		return errors.New("Middleware error")
+		return nil, errors.New("Middleware error")
 	}
 
+	return retObject, nil
+}
+
+// setResponse applies the headers and status code returned by the response hook.
+func (h *CustomMiddlewareResponseHook) setResponse(res *http.Response, sent, returned *coprocess.ResponseObject) {
 	// Clear all response headers before populating from coprocess response object:
 	for k := range res.Header {
 		delete(res.Header, k)
 	}
 
 	// check if we have changes in headers
-	if !areMapsEqual(object.Response.Headers, retObject.Response.Headers) {
+	if !areMapsEqual(sent.Headers, returned.Headers) {
 		// as we have changes we need to synchronize them
-		retObject.Response.MultivalueHeaders = syncHeadersAndMultiValueHeaders(retObject.Response.Headers, retObject.Response.MultivalueHeaders)
+		returned.MultivalueHeaders = syncHeadersAndMultiValueHeaders(returned.Headers, returned.MultivalueHeaders)
 	}
 
 	// Set headers:
 	ignoreCanonical := h.mw.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey
-	for _, v := range retObject.Response.MultivalueHeaders {
+	for _, v := range returned.MultivalueHeaders {
 		setCustomHeaderMultipleValues(res.Header, v.Key, v.Values, ignoreCanonical)
 	}
 
-	// Set response body:
-	bodyBuf := bytes.NewBuffer(retObject.Response.RawBody)
-	res.Body = ioutil.NopCloser(bodyBuf)
+	res.StatusCode = int(returned.StatusCode)
+}
 
-	res.StatusCode = int(retObject.Response.StatusCode)
-	return nil
+func (h *CustomMiddlewareResponseHook) setObjectBody(object *coprocess.Object, rawBody []byte) {
+	object.Response.RawBody = rawBody
+	object.Response.Body = ""
+	if utf8.Valid(rawBody) && !h.mw.RawBodyOnly {
+		object.Response.Body = string(rawBody)
+	}
+}
+
+// streamResponse dispatches the response body to the hook chunk by chunk, as it's sent to the client.
+// Headers and status code were already sent, so only the returned body is used.
+func (h *CustomMiddlewareResponseHook) streamResponse(res *http.Response, object *coprocess.Object) {
+	streamResponseBody(res, func(chunk []byte, last bool) ([]byte, error) {
+		object.Spec[coprocessStreamKey] = coprocessStreamChunk
+		if last {
+			object.Spec[coprocessStreamKey] = coprocessStreamEnd
+		}
+		h.setObjectBody(object, chunk)
+
+		retObject, err := h.dispatch(object)
+		if err != nil {
+			log.WithError(err).Error("Streaming response hook failed, aborting response")
+			return nil, err
+		}
+		if retObject == nil {
+			return chunk, nil
+		}
+		return retObject.Response.RawBody, nil
+	})
 }
 
 // syncHeadersAndMultiValueHeaders synchronizes the content of 'headers' and 'multiValueHeaders'.
@@ -12,6 +12,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/TykTechnologies/goverify"
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/crypto"
 )
 
 var (
@@ -33,6 +35,12 @@ var (
 	bundleMaxBackoffRetries uint64  = 4
 )
 
+// bundleChecksumSHA256Prefix marks SHA-256 manifest checksums, checksums without it are MD5.
+const bundleChecksumSHA256Prefix = "sha256:"
+
+// bundleDownloadTimeout is the timeout for downloads from object stores and registries.
+const bundleDownloadTimeout = 30 * time.Second
+
 // Bundle is the basic bundle data structure, it holds the bundle name and the data.
 type Bundle struct {
 	Name     string
@@ -49,7 +57,8 @@ func (b *Bundle) Verify() error {
 		"prefix": "main",
 	}).Info("----> Verifying bundle: ", b.Spec.CustomMiddlewareBundle)
 
-	var useSignature = b.Gw.GetConfig().PublicKeyPath != ""
+	gwConfig := b.Gw.GetConfig()
+	var useSignature = gwConfig.PublicKeyPath != "" || gwConfig.BundlePublicKeyPath != ""
 
 	var (
 		verifier goverify.Verifier
@@ -62,7 +71,7 @@ func (b *Bundle) Verify() error {
 			// Error: A public key is set, but the bundle isn't signed.
 			return errors.New("Bundle isn't signed")
 		}
-		verifier, err = b.Gw.SignatureVerifier()
+		verifier, err = b.Gw.bundleVerifier()
 		if err != nil {
 			return err
 		}
@@ -84,9 +93,8 @@ func (b *Bundle) Verify() error {
 		}
 	}
 
-	checksum := fmt.Sprintf("%x", md5.Sum(bundleData.Bytes()))
-	if checksum != b.Manifest.Checksum {
-		return errors.New("Invalid checksum")
+	if err := b.verifyChecksum(bundleData.Bytes()); err != nil {
+		return err
 	}
 
 	if useSignature {
@@ -99,6 +107,33 @@ func (b *Bundle) Verify() error {
 	return nil
 }
 
+// verifyChecksum compares the manifest checksum with the bundle files, using SHA-256 for
+// `sha256:` prefixed checksums and MD5 otherwise.
+func (b *Bundle) verifyChecksum(data []byte) error {
+	var checksum string
+	if strings.HasPrefix(b.Manifest.Checksum, bundleChecksumSHA256Prefix) {
+		checksum = fmt.Sprintf("%s%x", bundleChecksumSHA256Prefix, sha256.Sum256(data))
+	} else {
+		if b.Gw.GetConfig().BundleRequireSHA256 {
+			return errors.New("Bundle checksum isn't SHA-256")
+		}
+		checksum = fmt.Sprintf("%x", md5.Sum(data))
+	}
+
+	if checksum != b.Manifest.Checksum {
+		return errors.New("Invalid checksum")
+	}
+	return nil
+}
+
+// bundleVerifier returns the verifier for bundle signatures, using the bundle public key if set.
+func (gw *Gateway) bundleVerifier() (goverify.Verifier, error) {
+	if path := gw.GetConfig().BundlePublicKeyPath; path != "" {
+		return crypto.LoadVerifier(path)
+	}
+	return gw.SignatureVerifier()
+}
+
 // AddToSpec attaches the custom middleware settings to an API definition.
 func (b *Bundle) AddToSpec() {
 	b.Spec.CustomMiddleware = b.Manifest.CustomMiddleware
@@ -128,6 +163,12 @@ type BundleGetter interface {
 	Get() ([]byte, error)
 }
 
+// bundleSignatureLocator is implemented by BundleGetters that don't store the detached bundle signature
+// at the bundle location with a `.sig` suffix.
+type bundleSignatureLocator interface {
+	SignatureURL() (string, error)
+}
+
 // HTTPBundleGetter is a simple HTTP BundleGetter.
 type HTTPBundleGetter struct {
 	URL                string
@@ -236,37 +277,117 @@ func (gw *Gateway) FetchBundle(spec *APISpec) (Bundle, error) {
 
 	bundleURL := u.String()
 
-	var getter BundleGetter
+	getter, err := gw.bundleGetter(bundleURL)
+	if err != nil {
+		return bundle, err
+	}
+
+	bundleData, err := pullBundle(getter, bundleBackoffMultiplier)
+	if err == nil && gw.GetConfig().BundleCosignVerification {
+		err = gw.verifyBundleSignature(getter, bundleURL, bundleData)
+	}
+
+	bundle.Name = spec.CustomMiddlewareBundle
+	bundle.Data = bundleData
+	bundle.Spec = spec
+	return bundle, err
+}
+
+// bundleGetter returns the BundleGetter for the scheme of bundleURL.
+func (gw *Gateway) bundleGetter(bundleURL string) (BundleGetter, error) {
+	u, err := url.Parse(bundleURL)
+	if err != nil {
+		return nil, err
+	}
+
+	gwConfig := gw.GetConfig()
 
 	switch u.Scheme {
 	case "http":
-		getter = &HTTPBundleGetter{
+		return &HTTPBundleGetter{
 			URL:                bundleURL,
 			InsecureSkipVerify: false,
-		}
+		}, nil
 	case "https":
-		getter = &HTTPBundleGetter{
+		return &HTTPBundleGetter{
 			URL:                bundleURL,
-			InsecureSkipVerify: gw.GetConfig().BundleInsecureSkipVerify,
-		}
+			InsecureSkipVerify: gwConfig.BundleInsecureSkipVerify,
+		}, nil
 	case "file":
-		getter = &FileBundleGetter{
+		return &FileBundleGetter{
 			URL:                bundleURL,
-			InsecureSkipVerify: gw.GetConfig().BundleInsecureSkipVerify,
-		}
+			InsecureSkipVerify: gwConfig.BundleInsecureSkipVerify,
+		}, nil
+	case "s3":
+		return &S3BundleGetter{
+			URL:                bundleURL,
+			Config:             gwConfig.BundleS3,
+			InsecureSkipVerify: gwConfig.BundleInsecureSkipVerify,
+		}, nil
+	case "oci":
+		return &OCIBundleGetter{
+			URL:                bundleURL,
+			Config:             gwConfig.BundleOCI,
+			InsecureSkipVerify: gwConfig.BundleInsecureSkipVerify,
+		}, nil
 	default:
-		err = errors.New("Unknown URL scheme")
+		return nil, errors.New("Unknown URL scheme")
 	}
+}
+
+// verifyBundleSignature verifies the bundle archive against its detached, cosign-style signature,
+// fetched from the location resolved by bundleSignatureURL.
+func (gw *Gateway) verifyBundleSignature(bundleGetter BundleGetter, bundleURL string, data []byte) error {
+	keyPath := gw.GetConfig().BundlePublicKeyPath
+	if keyPath == "" {
+		return errors.New("Bundle public key path isn't set")
+	}
+
+	verifier, err := crypto.LoadVerifier(keyPath)
 	if err != nil {
-		return bundle, err
+		return err
 	}
 
-	bundleData, err := pullBundle(getter, bundleBackoffMultiplier)
+	signatureURL, err := bundleSignatureURL(bundleGetter, bundleURL)
+	if err != nil {
+		return err
+	}
 
-	bundle.Name = spec.CustomMiddlewareBundle
-	bundle.Data = bundleData
-	bundle.Spec = spec
-	return bundle, err
+	getter, err := gw.bundleGetter(signatureURL)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := pullBundle(getter, bundleBackoffMultiplier)
+	if err != nil {
+		return fmt.Errorf("Couldn't fetch bundle signature: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("Couldn't decode bundle signature: %w", err)
+	}
+
+	if err := verifier.Verify(data, signature); err != nil {
+		return fmt.Errorf("Bundle signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// bundleSignatureURL returns the location of the bundle signature, the bundle URL path with a `.sig`
+// suffix unless the getter locates signatures itself.
+func bundleSignatureURL(getter BundleGetter, bundleURL string) (string, error) {
+	if locator, ok := getter.(bundleSignatureLocator); ok {
+		return locator.SignatureURL()
+	}
+
+	u, err := url.Parse(bundleURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path += ".sig"
+	u.RawPath = ""
+	return u.String(), nil
 }
 
 func pullBundle(getter BundleGetter, backoffMultiplier float64) ([]byte, error) {
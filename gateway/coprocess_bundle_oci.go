@@ -0,0 +1,265 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// ociBundleLayerMediaType is the suggested media type for bundle layers, e.g.
+	// `oras push registry/bundles:v1 bundle.zip:application/vnd.tyk.bundle.layer.v1+zip`.
+	ociBundleLayerMediaType = "application/vnd.tyk.bundle.layer.v1+zip"
+)
+
+// OCIBundleGetter is a BundleGetter for bundles pushed to OCI registries as artifacts, using URLs of the
+// oci://registry/repository:tag or oci://registry/repository@digest form.
+type OCIBundleGetter struct {
+	URL                string
+	Config             config.BundleOCIConfig
+	InsecureSkipVerify bool
+
+	client *http.Client
+	token  string
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// bundleLayer returns the layer holding the bundle: the one with a zip media type,
+// or the only layer of the artifact.
+func (m ociManifest) bundleLayer() (ociDescriptor, error) {
+	for _, layer := range m.Layers {
+		if layer.MediaType == ociBundleLayerMediaType || layer.MediaType == "application/zip" {
+			return layer, nil
+		}
+	}
+
+	if len(m.Layers) == 1 {
+		return m.Layers[0], nil
+	}
+	return ociDescriptor{}, errors.New("OCI artifact doesn't have a bundle layer")
+}
+
+// Get resolves the artifact manifest and downloads the bundle layer.
+func (g *OCIBundleGetter) Get() ([]byte, error) {
+	registry, repository, reference, err := parseOCIReference(g.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "https"
+	if g.Config.PlainHTTP {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, registry, repository)
+
+	if g.client == nil {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: g.InsecureSkipVerify}
+		g.client = &http.Client{Transport: tr, Timeout: bundleDownloadTimeout}
+	}
+
+	log.Infof("Attempting to download plugin bundle: %v", g.URL)
+	manifestData, err := g.fetch(base+"/manifests/"+reference, ociManifestMediaType+", "+dockerManifestMediaType)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting bundle manifest: %w", err)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("Error decoding bundle manifest: %w", err)
+	}
+
+	layer, err := manifest.bundleLayer()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := g.fetch(base+"/blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, fmt.Errorf("Error getting bundle: %w", err)
+	}
+
+	if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data)); digest != layer.Digest {
+		return nil, fmt.Errorf("Bundle digest mismatch, expected %s, got %s", layer.Digest, digest)
+	}
+	return data, nil
+}
+
+// SignatureURL returns the URL of the artifact holding the bundle signature, tagged `<tag>.sig`,
+// or `sha256-<digest>.sig` for digest references as cosign does.
+func (g *OCIBundleGetter) SignatureURL() (string, error) {
+	registry, repository, reference, err := parseOCIReference(g.URL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("oci://%s/%s:%s.sig", registry, repository, strings.Replace(reference, ":", "-", 1)), nil
+}
+
+// fetch performs a GET request to the registry, authenticating when challenged.
+func (g *OCIBundleGetter) fetch(target, accept string) ([]byte, error) {
+	resp, err := g.do(target, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && g.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if err := g.authenticate(challenge); err != nil {
+			return nil, err
+		}
+
+		resp, err = g.do(target, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP Error, got status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (g *OCIBundleGetter) do(target, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	switch {
+	case g.token != "":
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	case g.Config.Username != "":
+		req.SetBasicAuth(g.Config.Username, g.Config.Password)
+	}
+
+	return g.client.Do(req)
+}
+
+// authenticate obtains a bearer token from the realm in the registry challenge,
+// as described by the distribution token authentication specification.
+func (g *OCIBundleGetter) authenticate(challenge string) error {
+	scheme, params := parseAuthChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return errors.New("Registry authentication failed")
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return err
+	}
+
+	query := realm.Query()
+	for _, param := range []string{"service", "scope"} {
+		if value := params[param]; value != "" {
+			query.Set(param, value)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if g.Config.Username != "" {
+		req.SetBasicAuth(g.Config.Username, g.Config.Password)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Registry authentication failed, got status code %d", resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+
+	g.token = token.Token
+	if g.token == "" {
+		g.token = token.AccessToken
+	}
+	if g.token == "" {
+		return errors.New("Registry authentication failed, no token returned")
+	}
+	return nil
+}
+
+// parseOCIReference splits oci://registry/repository:tag and oci://registry/repository@digest URLs.
+// The reference defaults to the latest tag.
+func parseOCIReference(rawURL string) (registry, repository, reference string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	registry, repository, reference = u.Host, strings.TrimPrefix(u.Path, "/"), "latest"
+
+	if i := strings.LastIndex(repository, "@"); i >= 0 {
+		repository, reference = repository[:i], repository[i+1:]
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, reference = repository[:i], repository[i+1:]
+	}
+
+	if registry == "" || repository == "" || reference == "" {
+		return "", "", "", fmt.Errorf("Invalid OCI bundle URL: %s", rawURL)
+	}
+	return registry, repository, reference, nil
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header value such as
+// `Bearer realm="https://auth.example.com/token",service="registry",scope="repository:bundles:pull"`.
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			params[strings.ToLower(key)] = value
+		}
+	}
+
+	return scheme, params
+}
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestOCIBundleGetter(t *testing.T) {
+	bundle := []byte("bundle archive")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(bundle))
+
+	manifest, err := json.Marshal(ociManifest{Layers: []ociDescriptor{
+		{MediaType: "application/vnd.oci.image.config.v1+json", Digest: "sha256:config"},
+		{MediaType: ociBundleLayerMediaType, Digest: digest},
+	}})
+	require.NoError(t, err)
+
+	var registry *httptest.Server
+	registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, _ := r.BasicAuth()
+			if user != "user" || pass != "pass" || r.URL.Query().Get("scope") != "repository:plugins/bundle:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"secret"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:plugins/bundle:pull"`, registry.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/plugins/bundle/manifests/v1", "/v2/plugins/bundle/manifests/" + digest:
+			assert.Contains(t, r.Header.Get("Accept"), ociManifestMediaType)
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			_, _ = w.Write(manifest)
+		case "/v2/plugins/bundle/blobs/" + digest:
+			_, _ = w.Write(bundle)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	host := strings.TrimPrefix(registry.URL, "http://")
+	conf := config.BundleOCIConfig{Username: "user", Password: "pass", PlainHTTP: true}
+
+	t.Run("tag", func(t *testing.T) {
+		getter := &OCIBundleGetter{URL: "oci://" + host + "/plugins/bundle:v1", Config: conf}
+		data, err := getter.Get()
+		require.NoError(t, err)
+		assert.Equal(t, bundle, data)
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		getter := &OCIBundleGetter{URL: "oci://" + host + "/plugins/bundle@" + digest, Config: conf}
+		data, err := getter.Get()
+		require.NoError(t, err)
+		assert.Equal(t, bundle, data)
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		getter := &OCIBundleGetter{URL: "oci://" + host + "/plugins/bundle:v2", Config: conf}
+		_, err := getter.Get()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		getter := &OCIBundleGetter{URL: "oci://" + host + "/plugins/bundle:v1", Config: config.BundleOCIConfig{PlainHTTP: true}}
+		_, err := getter.Get()
+		assert.Error(t, err)
+	})
+}
+
+func TestParseOCIReference(t *testing.T) {
+	tests := []struct {
+		url                             string
+		registry, repository, reference string
+		wantErr                         bool
+	}{
+		{url: "oci://registry.example.com/bundles/auth:v1", registry: "registry.example.com", repository: "bundles/auth", reference: "v1"},
+		{url: "oci://localhost:5000/auth", registry: "localhost:5000", repository: "auth", reference: "latest"},
+		{url: "oci://registry.example.com/auth@sha256:abc", registry: "registry.example.com", repository: "auth", reference: "sha256:abc"},
+		{url: "oci://registry.example.com", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			registry, repository, reference, err := parseOCIReference(tc.url)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.registry, registry)
+			assert.Equal(t, tc.repository, repository)
+			assert.Equal(t, tc.reference, reference)
+		})
+	}
+}
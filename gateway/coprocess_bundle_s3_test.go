@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestS3BundleGetter(t *testing.T) {
+	bundle := []byte("bundle archive")
+
+	store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Method != http.MethodGet || r.URL.Path != "/plugins/bundles/auth.zip" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+			return
+		}
+		_, _ = w.Write(bundle)
+	}))
+	defer store.Close()
+
+	conf := config.BundleS3Config{
+		Endpoint:        store.URL,
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		UsePathStyle:    true,
+	}
+
+	t.Run("existing object", func(t *testing.T) {
+		getter := &S3BundleGetter{URL: "s3://plugins/bundles/auth.zip", Config: conf}
+		data, err := getter.Get()
+		require.NoError(t, err)
+		assert.Equal(t, bundle, data)
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		getter := &S3BundleGetter{URL: "s3://plugins/bundles/missing.zip", Config: conf}
+		_, err := getter.Get()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		getter := &S3BundleGetter{URL: "s3://plugins", Config: conf}
+		_, err := getter.Get()
+		assert.Error(t, err)
+	})
+}
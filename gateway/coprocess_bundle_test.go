@@ -4,7 +4,9 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"io"
@@ -13,12 +15,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
 	"github.com/TykTechnologies/tyk/test"
 )
 
@@ -490,3 +494,154 @@ func TestBundle_Verify(t *testing.T) {
 		})
 	}
 }
+
+func TestBundle_VerifySignatureAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"pre.py": "print('pre')", "post.py": "print('post')"}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	data := []byte(files["pre.py"] + files["post.py"])
+
+	newBundle := func(conf config.Config, checksum string, signature []byte) Bundle {
+		gw := &Gateway{}
+		gw.SetConfig(conf)
+		return Bundle{
+			Path: dir,
+			Spec: &APISpec{APIDefinition: &apidef.APIDefinition{CustomMiddlewareBundle: "test-mw-bundle"}},
+			Manifest: apidef.BundleManifest{
+				FileList:  []string{"pre.py", "post.py"},
+				Checksum:  checksum,
+				Signature: base64.StdEncoding.EncodeToString(signature),
+			},
+			Gw: gw,
+		}
+	}
+	sha256Checksum := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+
+	for _, keyType := range []string{"rsa", "ecdsa", "ed25519"} {
+		t.Run(keyType, func(t *testing.T) {
+			privatePath, publicPath := crypto.GenerateSigningKeyPair(t, t.TempDir(), keyType)
+			signer, err := crypto.LoadSigner(privatePath)
+			assert.NoError(t, err)
+
+			signature, err := signer.Sign(data)
+			assert.NoError(t, err)
+
+			conf := config.Config{BundlePublicKeyPath: publicPath}
+
+			b := newBundle(conf, sha256Checksum, signature)
+			assert.NoError(t, b.Verify())
+
+			b = newBundle(conf, sha256Checksum, []byte("invalid"))
+			assert.Error(t, b.Verify())
+		})
+	}
+
+	t.Run("checksums", func(t *testing.T) {
+		md5Checksum := fmt.Sprintf("%x", md5.Sum(data))
+
+		b := newBundle(config.Config{}, md5Checksum, nil)
+		assert.NoError(t, b.Verify())
+
+		b = newBundle(config.Config{BundleRequireSHA256: true}, md5Checksum, nil)
+		assert.Error(t, b.Verify())
+
+		b = newBundle(config.Config{BundleRequireSHA256: true}, sha256Checksum, nil)
+		assert.NoError(t, b.Verify())
+
+		b = newBundle(config.Config{}, "sha256:invalid", nil)
+		assert.Error(t, b.Verify())
+	})
+}
+
+func TestBundleFetcher_CosignVerification(t *testing.T) {
+	dir := t.TempDir()
+	privatePath, publicPath := crypto.GenerateSigningKeyPair(t, t.TempDir(), "ecdsa")
+	signer, err := crypto.LoadSigner(privatePath)
+	assert.NoError(t, err)
+
+	archive := []byte("bundle archive")
+	signature, err := signer.Sign(archive)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "signed.zip"), archive, 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "signed.zip.sig"), []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tampered.zip"), []byte("tampered archive"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tampered.zip.sig"), []byte(base64.StdEncoding.EncodeToString(signature)), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "unsigned.zip"), archive, 0600))
+
+	retries := bundleMaxBackoffRetries
+	bundleMaxBackoffRetries = 0
+	defer func() { bundleMaxBackoffRetries = retries }()
+
+	gw := &Gateway{}
+	gw.SetConfig(config.Config{
+		EnableBundleDownloader:   true,
+		BundleBaseURL:            "file://" + dir,
+		BundlePublicKeyPath:      publicPath,
+		BundleCosignVerification: true,
+	})
+
+	fetch := func(name string) (Bundle, error) {
+		return gw.FetchBundle(&APISpec{APIDefinition: &apidef.APIDefinition{CustomMiddlewareBundle: name}})
+	}
+
+	bundle, err := fetch("signed.zip")
+	assert.NoError(t, err)
+	assert.Equal(t, archive, bundle.Data)
+
+	_, err = fetch("tampered.zip")
+	assert.ErrorIs(t, err, crypto.ErrInvalidSignature)
+
+	_, err = fetch("unsigned.zip")
+	assert.ErrorContains(t, err, "Couldn't fetch bundle signature")
+}
+
+func TestBundleSignatureURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		bundleURL string
+		getter    BundleGetter
+		expected  string
+	}{
+		{
+			name:      "http",
+			bundleURL: "https://bundles.example.com/auth.zip?version=2",
+			getter:    &HTTPBundleGetter{URL: "https://bundles.example.com/auth.zip?version=2"},
+			expected:  "https://bundles.example.com/auth.zip.sig?version=2",
+		},
+		{
+			name:      "s3",
+			bundleURL: "s3://bundles/prefix/auth.zip",
+			getter:    &S3BundleGetter{URL: "s3://bundles/prefix/auth.zip"},
+			expected:  "s3://bundles/prefix/auth.zip.sig",
+		},
+		{
+			name:      "oci tag",
+			bundleURL: "oci://registry.example.com/bundles/auth:v1",
+			getter:    &OCIBundleGetter{URL: "oci://registry.example.com/bundles/auth:v1"},
+			expected:  "oci://registry.example.com/bundles/auth:v1.sig",
+		},
+		{
+			name:      "oci digest",
+			bundleURL: "oci://registry.example.com/bundles/auth@sha256:0123abcd",
+			getter:    &OCIBundleGetter{URL: "oci://registry.example.com/bundles/auth@sha256:0123abcd"},
+			expected:  "oci://registry.example.com/bundles/auth:sha256-0123abcd.sig",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			signatureURL, err := bundleSignatureURL(tc.getter, tc.bundleURL)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, signatureURL)
+
+			if _, ok := tc.getter.(*OCIBundleGetter); ok {
+				_, _, reference, err := parseOCIReference(signatureURL)
+				assert.NoError(t, err)
+				assert.Equal(t, strings.TrimPrefix(tc.expected, "oci://registry.example.com/bundles/auth:"), reference)
+			}
+		})
+	}
+}
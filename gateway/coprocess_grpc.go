@@ -3,51 +3,151 @@ package gateway
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/coprocess"
 )
 
+const (
+	grpcRoundRobin  = "round_robin"
+	grpcLeastLoaded = "least_loaded"
+
+	grpcHealthCheckTimeout = 5 * time.Second
+)
+
 var (
-	grpcConnection *grpc.ClientConn
-	grpcClient     coprocess.DispatcherClient
+	// errCoProcessUnavailable is returned when no plugin server could process the call,
+	// allowing APIs to fail open.
+	errCoProcessUnavailable = errors.New("plugin server is unavailable")
+
+	// grpcHookTimeoutKeys maps hook types to their keys in grpc_hook_timeouts.
+	grpcHookTimeoutKeys = map[coprocess.HookType]string{
+		coprocess.HookType_Pre:            "pre",
+		coprocess.HookType_CustomKeyCheck: "auth_check",
+		coprocess.HookType_PostKeyAuth:    "post_key_auth",
+		coprocess.HookType_Post:           "post",
+		coprocess.HookType_Response:       "response",
+	}
 )
 
+// grpcEndpoint is a plugin server of the pool.
+type grpcEndpoint struct {
+	address  string
+	conn     *grpc.ClientConn
+	client   coprocess.DispatcherClient
+	health   healthpb.HealthClient
+	healthy  atomic.Bool
+	inflight atomic.Int64
+}
+
 // GRPCDispatcher implements a coprocess.Dispatcher
 type GRPCDispatcher struct {
 	coprocess.Dispatcher
+
+	endpoints    []*grpcEndpoint
+	balancing    string
+	next         atomic.Uint64
+	timeout      time.Duration
+	hookTimeouts map[coprocess.HookType]time.Duration
+	stop         chan struct{}
 }
 
-func (gw *Gateway) dialer(addr string, timeout time.Duration) (net.Conn, error) {
-	grpcURL, err := url.Parse(gw.GetConfig().CoProcessOptions.CoProcessGRPCServer)
-	if err != nil {
-		log.WithFields(logrus.Fields{
-			"prefix": "coprocess",
-		}).Error(err)
-		return nil, err
+// grpcDialer returns a dialer connecting to a plugin server address such as tcp://127.0.0.1:5555 or unix:///tmp/plugins.sock.
+func grpcDialer(address string) func(string, time.Duration) (net.Conn, error) {
+	return func(_ string, timeout time.Duration) (net.Conn, error) {
+		grpcURL, err := url.Parse(address)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).Error(err)
+			return nil, err
+		}
+
+		if grpcURL == nil || address == "" {
+			errString := "No gRPC URL is set!"
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).Error(errString)
+			return nil, errors.New(errString)
+		}
+
+		grpcURLString := address[len(grpcURL.Scheme)+3:]
+		return net.DialTimeout(grpcURL.Scheme, grpcURLString, timeout)
+	}
+}
+
+// pick selects a healthy endpoint according to the load balancing strategy.
+func (d *GRPCDispatcher) pick() (*grpcEndpoint, error) {
+	start := d.next.Add(1)
+
+	var picked *grpcEndpoint
+	for i := range d.endpoints {
+		endpoint := d.endpoints[(start+uint64(i))%uint64(len(d.endpoints))]
+		if !endpoint.healthy.Load() {
+			continue
+		}
+
+		if d.balancing != grpcLeastLoaded {
+			return endpoint, nil
+		}
+
+		if picked == nil || endpoint.inflight.Load() < picked.inflight.Load() {
+			picked = endpoint
+		}
 	}
 
-	if grpcURL == nil || gw.GetConfig().CoProcessOptions.CoProcessGRPCServer == "" {
-		errString := "No gRPC URL is set!"
-		log.WithFields(logrus.Fields{
-			"prefix": "coprocess",
-		}).Error(errString)
-		return nil, errors.New(errString)
+	if picked == nil {
+		return nil, fmt.Errorf("%w: no healthy gRPC server", errCoProcessUnavailable)
+	}
+	return picked, nil
+}
+
+// context returns the context for a call of the given hook type, with its deadline applied.
+func (d *GRPCDispatcher) context(hookType coprocess.HookType) (context.Context, context.CancelFunc) {
+	timeout, ok := d.hookTimeouts[hookType]
+	if !ok {
+		timeout = d.timeout
 	}
 
-	grpcURLString := gw.GetConfig().CoProcessOptions.CoProcessGRPCServer[len(grpcURL.Scheme)+3:]
-	return net.DialTimeout(grpcURL.Scheme, grpcURLString, timeout)
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
 }
 
 // Dispatch takes a CoProcessMessage and sends it to the CP.
 func (d *GRPCDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
-	return grpcClient.Dispatch(context.Background(), object)
+	endpoint, err := d.pick()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := d.context(object.HookType)
+	defer cancel()
+
+	endpoint.inflight.Add(1)
+	defer endpoint.inflight.Add(-1)
+
+	returnObject, err := endpoint.client.Dispatch(ctx, object)
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return nil, fmt.Errorf("%w: %s: %v", errCoProcessUnavailable, endpoint.address, err)
+		}
+		return nil, err
+	}
+	return returnObject, nil
 }
 
 // DispatchEvent dispatches a Tyk event.
@@ -56,7 +156,13 @@ func (d *GRPCDispatcher) DispatchEvent(eventJSON []byte) {
 		Payload: string(eventJSON),
 	}
 
-	_, err := grpcClient.DispatchEvent(context.Background(), eventObject)
+	endpoint, err := d.pick()
+	if err == nil {
+		ctx, cancel := d.context(coprocess.HookType_Unknown)
+		defer cancel()
+
+		_, err = endpoint.client.DispatchEvent(ctx, eventObject)
+	}
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "coprocess",
@@ -70,6 +176,60 @@ func (d *GRPCDispatcher) Reload() {}
 // HandleMiddlewareCache isn't used by gRPC.
 func (d *GRPCDispatcher) HandleMiddlewareCache(b *apidef.BundleManifest, basePath string) {}
 
+// Close stops the health checks and closes the connections to the plugin servers.
+func (d *GRPCDispatcher) Close() {
+	close(d.stop)
+	for _, endpoint := range d.endpoints {
+		endpoint.conn.Close()
+	}
+}
+
+// healthCheck periodically checks the health of the plugin servers until the dispatcher is closed.
+func (d *GRPCDispatcher) healthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.checkHealth()
+		}
+	}
+}
+
+// checkHealth ejects the plugin servers failing health checks and restores them once they recover.
+func (d *GRPCDispatcher) checkHealth() {
+	for _, endpoint := range d.endpoints {
+		healthy := endpoint.check()
+		if endpoint.healthy.Swap(healthy) == healthy {
+			continue
+		}
+
+		logger := log.WithFields(logrus.Fields{
+			"prefix":  "coprocess",
+			"address": endpoint.address,
+		})
+		if healthy {
+			logger.Info("gRPC server is healthy, restoring it")
+		} else {
+			logger.Warning("gRPC server is unhealthy, ejecting it")
+		}
+	}
+}
+
+func (e *grpcEndpoint) check() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcHealthCheckTimeout)
+	defer cancel()
+
+	res, err := e.health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+	return err == nil && res.Status == healthpb.HealthCheckResponse_SERVING
+}
+
 func (gw *Gateway) grpcCallOpts() grpc.DialOption {
 	recvSize := gw.GetConfig().CoProcessOptions.GRPCRecvMaxSize
 	sendSize := gw.GetConfig().CoProcessOptions.GRPCSendMaxSize
@@ -85,27 +245,52 @@ func (gw *Gateway) grpcCallOpts() grpc.DialOption {
 
 // NewGRPCDispatcher wraps all the actions needed for this CP.
 func (gw *Gateway) NewGRPCDispatcher() (coprocess.Dispatcher, error) {
-	if gw.GetConfig().CoProcessOptions.CoProcessGRPCServer == "" {
+	options := gw.GetConfig().CoProcessOptions
+	if options.CoProcessGRPCServer == "" {
 		return nil, errors.New("No gRPC URL is set")
 	}
-	authority := gw.GetConfig().CoProcessOptions.GRPCAuthority
 
-	var err error
-	grpcConnection, err = grpc.Dial("",
-		gw.grpcCallOpts(),
-		grpc.WithInsecure(),
-		grpc.WithAuthority(authority),
-		grpc.WithDialer(gw.dialer),
-	)
+	d := &GRPCDispatcher{
+		balancing:    options.GRPCLoadBalancing,
+		timeout:      time.Duration(options.GRPCTimeout) * time.Millisecond,
+		hookTimeouts: map[coprocess.HookType]time.Duration{},
+		stop:         make(chan struct{}),
+	}
 
-	grpcClient = coprocess.NewDispatcherClient(grpcConnection)
+	for hookType, key := range grpcHookTimeoutKeys {
+		if timeout, ok := options.GRPCHookTimeouts[key]; ok {
+			d.hookTimeouts[hookType] = time.Duration(timeout) * time.Millisecond
+		}
+	}
 
-	if err != nil {
+	for _, address := range append([]string{options.CoProcessGRPCServer}, options.CoProcessGRPCServers...) {
+		conn, err := grpc.Dial("",
+			gw.grpcCallOpts(),
+			grpc.WithInsecure(),
+			grpc.WithAuthority(options.GRPCAuthority),
+			grpc.WithDialer(grpcDialer(address)),
+		)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).Error(err)
+			d.Close()
+			return nil, err
+		}
 
-		log.WithFields(logrus.Fields{
-			"prefix": "coprocess",
-		}).Error(err)
-		return nil, err
+		endpoint := &grpcEndpoint{
+			address: address,
+			conn:    conn,
+			client:  coprocess.NewDispatcherClient(conn),
+			health:  healthpb.NewHealthClient(conn),
+		}
+		endpoint.healthy.Store(true)
+		d.endpoints = append(d.endpoints, endpoint)
 	}
-	return &GRPCDispatcher{}, nil
+
+	if interval := options.GRPCHealthCheckInterval; interval > 0 {
+		go d.healthCheck(time.Duration(interval) * time.Second)
+	}
+
+	return d, nil
 }
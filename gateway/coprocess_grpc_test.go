@@ -0,0 +1,233 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+type testGRPCPluginServer struct {
+	coprocess.UnimplementedDispatcherServer
+
+	name  string
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func (s *testGRPCPluginServer) Dispatch(ctx context.Context, object *coprocess.Object) (*coprocess.Object, error) {
+	s.calls.Add(1)
+
+	if object.HookType == coprocess.HookType_Pre && s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+		}
+	}
+
+	object.Request.SetHeaders = map[string]string{"X-Plugin-Server": s.name}
+	return object, nil
+}
+
+func (s *testGRPCPluginServer) DispatchEvent(context.Context, *coprocess.Event) (*coprocess.EventReply, error) {
+	return &coprocess.EventReply{}, nil
+}
+
+// startTestGRPCPluginServer serves the plugin server, and the health service if set, returning its address.
+func startTestGRPCPluginServer(t *testing.T, server coprocess.DispatcherServer, healthServer *health.Server) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	coprocess.RegisterDispatcherServer(s, server)
+	if healthServer != nil {
+		healthpb.RegisterHealthServer(s, healthServer)
+	}
+
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func newTestGRPCDispatcher(t *testing.T, options config.CoProcessConfig) *GRPCDispatcher {
+	t.Helper()
+
+	gw := &Gateway{}
+	gw.SetConfig(config.Config{CoProcessOptions: options})
+
+	dispatcher, err := gw.NewGRPCDispatcher()
+	require.NoError(t, err)
+	t.Cleanup(dispatcher.(*GRPCDispatcher).Close)
+
+	return dispatcher.(*GRPCDispatcher)
+}
+
+func testGRPCObject(hookType coprocess.HookType) *coprocess.Object {
+	return &coprocess.Object{
+		HookType: hookType,
+		Request:  &coprocess.MiniRequestObject{Url: "/test", Method: http.MethodGet},
+	}
+}
+
+func TestGRPCDispatcher_LoadBalancing(t *testing.T) {
+	first, second := &testGRPCPluginServer{name: "first"}, &testGRPCPluginServer{name: "second"}
+	firstAddr := startTestGRPCPluginServer(t, first, nil)
+	secondAddr := startTestGRPCPluginServer(t, second, nil)
+
+	t.Run("round robin", func(t *testing.T) {
+		d := newTestGRPCDispatcher(t, config.CoProcessConfig{
+			CoProcessGRPCServer:  firstAddr,
+			CoProcessGRPCServers: []string{secondAddr},
+		})
+
+		first.calls.Store(0)
+		second.calls.Store(0)
+		for i := 0; i < 10; i++ {
+			_, err := d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int64(5), first.calls.Load())
+		assert.Equal(t, int64(5), second.calls.Load())
+	})
+
+	t.Run("least loaded", func(t *testing.T) {
+		d := newTestGRPCDispatcher(t, config.CoProcessConfig{
+			CoProcessGRPCServer:  firstAddr,
+			CoProcessGRPCServers: []string{secondAddr},
+			GRPCLoadBalancing:    grpcLeastLoaded,
+		})
+
+		d.endpoints[0].inflight.Store(3)
+		for i := 0; i < 4; i++ {
+			endpoint, err := d.pick()
+			require.NoError(t, err)
+			assert.Equal(t, secondAddr, endpoint.address)
+		}
+
+		d.endpoints[1].inflight.Store(4)
+		endpoint, err := d.pick()
+		require.NoError(t, err)
+		assert.Equal(t, firstAddr, endpoint.address)
+	})
+}
+
+func TestGRPCDispatcher_HealthCheck(t *testing.T) {
+	healthy, unhealthy := &testGRPCPluginServer{name: "healthy"}, &testGRPCPluginServer{name: "unhealthy"}
+	healthServer := health.NewServer()
+
+	d := newTestGRPCDispatcher(t, config.CoProcessConfig{
+		CoProcessGRPCServer:  startTestGRPCPluginServer(t, unhealthy, healthServer),
+		CoProcessGRPCServers: []string{startTestGRPCPluginServer(t, healthy, nil)},
+	})
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	d.checkHealth()
+
+	for i := 0; i < 4; i++ {
+		_, err := d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+		require.NoError(t, err)
+	}
+	assert.Zero(t, unhealthy.calls.Load(), "unhealthy servers must be ejected")
+	assert.Equal(t, int64(4), healthy.calls.Load(), "servers without health service are healthy")
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	d.checkHealth()
+
+	_, err := d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+	require.NoError(t, err)
+	_, err = d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), unhealthy.calls.Load(), "recovered servers must be restored")
+
+	for _, endpoint := range d.endpoints {
+		endpoint.healthy.Store(false)
+	}
+	_, err = d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+	assert.ErrorIs(t, err, errCoProcessUnavailable)
+}
+
+func TestGRPCDispatcher_HookTimeouts(t *testing.T) {
+	server := &testGRPCPluginServer{name: "slow", delay: time.Second}
+
+	d := newTestGRPCDispatcher(t, config.CoProcessConfig{
+		CoProcessGRPCServer: startTestGRPCPluginServer(t, server, nil),
+		GRPCTimeout:         2000,
+		GRPCHookTimeouts:    map[string]int{"pre": 50},
+	})
+
+	start := time.Now()
+	_, err := d.Dispatch(testGRPCObject(coprocess.HookType_Pre))
+	assert.ErrorIs(t, err, errCoProcessUnavailable)
+	assert.Less(t, time.Since(start), time.Second)
+
+	_, err = d.Dispatch(testGRPCObject(coprocess.HookType_Post))
+	assert.NoError(t, err)
+}
+
+func TestGRPCDispatcher_FailOpen(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := "tcp://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ts := StartTest(nil, TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess:     true,
+			CoProcessGRPCServer: unreachable,
+		},
+	})
+	defer ts.Close()
+	defer func() {
+		if d, ok := loadedDrivers[apidef.GrpcDriver].(*GRPCDispatcher); ok {
+			d.Close()
+		}
+		delete(loadedDrivers, apidef.GrpcDriver)
+	}()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/fail-open/"
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver:   apidef.GrpcDriver,
+			Pre:      []apidef.MiddlewareDefinition{{Name: "pre"}},
+			FailOpen: true,
+		}
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/fail-closed/"
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver: apidef.GrpcDriver,
+			Pre:    []apidef.MiddlewareDefinition{{Name: "pre"}},
+		}
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/fail-open-auth/"
+		spec.UseKeylessAccess = false
+		spec.EnableCoProcessAuth = true
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver:    apidef.GrpcDriver,
+			AuthCheck: apidef.MiddlewareDefinition{Name: "auth"},
+			FailOpen:  true,
+		}
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/fail-open/", Code: http.StatusOK},
+		{Path: "/fail-closed/", Code: http.StatusInternalServerError},
+		{Path: "/fail-open-auth/", Headers: map[string]string{"Authorization": "key"}, Code: http.StatusForbidden},
+	}...)
+}
@@ -0,0 +1,396 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// WebAssembly plugins implement the Tyk ABI:
+//
+//   - the module exports its linear memory as `memory`,
+//   - the module exports `tyk_alloc(size i32) i32`, returning a buffer of size bytes,
+//   - every hook is an exported `(ptr i32, len i32) i64` function. It receives the protobuf encoded
+//     coprocess.Object and returns the location of the modified object, packed as `ptr<<32 | len`.
+//
+// Modules may import WASI and the `tyk.log(level i32, ptr i32, len i32)` host function.
+// Every invocation runs in a fresh module instance, so no state is shared between requests.
+const (
+	wasmMemoryExport = "memory"
+	wasmAllocExport  = "tyk_alloc"
+	wasmHostModule   = "tyk"
+
+	// wasmPageSize is the size of a WebAssembly memory page.
+	wasmPageSize = 64 * 1024
+
+	defaultWasmMemoryLimit      = 16
+	defaultWasmExecutionTimeout = time.Second
+)
+
+var (
+	errWasmHookNotLoaded = errors.New("wasm hook isn't loaded")
+	errWasmMemoryAccess  = errors.New("wasm hook accessed memory out of bounds")
+)
+
+// WasmDispatcher implements a coprocess.Dispatcher running WebAssembly plugins in a sandbox.
+type WasmDispatcher struct {
+	coprocess.Dispatcher
+
+	runtime wazero.Runtime
+	timeout time.Duration
+
+	mu      sync.Mutex
+	plugins map[string]*wasmPlugins
+	// modules holds the compiled modules by code checksum. The runtime shares compiled code
+	// between identical modules, so they can only be closed once no API or dispatch uses them anymore.
+	modules map[string]*wasmModule
+}
+
+type wasmModule struct {
+	compiled wazero.CompiledModule
+	// refs counts the APIs using the module and the dispatches running it.
+	refs int
+}
+
+// wasmPlugins holds the compiled hooks of a single API.
+type wasmPlugins struct {
+	checksums []string
+	hooks     map[wasmHookKey]*wasmHook
+}
+
+type wasmHookKey struct {
+	hookType coprocess.HookType
+	name     string
+}
+
+type wasmHook struct {
+	module   wazero.CompiledModule
+	checksum string
+	name     string
+}
+
+// NewWasmDispatcher creates the WebAssembly runtime shared by all APIs using the wasm driver.
+func NewWasmDispatcher(conf config.CoProcessConfig) (coprocess.Dispatcher, error) {
+	memoryLimit := conf.WasmMemoryLimit
+	if memoryLimit <= 0 {
+		memoryLimit = defaultWasmMemoryLimit
+	}
+
+	timeout := time.Duration(conf.WasmExecutionTimeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultWasmExecutionTimeout
+	}
+
+	ctx := context.Background()
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(memoryLimit * 1024 * 1024 / wasmPageSize)).
+		WithCloseOnContextDone(true)
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	_, err := runtime.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().WithFunc(wasmLog).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	return &WasmDispatcher{
+		runtime: runtime,
+		timeout: timeout,
+		plugins: map[string]*wasmPlugins{},
+		modules: map[string]*wasmModule{},
+	}, nil
+}
+
+// wasmLog is the `tyk.log` host function, it writes a message from the plugin to the gateway log.
+func wasmLog(_ context.Context, mod api.Module, level, ptr, size uint32) {
+	message, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return
+	}
+
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "coprocess",
+	})
+
+	switch level {
+	case 0:
+		logger.Debug(string(message))
+	case 1:
+		logger.Info(string(message))
+	case 2:
+		logger.Warning(string(message))
+	default:
+		logger.Error(string(message))
+	}
+}
+
+// Load compiles the modules used by the hooks of an API. Hooks that fail to load are logged and skipped,
+// invoking them fails the request. The returned plugins have to be passed to Unload when the API is unloaded.
+func (d *WasmDispatcher) Load(apiID string, hooks map[coprocess.HookType][]apidef.MiddlewareDefinition) *wasmPlugins {
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "coprocess",
+		"api_id": apiID,
+	})
+
+	plugins := &wasmPlugins{hooks: map[wasmHookKey]*wasmHook{}}
+	compiled := map[string]*wasmHook{}
+
+	for hookType, definitions := range hooks {
+		for _, definition := range definitions {
+			module, ok := compiled[definition.Path]
+			if !ok {
+				checksum, compiledModule, err := d.compile(definition.Path)
+				if err != nil {
+					logger.WithError(err).Errorf("Couldn't load wasm module %s", definition.Path)
+					continue
+				}
+				module = &wasmHook{module: compiledModule, checksum: checksum}
+				compiled[definition.Path] = module
+				plugins.checksums = append(plugins.checksums, checksum)
+			}
+
+			if err := validateWasmHook(module.module, definition.Name); err != nil {
+				logger.WithError(err).Errorf("Couldn't load wasm hook %s", definition.Name)
+				continue
+			}
+
+			plugins.hooks[wasmHookKey{hookType: hookType, name: definition.Name}] = &wasmHook{
+				module:   module.module,
+				checksum: module.checksum,
+				name:     definition.Name,
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.plugins[apiID] = plugins
+	d.mu.Unlock()
+
+	return plugins
+}
+
+// Unload releases the modules of an API. The hooks stay available if they were already replaced by a reload,
+// and the modules are closed once the dispatches running them are done.
+func (d *WasmDispatcher) Unload(apiID string, plugins *wasmPlugins) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.plugins[apiID] == plugins {
+		delete(d.plugins, apiID)
+	}
+
+	for _, checksum := range plugins.checksums {
+		d.releaseModule(checksum)
+	}
+}
+
+// releaseModule drops a reference to the module, closing it when it was the last one. d.mu must be held.
+func (d *WasmDispatcher) releaseModule(checksum string) {
+	module := d.modules[checksum]
+	module.refs--
+	if module.refs == 0 {
+		delete(d.modules, checksum)
+		module.compiled.Close(context.Background())
+	}
+}
+
+// acquireHook returns the hook invoked by the object, holding a reference to its module until it's released.
+func (d *WasmDispatcher) acquireHook(object *coprocess.Object) *wasmHook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	plugins := d.plugins[object.Spec["APIID"]]
+	if plugins == nil {
+		return nil
+	}
+
+	hook := plugins.hooks[wasmHookKey{hookType: object.HookType, name: object.HookName}]
+	if hook == nil {
+		return nil
+	}
+
+	d.modules[hook.checksum].refs++
+	return hook
+}
+
+func (d *WasmDispatcher) releaseHook(hook *wasmHook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.releaseModule(hook.checksum)
+}
+
+// compile returns the compiled module stored at path along with its checksum,
+// the module is compiled only if no other API uses it already.
+func (d *WasmDispatcher) compile(path string) (string, wazero.CompiledModule, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sum := sha256.Sum256(code)
+	checksum := hex.EncodeToString(sum[:])
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if module, ok := d.modules[checksum]; ok {
+		module.refs++
+		return checksum, module.compiled, nil
+	}
+
+	compiled, err := d.runtime.CompileModule(context.Background(), code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, ok := compiled.ExportedMemories()[wasmMemoryExport]; !ok {
+		compiled.Close(context.Background())
+		return "", nil, fmt.Errorf("module doesn't export %q", wasmMemoryExport)
+	}
+
+	if err := validateWasmFunction(compiled, wasmAllocExport, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}); err != nil {
+		compiled.Close(context.Background())
+		return "", nil, err
+	}
+
+	d.modules[checksum] = &wasmModule{compiled: compiled, refs: 1}
+	return checksum, compiled, nil
+}
+
+func validateWasmHook(module wazero.CompiledModule, name string) error {
+	return validateWasmFunction(module, name, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64})
+}
+
+func validateWasmFunction(module wazero.CompiledModule, name string, params, results []api.ValueType) error {
+	function, ok := module.ExportedFunctions()[name]
+	if !ok {
+		return fmt.Errorf("module doesn't export %q", name)
+	}
+
+	if !equalValueTypes(function.ParamTypes(), params) || !equalValueTypes(function.ResultTypes(), results) {
+		return fmt.Errorf("function %q has an invalid signature", name)
+	}
+
+	return nil
+}
+
+func equalValueTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Dispatch runs the hook in a new module instance, within the memory and execution time limits.
+func (d *WasmDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	hook := d.acquireHook(object)
+	if hook == nil {
+		return nil, errWasmHookNotLoaded
+	}
+	defer d.releaseHook(hook)
+
+	input, err := proto.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	output, err := d.call(ctx, hook, input)
+	if err != nil {
+		return nil, fmt.Errorf("wasm hook %s failed: %w", hook.name, err)
+	}
+
+	newObject := &coprocess.Object{}
+	if err := proto.Unmarshal(output, newObject); err != nil {
+		return nil, fmt.Errorf("wasm hook %s returned an invalid object: %w", hook.name, err)
+	}
+
+	return newObject, nil
+}
+
+func (d *WasmDispatcher) call(ctx context.Context, hook *wasmHook, input []byte) ([]byte, error) {
+	// modules are anonymous so that any number of them can be instantiated concurrently
+	moduleConfig := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+
+	mod, err := d.runtime.InstantiateModule(ctx, hook.module, moduleConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer mod.Close(context.Background())
+
+	results, err := mod.ExportedFunction(wasmAllocExport).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, input) {
+		return nil, errWasmMemoryAccess
+	}
+
+	results, err = mod.ExportedFunction(hook.name).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+
+	output, ok := mod.Memory().Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		return nil, errWasmMemoryAccess
+	}
+
+	// the memory is released with the module, keep a copy
+	return append([]byte(nil), output...), nil
+}
+
+// DispatchEvent isn't supported by WebAssembly plugins.
+func (d *WasmDispatcher) DispatchEvent(eventJSON []byte) {}
+
+// Reload isn't used by WebAssembly plugins, modules are compiled when the API is loaded.
+func (d *WasmDispatcher) Reload() {}
+
+// HandleMiddlewareCache isn't used by WebAssembly plugins.
+func (d *WasmDispatcher) HandleMiddlewareCache(b *apidef.BundleManifest, basePath string) {}
+
+// loadWasmPlugins compiles the WebAssembly modules of the API's custom middleware.
+func (gw *Gateway) loadWasmPlugins(spec *APISpec, hooks map[coprocess.HookType][]apidef.MiddlewareDefinition) {
+	dispatcher, ok := loadedDrivers[apidef.WasmDriver].(*WasmDispatcher)
+	if !ok {
+		return
+	}
+
+	plugins := dispatcher.Load(spec.APIID, hooks)
+	spec.AddUnloadHook(func() {
+		dispatcher.Unload(spec.APIID, plugins)
+	})
+}
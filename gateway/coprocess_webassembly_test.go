@@ -0,0 +1,251 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+const (
+	testWasmInputOffset  = 1024
+	testWasmOutputOffset = 32 * 1024
+)
+
+// testWasmModule assembles a module implementing the Tyk ABI with the hooks:
+//   - echo, returning the object unchanged,
+//   - respond, returning the given object,
+//   - spin, never returning.
+func testWasmModule(memoryPages uint32, respond []byte) []byte {
+	section := func(id byte, content ...[]byte) []byte {
+		var body []byte
+		for _, c := range content {
+			body = append(body, c...)
+		}
+		return append(append([]byte{id}, wasmULEB(uint64(len(body)))...), body...)
+	}
+	name := func(s string) []byte {
+		return append(wasmULEB(uint64(len(s))), s...)
+	}
+	code := func(instructions ...byte) []byte {
+		body := append([]byte{0x00}, instructions...)
+		return append(wasmULEB(uint64(len(body))), body...)
+	}
+
+	packed := int64(testWasmOutputOffset)<<32 | int64(len(respond))
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(0x01,
+		[]byte{0x02},
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},       // (i32) -> i32
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // (i32, i32) -> i64
+	)...)
+	module = append(module, section(0x03, []byte{0x04, 0x00, 0x01, 0x01, 0x01})...)
+	module = append(module, section(0x05, []byte{0x01, 0x00}, wasmULEB(uint64(memoryPages)))...)
+	module = append(module, section(0x07,
+		[]byte{0x05},
+		name("memory"), []byte{0x02, 0x00},
+		name("tyk_alloc"), []byte{0x00, 0x00},
+		name("echo"), []byte{0x00, 0x01},
+		name("respond"), []byte{0x00, 0x02},
+		name("spin"), []byte{0x00, 0x03},
+	)...)
+	module = append(module, section(0x0a,
+		[]byte{0x04},
+		// i32.const input offset
+		code(append(append([]byte{0x41}, wasmSLEB(testWasmInputOffset)...), 0x0b)...),
+		// (i64(ptr) << 32) | i64(len)
+		code(0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b),
+		// i64.const packed output location
+		code(append(append([]byte{0x42}, wasmSLEB(packed)...), 0x0b)...),
+		// loop br 0 end, i64.const 0
+		code(0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00, 0x0b),
+	)...)
+	module = append(module, section(0x0b,
+		[]byte{0x01, 0x00, 0x41}, wasmSLEB(testWasmOutputOffset), []byte{0x0b},
+		wasmULEB(uint64(len(respond))), respond,
+	)...)
+
+	return module
+}
+
+func wasmULEB(v uint64) (out []byte) {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func wasmSLEB(v int64) (out []byte) {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func writeTestWasmModule(t *testing.T, memoryPages uint32) string {
+	t.Helper()
+
+	respond, err := proto.Marshal(&coprocess.Object{
+		Request: &coprocess.MiniRequestObject{
+			Url:    "/respond/",
+			Method: http.MethodGet,
+			ReturnOverrides: &coprocess.ReturnOverrides{
+				ResponseCode:  http.StatusForbidden,
+				ResponseError: "denied by wasm",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "plugin.wasm")
+	require.NoError(t, os.WriteFile(path, testWasmModule(memoryPages, respond), 0644))
+	return path
+}
+
+func TestWasmDispatcher(t *testing.T) {
+	dispatcher, err := NewWasmDispatcher(config.CoProcessConfig{WasmMemoryLimit: 1, WasmExecutionTimeout: 100})
+	require.NoError(t, err)
+	d := dispatcher.(*WasmDispatcher)
+
+	path := writeTestWasmModule(t, 1)
+	hooks := func(names ...string) map[coprocess.HookType][]apidef.MiddlewareDefinition {
+		var definitions []apidef.MiddlewareDefinition
+		for _, name := range names {
+			definitions = append(definitions, apidef.MiddlewareDefinition{Name: name, Path: path})
+		}
+		return map[coprocess.HookType][]apidef.MiddlewareDefinition{coprocess.HookType_Pre: definitions}
+	}
+
+	plugins := d.Load("api", hooks("echo", "respond", "spin", "missing"))
+	assert.Len(t, plugins.hooks, 3)
+
+	object := func(name string) *coprocess.Object {
+		return &coprocess.Object{
+			HookType: coprocess.HookType_Pre,
+			HookName: name,
+			Request:  &coprocess.MiniRequestObject{Url: "/test", Method: http.MethodGet},
+			Spec:     map[string]string{"APIID": "api"},
+		}
+	}
+
+	t.Run("echo", func(t *testing.T) {
+		in := object("echo")
+		out, err := d.Dispatch(in)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(in, out))
+	})
+
+	t.Run("respond", func(t *testing.T) {
+		out, err := d.Dispatch(object("respond"))
+		require.NoError(t, err)
+		assert.Equal(t, int32(http.StatusForbidden), out.Request.ReturnOverrides.ResponseCode)
+	})
+
+	t.Run("execution timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := d.Dispatch(object("spin"))
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("hook not loaded", func(t *testing.T) {
+		_, err := d.Dispatch(object("missing"))
+		assert.ErrorIs(t, err, errWasmHookNotLoaded)
+
+		in := object("echo")
+		in.HookType = coprocess.HookType_Post
+		_, err = d.Dispatch(in)
+		assert.ErrorIs(t, err, errWasmHookNotLoaded)
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		plugins := d.Load("large", map[coprocess.HookType][]apidef.MiddlewareDefinition{
+			coprocess.HookType_Pre: {{Name: "echo", Path: writeTestWasmModule(t, 32)}},
+		})
+		assert.Empty(t, plugins.hooks)
+	})
+
+	t.Run("unload while dispatching", func(t *testing.T) {
+		busy := d.Load("busy", map[coprocess.HookType][]apidef.MiddlewareDefinition{
+			coprocess.HookType_Pre: {{Name: "echo", Path: writeTestWasmModule(t, 2)}},
+		})
+
+		in := object("echo")
+		in.Spec["APIID"] = "busy"
+		hook := d.acquireHook(in)
+		require.NotNil(t, hook)
+
+		d.Unload("busy", busy)
+		assert.Contains(t, d.modules, hook.checksum, "modules must stay open while dispatches run them")
+
+		input, err := proto.Marshal(in)
+		require.NoError(t, err)
+		_, err = d.call(context.Background(), hook, input)
+		assert.NoError(t, err)
+
+		d.releaseHook(hook)
+		assert.NotContains(t, d.modules, hook.checksum)
+	})
+
+	t.Run("unload", func(t *testing.T) {
+		reloaded := d.Load("api", hooks("echo"))
+		d.Unload("api", plugins)
+
+		_, err := d.Dispatch(object("echo"))
+		assert.NoError(t, err, "unloading replaced plugins must keep the new ones")
+
+		d.Unload("api", reloaded)
+		_, err = d.Dispatch(object("echo"))
+		assert.ErrorIs(t, err, errWasmHookNotLoaded)
+	})
+}
+
+func TestWasmMiddleware(t *testing.T) {
+	ts := StartTest(nil, TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess: true,
+		},
+	})
+	defer ts.Close()
+
+	path := writeTestWasmModule(t, 1)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/echo/"
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver: apidef.WasmDriver,
+			Pre:    []apidef.MiddlewareDefinition{{Name: "echo", Path: path}},
+		}
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/respond/"
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver: apidef.WasmDriver,
+			Pre:    []apidef.MiddlewareDefinition{{Name: "respond", Path: path}},
+		}
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/echo/", Code: http.StatusOK},
+		{Path: "/respond/", Code: http.StatusForbidden, BodyMatch: "denied by wasm"},
+	}...)
+}
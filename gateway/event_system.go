@@ -57,6 +57,8 @@ const (
 	EventTokenUpdated = event.TokenUpdated
 	// EventTokenDeleted is an alias maintained for backwards compatibility.
 	EventTokenDeleted = event.TokenDeleted
+	// EventResponseValidationFailed is the event triggered when an upstream response fails OAS validation.
+	EventResponseValidationFailed = event.ResponseValidationFailed
 )
 
 // EventMetaDefault is a standard embedded struct to be used with custom event metadata types, gives an interface for
@@ -111,6 +113,16 @@ type EventTriggerExceededMeta struct {
 	UsagePercentage int64  `json:"usage_percentage"`
 }
 
+// EventResponseValidationFailedMeta is the metadata structure for an upstream response failing OAS validation.
+type EventResponseValidationFailedMeta struct {
+	EventMetaDefault
+	Path       string
+	Method     string
+	APIID      string
+	StatusCode int
+	Reason     string
+}
+
 type EventTokenMeta struct {
 	EventMetaDefault
 	Org string
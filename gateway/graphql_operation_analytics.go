@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/otel"
+)
+
+// graphQLOperationRecord returns the operation record of the extracted stats, with the data source calls tracked
+// during the execution of the request.
+func graphQLOperationRecord(r *http.Request, operationName string, stats analytics.GraphQLStats) graphqlinternal.OperationRecord {
+	record := graphqlinternal.NewOperationRecord(operationName, stats)
+	if tracker := graphqlinternal.DataSourceTrackerFromContext(r.Context()); tracker != nil {
+		record.DataSources = tracker.Stats()
+	}
+	return record
+}
+
+// graphQLOperationStats holds the stats of a GraphQL operation and its response.
+type graphQLOperationStats struct {
+	operationName string
+	stats         analytics.GraphQLStats
+}
+
+// extractGraphQLOperationStats returns the stats of the GraphQL operation of the request and its response. They are
+// extracted once per request, for both the OpenTelemetry span and the analytics records.
+func extractGraphQLOperationStats(r *http.Request, resp *http.Response, spec *APISpec) (*graphQLOperationStats, error) {
+	if stats := ctxGetGraphQLOperationStats(r); stats != nil {
+		return stats, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody []byte
+	if resp.Body != nil {
+		httputil.RemoveResponseTransferEncoding(resp, "chunked")
+		respBody, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = respBodyReader(r, resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	extractor := graphqlinternal.NewGraphStatsExtractor()
+	extracted, err := extractor.ExtractStats(string(body), string(respBody), spec.graphQLSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &graphQLOperationStats{operationName: extractor.OperationName(), stats: extracted}
+	ctxSetGraphQLOperationStats(r, stats)
+	return stats, nil
+}
+
+// traceGraphQLOperation adds the operation record of a GraphQL request to its OpenTelemetry span.
+func (s *SuccessHandler) traceGraphQLOperation(r *http.Request, resp *http.Response) {
+	if !s.Gw.GetConfig().OpenTelemetry.Enabled || !s.Spec.GraphQL.Enabled ||
+		s.Spec.GraphQL.ExecutionMode == apidef.GraphQLExecutionModeSubgraph || r.Body == nil {
+		return
+	}
+
+	stats, err := extractGraphQLOperationStats(r, resp, s.Spec)
+	if err != nil {
+		s.Logger().WithError(err).Debug("GraphQL operation could not be traced")
+		return
+	}
+
+	graphqlinternal.TraceOperation(otel.SpanFromContext(r.Context()), graphQLOperationRecord(r, stats.operationName, stats.stats))
+}
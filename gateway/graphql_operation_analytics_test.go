@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGraphQLOperationAnalytics(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.AnalyticsConfig.EnableGraphQLOperationRecords = true
+	})
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1, "name": "Ada"}`))
+	}))
+	defer upstream.Close()
+
+	conf, err := json.Marshal(apidef.GraphQLEngineDataSourceConfigREST{
+		URL:    upstream.URL + "/users/{{ .arguments.id }}",
+		Method: http.MethodGet,
+	})
+	require.NoError(t, err)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "graphql-operation-analytics"
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `type Query { user(id: Int!): User } type User { id: Int name: String }`
+		spec.GraphQL.Engine.FieldConfigs = []apidef.GraphQLFieldConfig{
+			{TypeName: "Query", FieldName: "user", DisableDefaultMapping: true},
+		}
+		spec.GraphQL.Engine.DataSources = []apidef.GraphQLEngineDataSource{{
+			Kind:       apidef.GraphQLEngineDataSourceKindREST,
+			Name:       "users-api",
+			RootFields: []apidef.GraphQLTypeFields{{Type: "Query", Fields: []string{"user"}}},
+			Config:     conf,
+		}}
+	})
+
+	records := make(chan *graphqlinternal.OperationRecord, 1)
+	ts.Gw.Analytics.mockEnabled = true
+	ts.Gw.Analytics.mockRecordHit = func(*analytics.AnalyticsRecord) {}
+	ts.Gw.Analytics.mockRecordGraphQLOperation = func(record *graphqlinternal.OperationRecord) {
+		records <- record
+	}
+	defer func() {
+		ts.Gw.Analytics.mockEnabled = false
+	}()
+
+	t.Run("operation and fields", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Data: gql.Request{OperationName: "GetUser", Query: `query GetUser { user(id: 1) { name } }`},
+			Code: http.StatusOK,
+		})
+
+		record := <-records
+		assert.Equal(t, "graphql-operation-analytics", record.APIID)
+		assert.Equal(t, "GetUser", record.OperationName)
+		assert.Equal(t, "query", record.OperationType)
+		assert.Equal(t, []string{"user"}, record.RootFields)
+		assert.Equal(t, map[string][]string{"User": {"name"}}, record.Types)
+		assert.Zero(t, record.ErrorCount)
+		require.Len(t, record.DataSources, 1)
+		assert.Equal(t, "users-api", record.DataSources[0].Name)
+		assert.Equal(t, 1, record.DataSources[0].Calls)
+		assert.Zero(t, record.DataSources[0].Errors)
+	})
+
+	t.Run("data source errors", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Data: gql.Request{Query: `{ user(id: 2) { id } }`},
+			Code: http.StatusOK,
+		})
+
+		record := <-records
+		assert.Empty(t, record.OperationName)
+		require.Len(t, record.DataSources, 1)
+		assert.Equal(t, "users-api", record.DataSources[0].Name)
+		assert.Equal(t, 1, record.DataSources[0].Errors)
+	})
+}
+
+func TestGraphQLOperationAnalytics_Disabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.Analytics.mockEnabled = true
+	ts.Gw.Analytics.mockRecordGraphQLOperation = func(*graphqlinternal.OperationRecord) {
+		t.Error("GraphQL operation records must not be recorded unless enabled")
+	}
+	defer func() {
+		ts.Gw.Analytics.mockEnabled = false
+	}()
+
+	ts.Gw.Analytics.RecordGraphQLOperation(&graphqlinternal.OperationRecord{})
+}
+
+func TestRedisPurger_GraphQLOperationRecords(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	store := &storage.RedisCluster{KeyPrefix: "analytics-", IsAnalytics: true, ConnectionHandler: ts.Gw.StorageConnectionHandler}
+	store.AppendToSetPipelined(graphQLAnalyticsKeyName, [][]byte{[]byte("{}")})
+	defer store.GetAndDeleteSet(graphQLAnalyticsKeyName)
+
+	purger := RedisPurger{Store: store, Gw: ts.Gw}
+	purger.PurgeCache()
+
+	exp, err := store.GetExp(graphQLAnalyticsKeyName)
+	require.NoError(t, err)
+	assert.Greater(t, exp, int64(0))
+}
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGraphQLOverHTTP(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 1, "name": "Ada"}`))
+	}))
+	defer upstream.Close()
+
+	conf, err := json.Marshal(apidef.GraphQLEngineDataSourceConfigREST{
+		URL:    upstream.URL + "/users/1",
+		Method: http.MethodGet,
+	})
+	require.NoError(t, err)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `type Query { user: User } type Mutation { updateUser: User } type User { id: Int name: String }`
+		spec.GraphQL.Engine.FieldConfigs = []apidef.GraphQLFieldConfig{
+			{TypeName: "Query", FieldName: "user", DisableDefaultMapping: true},
+			{TypeName: "Mutation", FieldName: "updateUser", DisableDefaultMapping: true},
+		}
+		spec.GraphQL.Engine.DataSources = []apidef.GraphQLEngineDataSource{{
+			Kind: apidef.GraphQLEngineDataSourceKindREST,
+			Name: "users-api",
+			RootFields: []apidef.GraphQLTypeFields{
+				{Type: "Query", Fields: []string{"user"}},
+				{Type: "Mutation", Fields: []string{"updateUser"}},
+			},
+			Config: conf,
+		}}
+	})
+
+	queryString := func(query string) string {
+		return "/?" + url.Values{"query": {query}}.Encode()
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Method:    http.MethodGet,
+			Path:      queryString(`{ user { name } }`),
+			Code:      http.StatusOK,
+			BodyMatch: `{"data":{"user":{"name":"Ada"}}}`,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         queryString(`mutation { updateUser { name } }`),
+			Code:         http.StatusMethodNotAllowed,
+			HeadersMatch: map[string]string{"Allow": http.MethodPost},
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/?query={user{name}}&variables={",
+			Code:      http.StatusBadRequest,
+			BodyMatch: "invalid GraphQL request in query string",
+		},
+		{
+			Method:       http.MethodPost,
+			Data:         gql.Request{Query: `{ user { name } }`},
+			Headers:      map[string]string{"Accept": "application/graphql-response+json, application/json"},
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{"Content-Type": "application/graphql-response+json; charset=utf-8"},
+		},
+		{
+			Method:       http.MethodPost,
+			Data:         gql.Request{Query: `{ user { id ... @defer { name } } }`},
+			Headers:      map[string]string{"Accept": `multipart/mixed; deferSpec=20220824, application/json`},
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{"Content-Type": `multipart/mixed; boundary="-"; deferSpec=20220824`},
+			BodyMatch: `{"data":{"user":{"id":1}},"hasNext":true}` +
+				"\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+				`{"incremental":\[{"data":{"name":"Ada"},"path":\["user"\]}\],"hasNext":false}`,
+		},
+		{
+			Method:    http.MethodPost,
+			Data:      gql.Request{Query: `{ user { id ... @defer { name } } }`},
+			Code:      http.StatusOK,
+			BodyMatch: `{"data":{"user":{"id":1,"name":"Ada"}}}`,
+		},
+	}...)
+}
+
+func TestGraphQLOverHTTP_SchemaDefiningDefer(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstreamQuery := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request gql.Request
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		upstreamQuery <- request.Query
+		_, _ = w.Write([]byte(`{"data":{"user":{"id":1,"name":"Ada"}}}`))
+	}))
+	defer upstream.Close()
+
+	conf, err := json.Marshal(apidef.GraphQLEngineDataSourceConfigGraphQL{
+		URL:    upstream.URL,
+		Method: http.MethodPost,
+	})
+	require.NoError(t, err)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `directive @defer(label: String, if: Boolean! = true) on FRAGMENT_SPREAD | INLINE_FRAGMENT
+			type Query { user: User } type User { id: Int name: String }`
+		spec.GraphQL.Engine.DataSources = []apidef.GraphQLEngineDataSource{{
+			Kind:       apidef.GraphQLEngineDataSourceKindGraphQL,
+			Name:       "users-graph",
+			RootFields: []apidef.GraphQLTypeFields{{Type: "Query", Fields: []string{"user"}}},
+			Config:     conf,
+		}}
+	})
+
+	// the schema defines @defer, the directive is left to the upstream and the response isn't split
+	_, _ = ts.Run(t, test.TestCase{
+		Method:       http.MethodPost,
+		Data:         gql.Request{Query: `{ user { id ... @defer { name } } }`},
+		Headers:      map[string]string{"Accept": `multipart/mixed; deferSpec=20220824, application/json`},
+		Code:         http.StatusOK,
+		HeadersMatch: map[string]string{"Content-Type": "application/json"},
+	})
+
+	select {
+	case query := <-upstreamQuery:
+		assert.Contains(t, query, "@defer")
+	default:
+		t.Fatal("the upstream wasn't called")
+	}
+}
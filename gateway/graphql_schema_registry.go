@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	graphQLSchemaRegistryKeyPrefix = "graphql-schema-registry-"
+
+	defaultGraphQLSchemaMaxVersions = 10
+)
+
+// GraphQLSchemaVersion is a version of the schema a GraphQL API was loaded with.
+type GraphQLSchemaVersion struct {
+	Version   int       `json:"version"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	// Breaking tells whether any of the changes from the previous version is breaking.
+	Breaking bool                           `json:"breaking"`
+	Changes  []graphqlinternal.SchemaChange `json:"changes"`
+	SDL      string                         `json:"sdl,omitempty"`
+}
+
+// GraphQLSchemaDiff lists the changes between two schema versions of a GraphQL API.
+type GraphQLSchemaDiff struct {
+	APIID    string                         `json:"api_id"`
+	From     int                            `json:"from"`
+	To       int                            `json:"to"`
+	Breaking bool                           `json:"breaking"`
+	Changes  []graphqlinternal.SchemaChange `json:"changes"`
+}
+
+// graphQLSchemaBreakingChangesError is returned by the Control API when an API update is rejected because of
+// breaking schema changes.
+type graphQLSchemaBreakingChangesError struct {
+	apiStatusMessage
+	Changes []graphqlinternal.SchemaChange `json:"changes"`
+}
+
+func (gw *Gateway) graphQLSchemaRegistryStore() *storage.RedisCluster {
+	return &storage.RedisCluster{KeyPrefix: graphQLSchemaRegistryKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+}
+
+// graphQLSchemaVersions returns the recorded schema versions of an API, oldest first.
+func (gw *Gateway) graphQLSchemaVersions(apiID string) ([]GraphQLSchemaVersion, error) {
+	value, err := gw.graphQLSchemaRegistryStore().GetKey(apiID)
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return []GraphQLSchemaVersion{}, nil
+		}
+		return nil, err
+	}
+
+	versions := []GraphQLSchemaVersion{}
+	if err := json.Unmarshal([]byte(value), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// recordGraphQLSchemaVersion stores the schema of the API as a new version when it differs from the latest one.
+func (gw *Gateway) recordGraphQLSchemaVersion(spec *APISpec, logger *logrus.Entry) {
+	conf := spec.GraphQL.SchemaRegistry
+	schema := graphQLSpecSchema(spec)
+	if schema == "" {
+		return
+	}
+
+	versions, err := gw.graphQLSchemaVersions(spec.APIID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to read GraphQL schema versions")
+		return
+	}
+
+	version := GraphQLSchemaVersion{
+		Version:   1,
+		Hash:      graphQLDocumentHash(schema),
+		CreatedAt: time.Now(),
+		Changes:   []graphqlinternal.SchemaChange{},
+		SDL:       schema,
+	}
+
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if latest.Hash == version.Hash {
+			return
+		}
+
+		version.Version = latest.Version + 1
+		if version.Changes, err = graphqlinternal.DiffSchemas(latest.SDL, schema); err != nil {
+			logger.WithError(err).Error("Failed to diff GraphQL schema versions")
+			return
+		}
+		version.Breaking = graphqlinternal.HasBreakingChanges(version.Changes)
+	}
+
+	maxVersions := conf.MaxVersions
+	if maxVersions <= 0 {
+		maxVersions = defaultGraphQLSchemaMaxVersions
+	}
+
+	versions = append(versions, version)
+	if len(versions) > maxVersions {
+		versions = versions[len(versions)-maxVersions:]
+	}
+
+	value, err := json.Marshal(versions)
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode GraphQL schema versions")
+		return
+	}
+
+	if err := gw.graphQLSchemaRegistryStore().SetKey(spec.APIID, string(value), 0); err != nil {
+		logger.WithError(err).Error("Failed to store GraphQL schema versions")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"version":  version.Version,
+		"breaking": version.Breaking,
+	}).Info("Recorded GraphQL schema version")
+}
+
+// graphQLSchemaChanges diffs the schema the API is loaded with against the schema of the updated definition.
+func graphQLSchemaChanges(spec *APISpec, newDef *apidef.APIDefinition) ([]graphqlinternal.SchemaChange, error) {
+	newSchema := graphQLAPISchema(newDef)
+	if newDef.GraphQL.ExecutionMode == apidef.GraphQLExecutionModeSupergraph {
+		composition, err := graphqlinternal.ComposeSupergraph(newDef.GraphQL.Supergraph)
+		if err != nil {
+			return nil, err
+		}
+		if composition != nil {
+			newSchema = composition.SupergraphSDL
+		}
+	}
+
+	return graphqlinternal.DiffSchemas(graphQLSpecSchema(spec), newSchema)
+}
+
+// graphQLSpecSchema returns the schema exposed to the clients of a loaded GraphQL API.
+func graphQLSpecSchema(spec *APISpec) string {
+	if spec.GraphQL.Enabled && spec.composedSupergraphSDL != "" {
+		return spec.composedSupergraphSDL
+	}
+	return graphQLAPISchema(spec.APIDefinition)
+}
+
+// graphQLAPISchema returns the schema exposed to the clients of a GraphQL API.
+func graphQLAPISchema(def *apidef.APIDefinition) string {
+	if !def.GraphQL.Enabled {
+		return ""
+	}
+
+	if def.GraphQL.ExecutionMode == apidef.GraphQLExecutionModeSupergraph && def.GraphQL.Supergraph.MergedSDL != "" {
+		return def.GraphQL.Supergraph.MergedSDL
+	}
+	return def.GraphQL.Schema
+}
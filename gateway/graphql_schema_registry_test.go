@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGraphQLSchemaRegistry(t *testing.T) {
+	g := StartTest(nil)
+	t.Cleanup(g.Close)
+
+	// versions outlive the gateway, the registry must start empty
+	g.Gw.graphQLSchemaRegistryStore().DeleteKey("schema-registry")
+
+	buildAPI := func(schema string) *APISpec {
+		return BuildAPI(func(spec *APISpec) {
+			spec.APIID = "schema-registry"
+			spec.Proxy.ListenPath = "/"
+			spec.GraphQL.Enabled = true
+			spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+			spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+			spec.GraphQL.Schema = schema
+			spec.GraphQL.SchemaRegistry.Enabled = true
+			spec.GraphQL.SchemaRegistry.MaxVersions = 2
+			spec.GraphQL.SchemaRegistry.BlockBreakingChanges = true
+		})[0]
+	}
+
+	g.Gw.LoadAPI(buildAPI(`type Query { hello: String }`))
+	g.Gw.LoadAPI(buildAPI(`type Query { hello: String }`))
+	g.Gw.LoadAPI(buildAPI(`type Query { hello: String! }`))
+	spec := buildAPI(`type Query { hello: String! world: String }`)
+	g.Gw.LoadAPI(spec)
+
+	t.Run("versions", func(t *testing.T) {
+		resp, err := g.Run(t, test.TestCase{Path: "/tyk/apis/schema-registry/graphql/schema/versions", AdminAuth: true, Code: http.StatusOK})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var versions []GraphQLSchemaVersion
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&versions))
+		require.Len(t, versions, 2)
+
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Equal(t, 3, versions[1].Version)
+		assert.False(t, versions[1].Breaking)
+		assert.Empty(t, versions[1].SDL)
+		require.Len(t, versions[1].Changes, 1)
+		assert.Equal(t, graphqlinternal.SchemaChangeFieldAdded, versions[1].Changes[0].Type)
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Path: "/tyk/apis/schema-registry/graphql/schema/versions/3", AdminAuth: true, Code: http.StatusOK, BodyMatch: `"sdl":"type Query { hello: String! world: String }"`},
+			{Path: "/tyk/apis/schema-registry/graphql/schema/versions/1", AdminAuth: true, Code: http.StatusNotFound},
+		}...)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		_, _ = g.Run(t, []test.TestCase{
+			{Path: "/tyk/apis/schema-registry/graphql/schema/diff", AdminAuth: true, Code: http.StatusOK, BodyMatch: `"from":2,"to":3,"breaking":false`},
+			{Path: "/tyk/apis/schema-registry/graphql/schema/diff?from=3&to=2", AdminAuth: true, Code: http.StatusOK, BodyMatch: `"breaking":true`},
+			{Path: "/tyk/apis/schema-registry/graphql/schema/diff?from=1", AdminAuth: true, Code: http.StatusNotFound},
+			{Path: "/tyk/apis/unknown/graphql/schema/diff", AdminAuth: true, Code: http.StatusNotFound},
+		}...)
+	})
+
+	t.Run("block breaking changes", func(t *testing.T) {
+		breaking := *spec.APIDefinition
+		breaking.GraphQL.Schema = `type Query { world: String }`
+
+		_, _ = g.Run(t, test.TestCase{
+			Method: http.MethodPut, Path: "/tyk/apis/schema-registry", AdminAuth: true, Data: breaking,
+			Code: http.StatusBadRequest, BodyMatch: graphqlinternal.SchemaChangeFieldRemoved,
+		})
+	})
+}
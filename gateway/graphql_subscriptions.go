@@ -0,0 +1,313 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/subscription"
+	astv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	subscriptionv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
+
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+var GraphQLSubscriptionLimitExceededErr = errors.New("max concurrent subscriptions reached")
+
+// GraphQLSubscription is an active GraphQL subscription on this gateway node.
+type GraphQLSubscription struct {
+	ID            string     `json:"id"`
+	APIID         string     `json:"api_id"`
+	KeyHash       string     `json:"key_hash,omitempty"`
+	OperationName string     `json:"operation_name,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+type graphQLSubscription struct {
+	GraphQLSubscription
+
+	policies []string
+	conn     *graphQLSubscriptionConn
+	timer    *time.Timer
+}
+
+// graphQLSubscriptionRegistry tracks the active GraphQL subscriptions of this node, so the limits of keys can be
+// enforced and subscriptions can be terminated.
+type graphQLSubscriptionRegistry struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*graphQLSubscription
+}
+
+func newGraphQLSubscriptionRegistry() *graphQLSubscriptionRegistry {
+	return &graphQLSubscriptionRegistry{subscriptions: map[string]*graphQLSubscription{}}
+}
+
+// add registers the subscription unless the key already has max subscriptions to the same API. Max is unlimited
+// when it's 0 or less.
+func (s *graphQLSubscriptionRegistry) add(sub *graphQLSubscription, max int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if max > 0 && sub.KeyHash != "" {
+		count := 0
+		for _, active := range s.subscriptions {
+			if active.KeyHash == sub.KeyHash && active.APIID == sub.APIID {
+				count++
+			}
+		}
+
+		if count >= max {
+			return false
+		}
+	}
+
+	s.subscriptions[sub.ID] = sub
+	return true
+}
+
+func (s *graphQLSubscriptionRegistry) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.subscriptions[id]; ok {
+		if sub.timer != nil {
+			sub.timer.Stop()
+		}
+		delete(s.subscriptions, id)
+	}
+}
+
+// List returns the active subscriptions, optionally filtered by API, ordered by start time.
+func (s *graphQLSubscriptionRegistry) List(apiID string) []GraphQLSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []GraphQLSubscription{}
+	for _, sub := range s.subscriptions {
+		if apiID == "" || sub.APIID == apiID {
+			list = append(list, sub.GraphQLSubscription)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedAt.Before(list[j].StartedAt)
+	})
+
+	return list
+}
+
+// Terminate terminates the subscription with the given id and reports whether it was active.
+func (s *graphQLSubscriptionRegistry) Terminate(id string) bool {
+	return s.terminate(func(sub *graphQLSubscription) bool {
+		return sub.ID == id
+	}) > 0
+}
+
+// TerminateKey terminates the subscriptions of a key, e.g. when it was revoked or updated.
+func (s *graphQLSubscriptionRegistry) TerminateKey(keyHash string) int {
+	if keyHash == "" {
+		return 0
+	}
+
+	return s.terminate(func(sub *graphQLSubscription) bool {
+		return sub.KeyHash == keyHash
+	})
+}
+
+// TerminatePolicies terminates the subscriptions of keys with any of the policies applied.
+func (s *graphQLSubscriptionRegistry) TerminatePolicies(policyIDs []string) int {
+	if len(policyIDs) == 0 {
+		return 0
+	}
+
+	return s.terminate(func(sub *graphQLSubscription) bool {
+		for _, policyID := range policyIDs {
+			for _, applied := range sub.policies {
+				if applied == policyID {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// terminate closes the websocket connections of the matching subscriptions. Operations of a connection can't be
+// cancelled on their own, so all other subscriptions on the same connection are terminated too.
+func (s *graphQLSubscriptionRegistry) terminate(match func(sub *graphQLSubscription) bool) int {
+	s.mu.Lock()
+
+	conns := map[*graphQLSubscriptionConn]struct{}{}
+	terminated := 0
+	for _, sub := range s.subscriptions {
+		if match(sub) {
+			conns[sub.conn] = struct{}{}
+			terminated++
+		}
+	}
+
+	for id, sub := range s.subscriptions {
+		if _, ok := conns[sub.conn]; ok {
+			if sub.timer != nil {
+				sub.timer.Stop()
+			}
+			delete(s.subscriptions, id)
+		}
+	}
+
+	s.mu.Unlock()
+
+	for conn := range conns {
+		conn.close()
+	}
+
+	return terminated
+}
+
+// graphQLSubscriptionConn tracks the subscriptions of a GraphQL websocket connection. The engine gets an executor
+// from the pool of the connection right before calling the OnBeforeStart hook for the same operation, which registers
+// the subscription.
+type graphQLSubscriptionConn struct {
+	registry *graphQLSubscriptionRegistry
+	apiID    string
+
+	conn      net.Conn
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	pending interface{}
+	active  map[interface{}]string
+}
+
+// got records the executor the engine got for the next operation of the connection.
+func (c *graphQLSubscriptionConn) got(executor interface{}, isSubscription bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = nil
+	if isSubscription {
+		c.pending = executor
+	}
+}
+
+// put unregisters the subscription of an executor that was returned to the pool.
+func (c *graphQLSubscriptionConn) put(executor interface{}) {
+	c.mu.Lock()
+	id, ok := c.active[executor]
+	delete(c.active, executor)
+	c.mu.Unlock()
+
+	if ok {
+		c.registry.remove(id)
+	}
+}
+
+// graphQLSubscriptionPool wraps the executor pool of a websocket connection handled by graphql-go-tools v1.
+type graphQLSubscriptionPool struct {
+	*graphQLSubscriptionConn
+	pool subscription.ExecutorPool
+}
+
+func (p *graphQLSubscriptionPool) Get(payload []byte) (subscription.Executor, error) {
+	executor, err := p.pool.Get(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	p.got(executor, executor.OperationType() == ast.OperationTypeSubscription)
+	return executor, nil
+}
+
+func (p *graphQLSubscriptionPool) Put(executor subscription.Executor) error {
+	p.put(executor)
+	return p.pool.Put(executor)
+}
+
+// graphQLSubscriptionPoolV2 wraps the executor pool of a websocket connection handled by graphql-go-tools v2.
+type graphQLSubscriptionPoolV2 struct {
+	*graphQLSubscriptionConn
+	pool subscriptionv2.ExecutorPool
+}
+
+func (p *graphQLSubscriptionPoolV2) Get(payload []byte) (subscriptionv2.Executor, error) {
+	executor, err := p.pool.Get(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	p.got(executor, executor.OperationType() == astv2.OperationTypeSubscription)
+	return executor, nil
+}
+
+func (p *graphQLSubscriptionPoolV2) Put(executor subscriptionv2.Executor) error {
+	p.put(executor)
+	return p.pool.Put(executor)
+}
+
+// start registers the pending subscription of the connection, enforcing the limits of the key.
+// It does nothing when the operation isn't a subscription.
+func (c *graphQLSubscriptionConn) start(operationName string, session *user.SessionState, limit user.APILimit) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	executor := c.pending
+	c.pending = nil
+	if executor == nil {
+		return nil
+	}
+
+	sub := &graphQLSubscription{
+		GraphQLSubscription: GraphQLSubscription{
+			ID:            uuid.New(),
+			APIID:         c.apiID,
+			OperationName: operationName,
+			StartedAt:     time.Now(),
+		},
+		conn: c,
+	}
+
+	if session != nil && !session.KeyHashEmpty() {
+		sub.KeyHash = session.KeyHash()
+		sub.policies = session.PolicyIDs()
+	}
+
+	if limit.MaxSubscriptionLifetime > 0 {
+		lifetime := time.Duration(limit.MaxSubscriptionLifetime) * time.Second
+		expiresAt := sub.StartedAt.Add(lifetime)
+		sub.ExpiresAt = &expiresAt
+		sub.timer = time.AfterFunc(lifetime, func() {
+			c.registry.Terminate(sub.ID)
+		})
+	}
+
+	if !c.registry.add(sub, limit.MaxSubscriptions) {
+		if sub.timer != nil {
+			sub.timer.Stop()
+		}
+		return GraphQLSubscriptionLimitExceededErr
+	}
+
+	if c.active == nil {
+		c.active = map[interface{}]string{}
+	}
+	c.active[executor] = sub.ID
+
+	return nil
+}
+
+func (c *graphQLSubscriptionConn) close() {
+	c.closeOnce.Do(func() {
+		if c.conn == nil {
+			return
+		}
+
+		if err := c.conn.Close(); err != nil {
+			log.WithError(err).Debug("Failed to close GraphQL websocket connection")
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gqlwebsocket "github.com/TykTechnologies/graphql-go-tools/pkg/subscription/websocket"
+	gqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	subscriptionv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestGraphQLSubscriptions(t *testing.T) {
+	g := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.EnableWebSockets = true
+	})
+	t.Cleanup(g.Close)
+
+	// the upstream never accepts the subscription, so it stays active until it's terminated
+	upstreamDone := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-upstreamDone:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	t.Cleanup(func() { close(upstreamDone) })
+
+	api := g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `type Query { hello: String } type Subscription { ticks: Int }`
+	})[0]
+
+	_, key := g.CreateSession(func(s *user.SessionState) {
+		s.MaxSubscriptions = 1
+		s.AccessRights = map[string]user.AccessDefinition{
+			api.APIID: {APIID: api.APIID, APIName: api.Name},
+		}
+	})
+
+	baseURL := strings.Replace(g.URL, "http://", "ws://", -1)
+	connect := func(t *testing.T) *websocket.Conn {
+		t.Helper()
+
+		wsConn, _, err := websocket.DefaultDialer.Dial(baseURL, map[string][]string{
+			header.SecWebSocketProtocol: {string(gqlwebsocket.ProtocolGraphQLWS)},
+			header.Authorization:        {key},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = wsConn.Close() })
+
+		require.NoError(t, wsConn.WriteMessage(websocket.BinaryMessage, []byte(`{"type":"connection_init","payload":{}}`)))
+		_, msg, err := wsConn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, `{"type":"connection_ack"}`, string(msg))
+
+		return wsConn
+	}
+
+	subscribe := func(t *testing.T, wsConn *websocket.Conn, id string) {
+		t.Helper()
+		require.NoError(t, wsConn.WriteMessage(websocket.BinaryMessage, []byte(`{"id":"`+id+`","type":"start","payload":{"operationName":"Ticks","query":"subscription Ticks { ticks }"}}`)))
+	}
+
+	listSubscriptions := func(t *testing.T) []GraphQLSubscription {
+		t.Helper()
+
+		resp, err := g.Run(t, test.TestCase{Path: "/tyk/graphql/subscriptions?api_id=" + api.APIID, AdminAuth: true, Code: http.StatusOK})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var subscriptions []GraphQLSubscription
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&subscriptions))
+		return subscriptions
+	}
+
+	waitForSubscriptions := func(t *testing.T, count int) []GraphQLSubscription {
+		t.Helper()
+
+		var subscriptions []GraphQLSubscription
+		require.Eventually(t, func() bool {
+			subscriptions = listSubscriptions(t)
+			return len(subscriptions) == count
+		}, 5*time.Second, 50*time.Millisecond)
+		return subscriptions
+	}
+
+	assertClosed := func(t *testing.T, wsConn *websocket.Conn) {
+		t.Helper()
+
+		_ = wsConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err := wsConn.ReadMessage()
+		assert.Error(t, err)
+	}
+
+	t.Run("max concurrent subscriptions and termination", func(t *testing.T) {
+		wsConn := connect(t)
+		subscribe(t, wsConn, "1")
+
+		subscriptions := waitForSubscriptions(t, 1)
+		assert.Equal(t, "Ticks", subscriptions[0].OperationName)
+		assert.Nil(t, subscriptions[0].ExpiresAt)
+
+		subscribe(t, wsConn, "2")
+		_, msg, err := wsConn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"2","type":"error","payload":[{"message":"max concurrent subscriptions reached"}]}`, string(msg))
+
+		path := "/tyk/graphql/subscriptions/" + subscriptions[0].ID
+		_, _ = g.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: path, AdminAuth: true, Code: http.StatusOK},
+			{Method: http.MethodDelete, Path: path, AdminAuth: true, Code: http.StatusNotFound},
+		}...)
+
+		assertClosed(t, wsConn)
+		waitForSubscriptions(t, 0)
+	})
+
+	t.Run("key revoked", func(t *testing.T) {
+		wsConn := connect(t)
+		subscribe(t, wsConn, "1")
+		waitForSubscriptions(t, 1)
+
+		_, _ = g.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/keys/" + key, AdminAuth: true, Code: http.StatusOK})
+
+		assertClosed(t, wsConn)
+		waitForSubscriptions(t, 0)
+	})
+}
+
+// TestGraphQLSubscriptionsV3 covers the tracking of GraphQL config version 3 subscriptions. The execution of
+// subscriptions isn't part of it, so the hook and the executor pool are used directly.
+func TestGraphQLSubscriptionsV3(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "api"}}
+	spec.GraphQL.Version = apidef.GraphQLConfigVersion3Preview
+	m := &GraphQLMiddleware{BaseMiddleware: &BaseMiddleware{Spec: spec}}
+	hook := websocketBeforeStartHookV2{m}
+
+	registry := newGraphQLSubscriptionRegistry()
+
+	session := user.NewSessionState()
+	session.SetKeyHash("key-hash")
+	session.SetPolicies("policy")
+	session.MaxSubscriptions = 1
+	session.MaxSubscriptionLifetime = 60
+
+	connect := func(t *testing.T) (subscriptionv2.ExecutorPool, net.Conn, context.Context) {
+		t.Helper()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctxSetGraphQLSubscriptionConn(r, &graphQLSubscriptionConn{registry: registry, apiID: spec.APIID})
+		ctxSetSession(r, session, false, false)
+
+		server, client := net.Pipe()
+		t.Cleanup(func() { _ = client.Close() })
+
+		pool := m.trackSubscriptionsV2(r, server, subscriptionv2.NewExecutorV2Pool(nil, r.Context()))
+		return pool, client, r.Context()
+	}
+
+	start := func(t *testing.T, pool subscriptionv2.ExecutorPool, reqCtx context.Context, query string) (subscriptionv2.Executor, error) {
+		t.Helper()
+
+		executor, err := pool.Get([]byte(`{"operationName":"Ticks","query":"` + query + `"}`))
+		require.NoError(t, err)
+		return executor, hook.OnBeforeStart(reqCtx, &gqlv2.Request{OperationName: "Ticks", Query: query})
+	}
+
+	assertClosed := func(t *testing.T, conn net.Conn) {
+		t.Helper()
+
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, err := conn.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.EOF)
+	}
+
+	t.Run("max concurrent subscriptions and termination", func(t *testing.T) {
+		pool, conn, reqCtx := connect(t)
+
+		_, err := start(t, pool, reqCtx, "query Ticks { hello }")
+		require.NoError(t, err)
+		assert.Empty(t, registry.List(spec.APIID))
+
+		executor, err := start(t, pool, reqCtx, "subscription Ticks { ticks }")
+		require.NoError(t, err)
+		subscriptions := registry.List(spec.APIID)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, "Ticks", subscriptions[0].OperationName)
+		assert.Equal(t, "key-hash", subscriptions[0].KeyHash)
+		assert.NotNil(t, subscriptions[0].ExpiresAt)
+
+		_, err = start(t, pool, reqCtx, "subscription Ticks { ticks }")
+		assert.ErrorIs(t, err, GraphQLSubscriptionLimitExceededErr)
+
+		require.NoError(t, pool.Put(executor))
+		assert.Empty(t, registry.List(spec.APIID))
+
+		_, err = start(t, pool, reqCtx, "subscription Ticks { ticks }")
+		require.NoError(t, err)
+		assert.Equal(t, 1, registry.TerminatePolicies([]string{"policy"}))
+
+		assertClosed(t, conn)
+		assert.Empty(t, registry.List(spec.APIID))
+	})
+
+	t.Run("key revoked", func(t *testing.T) {
+		pool, conn, reqCtx := connect(t)
+
+		_, err := start(t, pool, reqCtx, "subscription Ticks { ticks }")
+		require.NoError(t, err)
+		assert.Equal(t, 1, registry.TerminateKey("key-hash"))
+
+		assertClosed(t, conn)
+		assert.Empty(t, registry.List(spec.APIID))
+	})
+}
+
+func TestChangedPolicies(t *testing.T) {
+	previous := map[string]user.Policy{
+		"unchanged": {ID: "unchanged", Rate: 1},
+		"updated":   {ID: "updated", Rate: 1},
+		"removed":   {ID: "removed"},
+	}
+	current := map[string]user.Policy{
+		"unchanged": {ID: "unchanged", Rate: 1},
+		"updated":   {ID: "updated", Rate: 2},
+		"added":     {ID: "added"},
+	}
+
+	assert.ElementsMatch(t, []string{"updated", "removed"}, changedPolicies(previous, current))
+}
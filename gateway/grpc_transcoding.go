@@ -0,0 +1,674 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// grpcFrameHeaderSize is the size of the compressed flag and length prefix of gRPC messages.
+const grpcFrameHeaderSize = 5
+
+var (
+	errGRPCNoDescriptors      = errors.New("no descriptor set configured")
+	errGRPCUnknownField       = errors.New("unknown field")
+	errGRPCCompressedMessage  = errors.New("compressed gRPC messages aren't supported")
+	errGRPCIncompleteResponse = errors.New("incomplete gRPC response message")
+)
+
+// grpcTranscoder maps HTTP/JSON requests to the gRPC methods of a FileDescriptorSet.
+type grpcTranscoder struct {
+	routes []*grpcRoute
+}
+
+// grpcRoute is an HTTP binding of a gRPC method, from a `google.api.http` rule.
+type grpcRoute struct {
+	httpMethod string
+	template   *grpcPathTemplate
+	// body is the request field mapped to the HTTP body, `*` for the whole request.
+	body              string
+	bodyField         protoreflect.FieldDescriptor
+	responseBodyField protoreflect.FieldDescriptor
+
+	method     protoreflect.MethodDescriptor
+	grpcMethod string
+}
+
+// grpcTranscodedCall is the gRPC call matching a REST/JSON request.
+type grpcTranscodedCall struct {
+	route *grpcRoute
+	vars  map[string]string
+	// status is the grpc-status of the upstream response, recorded in analytics.
+	status string
+}
+
+// newGRPCTranscoder loads the descriptors configured for transcoding. Relative descriptor set paths are
+// resolved against bundlePath, the custom middleware bundle of the API.
+func newGRPCTranscoder(conf apidef.GRPCTranscoding, bundlePath string) (*grpcTranscoder, error) {
+	data, err := readGRPCDescriptorSet(conf, bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("couldn't decode descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load descriptor set: %w", err)
+	}
+
+	services := make(map[string]bool, len(conf.Services))
+	for _, s := range conf.Services {
+		services[s] = true
+	}
+
+	t := &grpcTranscoder{}
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+			if len(services) > 0 && !services[string(service.FullName())] {
+				continue
+			}
+
+			for j := 0; j < service.Methods().Len(); j++ {
+				if err = t.addMethod(service.Methods().Get(j)); err != nil {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// custom verbs are more specific than the variables that would match them
+	sort.SliceStable(t.routes, func(i, j int) bool {
+		return t.routes[i].template.verb != "" && t.routes[j].template.verb == ""
+	})
+	return t, nil
+}
+
+func readGRPCDescriptorSet(conf apidef.GRPCTranscoding, bundlePath string) ([]byte, error) {
+	if conf.DescriptorSet != "" {
+		return base64.StdEncoding.DecodeString(conf.DescriptorSet)
+	}
+
+	if conf.DescriptorSetPath == "" {
+		return nil, errGRPCNoDescriptors
+	}
+
+	path := conf.DescriptorSetPath
+	if bundlePath != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(bundlePath, path)
+	}
+	return os.ReadFile(path)
+}
+
+// addMethod adds the routes of a method. Methods without `google.api.http` rule are bound to
+// `POST /package.Service/Method`, with the whole request as body. Client streaming methods can't be transcoded.
+func (t *grpcTranscoder) addMethod(method protoreflect.MethodDescriptor) error {
+	if method.IsStreamingClient() {
+		log.WithField("method", method.FullName()).Debug("Skipping transcoding of client streaming gRPC method")
+		return nil
+	}
+
+	grpcMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+
+	rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if rule == nil || rule.Pattern == nil {
+		rule = &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Post{Post: grpcMethod},
+			Body:    "*",
+		}
+	}
+
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+		route, err := newGRPCRoute(method, grpcMethod, r)
+		if err != nil {
+			return fmt.Errorf("invalid HTTP rule of %s: %w", method.FullName(), err)
+		}
+		t.routes = append(t.routes, route)
+	}
+	return nil
+}
+
+func newGRPCRoute(method protoreflect.MethodDescriptor, grpcMethod string, rule *annotations.HttpRule) (*grpcRoute, error) {
+	var httpMethod, path string
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		httpMethod, path = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		httpMethod, path = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		httpMethod, path = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		httpMethod, path = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		httpMethod, path = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		httpMethod, path = strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	default:
+		return nil, errors.New("missing pattern")
+	}
+
+	template, err := parseGRPCPathTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range template.variables {
+		if _, err := grpcFieldPath(method.Input(), v.field); err != nil {
+			return nil, fmt.Errorf("path variable %s: %w", v.field, err)
+		}
+	}
+
+	route := &grpcRoute{
+		httpMethod: httpMethod,
+		template:   template,
+		body:       rule.Body,
+		method:     method,
+		grpcMethod: grpcMethod,
+	}
+
+	if rule.Body != "" && rule.Body != "*" {
+		if route.bodyField = findGRPCField(method.Input(), rule.Body); route.bodyField == nil {
+			return nil, fmt.Errorf("body %s: %w", rule.Body, errGRPCUnknownField)
+		}
+	}
+
+	if rule.ResponseBody != "" {
+		if route.responseBodyField = findGRPCField(method.Output(), rule.ResponseBody); route.responseBodyField == nil {
+			return nil, fmt.Errorf("response body %s: %w", rule.ResponseBody, errGRPCUnknownField)
+		}
+	}
+	return route, nil
+}
+
+// match returns the call bound to an HTTP method and escaped path, relative to the listen path.
+func (t *grpcTranscoder) match(httpMethod, path string) *grpcTranscodedCall {
+	for _, route := range t.routes {
+		if route.httpMethod != httpMethod {
+			continue
+		}
+
+		if vars, ok := route.template.match(path); ok {
+			return &grpcTranscodedCall{route: route, vars: vars}
+		}
+	}
+	return nil
+}
+
+// grpcPathSegment is a literal segment of a path template, or a wildcard matching one segment (`*`)
+// or any number of segments (`**`).
+type grpcPathSegment struct {
+	literal  string
+	wildcard bool
+	multi    bool
+}
+
+// grpcPathVariable binds the segments [start, end) of a path template to a request field.
+type grpcPathVariable struct {
+	field      string
+	start, end int
+}
+
+// grpcPathTemplate is a parsed `google.api.http` path template.
+type grpcPathTemplate struct {
+	segments  []grpcPathSegment
+	variables []grpcPathVariable
+	verb      string
+}
+
+func parseGRPCPathTemplate(tmpl string) (*grpcPathTemplate, error) {
+	if !strings.HasPrefix(tmpl, "/") {
+		return nil, fmt.Errorf("path template %q doesn't start with /", tmpl)
+	}
+
+	t := &grpcPathTemplate{}
+	rest := tmpl[1:]
+	if i := strings.LastIndex(rest, ":"); i >= 0 && i > strings.LastIndex(rest, "}") && i > strings.LastIndex(rest, "/") {
+		t.verb, rest = rest[i+1:], rest[:i]
+	}
+
+	for rest != "" {
+		if rest[0] == '{' {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("path template %q has an unterminated variable", tmpl)
+			}
+
+			field, pattern, found := strings.Cut(rest[1:end], "=")
+			if !found {
+				pattern = "*"
+			}
+
+			v := grpcPathVariable{field: field, start: len(t.segments)}
+			for _, s := range strings.Split(pattern, "/") {
+				t.segments = append(t.segments, newGRPCPathSegment(s))
+			}
+			v.end = len(t.segments)
+			t.variables = append(t.variables, v)
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexByte(rest, '/')
+			if end < 0 {
+				end = len(rest)
+			}
+			t.segments = append(t.segments, newGRPCPathSegment(rest[:end]))
+			rest = rest[end:]
+		}
+
+		if rest == "" {
+			break
+		}
+		if rest[0] != '/' {
+			return nil, fmt.Errorf("path template %q has an invalid segment", tmpl)
+		}
+		rest = rest[1:]
+	}
+
+	for i, s := range t.segments {
+		if s.multi && i != len(t.segments)-1 {
+			return nil, fmt.Errorf("path template %q has ** before its last segment", tmpl)
+		}
+	}
+	return t, nil
+}
+
+func newGRPCPathSegment(s string) grpcPathSegment {
+	switch s {
+	case "*":
+		return grpcPathSegment{wildcard: true}
+	case "**":
+		return grpcPathSegment{wildcard: true, multi: true}
+	}
+	return grpcPathSegment{literal: s}
+}
+
+// match matches an escaped path against the template, returning the unescaped values of its variables.
+func (t *grpcPathTemplate) match(path string) (map[string]string, bool) {
+	if t.verb != "" {
+		var ok bool
+		if path, ok = strings.CutSuffix(path, ":"+t.verb); !ok {
+			return nil, false
+		}
+	}
+
+	var parts []string
+	if path = strings.TrimPrefix(path, "/"); path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	n := len(t.segments)
+	multi := n > 0 && t.segments[n-1].multi
+	if (multi && len(parts) < n-1) || (!multi && len(parts) != n) {
+		return nil, false
+	}
+
+	for i, s := range t.segments {
+		if s.multi {
+			break
+		}
+		if !s.wildcard && s.literal != parts[i] {
+			return nil, false
+		}
+	}
+
+	vars := make(map[string]string, len(t.variables))
+	for _, v := range t.variables {
+		end := v.end
+		if multi && end == n {
+			end = len(parts)
+		}
+
+		value, err := url.PathUnescape(strings.Join(parts[v.start:end], "/"))
+		if err != nil {
+			return nil, false
+		}
+		vars[v.field] = value
+	}
+	return vars, true
+}
+
+// oasPath returns the template as an OAS path, variables become path parameters named after their field.
+func (t *grpcPathTemplate) oasPath() string {
+	var sb strings.Builder
+	for i := 0; i < len(t.segments); {
+		sb.WriteByte('/')
+
+		if v, ok := t.variableAt(i); ok {
+			sb.WriteString("{" + v.field + "}")
+			i = v.end
+			continue
+		}
+
+		switch s := t.segments[i]; {
+		case s.multi:
+			sb.WriteString("**")
+		case s.wildcard:
+			sb.WriteString("*")
+		default:
+			sb.WriteString(s.literal)
+		}
+		i++
+	}
+
+	if sb.Len() == 0 {
+		sb.WriteByte('/')
+	}
+	if t.verb != "" {
+		sb.WriteString(":" + t.verb)
+	}
+	return sb.String()
+}
+
+func (t *grpcPathTemplate) variableAt(segment int) (grpcPathVariable, bool) {
+	for _, v := range t.variables {
+		if v.start == segment {
+			return v, true
+		}
+	}
+	return grpcPathVariable{}, false
+}
+
+// findGRPCField looks a field up by proto or JSON name.
+func findGRPCField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// grpcFieldPath resolves a dotted field path, like `book.author.name`, from a message.
+func grpcFieldPath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	names := strings.Split(path, ".")
+	fields := make([]protoreflect.FieldDescriptor, 0, len(names))
+	for i, name := range names {
+		fd := findGRPCField(md, name)
+		if fd == nil {
+			return nil, errGRPCUnknownField
+		}
+
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, fmt.Errorf("%s isn't a message field", name)
+			}
+			md = fd.Message()
+		}
+		fields = append(fields, fd)
+	}
+	return fields, nil
+}
+
+// setGRPCField sets the field at path from its string representation, repeated fields are appended to.
+func setGRPCField(msg protoreflect.Message, path, value string) error {
+	fields, err := grpcFieldPath(msg.Descriptor(), path)
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range fields[:len(fields)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	fd := fields[len(fields)-1]
+	if fd.IsMap() {
+		return fmt.Errorf("map field %s can't be set from a string", path)
+	}
+
+	v, err := parseGRPCFieldValue(fd, value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", path, err)
+	}
+
+	if fd.IsList() {
+		msg.Mutable(fd).List().Append(v)
+		return nil
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+func parseGRPCFieldValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	case protoreflect.MessageKind:
+		// well-known types, like timestamps and wrappers, have a JSON representation as string or scalar
+		msg := dynamicpb.NewMessage(fd.Message())
+		if err := protojson.Unmarshal([]byte(strconv.Quote(s)), msg); err != nil {
+			if err := protojson.Unmarshal([]byte(s), msg); err != nil {
+				return protoreflect.Value{}, err
+			}
+		}
+		return protoreflect.ValueOfMessage(msg), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+}
+
+// requestMessage builds the gRPC request of the call from the body, path variables and query parameters
+// of the HTTP request, in that order. Query parameters not matching a request field are ignored.
+func (c *grpcTranscodedCall) requestMessage(r *http.Request) ([]byte, error) {
+	route := c.route
+	msg := dynamicpb.NewMessage(route.method.Input())
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		r.Body.Close()
+	}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		switch {
+		case route.body == "*":
+			if err := protojson.Unmarshal(body, msg); err != nil {
+				return nil, err
+			}
+		case route.bodyField != nil:
+			wrapped := append([]byte(`{"`+route.bodyField.JSONName()+`":`), body...)
+			if err := protojson.Unmarshal(append(wrapped, '}'), msg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for field, value := range c.vars {
+		if err := setGRPCField(msg, field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if route.body != "*" {
+		for key, values := range r.URL.Query() {
+			if _, ok := c.vars[key]; ok {
+				continue
+			}
+
+			for _, value := range values {
+				if err := setGRPCField(msg, key, value); err != nil && !errors.Is(err, errGRPCUnknownField) {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return proto.Marshal(msg)
+}
+
+// responseJSON decodes a gRPC response message as JSON, or the response body field of the route.
+func (r *grpcRoute) responseJSON(data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(r.method.Output())
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	out, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// protojson randomizes whitespaces, compacting keeps responses stable
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, out); err != nil {
+		return nil, err
+	}
+
+	if r.responseBodyField == nil {
+		return buf.Bytes(), nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		return nil, err
+	}
+	if value, ok := fields[r.responseBodyField.JSONName()]; ok {
+		return value, nil
+	}
+	return []byte("null"), nil
+}
+
+// frameGRPCMessage prefixes a message with its gRPC length prefix, uncompressed.
+func frameGRPCMessage(data []byte) []byte {
+	frame := make([]byte, grpcFrameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(frame[1:grpcFrameHeaderSize], uint32(len(data)))
+	copy(frame[grpcFrameHeaderSize:], data)
+	return frame
+}
+
+// readGRPCFrame splits the first length-prefixed message off data, ok is false until the message is complete.
+func readGRPCFrame(data []byte) (message, rest []byte, ok bool, err error) {
+	if len(data) < grpcFrameHeaderSize {
+		return nil, data, false, nil
+	}
+	if data[0] != 0 {
+		return nil, nil, false, errGRPCCompressedMessage
+	}
+
+	size := uint64(binary.BigEndian.Uint32(data[1:grpcFrameHeaderSize]))
+	if uint64(len(data)-grpcFrameHeaderSize) < size {
+		return nil, data, false, nil
+	}
+
+	end := grpcFrameHeaderSize + int(size)
+	return data[grpcFrameHeaderSize:end], data[end:], true, nil
+}
+
+// grpcResponseStatus returns the status of a gRPC response, sent in trailers or in headers for
+// trailers-only responses. Trailers are only available once the body is read.
+func grpcResponseStatus(res *http.Response) (codes.Code, string) {
+	status, message := res.Trailer.Get(grpcStatusHeader), res.Trailer.Get(grpcMessageHeader)
+	if status == "" {
+		status, message = res.Header.Get(grpcStatusHeader), res.Header.Get(grpcMessageHeader)
+	}
+
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		return codes.Unknown, "missing grpc-status"
+	}
+
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+	return codes.Code(code), message
+}
+
+// httpStatusFromGRPC maps gRPC status codes to the HTTP status codes of transcoded responses.
+func httpStatusFromGRPC(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// grpcErrorJSON returns the JSON body of a failed transcoded call, in the `google.rpc.Status` format.
+func grpcErrorJSON(code codes.Code, message string) []byte {
+	body, _ := json.Marshal(struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{int(code), message})
+	return body
+}
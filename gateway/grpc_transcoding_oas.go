@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+)
+
+const (
+	grpcOASSchemaPrefix = "#/components/schemas/"
+	grpcOASStatusSchema = "google.rpc.Status"
+)
+
+// grpcTranscodedOAS returns the OAS document of a transcoding API. The routes are added to the paths of
+// OAS APIs, unless the API documents the same operation, classic APIs get a document of their own.
+func (a *APISpec) grpcTranscodedOAS() (*oas.OAS, error) {
+	if !a.IsOAS {
+		version := a.VersionData.DefaultVersion
+		if version == "" {
+			version = "1.0.0"
+		}
+
+		doc := &oas.OAS{T: openapi3.T{
+			OpenAPI: oas.DefaultOpenAPI,
+			Info:    &openapi3.Info{Title: a.Name, Version: version},
+		}}
+		a.grpcTranscoder.fillOAS(doc)
+		return doc, nil
+	}
+
+	a.OAS.Fill(*a.APIDefinition)
+	doc, err := a.OAS.Clone()
+	if err != nil {
+		return nil, err
+	}
+	a.grpcTranscoder.fillOAS(doc)
+	return doc, nil
+}
+
+// fillOAS adds an operation for each route of the transcoder, with the schemas of the protobuf messages.
+func (t *grpcTranscoder) fillOAS(doc *oas.OAS) {
+	if doc.Paths == nil {
+		doc.Paths = openapi3.Paths{}
+	}
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = openapi3.Schemas{}
+	}
+
+	schemas := grpcOASSchemas(doc.Components.Schemas)
+	if _, ok := schemas[grpcOASStatusSchema]; !ok {
+		status := openapi3.NewObjectSchema().
+			WithProperty("code", openapi3.NewInt32Schema()).
+			WithProperty("message", openapi3.NewStringSchema())
+		schemas[grpcOASStatusSchema] = openapi3.NewSchemaRef("", status)
+	}
+
+	operationIDs := map[string]int{}
+	for _, route := range t.routes {
+		path := route.template.oasPath()
+		item := doc.Paths[path]
+		if item == nil {
+			item = &openapi3.PathItem{}
+			doc.Paths[path] = item
+		}
+
+		if item.GetOperation(route.httpMethod) != nil {
+			continue
+		}
+
+		operation := route.oasOperation(schemas)
+		operationID := fmt.Sprintf("%s_%s", route.method.Parent().Name(), route.method.Name())
+		if n := operationIDs[operationID]; n > 0 {
+			operation.OperationID = fmt.Sprintf("%s%d", operationID, n)
+		} else {
+			operation.OperationID = operationID
+		}
+		operationIDs[operationID]++
+
+		item.SetOperation(route.httpMethod, operation)
+	}
+}
+
+func (r *grpcRoute) oasOperation(schemas grpcOASSchemas) *openapi3.Operation {
+	operation := openapi3.NewOperation()
+	operation.Summary = string(r.method.FullName())
+	operation.Tags = []string{string(r.method.Parent().FullName())}
+
+	input := r.method.Input()
+	bound := map[string]bool{}
+	for _, v := range r.template.variables {
+		fields, _ := grpcFieldPath(input, v.field)
+		param := openapi3.NewPathParameter(v.field)
+		param.Schema = schemas.fieldRef(fields[len(fields)-1])
+		operation.AddParameter(param)
+		bound[string(fields[0].Name())] = true
+	}
+
+	switch {
+	case r.body == "*":
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithJSONSchemaRef(schemas.messageRef(input)),
+		}
+	case r.bodyField != nil:
+		bound[string(r.bodyField.Name())] = true
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithJSONSchemaRef(schemas.fieldRef(r.bodyField)),
+		}
+	}
+
+	if r.body != "*" {
+		fields := input.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if bound[string(fd.Name())] || fd.IsMap() || fd.Message() != nil {
+				continue
+			}
+
+			param := openapi3.NewQueryParameter(fd.JSONName())
+			param.Schema = schemas.fieldRef(fd)
+			operation.AddParameter(param)
+		}
+	}
+
+	output := schemas.messageRef(r.method.Output())
+	if r.responseBodyField != nil {
+		output = schemas.fieldRef(r.responseBodyField)
+	}
+
+	response := openapi3.NewResponse().WithDescription("OK")
+	if r.method.IsStreamingServer() {
+		response.WithContent(openapi3.Content{
+			textEventStream: openapi3.NewMediaType().WithSchemaRef(output),
+		})
+	} else {
+		response.WithJSONSchemaRef(output)
+	}
+
+	operation.AddResponse(http.StatusOK, response)
+	operation.AddResponse(0, openapi3.NewResponse().WithDescription("gRPC error").
+		WithJSONSchemaRef(openapi3.NewSchemaRef(grpcOASSchemaPrefix+grpcOASStatusSchema, schemas[grpcOASStatusSchema].Value)))
+	return operation
+}
+
+// grpcOASSchemas holds the OAS schemas of protobuf messages, by message full name.
+type grpcOASSchemas openapi3.Schemas
+
+func (s grpcOASSchemas) messageRef(md protoreflect.MessageDescriptor) *openapi3.SchemaRef {
+	if schema := s.wellKnownSchema(md); schema != nil {
+		return openapi3.NewSchemaRef("", schema)
+	}
+
+	name := string(md.FullName())
+	if ref, ok := s[name]; ok {
+		return openapi3.NewSchemaRef(grpcOASSchemaPrefix+name, ref.Value)
+	}
+
+	schema := openapi3.NewObjectSchema()
+	// added before its fields, so recursive messages refer to it
+	s[name] = openapi3.NewSchemaRef("", schema)
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		schema.Properties[fd.JSONName()] = s.fieldRef(fd)
+	}
+	return openapi3.NewSchemaRef(grpcOASSchemaPrefix+name, schema)
+}
+
+func (s grpcOASSchemas) fieldRef(fd protoreflect.FieldDescriptor) *openapi3.SchemaRef {
+	switch {
+	case fd.IsMap():
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: s.valueRef(fd.MapValue())}
+		return openapi3.NewSchemaRef("", schema)
+	case fd.IsList():
+		schema := openapi3.NewArraySchema()
+		schema.Items = s.valueRef(fd)
+		return openapi3.NewSchemaRef("", schema)
+	}
+	return s.valueRef(fd)
+}
+
+// valueRef returns the schema of a single value of a field, as encoded by protojson.
+func (s grpcOASSchemas) valueRef(fd protoreflect.FieldDescriptor) *openapi3.SchemaRef {
+	var schema *openapi3.Schema
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		schema = openapi3.NewBoolSchema()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		schema = openapi3.NewInt32Schema()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		schema = openapi3.NewIntegerSchema()
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		schema = openapi3.NewStringSchema().WithFormat("int64")
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		schema = openapi3.NewStringSchema().WithFormat("uint64")
+	case protoreflect.FloatKind:
+		schema = openapi3.NewFloat64Schema().WithFormat("float")
+	case protoreflect.DoubleKind:
+		schema = openapi3.NewFloat64Schema().WithFormat("double")
+	case protoreflect.BytesKind:
+		schema = openapi3.NewBytesSchema()
+	case protoreflect.EnumKind:
+		schema = openapi3.NewStringSchema()
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			schema.Enum = append(schema.Enum, string(values.Get(i).Name()))
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return s.messageRef(fd.Message())
+	default:
+		schema = openapi3.NewStringSchema()
+	}
+	return openapi3.NewSchemaRef("", schema)
+}
+
+// wellKnownSchema returns the schema of the well-known types protojson encodes as scalars or free-form JSON.
+func (s grpcOASSchemas) wellKnownSchema(md protoreflect.MessageDescriptor) *openapi3.Schema {
+	if md.ParentFile() == nil || md.ParentFile().Package() != "google.protobuf" {
+		return nil
+	}
+
+	switch md.Name() {
+	case "Timestamp":
+		return openapi3.NewDateTimeSchema()
+	case "Duration", "FieldMask":
+		return openapi3.NewStringSchema()
+	case "Struct", "Any", "Empty":
+		return openapi3.NewObjectSchema()
+	case "Value":
+		return openapi3.NewSchema()
+	case "ListValue":
+		return openapi3.NewArraySchema().WithItems(openapi3.NewSchema())
+	case "DoubleValue", "FloatValue", "Int64Value", "UInt64Value", "Int32Value", "UInt32Value",
+		"BoolValue", "StringValue", "BytesValue":
+		return s.valueRef(md.Fields().ByName("value")).Value
+	}
+	return nil
+}
@@ -92,7 +92,17 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 	defer e.Base().UpdateRequestSession(r)
 	response := &http.Response{}
 
-	if writeResponse {
+	if writeResponse && e.Spec.GRPC.Enabled && e.Spec.GRPC.Web.Enabled && isGRPCWebRequest(r) &&
+		errMsg != errCustomBodyResponse.Error() {
+		contentType := r.Header.Get(header.ContentType)
+		if call := ctxGetGRPCWebCall(r); call != nil {
+			contentType = call.contentType
+		}
+		writeGRPCWebError(w, response, contentType, errMsg, errCode)
+	} else if writeResponse && e.Spec.GRPC.Enabled && isGRPCRequest(r) && ctxGetGRPCTranscodedCall(r) == nil &&
+		errMsg != errCustomBodyResponse.Error() {
+		writeGRPCError(w, response, errMsg, errCode)
+	} else if writeResponse {
 		var templateExtension string
 		contentType := r.Header.Get(header.ContentType)
 		contentType = strings.Split(contentType, ";")[0]
@@ -213,6 +223,11 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		if len(e.Spec.Tags) > 0 {
 			tags = append(tags, e.Spec.Tags...)
 		}
+
+		if tag := grpcStatusTag(response); tag != "" {
+			tags = append(tags, tag)
+		}
+
 		trackEP := false
 		trackedPath := r.URL.Path
 
@@ -256,7 +271,7 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 			TrackPath:     trackEP,
 			ExpireAt:      t,
 		}
-		recordGraphDetails(&record, r, response, e.Spec)
+		graphQLOperation := recordGraphDetails(&record, r, response, e.Spec)
 
 		rawRequest := ""
 		rawResponse := ""
@@ -282,7 +297,7 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		}
 		if e.Spec.GraphQL.Enabled && e.Spec.GraphQL.ExecutionMode != apidef.GraphQLExecutionModeSubgraph {
 			record.Tags = append(record.Tags, "tyk-graph-analytics")
-			record.ApiSchema = base64.StdEncoding.EncodeToString([]byte(e.Spec.GraphQL.Schema))
+			record.ApiSchema = base64.StdEncoding.EncodeToString([]byte(e.Spec.graphQLSchema()))
 		}
 
 		expiresAfter := e.Spec.ExpireAnalyticsAfter
@@ -310,6 +325,10 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		if err != nil {
 			log.WithError(err).Error("could not store analytic record")
 		}
+
+		if graphQLOperation != nil {
+			e.Gw.Analytics.RecordGraphQLOperation(graphQLOperation)
+		}
 	}
 	// Report in health check
 	reportHealthValue(e.Spec, BlockedRequestLog, "-1")
@@ -120,46 +120,29 @@ func getSessionTags(session *user.SessionState) []string {
 	return tags
 }
 
-func recordGraphDetails(rec *analytics.AnalyticsRecord, r *http.Request, resp *http.Response, spec *APISpec) {
+// recordGraphDetails adds the GraphQL stats to the analytics record and returns the operation record of the request,
+// or nil when the request isn't a GraphQL operation.
+func recordGraphDetails(rec *analytics.AnalyticsRecord, r *http.Request, resp *http.Response, spec *APISpec) *graphqlinternal.OperationRecord {
 	if !spec.GraphQL.Enabled || spec.GraphQL.ExecutionMode == apidef.GraphQLExecutionModeSubgraph {
-		return
+		return nil
 	}
 	logger := log.WithField("location", "recordGraphDetails")
 	if r.Body == nil {
-		return
-	}
-	body, err := io.ReadAll(r.Body)
-	defer func() {
-		_ = r.Body.Close()
-		r.Body = io.NopCloser(bytes.NewBuffer(body))
-	}()
-	if err != nil {
-		logger.WithError(err).Error("error recording graph analytics")
-		return
-	}
-	var (
-		respBody []byte
-	)
-	if resp.Body != nil {
-		httputil.RemoveResponseTransferEncoding(resp, "chunked")
-		respBody, err = io.ReadAll(resp.Body)
-		defer func() {
-			_ = resp.Body.Close()
-			resp.Body = respBodyReader(r, resp)
-		}()
-		if err != nil {
-			logger.WithError(err).Error("error recording graph analytics")
-			return
-		}
+		return nil
 	}
 
-	extractor := graphqlinternal.NewGraphStatsExtractor()
-	stats, err := extractor.ExtractStats(string(body), string(respBody), spec.GraphQL.Schema)
+	stats, err := extractGraphQLOperationStats(r, resp, spec)
 	if err != nil {
 		logger.WithError(err).Error("error recording graph analytics")
-		return
+		return nil
 	}
-	rec.GraphQLStats = stats
+	rec.GraphQLStats = stats.stats
+
+	operation := graphQLOperationRecord(r, stats.operationName, stats.stats)
+	operation.APIID = rec.APIID
+	operation.OrgID = rec.OrgID
+	operation.TimeStamp = rec.TimeStamp
+	return &operation
 }
 
 func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, code int, responseCopy *http.Response, cached bool) {
@@ -205,6 +188,16 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 			tags = append(tags, "cached-response")
 		}
 
+		if tag := ctxGetResponseLimitExceeded(r); tag != "" {
+			tags = append(tags, tag)
+		}
+
+		if s.Spec.GRPC.Enabled {
+			if tag := grpcAnalyticsTag(r, responseCopy); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
 		rawRequest := ""
 		rawResponse := ""
 
@@ -287,11 +280,11 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 			record.GetGeo(ip, s.Gw.Analytics.GeoIPDB)
 		}
 
-		recordGraphDetails(&record, r, responseCopy, s.Spec)
+		graphQLOperation := recordGraphDetails(&record, r, responseCopy, s.Spec)
 		// skip tagging subgraph requests for graphpump, it only handles generated supergraph requests
 		if s.Spec.GraphQL.Enabled && s.Spec.GraphQL.ExecutionMode != apidef.GraphQLExecutionModeSubgraph {
 			record.Tags = append(record.Tags, "tyk-graph-analytics")
-			record.ApiSchema = base64.StdEncoding.EncodeToString([]byte(s.Spec.GraphQL.Schema))
+			record.ApiSchema = base64.StdEncoding.EncodeToString([]byte(s.Spec.graphQLSchema()))
 		}
 
 		expiresAfter := s.Spec.ExpireAnalyticsAfter
@@ -321,6 +314,10 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 		if err != nil {
 			log.WithError(err).Error("could not store analytic record")
 		}
+
+		if graphQLOperation != nil {
+			s.Gw.Analytics.RecordGraphQLOperation(graphQLOperation)
+		}
 	}
 
 	// Report in health check
@@ -377,6 +374,8 @@ func (s *SuccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) *http
 	log.Debug("Upstream request took (ms): ", millisec)
 
 	if resp.Response != nil {
+		s.traceGraphQLOperation(r, resp.Response)
+
 		latency := analytics.Latency{
 			Total:    int64(millisec),
 			Upstream: int64(DurationToMillisecond(resp.UpstreamLatency)),
@@ -404,6 +403,8 @@ func (s *SuccessHandler) ServeHTTPWithCache(w http.ResponseWriter, r *http.Reque
 	log.Debug("Upstream request took (ms): ", millisec)
 
 	if inRes.Response != nil {
+		s.traceGraphQLOperation(r, inRes.Response)
+
 		latency := analytics.Latency{
 			Total:    int64(millisec),
 			Upstream: int64(DurationToMillisecond(inRes.UpstreamLatency)),
@@ -52,7 +52,7 @@ func (l *JSVMEventHandler) HandleEvent(em config.EventMessage) {
 	}
 
 	// Execute the method name with the JSON object
-	_, err = l.Gw.GlobalEventsJSVM.VM.Run(l.methodName + `.DoProcessEvent(` + string(msgAsJSON) + `,` + l.SpecJSON + `);`)
+	err = l.Gw.GlobalEventsJSVM.Exec(l.methodName + `.DoProcessEvent(` + string(msgAsJSON) + `,` + l.SpecJSON + `);`)
 	if err != nil {
 		log.WithError(err).Error("executing JSVM method")
 	}
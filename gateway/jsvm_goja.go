@@ -0,0 +1,215 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/robertkrimen/otto/underscore"
+	"github.com/sirupsen/logrus"
+)
+
+// gojaVM holds the code loaded into a JSVM running on goja. goja runtimes can't be
+// copied or shared between goroutines, so the code is kept compiled and calls take an
+// initialised runtime from a pool, running only the code loaded since the runtime was last used.
+type gojaVM struct {
+	mu       sync.RWMutex
+	programs []*goja.Program
+
+	runtimes sync.Pool
+}
+
+// gojaRuntime is a pooled goja runtime and the number of loaded programs it ran.
+type gojaRuntime struct {
+	vm     *goja.Runtime
+	loaded int
+	// globals holds the global variables set by the loaded programs, restored after each call.
+	globals map[string]goja.Value
+}
+
+func (rt *gojaRuntime) saveGlobals() {
+	global := rt.vm.GlobalObject()
+	rt.globals = make(map[string]goja.Value)
+	for _, key := range global.Keys() {
+		rt.globals[key] = global.Get(key)
+	}
+}
+
+// resetGlobals removes the global variables set by a call and restores the ones it reassigned.
+func (rt *gojaRuntime) resetGlobals() error {
+	global := rt.vm.GlobalObject()
+	for _, key := range global.Keys() {
+		if _, ok := rt.globals[key]; !ok {
+			if err := global.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, value := range rt.globals {
+		if current := global.Get(key); current == nil || !current.SameAs(value) {
+			if err := global.Set(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *gojaVM) load(name, src string) error {
+	program, err := goja.Compile(name, src, false)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	g.programs = append(g.programs, program)
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gojaVM) loaded() []*goja.Program {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.programs
+}
+
+// initGoja compiles the core library and the user's TykJS for goja.
+func (j *JSVM) initGoja(logger *logrus.Entry) bool {
+	g := &gojaVM{}
+
+	// underscore is built into otto runtimes, load it for compatibility
+	if err := g.load("underscore.js", underscore.Source()); err != nil {
+		logger.WithError(err).Error("Could not load underscore")
+		return false
+	}
+
+	// Init TykJS namespace, constructors etc.
+	if err := g.load("core.js", coreJS+tykJSApi); err != nil {
+		logger.WithError(err).Error("Could not load TykJS")
+		return false
+	}
+
+	// Load user's TykJS on top, if any
+	if path := j.Gw.GetConfig().TykJSPath; path != "" {
+		src, err := os.ReadFile(path)
+		if err == nil {
+			if err := g.load(path, string(src)); err != nil {
+				logger.WithError(err).Error("Could not load user's TykJS")
+			}
+		}
+	}
+
+	j.goja = g
+	return true
+}
+
+// callGoja runs expr in a pooled goja runtime, interrupting it once the JSVM timeout elapses.
+// The global variables are reset before the runtime is returned to the pool, while runtimes
+// that fail or time out are dropped.
+func (j *JSVM) callGoja(expr string) (string, error) {
+	rt, ok := j.goja.runtimes.Get().(*gojaRuntime)
+	if !ok {
+		rt = &gojaRuntime{vm: goja.New()}
+		j.loadGojaTykJSApi(rt.vm)
+	}
+
+	timer := time.AfterFunc(j.Timeout, func() {
+		rt.vm.Interrupt(errJSVMTimeout)
+	})
+
+	value, err := j.runGoja(rt, expr)
+	if !timer.Stop() {
+		return "", errJSVMTimeout
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if rt.resetGlobals() == nil {
+		j.goja.runtimes.Put(rt)
+	}
+	return value, nil
+}
+
+// runGoja runs the programs loaded since rt was last used, then expr.
+func (j *JSVM) runGoja(rt *gojaRuntime, expr string) (string, error) {
+	programs := j.goja.loaded()
+	if rt.loaded < len(programs) {
+		for _, program := range programs[rt.loaded:] {
+			if _, err := rt.vm.RunProgram(program); err != nil {
+				return "", gojaError(err)
+			}
+			rt.loaded++
+		}
+		rt.saveGlobals()
+	}
+
+	value, err := rt.vm.RunString(expr)
+	if err != nil {
+		return "", gojaError(err)
+	}
+	return value.String(), nil
+}
+
+func gojaError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return errJSVMTimeout
+	}
+	return err
+}
+
+// loadGojaTykJSApi exposes the Tyk JS API to a goja runtime, with the same signatures as on otto.
+func (j *JSVM) loadGojaTykJSApi(vm *goja.Runtime) {
+	arg := func(call goja.FunctionCall, i int) string {
+		return call.Argument(i).String()
+	}
+	value := func(out string, ok bool) goja.Value {
+		if !ok {
+			return goja.Undefined()
+		}
+		return vm.ToValue(out)
+	}
+
+	vm.Set("log", func(call goja.FunctionCall) goja.Value {
+		j.log(arg(call, 0))
+		return goja.Undefined()
+	})
+	vm.Set("rawlog", func(call goja.FunctionCall) goja.Value {
+		j.rawlog(arg(call, 0))
+		return goja.Undefined()
+	})
+
+	vm.Set("b64dec", func(call goja.FunctionCall) goja.Value {
+		out, err := j.b64dec(arg(call, 0))
+		return value(out, err == nil)
+	})
+	vm.Set("b64enc", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(base64.StdEncoding.EncodeToString([]byte(arg(call, 0))))
+	})
+	vm.Set("rawb64dec", func(call goja.FunctionCall) goja.Value {
+		out, err := j.rawb64dec(arg(call, 0))
+		return value(out, err == nil)
+	})
+	vm.Set("rawb64enc", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(base64.RawStdEncoding.EncodeToString([]byte(arg(call, 0))))
+	})
+
+	vm.Set("TykMakeHttpRequest", func(call goja.FunctionCall) goja.Value {
+		return value(j.makeHTTPRequest(arg(call, 0)))
+	})
+	vm.Set("TykGetKeyData", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(j.getKeyData(arg(call, 0), arg(call, 1)))
+	})
+	vm.Set("TykSetKeyData", func(call goja.FunctionCall) goja.Value {
+		j.setKeyData(arg(call, 0), arg(call, 1), arg(call, 2))
+		return goja.Undefined()
+	})
+	vm.Set("TykBatchRequest", func(call goja.FunctionCall) goja.Value {
+		return value(j.batchRequest(arg(call, 0)))
+	})
+}
@@ -727,7 +727,7 @@ func handleOtelTracedResponse(rh TykResponseHandler, rw http.ResponseWriter, res
 }
 
 func shouldPerformTracing(rh TykResponseHandler, baseMw *BaseTykResponseHandler) bool {
-	return rh.Name() != "ResponseCacheMiddleware" || baseMw.Spec.CacheOptions.EnableCache
+	return rh.Name() != "ResponseCacheMiddleware" || baseMw.Spec.CacheOptions.EnableCache || isGraphQLCacheEnabled(baseMw.Spec)
 }
 
 func parseForm(r *http.Request) {
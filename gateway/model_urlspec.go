@@ -20,6 +20,10 @@ func (u *URLSpec) modeSpecificSpec(mode URLStatus) (interface{}, bool) {
 		return &u.TransformResponseAction, true
 	case TransformedJQResponse:
 		return &u.TransformJQResponseAction, true
+	case TransformedExpression:
+		return &u.TransformExpressionAction, true
+	case TransformedExpressionResponse:
+		return &u.TransformExpressionResponseAction, true
 	case HardTimeout:
 		return &u.HardTimeout.TimeOut, true
 	case CircuitBreaker:
@@ -30,6 +34,8 @@ func (u *URLSpec) modeSpecificSpec(mode URLStatus) (interface{}, bool) {
 		return &u.VirtualPathSpec, true
 	case RequestSizeLimit:
 		return &u.RequestSize, true
+	case ResponseSizeLimit:
+		return &u.ResponseSize, true
 	case MethodTransformed:
 		return &u.MethodTransform, true
 	case RequestTracked:
@@ -68,6 +74,10 @@ func (u *URLSpec) matchesMethod(method string) bool {
 		return method == u.TransformResponseAction.Method
 	case TransformedJQResponse:
 		return method == u.TransformJQResponseAction.Method
+	case TransformedExpression:
+		return method == u.TransformExpressionAction.Method
+	case TransformedExpressionResponse:
+		return method == u.TransformExpressionResponseAction.Method
 	case HardTimeout:
 		return method == u.HardTimeout.Method
 	case CircuitBreaker:
@@ -78,6 +88,8 @@ func (u *URLSpec) matchesMethod(method string) bool {
 		return method == u.VirtualPathSpec.Method
 	case RequestSizeLimit:
 		return method == u.RequestSize.Method
+	case ResponseSizeLimit:
+		return method == u.ResponseSize.Method
 	case MethodTransformed:
 		return method == u.MethodTransform.Method
 	case RequestTracked:
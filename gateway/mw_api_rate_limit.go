@@ -39,6 +39,15 @@ func (k *RateLimitForAPI) shouldEnable() bool {
 		}
 	}
 
+	// per method gRPC rate limits
+	if k.Spec.GRPC.Enabled {
+		for _, v := range k.Spec.GRPC.MethodRateLimits {
+			if !v.Disabled {
+				return true
+			}
+		}
+	}
+
 	// global api rate limit
 	if k.Spec.GlobalRateLimit.Rate == 0 || k.Spec.GlobalRateLimit.Disabled {
 		return false
@@ -48,6 +57,11 @@ func (k *RateLimitForAPI) shouldEnable() bool {
 }
 
 func (k *RateLimitForAPI) getSession(r *http.Request) *user.SessionState {
+	// track per-method with a hash of the method pattern
+	if limits, ok := k.Spec.grpcMethodRateLimit(r); ok {
+		return k.limitSession(limits.Rate, limits.Per, "grpc:"+limits.Method)
+	}
+
 	versionInfo, _ := k.Spec.Version(r)
 	versionPaths := k.Spec.RxPaths[versionInfo.Name]
 
@@ -55,22 +69,27 @@ func (k *RateLimitForAPI) getSession(r *http.Request) *user.SessionState {
 	if ok {
 		if limits := spec.RateLimit; limits.Valid() {
 			// track per-endpoint with a hash of the path
-			keyname := k.keyName + "-" + storage.HashStr(fmt.Sprintf("%s:%s", limits.Method, limits.Path))
-
-			session := &user.SessionState{
-				Rate:        limits.Rate,
-				Per:         limits.Per,
-				LastUpdated: k.apiSess.LastUpdated,
-			}
-			session.SetKeyHash(storage.HashKey(keyname, k.Gw.GetConfig().HashKeys))
-
-			return session
+			return k.limitSession(limits.Rate, limits.Per, fmt.Sprintf("%s:%s", limits.Method, limits.Path))
 		}
 	}
 
 	return k.apiSess
 }
 
+// limitSession returns the session tracking a rate limit of the API, in a bucket named after the limited resource.
+func (k *RateLimitForAPI) limitSession(rate, per float64, resource string) *user.SessionState {
+	keyname := k.keyName + "-" + storage.HashStr(resource)
+
+	session := &user.SessionState{
+		Rate:        rate,
+		Per:         per,
+		LastUpdated: k.apiSess.LastUpdated,
+	}
+	session.SetKeyHash(storage.HashKey(keyname, k.Gw.GetConfig().HashKeys))
+
+	return session
+}
+
 func (k *RateLimitForAPI) EnabledForSpec() bool {
 	if !k.shouldEnable() {
 		return false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -11,9 +12,11 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/engine/resolve"
 	"github.com/TykTechnologies/graphql-go-tools/pkg/execution/datasource"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/subscription"
 	gqlwebsocket "github.com/TykTechnologies/graphql-go-tools/pkg/subscription/websocket"
 
 	"github.com/TykTechnologies/tyk/internal/graphengine"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/ctx"
@@ -22,15 +25,19 @@ import (
 
 	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 	gqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	subscriptionv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
 )
 
 var (
 	ProxyingRequestFailedErr     = errors.New("there was a problem proxying the request")
 	GraphQLDepthLimitExceededErr = errors.New("depth limit exceeded")
+	GraphQLCostLimitExceededErr  = errors.New("query cost limit exceeded")
 )
 
 type GraphQLMiddleware struct {
 	*BaseMiddleware
+
+	dataSourceNames *graphqlinternal.DataSourceNames
 }
 
 func (m *GraphQLMiddleware) Name() string {
@@ -42,7 +49,9 @@ func (m *GraphQLMiddleware) EnabledForSpec() bool {
 }
 
 func (m *GraphQLMiddleware) Init() {
-	schema, err := gql.NewSchemaFromString(m.Spec.GraphQL.Schema)
+	m.dataSourceNames = graphqlinternal.NewDataSourceNames(m.Spec.APIDefinition)
+
+	schema, err := gql.NewSchemaFromString(m.Spec.graphQLSchema())
 	if err != nil {
 		log.Errorf("Error while creating schema from API definition: %v", err)
 		return
@@ -57,6 +66,15 @@ func (m *GraphQLMiddleware) Init() {
 		log.Errorf("Schema normalization was not successful. Reason: %v", normalizationResult.Errors)
 	}
 
+	// the engine is built from a copy carrying the composed supergraph, the loaded definition is left as it is
+	apiDefinition := m.Spec.APIDefinition
+	if m.Spec.composedSupergraphSDL != "" {
+		composed := *m.Spec.APIDefinition
+		composed.GraphQL.Schema = m.Spec.composedSupergraphSDL
+		composed.GraphQL.Supergraph.MergedSDL = m.Spec.composedSupergraphSDL
+		apiDefinition = &composed
+	}
+
 	reusableBodyReadCloser := func(buf io.ReadCloser) (io.ReadCloser, error) {
 		return newNopCloserBuffer(buf)
 	}
@@ -69,7 +87,7 @@ func (m *GraphQLMiddleware) Init() {
 		log.Info("GraphQL Config Version 1 is deprecated - Please consider migrating to version 2 or higher")
 		m.Spec.GraphEngine, err = graphengine.NewEngineV1(graphengine.EngineV1Options{
 			Logger:        log,
-			ApiDefinition: m.Spec.APIDefinition,
+			ApiDefinition: apiDefinition,
 			Schema:        schema,
 			HttpClient: &http.Client{
 				Transport: &http.Transport{TLSClientConfig: tlsClientConfig(m.Spec, nil)},
@@ -86,7 +104,7 @@ func (m *GraphQLMiddleware) Init() {
 		m.Spec.GraphEngine, err = graphengine.NewEngineV2(graphengine.EngineV2Options{
 			Logger:        log,
 			Schema:        schema,
-			ApiDefinition: m.Spec.APIDefinition,
+			ApiDefinition: apiDefinition,
 			HttpClient: &http.Client{
 				Transport: &http.Transport{TLSClientConfig: tlsClientConfig(m.Spec, nil)},
 			},
@@ -102,6 +120,7 @@ func (m *GraphQLMiddleware) Init() {
 				BeforeFetchHook:           m,
 				AfterFetchHook:            m,
 				WebsocketOnBeforeStart:    m,
+				WebsocketExecutorPool:     m.trackSubscriptions,
 				ContextStoreRequest:       ctxSetGraphQLRequest,
 				ContextRetrieveRequest:    ctxGetGraphQLRequest,
 				NewReusableBodyReadCloser: reusableBodyReadCloser,
@@ -120,7 +139,7 @@ func (m *GraphQLMiddleware) Init() {
 			},
 		})
 	} else if m.Spec.GraphQL.Version == apidef.GraphQLConfigVersion3Preview {
-		v2Schema, err := gqlv2.NewSchemaFromString(m.Spec.GraphQL.Schema)
+		v2Schema, err := gqlv2.NewSchemaFromString(m.Spec.graphQLSchema())
 		if err != nil {
 			log.Errorf("Error while creating schema from API definition: %v", err)
 			return
@@ -128,7 +147,7 @@ func (m *GraphQLMiddleware) Init() {
 		engine, err := graphengine.NewEngineV3(graphengine.EngineV3Options{
 			Logger:        log,
 			Schema:        v2Schema,
-			ApiDefinition: m.Spec.APIDefinition,
+			ApiDefinition: apiDefinition,
 			OpenTelemetry: graphengine.EngineV2OTelConfig{
 				Enabled:        m.Gw.GetConfig().OpenTelemetry.Enabled,
 				TracerProvider: m.Gw.TracerProvider,
@@ -137,10 +156,10 @@ func (m *GraphQLMiddleware) Init() {
 				Transport: &http.Transport{TLSClientConfig: tlsClientConfig(m.Spec, nil)},
 			},
 			Injections: graphengine.EngineV3Injections{
-				ContextRetrieveRequest: ctxGetGraphQLRequestV2,
-				ContextStoreRequest:    ctxSetGraphQLRequestV2,
-				// TODO use proper version or request for this
-				//WebsocketOnBeforeStart:    m,
+				ContextRetrieveRequest:    ctxGetGraphQLRequestV2,
+				ContextStoreRequest:       ctxSetGraphQLRequestV2,
+				WebsocketOnBeforeStart:    websocketBeforeStartHookV2{m},
+				WebsocketExecutorPool:     m.trackSubscriptionsV2,
 				NewReusableBodyReadCloser: reusableBodyReadCloser,
 				SeekReadCloser: func(readCloser io.ReadCloser) (io.ReadCloser, error) {
 					body, ok := readCloser.(*nopCloserBuffer)
@@ -193,6 +212,7 @@ func (m *GraphQLMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reques
 		}
 
 		ctxSetGraphQLIsWebSocketUpgrade(r, true)
+		ctxSetGraphQLSubscriptionConn(r, &graphQLSubscriptionConn{registry: m.Gw.graphQLSubscriptions, apiID: m.Spec.APIID})
 		return nil, http.StatusSwitchingProtocols
 	}
 
@@ -200,6 +220,9 @@ func (m *GraphQLMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reques
 	// as for proxy only API we are sending it as is
 	nopCloseRequestBody(r)
 
+	// the data source calls made to resolve the operation are part of its analytics record
+	setContext(r, graphqlinternal.ContextWithDataSourceTracker(r.Context(), m.dataSourceNames.NewTracker()))
+
 	return m.Spec.GraphEngine.ProcessAndStoreGraphQLRequest(w, r)
 }
 
@@ -227,7 +250,15 @@ func (m *GraphQLMiddleware) isSupergraphAPIDefinition() bool {
 
 // OnBeforeStart - is a graphql.WebsocketBeforeStartHook which allows to perform security checks for all operations over websocket connections
 func (m *GraphQLMiddleware) OnBeforeStart(reqCtx context.Context, operation *gql.Request) error {
+	if m.Spec.GraphQL.PersistedQueries.TrustedDocumentsOnly && !m.Gw.isTrustedGraphQLDocument(m.Spec.APIID, operation.Query) {
+		return GraphQLOperationNotTrustedErr
+	}
+
+	subscriptions := ctxGetGraphQLSubscriptionConn(reqCtx)
 	if m.Spec.UseKeylessAccess {
+		if subscriptions != nil {
+			return subscriptions.start(operation.OperationName, nil, user.APILimit{})
+		}
 		return nil
 	}
 
@@ -258,6 +289,20 @@ func (m *GraphQLMiddleware) OnBeforeStart(reqCtx context.Context, operation *gql
 		return GraphQLDepthLimitExceededErr
 	}
 
+	if m.Spec.GraphQL.CostAnalysis.Enabled {
+		if m.Spec.graphqlCostCalculator == nil {
+			return ProxyingRequestFailedErr
+		}
+
+		_, costResult := complexityCheck.QueryCostExceeded(operation, accessDef, m.Spec.graphqlCostCalculator)
+		switch costResult {
+		case ComplexityFailReasonInternalError:
+			return ProxyingRequestFailedErr
+		case ComplexityFailReasonCostLimitExceeded:
+			return GraphQLCostLimitExceededErr
+		}
+	}
+
 	granularAccessCheck := &GraphqlGranularAccessChecker{}
 	result := granularAccessCheck.CheckGraphqlRequestFieldAllowance(operation, accessDef, schema)
 	switch result.failReason {
@@ -269,9 +314,73 @@ func (m *GraphQLMiddleware) OnBeforeStart(reqCtx context.Context, operation *gql
 		return result.validationResult.Errors
 	}
 
+	if subscriptions != nil {
+		return subscriptions.start(operation.OperationName, session, accessDef.Limit)
+	}
+
 	return nil
 }
 
+// trackSubscriptions wraps the executor pool of a websocket connection, so its subscriptions are tracked by the
+// OnBeforeStart hook and can be terminated.
+func (m *GraphQLMiddleware) trackSubscriptions(r *http.Request, conn net.Conn, executorPool subscription.ExecutorPool) subscription.ExecutorPool {
+	subscriptions := ctxGetGraphQLSubscriptionConn(r.Context())
+	if subscriptions == nil {
+		return executorPool
+	}
+
+	subscriptions.conn = conn
+	return &graphQLSubscriptionPool{graphQLSubscriptionConn: subscriptions, pool: executorPool}
+}
+
+// trackSubscriptionsV2 is trackSubscriptions for the websocket connections of graphql-go-tools v2.
+func (m *GraphQLMiddleware) trackSubscriptionsV2(r *http.Request, conn net.Conn, executorPool subscriptionv2.ExecutorPool) subscriptionv2.ExecutorPool {
+	subscriptions := ctxGetGraphQLSubscriptionConn(r.Context())
+	if subscriptions == nil {
+		return executorPool
+	}
+
+	subscriptions.conn = conn
+	return &graphQLSubscriptionPoolV2{graphQLSubscriptionConn: subscriptions, pool: executorPool}
+}
+
+// websocketBeforeStartHookV2 is the graphqlv2.WebsocketBeforeStartHook of GraphQL config version 3. It checks
+// trusted documents and tracks subscriptions, the other checks of OnBeforeStart aren't supported by this version yet.
+type websocketBeforeStartHookV2 struct {
+	m *GraphQLMiddleware
+}
+
+func (h websocketBeforeStartHookV2) OnBeforeStart(reqCtx context.Context, operation *gqlv2.Request) error {
+	m := h.m
+	if m.Spec.GraphQL.PersistedQueries.TrustedDocumentsOnly && !m.Gw.isTrustedGraphQLDocument(m.Spec.APIID, operation.Query) {
+		return GraphQLOperationNotTrustedErr
+	}
+
+	subscriptions := ctxGetGraphQLSubscriptionConn(reqCtx)
+	if subscriptions == nil {
+		return nil
+	}
+
+	if m.Spec.UseKeylessAccess {
+		return subscriptions.start(operation.OperationName, nil, user.APILimit{})
+	}
+
+	v := reqCtx.Value(ctx.SessionData)
+	if v == nil {
+		m.Logger().Error("failed to get session in OnBeforeStart hook")
+		return errors.New("empty session")
+	}
+	session := v.(*user.SessionState)
+
+	accessDef, _, err := GetAccessDefinitionByAPIIDOrSession(session, m.Spec)
+	if err != nil {
+		m.Logger().Errorf("failed to get access definition in OnBeforeStart hook: '%s'", err)
+		return err
+	}
+
+	return subscriptions.start(operation.OperationName, session, accessDef.Limit)
+}
+
 func (m *GraphQLMiddleware) OnBeforeFetch(ctx resolve.HookContext, input []byte) {
 	m.BaseMiddleware.Logger().
 		WithFields(
@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	// defaultGraphQLBatchMaxOperations is the maximum number of operations of a batch when the API doesn't set one.
+	defaultGraphQLBatchMaxOperations = 10
+	// graphQLBatchConcurrency is the maximum number of operations of a batch executed at the same time.
+	graphQLBatchConcurrency = 4
+)
+
+var (
+	GraphQLBatchTooLargeErr   = errors.New("too many operations in batch")
+	errGraphQLBatchBadRequest = errors.New("could not read batched GraphQL request")
+)
+
+// GraphQLBatchMiddleware accepts requests carrying an array of GraphQL operations. Each operation goes through the
+// middleware chain of the API as a request of its own, so it is authenticated, rate limited and checked on its own,
+// and the response is the array of the operation results in the order of the batch.
+type GraphQLBatchMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *GraphQLBatchMiddleware) Name() string {
+	return "GraphQLBatchMiddleware"
+}
+
+func (m *GraphQLBatchMiddleware) EnabledForSpec() bool {
+	return m.Spec.GraphQL.Enabled && m.Spec.GraphQL.Batching.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GraphQLBatchMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if r.Method != http.MethodPost || websocket.IsWebSocketUpgrade(r) {
+		return nil, http.StatusOK
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		m.Logger().WithError(err).Debug("Could not read GraphQL request")
+		return errGraphQLBatchBadRequest, http.StatusBadRequest
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		return nil, http.StatusOK
+	}
+
+	var operations []json.RawMessage
+	if err := json.Unmarshal(body, &operations); err != nil || len(operations) == 0 {
+		return errGraphQLBatchBadRequest, http.StatusBadRequest
+	}
+
+	maxOperations := m.Spec.GraphQL.Batching.MaxOperations
+	if maxOperations <= 0 {
+		maxOperations = defaultGraphQLBatchMaxOperations
+	}
+	if len(operations) > maxOperations {
+		return GraphQLBatchTooLargeErr, http.StatusBadRequest
+	}
+
+	chain, found := m.Gw.apisHandlesByID.Load(m.Spec.APIID)
+	if !found {
+		m.Logger().Error("Could not find the handler of the API to execute the batched operations")
+		return ProxyingRequestFailedErr, http.StatusInternalServerError
+	}
+	handler := chain.(*ChainObject).ThisHandler
+
+	results := make([]json.RawMessage, len(operations))
+	workers := make(chan struct{}, graphQLBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, operation := range operations {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, operation json.RawMessage) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			results[i] = m.executeOperation(handler, r, operation)
+		}(i, operation)
+	}
+	wg.Wait()
+
+	doJSONWrite(w, http.StatusOK, results)
+	return nil, mwStatusRespond
+}
+
+// executeOperation sends the operation of the batch through the API and returns its result.
+func (m *GraphQLBatchMiddleware) executeOperation(handler http.Handler, r *http.Request, operation json.RawMessage) json.RawMessage {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(operation))
+	req.ContentLength = int64(len(operation))
+	req.Header.Set(header.ContentLength, strconv.Itoa(len(operation)))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return graphQLBatchResult(recorder.Code, recorder.Body.Bytes())
+}
+
+// graphQLBatchResult returns the result of an operation of a batch. The gateway errors rejecting the operation become
+// GraphQL errors, so every item of the batch response is a GraphQL result.
+func graphQLBatchResult(code int, body []byte) json.RawMessage {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(body, &result); err == nil {
+		_, hasData := result["data"]
+		_, hasErrors := result["errors"]
+		if hasData || hasErrors {
+			return body
+		}
+	}
+
+	message := http.StatusText(code)
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != "" {
+		message = apiErr.Error
+	}
+
+	gqlErr, _ := json.Marshal(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+	return gqlErr
+}
@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestGraphQLBatchMiddleware(t *testing.T) {
+	g := StartTest(nil)
+	t.Cleanup(g.Close)
+
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = "schema { query: Query } type Query { hello: word } type word { numOfLetters: Int }"
+		spec.GraphQL.Batching.Enabled = true
+		spec.GraphQL.Batching.MaxOperations = 2
+	})[0]
+	g.Gw.LoadAPI(spec)
+
+	_, key := g.CreateSession(func(s *user.SessionState) {
+		s.MaxQueryDepth = 1
+		s.AccessRights = map[string]user.AccessDefinition{
+			spec.APIID: {APIID: spec.APIID, APIName: spec.Name},
+		}
+	})
+	authHeader := map[string]string{header.Authorization: key}
+
+	batch := []map[string]string{
+		{"query": "{ hello { numOfLetters } }"},
+		{"query": "{ __typename }"},
+	}
+
+	_, _ = g.Run(t, []test.TestCase{
+		{
+			Method:    http.MethodPost,
+			Headers:   authHeader,
+			Data:      batch,
+			Code:      http.StatusOK,
+			BodyMatch: `^\[{"errors":\[{"message":"depth limit exceeded"}\]},{"data":{"__typename":"Query"}}\]`,
+		},
+		{
+			Method:    http.MethodPost,
+			Data:      batch,
+			Code:      http.StatusOK,
+			BodyMatch: `^\[{"errors":\[{"message":"Authorization field missing"}\]},{"errors":\[{"message":"Authorization field missing"}\]}\]`,
+		},
+		{
+			Method:    http.MethodPost,
+			Headers:   authHeader,
+			Data:      append(batch, batch...),
+			Code:      http.StatusBadRequest,
+			BodyMatch: GraphQLBatchTooLargeErr.Error(),
+		},
+		{
+			Method:    http.MethodPost,
+			Headers:   authHeader,
+			Data:      "[]",
+			Code:      http.StatusBadRequest,
+			BodyMatch: errGraphQLBatchBadRequest.Error(),
+		},
+		{
+			Method:    http.MethodPost,
+			Headers:   authHeader,
+			Data:      batch[1],
+			Code:      http.StatusOK,
+			BodyMatch: `^{"data":{"__typename":"Query"}}`,
+		},
+	}...)
+}
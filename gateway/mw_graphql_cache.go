@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
+)
+
+// GraphQLCacheMiddleware caches GraphQL query responses. Unlike RedisCacheMiddleware the cache key
+// is built from the normalised operation, its variables and the configured claims, so requests that
+// only differ in formatting share the same cache entry. Mutations and subscriptions are never cached.
+type GraphQLCacheMiddleware struct {
+	*RedisCacheMiddleware
+
+	keyer *graphqlinternal.OperationCacheKeyer
+}
+
+func (m *GraphQLCacheMiddleware) Name() string {
+	return "GraphQLCacheMiddleware"
+}
+
+func (m *GraphQLCacheMiddleware) EnabledForSpec() bool {
+	return isGraphQLCacheEnabled(m.Spec)
+}
+
+func (m *GraphQLCacheMiddleware) Init() {
+	m.RedisCacheMiddleware.Init()
+
+	keyer, err := graphqlinternal.NewOperationCacheKeyer(m.Spec.graphQLSchema())
+	if err != nil {
+		m.Logger().WithError(err).Error("Could not parse schema for GraphQL cache, caching is disabled")
+		return
+	}
+	m.keyer = keyer
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GraphQLCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	t1 := time.Now()
+
+	if m.keyer == nil || r.Method != http.MethodPost || ctxGetGraphQLIsWebSocketUpgrade(r) {
+		return nil, http.StatusOK
+	}
+
+	// responses filtered for a key must not be shared through the cache
+	if session := ctxGetSession(r); session != nil && !session.AccessRights[m.Spec.APIID].ResponseFields.IsEmpty() {
+		return nil, http.StatusOK
+	}
+
+	if len(ctxGetGraphQLMaskedFields(r)) > 0 {
+		return nil, http.StatusOK
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		m.Logger().WithError(err).Debug("Could not read GraphQL request. Skipping cache check")
+		return nil, http.StatusOK
+	}
+
+	var gqlRequest gql.Request
+	if err := gql.UnmarshalRequest(bytes.NewReader(body), &gqlRequest); err != nil {
+		m.Logger().WithError(err).Debug("Could not parse GraphQL request. Skipping cache check")
+		return nil, http.StatusOK
+	}
+
+	cfg := m.Spec.GraphQL.Cache
+	operation, err := m.keyer.CacheableOperation(&gqlRequest, cfg.UseCacheControlDirectives)
+	if err != nil {
+		if !errors.Is(err, graphqlinternal.ErrOperationNotCacheable) {
+			m.Logger().WithError(err).Debug("Could not normalise GraphQL operation. Skipping cache check")
+		}
+		return nil, http.StatusOK
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = m.Spec.CacheOptions.CacheTimeout
+	}
+	if operation.HasMaxAge {
+		timeout = operation.MaxAge
+	}
+	if timeout <= 0 {
+		m.Logger().Debug("GraphQL operation has no cache TTL. Skipping cache check")
+		return nil, http.StatusOK
+	}
+
+	cacheOnlyResponseCodes := m.Spec.CacheOptions.CacheOnlyResponseCodes
+	if len(cacheOnlyResponseCodes) == 0 {
+		cacheOnlyResponseCodes = []int{http.StatusOK}
+	}
+
+	key := m.createCacheKey(r, operation.Hash)
+	ctxSetCacheOptions(r, &cacheOptions{
+		key:                    key,
+		cacheOnlyResponseCodes: cacheOnlyResponseCodes,
+		timeout:                timeout,
+	})
+
+	return m.serveCachedResponse(w, r, key, t1)
+}
+
+// createCacheKey combines the operation hash with the key making the request, or the organisation of the API when
+// the cache is shared, and the values of the configured claims.
+// Claims are looked up in the JWT claims context variables first, then in the session metadata.
+func (m *GraphQLCacheMiddleware) createCacheKey(r *http.Request, operationHash string) string {
+	h := sha256.New()
+	io.WriteString(h, operationHash)
+
+	session := ctxGetSession(r)
+	if m.Spec.GraphQL.Cache.Shared {
+		io.WriteString(h, "-org:"+m.Spec.OrgID)
+	} else if session != nil {
+		io.WriteString(h, "-key:"+session.KeyHash())
+	}
+
+	contextData := ctxGetData(r)
+	for _, claim := range m.Spec.GraphQL.Cache.CacheByClaims {
+		var value interface{}
+		if v, ok := contextData["jwt_claims_"+claim]; ok {
+			value = v
+		} else if session != nil {
+			value = session.MetaData[claim]
+		}
+
+		io.WriteString(h, fmt.Sprintf("-%s:%v", claim, value))
+	}
+
+	return m.Spec.APIID + "-graphql-" + hex.EncodeToString(h.Sum(nil))
+}
+
+func isGraphQLCacheEnabled(spec *APISpec) bool {
+	return spec.GraphQL.Enabled && spec.GraphQL.Cache.Enabled
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gql "github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestGraphQLCacheMiddleware(t *testing.T) {
+	test.Exclusive(t) // Need to limit parallelism due to DeleteScanMatch.
+
+	g := StartTest(nil)
+	defer g.Close()
+
+	// cached responses outlive the gateway, the test must start with an empty cache
+	cache := storage.RedisCluster{KeyPrefix: "cache-", IsCache: true, ConnectionHandler: g.Gw.StorageConnectionHandler}
+	cache.DeleteScanMatch("*")
+
+	g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = testGraphQLProxyUpstream
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = gqlProxyUpstreamSchema
+		spec.GraphQL.Cache.Enabled = true
+		spec.GraphQL.Cache.Timeout = 60
+	})
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+
+	t.Run("formatting differences hit the cache", func(t *testing.T) {
+		_, _ = g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Data: gql.Request{Query: `{ hello(name: "cache") httpMethod }`}, Code: http.StatusOK, HeadersNotMatch: cached},
+			{Method: http.MethodPost, Data: gql.Request{Query: "query {\n hello(name: \"cache\")\n httpMethod\n}"}, Code: http.StatusOK, HeadersMatch: cached},
+		}...)
+	})
+
+	t.Run("different arguments miss the cache", func(t *testing.T) {
+		_, _ = g.Run(t, test.TestCase{
+			Method: http.MethodPost, Data: gql.Request{Query: `{ hello(name: "other") httpMethod }`}, Code: http.StatusOK, HeadersNotMatch: cached,
+		})
+	})
+}
+
+func TestGraphQLCacheMiddleware_EnabledForSpec(t *testing.T) {
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.CacheOptions.EnableCache = true
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.Cache.Enabled = true
+	})[0]
+
+	redisCache := &RedisCacheMiddleware{BaseMiddleware: &BaseMiddleware{Spec: spec}}
+	graphqlCache := &GraphQLCacheMiddleware{RedisCacheMiddleware: redisCache}
+
+	assert.True(t, graphqlCache.EnabledForSpec())
+	assert.False(t, redisCache.EnabledForSpec(), "generic cache should be replaced by the GraphQL cache")
+
+	spec.GraphQL.Enabled = false
+	assert.False(t, graphqlCache.EnabledForSpec())
+	assert.True(t, redisCache.EnabledForSpec())
+}
+
+func TestGraphQLCacheMiddleware_createCacheKey(t *testing.T) {
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.APIID = "graphql-cache"
+		spec.GraphQL.Cache.CacheByClaims = []string{"tenant"}
+	})[0]
+
+	mw := &GraphQLCacheMiddleware{RedisCacheMiddleware: &RedisCacheMiddleware{BaseMiddleware: &BaseMiddleware{Spec: spec}}}
+
+	newRequest := func(tenant string) *http.Request {
+		r := TestReq(t, http.MethodPost, "/", nil)
+		ctxSetData(r, map[string]interface{}{"jwt_claims_tenant": tenant})
+		return r
+	}
+
+	keyA := mw.createCacheKey(newRequest("a"), "hash")
+	assert.Equal(t, keyA, mw.createCacheKey(newRequest("a"), "hash"))
+	assert.NotEqual(t, keyA, mw.createCacheKey(newRequest("b"), "hash"))
+	assert.NotEqual(t, keyA, mw.createCacheKey(newRequest("a"), "other"))
+
+	withSession := func(r *http.Request, keyID string) *http.Request {
+		ctxSetSession(r, &user.SessionState{KeyID: keyID}, false, false)
+		return r
+	}
+
+	t.Run("keys don't share cached responses", func(t *testing.T) {
+		key := mw.createCacheKey(withSession(newRequest("a"), "key-a"), "hash")
+		assert.NotEqual(t, keyA, key)
+		assert.Equal(t, key, mw.createCacheKey(withSession(newRequest("a"), "key-a"), "hash"))
+		assert.NotEqual(t, key, mw.createCacheKey(withSession(newRequest("a"), "key-b"), "hash"))
+	})
+
+	t.Run("shared cache is per organisation", func(t *testing.T) {
+		spec.GraphQL.Cache.Shared = true
+		defer func() { spec.GraphQL.Cache.Shared = false }()
+
+		assert.Equal(t,
+			mw.createCacheKey(withSession(newRequest("a"), "key-a"), "hash"),
+			mw.createCacheKey(withSession(newRequest("a"), "key-b"), "hash"),
+		)
+	})
+}
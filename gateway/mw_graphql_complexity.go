@@ -7,7 +7,9 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/internal/event"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -17,6 +19,7 @@ const (
 	ComplexityFailReasonNone ComplexityFailReason = iota
 	ComplexityFailReasonInternalError
 	ComplexityFailReasonDepthLimitExceeded
+	ComplexityFailReasonCostLimitExceeded
 )
 
 type GraphQLComplexityMiddleware struct {
@@ -56,7 +59,64 @@ func (m *GraphQLComplexityMiddleware) ProcessRequest(w http.ResponseWriter, r *h
 		})
 	}
 
-	return m.Spec.GraphEngine.ProcessGraphQLComplexity(r, graphEngineComplexityAccessDefinition)
+	if err, code := m.Spec.GraphEngine.ProcessGraphQLComplexity(r, graphEngineComplexityAccessDefinition); err != nil {
+		return err, code
+	}
+
+	if !m.Spec.GraphQL.CostAnalysis.Enabled {
+		return nil, http.StatusOK
+	}
+
+	cost, failReason := m.checkQueryCost(r, accessDef)
+	if failReason != ComplexityFailReasonNone {
+		return m.handleComplexityFailReason(failReason)
+	}
+
+	if isGraphQLCostRateLimited(m.Spec) {
+		return m.forwardQueryCost(r, cost)
+	}
+	return nil, http.StatusOK
+}
+
+// checkQueryCost estimates the cost of the operation and checks it against the max_query_cost of the key.
+func (m *GraphQLComplexityMiddleware) checkQueryCost(r *http.Request, accessDef *user.AccessDefinition) (int, ComplexityFailReason) {
+	if m.Spec.graphqlCostCalculator == nil {
+		return 0, ComplexityFailReasonInternalError
+	}
+
+	// websocket upgrades and playground requests don't carry an operation
+	gqlRequest := ctxGetGraphQLOperation(r)
+	if gqlRequest == nil {
+		return 0, ComplexityFailReasonNone
+	}
+
+	complexityCheck := &GraphqlComplexityChecker{logger: m.Logger()}
+	cost, failReason := complexityCheck.QueryCostExceeded(gqlRequest, accessDef, m.Spec.graphqlCostCalculator)
+	if failReason == ComplexityFailReasonNone {
+		ctxSetGraphQLQueryCost(r, cost)
+	}
+	return cost, failReason
+}
+
+// forwardQueryCost consumes rate limit allowance equal to the cost of the operation. Requests without
+// a costed operation, like introspection queries, consume 1.
+func (m *GraphQLComplexityMiddleware) forwardQueryCost(r *http.Request, cost int) (error, int) {
+	if ctxGetRequestStatus(r) == StatusOkAndIgnore || !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	if cost < 1 {
+		cost = 1
+	}
+
+	session := ctxGetSession(r)
+	rateLimitKey, quotaKey := m.rateLimitKeys(r, session)
+
+	reason := m.Gw.SessionLimiter.ForwardGraphQLCost(r, session, rateLimitKey, quotaKey != "", m.Spec, cost)
+	if reason == sessionFailRateLimit {
+		return m.handleRateLimitFailure(r, event.RateLimitExceeded, "Rate Limit Exceeded", rateLimitKey)
+	}
+	return nil, http.StatusOK
 }
 
 func (m *GraphQLComplexityMiddleware) handleComplexityFailReason(failReason ComplexityFailReason) (error, int) {
@@ -65,6 +125,8 @@ func (m *GraphQLComplexityMiddleware) handleComplexityFailReason(failReason Comp
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	case ComplexityFailReasonDepthLimitExceeded:
 		return GraphQLDepthLimitExceededErr, http.StatusForbidden
+	case ComplexityFailReasonCostLimitExceeded:
+		return GraphQLCostLimitExceededErr, http.StatusForbidden
 	}
 
 	return nil, http.StatusOK
@@ -162,3 +224,37 @@ func (c *GraphqlComplexityChecker) DepthLimitExceeded(gqlRequest *graphql.Reques
 	}
 	return ComplexityFailReasonNone
 }
+
+// QueryCostExceeded estimates the cost of the operation and checks it against the max_query_cost of the key.
+// Introspection queries are free.
+func (c *GraphqlComplexityChecker) QueryCostExceeded(gqlRequest *graphql.Request, accessDef *user.AccessDefinition, calculator *graphqlinternal.QueryCostCalculator) (int, ComplexityFailReason) {
+	isIntrospectionQuery, err := gqlRequest.IsIntrospectionQuery()
+	if err != nil {
+		c.logger.Debugf("Error while checking for introspection query: '%s'", err.Error())
+		return 0, ComplexityFailReasonInternalError
+	}
+
+	if isIntrospectionQuery {
+		return 0, ComplexityFailReasonNone
+	}
+
+	cost, err := calculator.Cost(gqlRequest)
+	if err != nil {
+		c.logger.Errorf("Error while calculating cost of GraphQL request: '%s'", err)
+		return 0, ComplexityFailReasonInternalError
+	}
+
+	// If MaxQueryCost is -1 or 0, it means unlimited.
+	if accessDef.Limit.MaxQueryCost > 0 && cost > accessDef.Limit.MaxQueryCost {
+		c.logger.Debugf("Cost '%d' of the request is higher than the allowed limit '%d'", cost, accessDef.Limit.MaxQueryCost)
+		return cost, ComplexityFailReasonCostLimitExceeded
+	}
+
+	return cost, ComplexityFailReasonNone
+}
+
+// isGraphQLCostRateLimited checks if operations of the API consume rate limit allowance equal to their cost.
+func isGraphQLCostRateLimited(spec *APISpec) bool {
+	cfg := spec.GraphQL.CostAnalysis
+	return spec.GraphQL.Enabled && cfg.Enabled && cfg.RateLimitByCost && !spec.DisableRateLimit && !spec.UseKeylessAccess
+}
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -10,6 +11,9 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -400,3 +404,65 @@ const gqlInvalidCountriesQuery = `{
       }
     }
 }`
+
+func TestGraphQLComplexityMiddleware_QueryCost(t *testing.T) {
+	g := StartTest(nil)
+	t.Cleanup(g.Close)
+
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `type Query { users(first: Int): [User] } type User { name: String }`
+		spec.GraphQL.CostAnalysis = apidef.GraphQLCostAnalysisConfig{
+			Enabled: true,
+			FieldCosts: map[string]apidef.GraphQLFieldCost{
+				"Query.users": {SlicingArguments: []string{"first"}},
+			},
+		}
+	})[0]
+	g.Gw.LoadAPI(spec)
+
+	request := func(first int) graphql.Request {
+		return graphql.Request{Query: fmt.Sprintf("{ users(first: %d) { name } }", first)}
+	}
+
+	t.Run("max query cost", func(t *testing.T) {
+		_, key := g.CreateSession(func(s *user.SessionState) {
+			s.MaxQueryCost = 5
+			s.AccessRights = map[string]user.AccessDefinition{
+				spec.APIID: {APIID: spec.APIID, APIName: spec.Name},
+			}
+		})
+		authHeader := map[string]string{header.Authorization: key}
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Headers: authHeader, Data: request(5), Code: http.StatusOK},
+			{Headers: authHeader, Data: request(6), BodyMatch: "query cost limit exceeded", Code: http.StatusForbidden},
+			{Headers: authHeader, Data: graphql.Request{Query: gqlIntrospectionQuery}, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("rate limit by cost", func(t *testing.T) {
+		spec.GraphQL.CostAnalysis.RateLimitByCost = true
+		g.Gw.LoadAPI(spec)
+
+		_, key := g.CreateSession(func(s *user.SessionState) {
+			s.Rate = 10
+			s.Per = 60
+			s.AccessRights = map[string]user.AccessDefinition{
+				spec.APIID: {APIID: spec.APIID, APIName: spec.Name},
+			}
+		})
+		authHeader := map[string]string{header.Authorization: key}
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Headers: authHeader, Data: request(4), Code: http.StatusOK},
+			{Headers: authHeader, Data: request(4), Code: http.StatusOK},
+			{Headers: authHeader, Data: request(4), Code: http.StatusTooManyRequests},
+			{Headers: authHeader, Data: request(2), Code: http.StatusOK},
+		}...)
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+	gqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 
 	"github.com/TykTechnologies/tyk/internal/graphengine"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/user"
 )
 
@@ -21,6 +23,8 @@ const RestrictedFieldValidationFailedLogMsg = "Error during GraphQL request rest
 
 type GraphQLGranularAccessMiddleware struct {
 	*BaseMiddleware
+
+	fieldAccessChecker *graphqlinternal.FieldAccessChecker
 }
 
 func (m *GraphQLGranularAccessMiddleware) Name() string {
@@ -31,6 +35,15 @@ func (m *GraphQLGranularAccessMiddleware) EnabledForSpec() bool {
 	return m.Spec.GraphQL.Enabled
 }
 
+func (m *GraphQLGranularAccessMiddleware) Init() {
+	checker, err := graphqlinternal.NewFieldAccessChecker(m.Spec.graphQLSchema())
+	if err != nil {
+		m.Logger().WithError(err).Error("Could not parse schema for GraphQL field access rules")
+		return
+	}
+	m.fieldAccessChecker = checker
+}
+
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (m *GraphQLGranularAccessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	if ctxGetRequestStatus(r) == StatusOkAndIgnore {
@@ -44,6 +57,12 @@ func (m *GraphQLGranularAccessMiddleware) ProcessRequest(w http.ResponseWriter,
 		return nil, http.StatusOK
 	}
 
+	if len(accessDef.FieldArgumentRules) > 0 || accessDef.MaskRestrictedFields {
+		if err, code, handled := m.checkFieldAccess(w, r, &accessDef); handled {
+			return err, code
+		}
+	}
+
 	graphEngineGranularAccessDefinition := &graphengine.GranularAccessDefinition{
 		AllowedTypes:         make([]graphengine.GranularAccessType, 0),
 		RestrictedTypes:      make([]graphengine.GranularAccessType, 0),
@@ -66,6 +85,79 @@ func (m *GraphQLGranularAccessMiddleware) ProcessRequest(w http.ResponseWriter,
 	return m.Spec.GraphEngine.ProcessGraphQLGranularAccess(w, r, graphEngineGranularAccessDefinition)
 }
 
+// checkFieldAccess checks the operation against the allowed and restricted types and the argument rules
+// of the access definition. Restricted fields either fail the request, or are removed from the operation
+// to be resolved to null by GraphQLMaskedFieldsMiddleware. Introspection queries aren't handled.
+func (m *GraphQLGranularAccessMiddleware) checkFieldAccess(w http.ResponseWriter, r *http.Request, accessDef *user.AccessDefinition) (err error, code int, handled bool) {
+	gqlRequest := ctxGetGraphQLOperation(r)
+	if gqlRequest == nil {
+		return nil, http.StatusOK, true
+	}
+
+	isIntrospection, err := gqlRequest.IsIntrospectionQueryStrict()
+	if err != nil || isIntrospection {
+		return nil, http.StatusOK, false
+	}
+
+	if m.fieldAccessChecker == nil {
+		return ProxyingRequestFailedErr, http.StatusInternalServerError, true
+	}
+
+	rules := graphqlinternal.FieldAccessRules{
+		AllowedTypes:    accessDef.AllowedTypes,
+		RestrictedTypes: accessDef.RestrictedTypes,
+		ArgumentRules:   make([]user.FieldArgumentRule, len(accessDef.FieldArgumentRules)),
+	}
+	for i, rule := range accessDef.FieldArgumentRules {
+		rule.AllowedValues = make([]string, len(accessDef.FieldArgumentRules[i].AllowedValues))
+		for j, value := range accessDef.FieldArgumentRules[i].AllowedValues {
+			rule.AllowedValues[j] = m.Gw.ReplaceTykVariables(r, value, false)
+		}
+		rules.ArgumentRules[i] = rule
+	}
+
+	result, err := m.fieldAccessChecker.Check(gqlRequest, rules, accessDef.MaskRestrictedFields)
+	if err != nil {
+		m.Logger().Errorf(RestrictedFieldValidationFailedLogMsg, err)
+		return ProxyingRequestFailedErr, http.StatusInternalServerError, true
+	}
+
+	if len(result.Restricted) == 0 {
+		return nil, http.StatusOK, true
+	}
+
+	if !accessDef.MaskRestrictedFields {
+		m.Logger().Debugf(RestrictedFieldValidationFailedLogMsg, result.Restricted[0].Message)
+		doJSONWrite(w, http.StatusBadRequest, restrictedFieldErrors(result.Restricted))
+		return errCustomBodyResponse, http.StatusBadRequest, true
+	}
+
+	if ctxGetGraphQLRequestV2(r) != nil {
+		ctxSetGraphQLRequestV2(r, &gqlv2.Request{
+			OperationName: result.Request.OperationName,
+			Variables:     result.Request.Variables,
+			Query:         result.Request.Query,
+		})
+	} else {
+		ctxSetGraphQLRequest(r, result.Request)
+	}
+	ctxSetGraphQLMaskedFields(r, result.Restricted)
+
+	return nil, http.StatusOK, true
+}
+
+// restrictedFieldErrors builds the GraphQL errors response entries of restricted fields.
+func restrictedFieldErrors(fields []graphqlinternal.RestrictedField) map[string]interface{} {
+	errs := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		errs = append(errs, map[string]interface{}{
+			"message": field.Message,
+			"path":    field.Path,
+		})
+	}
+	return map[string]interface{}{"errors": errs}
+}
+
 type GraphqlGranularAccessResult struct {
 	failReason       GranularAccessFailReason
 	validationResult *graphql.RequestFieldsValidationResult
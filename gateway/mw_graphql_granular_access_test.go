@@ -1,13 +1,16 @@
 package gateway
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
@@ -457,3 +460,76 @@ func TestGraphQL_DisableIntrospection(t *testing.T) {
 		}...)
 	})
 }
+
+func TestGraphQL_FieldArgumentRules(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	var upstreamQuery string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		upstreamQuery = req.Query
+
+		w.Header().Set(header.ContentType, header.ApplicationJSON)
+		_, _ = w.Write([]byte(`{"data":{"orders":[{"id":"1"}],"me":{"name":"Ada"}}}`))
+	}))
+	defer upstream.Close()
+
+	api := g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = false
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = `type Query { orders(tenantId: ID!): [Order] me: User } type Order { id: ID } type User { name: String }`
+	})[0]
+
+	createKey := func(mask bool) map[string]string {
+		_, key := g.CreateSession(func(s *user.SessionState) {
+			s.MetaData = map[string]interface{}{"tenant_id": "t1"}
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {
+					APIID:   api.APIID,
+					APIName: api.Name,
+					FieldArgumentRules: []user.FieldArgumentRule{
+						{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"$tyk_meta.tenant_id"}},
+					},
+					MaskRestrictedFields: mask,
+				},
+			}
+		})
+		return map[string]string{header.Authorization: key}
+	}
+
+	ownOrders := graphql.Request{Query: `{ orders(tenantId: "t1") { id } me { name } }`}
+	otherOrders := graphql.Request{Query: `query Orders($tenant: ID!) { orders(tenantId: $tenant) { id } me { name } }`, Variables: []byte(`{"tenant":"t2"}`)}
+
+	t.Run("reject", func(t *testing.T) {
+		authHeader := createKey(false)
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Data: ownOrders, Headers: authHeader, BodyMatch: `"orders":\[{"id":"1"}\]`, Code: http.StatusOK},
+			{Data: otherOrders, Headers: authHeader, BodyMatch: `argument: tenantId of field: orders is restricted on type: Query`, Code: http.StatusBadRequest},
+		}...)
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		authHeader := createKey(true)
+
+		_, _ = g.Run(t, test.TestCase{
+			Data:    otherOrders,
+			Headers: authHeader,
+			BodyMatchFunc: func(body []byte) bool {
+				return assert.JSONEq(t, `{
+					"data": {"me": {"name": "Ada"}, "orders": null},
+					"errors": [{"message": "argument: tenantId of field: orders is restricted on type: Query", "path": ["orders"]}]
+				}`, string(body))
+			},
+			Code: http.StatusOK,
+		})
+
+		assert.NotContains(t, upstreamQuery, "orders")
+	})
+}
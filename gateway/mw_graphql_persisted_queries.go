@@ -0,0 +1,253 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/graphengine"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	graphQLPersistedQueryKeyPrefix = "graphql-persisted-query-"
+
+	persistedQueryNotFound     = "PersistedQueryNotFound"
+	persistedQueryNotFoundCode = "PERSISTED_QUERY_NOT_FOUND"
+)
+
+var (
+	GraphQLOperationNotTrustedErr      = errors.New("operation is not a trusted document")
+	GraphQLPersistedQueryHashErr       = errors.New("provided sha does not match query")
+	GraphQLPersistedQueryVersionErr    = errors.New("unsupported persisted query version")
+	errGraphQLPersistedQueryBadRequest = errors.New("could not read persisted query request")
+	errGraphQLWebsocketNotTrusted      = errors.New("websocket operations cannot be checked against trusted documents")
+)
+
+// GraphQLPersistedQueryMiddleware implements automatic persisted queries (APQ) and the trusted documents
+// only mode. Requests carrying only the hash of a known query are rewritten to carry the query, so the
+// GraphQL middleware and upstreams never see the hash.
+type GraphQLPersistedQueryMiddleware struct {
+	*BaseMiddleware
+
+	store storage.Handler
+}
+
+func (m *GraphQLPersistedQueryMiddleware) Name() string {
+	return "GraphQLPersistedQueryMiddleware"
+}
+
+func (m *GraphQLPersistedQueryMiddleware) EnabledForSpec() bool {
+	cfg := m.Spec.GraphQL.PersistedQueries
+	return m.Spec.GraphQL.Enabled && (cfg.Enabled || cfg.TrustedDocumentsOnly)
+}
+
+func (m *GraphQLPersistedQueryMiddleware) isGraphQLConfigVersion1() bool {
+	return m.Spec.GraphQL.Version == apidef.GraphQLConfigVersion1 || m.Spec.GraphQL.Version == apidef.GraphQLConfigVersionNone
+}
+
+func (m *GraphQLPersistedQueryMiddleware) Init() {
+	m.store = m.Gw.graphQLPersistedQueryStore()
+}
+
+type persistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GraphQLPersistedQueryMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if websocket.IsWebSocketUpgrade(r) {
+		// operations over websockets are checked by the OnBeforeStart hook of the engine, version 1 has none
+		if m.Spec.GraphQL.PersistedQueries.TrustedDocumentsOnly && m.isGraphQLConfigVersion1() {
+			return errGraphQLWebsocketNotTrusted, http.StatusForbidden
+		}
+		return nil, http.StatusOK
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		return nil, http.StatusOK
+	}
+
+	body, err := m.readRequest(r)
+	if err != nil {
+		m.Logger().WithError(err).Debug("Could not read GraphQL request")
+		return errGraphQLPersistedQueryBadRequest, http.StatusBadRequest
+	}
+
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		// invalid requests are rejected by the GraphQL middleware
+		return nil, http.StatusOK
+	}
+
+	var query string
+	if raw, ok := request["query"]; ok {
+		_ = json.Unmarshal(raw, &query)
+	}
+
+	var extensions map[string]json.RawMessage
+	if raw, ok := request["extensions"]; ok {
+		_ = json.Unmarshal(raw, &extensions)
+	}
+
+	var persistedQuery persistedQueryExtension
+	if raw, ok := extensions["persistedQuery"]; ok {
+		if err := json.Unmarshal(raw, &persistedQuery); err != nil {
+			return errGraphQLPersistedQueryBadRequest, http.StatusBadRequest
+		}
+	}
+
+	cfg := m.Spec.GraphQL.PersistedQueries
+	hash := strings.ToLower(persistedQuery.Sha256Hash)
+
+	switch {
+	case hash == "" && query == "":
+		return nil, http.StatusOK
+	case hash == "":
+		if cfg.TrustedDocumentsOnly && !m.Gw.isTrustedGraphQLDocument(m.Spec.APIID, query) {
+			return GraphQLOperationNotTrustedErr, http.StatusForbidden
+		}
+		return nil, http.StatusOK
+	case persistedQuery.Version != 1:
+		return GraphQLPersistedQueryVersionErr, http.StatusBadRequest
+	case query != "":
+		if graphQLDocumentHash(query) != hash {
+			return GraphQLPersistedQueryHashErr, http.StatusBadRequest
+		}
+
+		if cfg.TrustedDocumentsOnly {
+			if !m.Gw.isTrustedGraphQLDocument(m.Spec.APIID, query) {
+				return GraphQLOperationNotTrustedErr, http.StatusForbidden
+			}
+		} else if err := m.store.SetKey(apqStorageKey(m.Spec.APIID, hash), query, cfg.TTL); err != nil {
+			m.Logger().WithError(err).Error("Could not store persisted query")
+		}
+	default:
+		var found bool
+		query, found = m.lookupQuery(hash)
+		if !found {
+			if cfg.TrustedDocumentsOnly {
+				return GraphQLOperationNotTrustedErr, http.StatusForbidden
+			}
+			return m.persistedQueryNotFound(w)
+		}
+	}
+
+	// the upstream receives the query, it may not support APQ
+	delete(extensions, "persistedQuery")
+	if err := m.rewriteRequest(r, request, query, extensions); err != nil {
+		m.Logger().WithError(err).Error("Could not rewrite persisted query request")
+		return ProxyingRequestFailedErr, http.StatusInternalServerError
+	}
+
+	return nil, http.StatusOK
+}
+
+// lookupQuery returns the query of the hash, trusted documents take precedence over queries registered through APQ.
+func (m *GraphQLPersistedQueryMiddleware) lookupQuery(hash string) (string, bool) {
+	if query, err := m.store.GetKey(trustedDocumentStorageKey(m.Spec.APIID, hash)); err == nil {
+		return query, true
+	}
+
+	if m.Spec.GraphQL.PersistedQueries.TrustedDocumentsOnly || !m.Spec.GraphQL.PersistedQueries.Enabled {
+		return "", false
+	}
+
+	query, err := m.store.GetKey(apqStorageKey(m.Spec.APIID, hash))
+	return query, err == nil
+}
+
+// persistedQueryNotFound responds with the GraphQL error APQ clients expect before retrying with the full query.
+func (m *GraphQLPersistedQueryMiddleware) persistedQueryNotFound(w http.ResponseWriter) (error, int) {
+	resp := map[string]interface{}{
+		"errors": []map[string]interface{}{
+			{
+				"message":    persistedQueryNotFound,
+				"extensions": map[string]string{"code": persistedQueryNotFoundCode},
+			},
+		},
+	}
+
+	w.Header().Set(header.CacheControl, "private, no-store")
+	doJSONWrite(w, http.StatusOK, resp)
+	return nil, mwStatusRespond
+}
+
+// readRequest returns the GraphQL request of the body, or of the query string for GET requests.
+func (m *GraphQLPersistedQueryMiddleware) readRequest(r *http.Request) ([]byte, error) {
+	if r.Method == http.MethodGet {
+		return graphengine.GraphQLRequestFromQueryString(r.URL.Query())
+	}
+	return readBody(r)
+}
+
+func (m *GraphQLPersistedQueryMiddleware) rewriteRequest(r *http.Request, request map[string]json.RawMessage, query string, extensions map[string]json.RawMessage) error {
+	rawQuery, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+	request["query"] = rawQuery
+
+	if len(extensions) == 0 {
+		delete(request, "extensions")
+	} else if request["extensions"], err = json.Marshal(extensions); err != nil {
+		return err
+	}
+
+	if r.Method == http.MethodGet {
+		values := r.URL.Query()
+		values.Set("query", query)
+		if len(extensions) == 0 {
+			values.Del("extensions")
+		} else {
+			values.Set("extensions", string(request["extensions"]))
+		}
+		r.URL.RawQuery = values.Encode()
+		return nil
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set(header.ContentLength, strconv.Itoa(len(body)))
+	return nil
+}
+
+func (gw *Gateway) graphQLPersistedQueryStore() *storage.RedisCluster {
+	return &storage.RedisCluster{KeyPrefix: graphQLPersistedQueryKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+}
+
+// isTrustedGraphQLDocument checks if the query was uploaded as a trusted document of the API.
+func (gw *Gateway) isTrustedGraphQLDocument(apiID, query string) bool {
+	exists, err := gw.graphQLPersistedQueryStore().Exists(trustedDocumentStorageKey(apiID, graphQLDocumentHash(query)))
+	return err == nil && exists
+}
+
+// graphQLDocumentHash returns the hex encoded SHA-256 hash of the document, as used by APQ clients.
+func graphQLDocumentHash(document string) string {
+	hash := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(hash[:])
+}
+
+func apqStorageKey(apiID, hash string) string {
+	return "apq-" + apiID + "-" + hash
+}
+
+func trustedDocumentStorageKey(apiID, hash string) string {
+	return "trusted-" + apiID + "-" + hash
+}
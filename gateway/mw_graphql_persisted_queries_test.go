@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGraphQLPersistedQueryMiddleware(t *testing.T) {
+	g := StartTest(nil)
+	t.Cleanup(g.Close)
+
+	const query = "query Hello { hello { numOfLetters } }"
+	hash := graphQLDocumentHash(query)
+
+	spec := BuildAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+		spec.GraphQL.Enabled = true
+		spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		spec.GraphQL.Version = apidef.GraphQLConfigVersion2
+		spec.GraphQL.Schema = "schema { query: Query } type Query { hello: word } type word { numOfLetters: Int }"
+		spec.GraphQL.PersistedQueries.Enabled = true
+	})[0]
+	g.Gw.LoadAPI(spec)
+
+	persistedQuery := func(hash string) map[string]interface{} {
+		return map[string]interface{}{
+			"extensions": map[string]interface{}{
+				"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+			},
+		}
+	}
+
+	withQuery := func(req map[string]interface{}, query string) map[string]interface{} {
+		req["query"] = query
+		return req
+	}
+
+	t.Run("automatic persisted queries", func(t *testing.T) {
+		_, _ = g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Data: persistedQuery(hash), BodyMatch: persistedQueryNotFoundCode, Code: http.StatusOK},
+			{Method: http.MethodPost, Data: withQuery(persistedQuery(graphQLDocumentHash("{ hello }")), query), BodyMatch: GraphQLPersistedQueryHashErr.Error(), Code: http.StatusBadRequest},
+			{Method: http.MethodPost, Data: withQuery(persistedQuery(hash), query), BodyMatch: `"hello"`, Code: http.StatusOK},
+			{Method: http.MethodPost, Data: persistedQuery(hash), BodyMatch: `"hello"`, Code: http.StatusOK},
+			{Method: http.MethodGet, Path: "/?" + url.Values{"extensions": {`{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`}}.Encode(), BodyMatch: `"hello"`, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("trusted documents only", func(t *testing.T) {
+		spec.GraphQL.PersistedQueries.TrustedDocumentsOnly = true
+		g.Gw.LoadAPI(spec)
+
+		const trustedQuery = "query Trusted { hello { numOfLetters } }"
+		manifest := TrustedDocumentsManifest{
+			Operations: []TrustedDocument{{Body: trustedQuery}},
+		}
+		trustedDocumentsPath := "/tyk/apis/" + spec.APIID + "/graphql/trusted-documents"
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: trustedDocumentsPath, AdminAuth: true, Data: TrustedDocumentsManifest{Operations: []TrustedDocument{{ID: hash, Body: trustedQuery}}}, Code: http.StatusBadRequest},
+			{Method: http.MethodPost, Path: trustedDocumentsPath, AdminAuth: true, Data: manifest, BodyMatch: "1 trusted documents added", Code: http.StatusOK},
+			{Method: http.MethodGet, Path: trustedDocumentsPath, AdminAuth: true, BodyMatch: graphQLDocumentHash(trustedQuery), Code: http.StatusOK},
+
+			{Method: http.MethodPost, Data: map[string]string{"query": trustedQuery}, BodyMatch: `"hello"`, Code: http.StatusOK},
+			{Method: http.MethodPost, Data: persistedQuery(graphQLDocumentHash(trustedQuery)), BodyMatch: `"hello"`, Code: http.StatusOK},
+			// queries registered through APQ before aren't trusted
+			{Method: http.MethodPost, Data: persistedQuery(hash), BodyMatch: GraphQLOperationNotTrustedErr.Error(), Code: http.StatusForbidden},
+			{Method: http.MethodPost, Data: map[string]string{"query": query}, BodyMatch: GraphQLOperationNotTrustedErr.Error(), Code: http.StatusForbidden},
+
+			{Method: http.MethodDelete, Path: trustedDocumentsPath, AdminAuth: true, Code: http.StatusOK},
+			{Method: http.MethodPost, Data: map[string]string{"query": trustedQuery}, Code: http.StatusForbidden},
+		}...)
+	})
+
+	t.Run("trusted documents over websockets", func(t *testing.T) {
+		specs := BuildAPI(func(spec *APISpec) {
+			spec.APIID = "v1"
+			spec.UseKeylessAccess = true
+			spec.Proxy.ListenPath = "/v1/"
+			spec.GraphQL.Enabled = true
+			spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+			spec.GraphQL.Version = apidef.GraphQLConfigVersion1
+			spec.GraphQL.PersistedQueries.TrustedDocumentsOnly = true
+		}, func(spec *APISpec) {
+			spec.APIID = "v3"
+			spec.UseKeylessAccess = true
+			spec.Proxy.ListenPath = "/v3/"
+			spec.GraphQL.Enabled = true
+			spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+			spec.GraphQL.Version = apidef.GraphQLConfigVersion3Preview
+			spec.GraphQL.Schema = "schema { query: Query } type Query { hello: word } type word { numOfLetters: Int }"
+			spec.GraphQL.PersistedQueries.TrustedDocumentsOnly = true
+		})
+		g.Gw.LoadAPI(append(specs, spec)...)
+
+		const trustedQuery = "query Trusted { hello { numOfLetters } }"
+		manifest := TrustedDocumentsManifest{Operations: []TrustedDocument{{Body: trustedQuery}}}
+
+		// version 1 has no hook to check the operations, so the upgrade is rejected
+		_, _ = g.Run(t, []test.TestCase{
+			{Path: "/v1/", Headers: map[string]string{
+				header.Connection:           "Upgrade",
+				header.Upgrade:              "websocket",
+				header.SecWebSocketVersion:  "13",
+				header.SecWebSocketKey:      "dGhlIHNhbXBsZSBub25jZQ==",
+				header.SecWebSocketProtocol: "graphql-ws",
+			}, BodyMatch: errGraphQLWebsocketNotTrusted.Error(), Code: http.StatusForbidden},
+			{Method: http.MethodPost, Path: "/tyk/apis/v3/graphql/trusted-documents", AdminAuth: true, Data: manifest, Code: http.StatusOK},
+		}...)
+
+		hook := websocketBeforeStartHookV2{&GraphQLMiddleware{BaseMiddleware: &BaseMiddleware{Spec: specs[1], Gw: g.Gw}}}
+		assert.NoError(t, hook.OnBeforeStart(context.Background(), &gqlv2.Request{Query: trustedQuery}))
+		assert.ErrorIs(t, hook.OnBeforeStart(context.Background(), &gqlv2.Request{Query: query}), GraphQLOperationNotTrustedErr)
+	})
+
+	t.Run("trusted documents of APIs with prefixed IDs", func(t *testing.T) {
+		specs := BuildAPI(func(spec *APISpec) {
+			spec.APIID = "my-api"
+			spec.Proxy.ListenPath = "/my-api/"
+			spec.GraphQL.Enabled = true
+		}, func(spec *APISpec) {
+			spec.APIID = "my-api-v2"
+			spec.Proxy.ListenPath = "/my-api-v2/"
+			spec.GraphQL.Enabled = true
+		})
+		g.Gw.LoadAPI(append(specs, spec)...)
+
+		const v2Query = "query V2 { hello { numOfLetters } }"
+		manifest := TrustedDocumentsManifest{Operations: []TrustedDocument{{Body: v2Query}}}
+
+		_, _ = g.Run(t, []test.TestCase{
+			{Method: http.MethodPost, Path: "/tyk/apis/my-api-v2/graphql/trusted-documents", AdminAuth: true, Data: manifest, Code: http.StatusOK},
+			{Method: http.MethodGet, Path: "/tyk/apis/my-api/graphql/trusted-documents", AdminAuth: true, BodyMatch: `"operations":\[\]`, Code: http.StatusOK},
+			{Method: http.MethodDelete, Path: "/tyk/apis/my-api/graphql/trusted-documents", AdminAuth: true, Code: http.StatusOK},
+			{Method: http.MethodGet, Path: "/tyk/apis/my-api-v2/graphql/trusted-documents", AdminAuth: true, BodyMatch: graphQLDocumentHash(v2Query), Code: http.StatusOK},
+			{Method: http.MethodDelete, Path: "/tyk/apis/my-api-v2/graphql/trusted-documents", AdminAuth: true, Code: http.StatusOK},
+		}...)
+	})
+}
@@ -2,10 +2,12 @@ package gateway
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -1931,3 +1933,60 @@ type Review {
 	author: User!
 	product: Product!
 }`
+
+func TestGraphQLMiddleware_UDGDataSources(t *testing.T) {
+	g := StartTest(nil)
+	defer g.Close()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "users.db")
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER, name TEXT); INSERT INTO users VALUES (1, 'Ada'), (2, 'Grace')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	sqlDataSource := func(field, query string, arguments ...string) apidef.GraphQLEngineDataSource {
+		conf, err := json.Marshal(apidef.GraphQLEngineDataSourceConfigSQL{
+			Driver:           "sqlite",
+			ConnectionString: dsn,
+			Query:            query,
+			Arguments:        arguments,
+		})
+		require.NoError(t, err)
+
+		return apidef.GraphQLEngineDataSource{
+			Kind:       apidef.GraphQLEngineDataSourceKindSQL,
+			Name:       field,
+			RootFields: []apidef.GraphQLTypeFields{{Type: "Query", Fields: []string{field}}},
+			Config:     conf,
+		}
+	}
+
+	for _, version := range []apidef.GraphQLConfigVersion{apidef.GraphQLConfigVersion2, apidef.GraphQLConfigVersion3Preview} {
+		t.Run(string(version), func(t *testing.T) {
+			g.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+				spec.UseKeylessAccess = true
+				spec.Proxy.ListenPath = "/"
+				spec.GraphQL.Enabled = true
+				spec.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+				spec.GraphQL.Version = version
+				spec.GraphQL.Schema = `type Query { user(id: Int!): User users(name: String!): [User] } type User { id: Int name: String }`
+				spec.GraphQL.Engine.FieldConfigs = []apidef.GraphQLFieldConfig{
+					{TypeName: "Query", FieldName: "user", DisableDefaultMapping: true},
+					{TypeName: "Query", FieldName: "users", DisableDefaultMapping: true},
+				}
+				spec.GraphQL.Engine.DataSources = []apidef.GraphQLEngineDataSource{
+					sqlDataSource("user", "SELECT id, name FROM users WHERE id = ?", "{{ .arguments.id }}"),
+					sqlDataSource("users", "SELECT id, name FROM users WHERE name = ?", "{{ .arguments.name }}"),
+				}
+			})
+
+			_, _ = g.Run(t, test.TestCase{Data: gql.Request{Query: `{ user(id: 1) { id name } }`}, BodyMatch: `{"data":{"user":{"id":1,"name":"Ada"}}}`, Code: http.StatusOK})
+
+			// list root fields of REST based data sources aren't supported by engine v3 yet
+			if version == apidef.GraphQLConfigVersion2 {
+				_, _ = g.Run(t, test.TestCase{Data: gql.Request{Query: `{ users(name: "Ada") { id } }`}, BodyMatch: `{"data":{"users":\[{"id":1}\]}}`, Code: http.StatusOK})
+			}
+		})
+	}
+}
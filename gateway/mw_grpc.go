@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	grpcContentType = "application/grpc"
+
+	grpcStatusHeader  = "Grpc-Status"
+	grpcMessageHeader = "Grpc-Message"
+)
+
+var errGRPCMethodNotAllowed = errors.New("gRPC method isn't allowed")
+
+// GRPCMiddleware rejects calls to the methods not allowed by a gRPC API.
+type GRPCMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *GRPCMiddleware) Name() string {
+	return "GRPCMiddleware"
+}
+
+func (m *GRPCMiddleware) EnabledForSpec() bool {
+	return m.Spec.GRPC.Enabled && len(m.Spec.GRPC.AllowedMethods) > 0
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GRPCMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	method := m.Spec.grpcMethod(r)
+	for _, allowed := range m.Spec.GRPC.AllowedMethods {
+		if grpcMethodMatches(allowed, method) {
+			return nil, http.StatusOK
+		}
+	}
+
+	m.Logger().WithField("method", method).Debug("gRPC method isn't allowed")
+	return errGRPCMethodNotAllowed, http.StatusForbidden
+}
+
+// isGRPCRequest checks the content type of the request, gRPC-Web requests aren't gRPC requests.
+func isGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get(header.ContentType)
+	return contentType == grpcContentType ||
+		strings.HasPrefix(contentType, grpcContentType+"+") ||
+		strings.HasPrefix(contentType, grpcContentType+";")
+}
+
+// grpcMethod returns the gRPC method called by the request, as /package.Service/Method.
+func (a *APISpec) grpcMethod(r *http.Request) string {
+	if call := ctxGetGRPCTranscodedCall(r); call != nil {
+		return call.route.grpcMethod
+	}
+
+	method := r.URL.Path
+	if a.Proxy.StripListenPath {
+		method = a.StripListenPath(method)
+	}
+	return "/" + strings.TrimPrefix(method, "/")
+}
+
+// grpcMethodMatches checks a method against a /package.Service/Method or /package.Service/* pattern.
+func grpcMethodMatches(pattern, method string) bool {
+	pattern = "/" + strings.TrimPrefix(pattern, "/")
+	if service, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(method, service+"/")
+	}
+	return pattern == method
+}
+
+// grpcMethodRateLimit returns the rate limit of the method called by the request, preferring
+// limits of the method over limits of its service.
+func (a *APISpec) grpcMethodRateLimit(r *http.Request) (apidef.GRPCMethodRateLimit, bool) {
+	if !a.GRPC.Enabled {
+		return apidef.GRPCMethodRateLimit{}, false
+	}
+
+	var (
+		method  = a.grpcMethod(r)
+		limit   apidef.GRPCMethodRateLimit
+		matched bool
+	)
+	for _, l := range a.GRPC.MethodRateLimits {
+		if l.Disabled || l.Rate <= 0 || l.Per <= 0 || !grpcMethodMatches(l.Method, method) {
+			continue
+		}
+
+		if !strings.HasSuffix(l.Method, "/*") {
+			return l, true
+		}
+		limit, matched = l, true
+	}
+	return limit, matched
+}
+
+// grpcCodeFromHTTP maps the status codes of gateway errors to gRPC status codes.
+func grpcCodeFromHTTP(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// writeGRPCError writes a gateway error as a gRPC response, with the status in trailers.
+// The response is filled for analytics.
+func writeGRPCError(w http.ResponseWriter, response *http.Response, errMsg string, errCode int) {
+	status := strconv.Itoa(int(grpcCodeFromHTTP(errCode)))
+	message := encodeGRPCMessage(errMsg)
+
+	w.Header().Set(header.ContentType, grpcContentType)
+	w.Header().Set("Trailer", grpcStatusHeader+", "+grpcMessageHeader)
+	w.WriteHeader(http.StatusOK)
+	w.Header().Set(grpcStatusHeader, status)
+	w.Header().Set(grpcMessageHeader, message)
+
+	response.StatusCode = http.StatusOK
+	response.Header = http.Header{}
+	response.Header.Set(header.ContentType, grpcContentType)
+	response.Trailer = http.Header{}
+	response.Trailer.Set(grpcStatusHeader, status)
+	response.Trailer.Set(grpcMessageHeader, message)
+}
+
+// encodeGRPCMessage percent-encodes the grpc-message as required by the gRPC HTTP/2 protocol.
+func encodeGRPCMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// grpcAnalyticsTag returns the grpc-status tag of a call. The status is kept on the request by the bridges
+// consuming the upstream trailers.
+func grpcAnalyticsTag(r *http.Request, res *http.Response) string {
+	if call := ctxGetGRPCTranscodedCall(r); call != nil && call.status != "" {
+		return "grpc-status-" + call.status
+	}
+	if call := ctxGetGRPCWebCall(r); call != nil && call.status != "" {
+		return "grpc-status-" + call.status
+	}
+	return grpcStatusTag(res)
+}
+
+// grpcStatusTag returns the analytics tag recording the grpc-status of the response, sent in
+// trailers or in headers for trailers-only responses.
+func grpcStatusTag(res *http.Response) string {
+	if res == nil {
+		return ""
+	}
+
+	status := res.Trailer.Get(grpcStatusHeader)
+	if status == "" {
+		status = res.Header.Get(grpcStatusHeader)
+	}
+	if status == "" {
+		return ""
+	}
+	return "grpc-status-" + status
+}
@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	pbexample "google.golang.org/grpc/examples/helloworld/helloworld"
+	"google.golang.org/grpc/status"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+func TestGRPCMethodMatches(t *testing.T) {
+	const method = "/helloworld.Greeter/SayHello"
+
+	assert.True(t, grpcMethodMatches("/helloworld.Greeter/SayHello", method))
+	assert.True(t, grpcMethodMatches("helloworld.Greeter/SayHello", method))
+	assert.True(t, grpcMethodMatches("/helloworld.Greeter/*", method))
+	assert.False(t, grpcMethodMatches("/helloworld.Greeter/SayBye", method))
+	assert.False(t, grpcMethodMatches("/helloworld.Greet/*", method))
+	assert.False(t, grpcMethodMatches("/helloworldXGreeter/SayHello", method))
+}
+
+func TestWriteGRPCError(t *testing.T) {
+	w := httptest.NewRecorder()
+	response := &http.Response{}
+
+	writeGRPCError(w, response, "Key not authorised: 100%", http.StatusUnauthorized)
+
+	res := w.Result()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, grpcContentType, res.Header.Get(header.ContentType))
+	assert.Equal(t, "16", res.Trailer.Get(grpcStatusHeader))
+	assert.Equal(t, "Key not authorised: 100%25", res.Trailer.Get(grpcMessageHeader))
+	assert.Equal(t, "grpc-status-16", grpcStatusTag(response))
+}
+
+func callSayHello(t *testing.T, address string) error {
+	t.Helper()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = pbexample.NewGreeterClient(conn).SayHello(ctx, &pbexample.HelloRequest{Name: "Tyk"})
+	return err
+}
+
+func TestGRPC_NativeAPI(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var port = 6667
+	ts.EnablePort(port, "h2c")
+
+	target, s := startGRPCServerH2C(t, setupHelloSVC)
+	defer target.Close()
+	defer s.Stop()
+
+	var (
+		mu   sync.Mutex
+		tags []string
+	)
+	ts.Gw.Analytics.mockEnabled = true
+	ts.Gw.Analytics.mockRecordHit = func(record *analytics.AnalyticsRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, tag := range record.Tags {
+			if strings.HasPrefix(tag, "grpc-status-") {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	lastTag := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(tags) == 0 {
+			return ""
+		}
+		return tags[len(tags)-1]
+	}
+
+	loadAPI := func(fn func(spec *APISpec)) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Name = "grpc_api"
+			spec.Proxy.ListenPath = "/"
+			spec.UseKeylessAccess = true
+			spec.Proxy.TargetURL = toTarget(t, "h2c", target)
+			spec.ListenPort = port
+			spec.Protocol = "h2c"
+			spec.GRPC.Enabled = true
+			fn(spec)
+		})
+	}
+	address := "localhost:6667"
+
+	t.Run("allowed method", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.GRPC.AllowedMethods = []string{"/helloworld.Greeter/*"}
+		})
+
+		require.NoError(t, callSayHello(t, address))
+		assert.Eventually(t, func() bool { return lastTag() == "grpc-status-0" }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.GRPC.AllowedMethods = []string{"/helloworld.Greeter/SayBye"}
+		})
+
+		err := callSayHello(t, address)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, errGRPCMethodNotAllowed.Error(), status.Convert(err).Message())
+		assert.Eventually(t, func() bool { return lastTag() == "grpc-status-7" }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("auth failure", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.UseKeylessAccess = false
+		})
+
+		err := callSayHello(t, address)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Eventually(t, func() bool { return lastTag() == "grpc-status-16" }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("method rate limit", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.GRPC.MethodRateLimits = []apidef.GRPCMethodRateLimit{
+				{Method: "/helloworld.Greeter/*", Rate: 100, Per: 1},
+				{Method: "/helloworld.Greeter/SayHello", Rate: 1, Per: 60},
+			}
+		})
+
+		require.NoError(t, callSayHello(t, address))
+		err := callSayHello(t, address)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
@@ -0,0 +1,91 @@
+package gateway
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+var (
+	errGRPCTranscodingUnavailable = errors.New("gRPC transcoding descriptors couldn't be loaded")
+	errGRPCNoRoute                = errors.New("no gRPC method matches the request")
+)
+
+// GRPCTranscodingMiddleware matches REST/JSON requests to the gRPC methods of a transcoding API. It runs
+// before the gRPC method checks, so allow lists and method rate limits apply to transcoded calls.
+type GRPCTranscodingMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *GRPCTranscodingMiddleware) Name() string {
+	return "GRPCTranscodingMiddleware"
+}
+
+func (m *GRPCTranscodingMiddleware) EnabledForSpec() bool {
+	return m.Spec.GRPC.Enabled && m.Spec.GRPC.Transcoding.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GRPCTranscodingMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if isGRPCRequest(r) || isGRPCWebRequest(r) {
+		return nil, http.StatusOK
+	}
+
+	if m.Spec.grpcTranscoder == nil {
+		return errGRPCTranscodingUnavailable, http.StatusInternalServerError
+	}
+
+	path := "/" + strings.TrimPrefix(m.Spec.StripListenPath(r.URL.EscapedPath()), "/")
+	call := m.Spec.grpcTranscoder.match(r.Method, path)
+	if call == nil {
+		m.Logger().WithField("path", path).Debug("No gRPC method matches the request")
+		return errGRPCNoRoute, http.StatusNotFound
+	}
+
+	ctxSetGRPCTranscodedCall(r, call)
+	return nil, http.StatusOK
+}
+
+// GRPCTranscodeRequestMiddleware turns transcoded requests into gRPC calls. It runs last, so the other
+// middleware see the REST/JSON request.
+type GRPCTranscodeRequestMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *GRPCTranscodeRequestMiddleware) Name() string {
+	return "GRPCTranscodeRequestMiddleware"
+}
+
+func (m *GRPCTranscodeRequestMiddleware) EnabledForSpec() bool {
+	return m.Spec.GRPC.Enabled && m.Spec.GRPC.Transcoding.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GRPCTranscodeRequestMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	call := ctxGetGRPCTranscodedCall(r)
+	if call == nil {
+		return nil, http.StatusOK
+	}
+
+	message, err := call.requestMessage(r)
+	if err != nil {
+		m.Logger().WithError(err).Debug("Couldn't transcode request")
+		return err, http.StatusBadRequest
+	}
+
+	body := frameGRPCMessage(message)
+	r.Method = http.MethodPost
+	r.URL.Path = call.route.grpcMethod
+	r.URL.RawPath = ""
+	r.URL.RawQuery = ""
+	r.Header.Set(header.ContentType, grpcContentType)
+	r.Header.Set("Te", "trailers")
+	r.Header.Del(header.ContentLength)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil, http.StatusOK
+}
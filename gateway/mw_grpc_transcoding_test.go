@@ -0,0 +1,214 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	pbexample "google.golang.org/grpc/examples/helloworld/helloworld"
+	"google.golang.org/grpc/examples/route_guide/routeguide"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestGRPCPathTemplate(t *testing.T) {
+	testCases := []struct {
+		template string
+		path     string
+		vars     map[string]string
+		oasPath  string
+	}{
+		{"/v1/hello/{name}", "/v1/hello/Tyk%20Gateway", map[string]string{"name": "Tyk Gateway"}, "/v1/hello/{name}"},
+		{"/v1/hello/{name}", "/v1/hello/a/b", nil, "/v1/hello/{name}"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/books/2", map[string]string{"name": "shelves/1/books/2"}, "/v1/{name}"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/files/2", nil, "/v1/{name}"},
+		{"/files/{path=**}", "/files/a/b/c", map[string]string{"path": "a/b/c"}, "/files/{path}"},
+		{"/v1/{book.id}:publish", "/v1/42:publish", map[string]string{"book.id": "42"}, "/v1/{book.id}:publish"},
+		{"/v1/{book.id}:publish", "/v1/42", nil, "/v1/{book.id}:publish"},
+		{"/", "/", map[string]string{}, "/"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.template+" "+tc.path, func(t *testing.T) {
+			tmpl, err := parseGRPCPathTemplate(tc.template)
+			require.NoError(t, err)
+			assert.Equal(t, tc.oasPath, tmpl.oasPath())
+
+			vars, ok := tmpl.match(tc.path)
+			assert.Equal(t, tc.vars != nil, ok)
+			assert.Equal(t, tc.vars, vars)
+		})
+	}
+
+	for _, invalid := range []string{"v1/hello", "/v1/{name", "/v1/**/name"} {
+		_, err := parseGRPCPathTemplate(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+type testRouteGuideServer struct {
+	routeguide.UnimplementedRouteGuideServer
+}
+
+func (testRouteGuideServer) GetFeature(_ context.Context, p *routeguide.Point) (*routeguide.Feature, error) {
+	if p.Latitude < 0 {
+		return nil, status.Error(codes.NotFound, "no feature")
+	}
+	return &routeguide.Feature{Name: fmt.Sprintf("%d,%d", p.Latitude, p.Longitude)}, nil
+}
+
+func (testRouteGuideServer) ListFeatures(rect *routeguide.Rectangle, stream routeguide.RouteGuide_ListFeaturesServer) error {
+	for _, p := range []*routeguide.Point{rect.Lo, rect.Hi} {
+		if p.GetLatitude() < 0 {
+			return status.Error(codes.NotFound, "no feature")
+		}
+		if err := stream.Send(&routeguide.Feature{Name: fmt.Sprintf("%d,%d", p.GetLatitude(), p.GetLongitude())}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testGRPCDescriptorSet returns the descriptors of the example services, with HTTP rules for SayHello and ListFeatures.
+func testGRPCDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	setRule := func(method *descriptorpb.MethodDescriptorProto, rule *annotations.HttpRule) {
+		method.Options = &descriptorpb.MethodOptions{}
+		proto.SetExtension(method.Options, annotations.E_Http, rule)
+	}
+
+	hello := protodesc.ToFileDescriptorProto(pbexample.File_examples_helloworld_helloworld_helloworld_proto)
+	setRule(hello.Service[0].Method[0], &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/hello/{name}"},
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Post{Post: "/v1/hello"}, Body: "*"},
+		},
+	})
+
+	guide := protodesc.ToFileDescriptorProto(routeguide.File_examples_route_guide_routeguide_route_guide_proto)
+	for _, method := range guide.Service[0].Method {
+		if method.GetName() == "ListFeatures" {
+			setRule(method, &annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/v1/features/{lo.latitude}/{lo.longitude}"},
+			})
+		}
+	}
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{hello, guide}})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestGRPC_Transcoding(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	target, s := startGRPCServerH2C(t, func(t *testing.T, s *grpc.Server) {
+		setupHelloSVC(t, s)
+		routeguide.RegisterRouteGuideServer(s, testRouteGuideServer{})
+	})
+	defer target.Close()
+	defer s.Stop()
+
+	descriptors := testGRPCDescriptorSet(t)
+	loadAPI := func(fn func(spec *APISpec)) *APISpec {
+		return ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "grpc-transcoding"
+			spec.Proxy.ListenPath = "/rest/"
+			spec.Proxy.StripListenPath = true
+			spec.UseKeylessAccess = true
+			spec.Proxy.TargetURL = toTarget(t, "h2c", target)
+			spec.GRPC.Enabled = true
+			spec.GRPC.Transcoding.Enabled = true
+			spec.GRPC.Transcoding.DescriptorSet = descriptors
+			fn(spec)
+		})[0]
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rest/v1/hello/Tyk", Code: http.StatusOK, BodyMatch: `^{"message":"Hello Tyk"}$`,
+				HeadersMatch: map[string]string{"Content-Type": "application/json"}},
+			{Method: http.MethodPost, Path: "/rest/v1/hello", Data: `{"name":"Tyk"}`, Code: http.StatusOK,
+				BodyMatch: `^{"message":"Hello Tyk"}$`},
+			{Method: http.MethodPost, Path: "/rest/routeguide.RouteGuide/GetFeature", Data: `{"latitude":1,"longitude":2}`,
+				Code: http.StatusOK, BodyMatch: `^{"name":"1,2"}$`},
+			{Method: http.MethodPost, Path: "/rest/routeguide.RouteGuide/GetFeature", Data: `{"latitude":-1}`,
+				Code: http.StatusNotFound, BodyMatch: `^{"code":5,"message":"no feature"}$`},
+			{Method: http.MethodPost, Path: "/rest/v1/hello", Data: `{"unknown":true}`, Code: http.StatusBadRequest},
+			{Method: http.MethodDelete, Path: "/rest/v1/hello/Tyk", Code: http.StatusNotFound, BodyMatch: errGRPCNoRoute.Error()},
+		}...)
+	})
+
+	t.Run("server streaming", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rest/v1/features/1/2?hi.latitude=3&hi.longitude=4", Code: http.StatusOK,
+				BodyMatch:    `^data: {"name":"1,2"}\n\ndata: {"name":"3,4"}\n\n$`,
+				HeadersMatch: map[string]string{"Content-Type": textEventStream}},
+			{Path: "/rest/v1/features/1/2?hi.latitude=-1", Code: http.StatusOK,
+				BodyMatch: `^data: {"name":"1,2"}\n\nevent: error\ndata: {"code":5,"message":"no feature"}\n\n$`},
+			{Path: "/rest/v1/features/-1/2", Code: http.StatusNotFound, BodyMatch: `^{"code":5,"message":"no feature"}$`},
+		}...)
+	})
+
+	t.Run("allowed methods", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.GRPC.AllowedMethods = []string{"/helloworld.Greeter/*"}
+		})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/rest/v1/hello/Tyk", Code: http.StatusOK},
+			{Path: "/rest/v1/features/1/2", Code: http.StatusForbidden, BodyMatch: "gRPC method isn"},
+		}...)
+	})
+
+	t.Run("OAS export", func(t *testing.T) {
+		spec := loadAPI(func(spec *APISpec) {
+			spec.Name = "transcoded"
+		})
+
+		obj, code := ts.Gw.handleGetAPIOAS(spec.APIID, true)
+		require.Equal(t, http.StatusOK, code)
+		doc, ok := obj.(*oas.OAS)
+		require.True(t, ok)
+
+		assert.Equal(t, "transcoded", doc.Info.Title)
+
+		hello := doc.Paths["/v1/hello/{name}"]
+		require.NotNil(t, hello)
+		require.NotNil(t, hello.Get)
+		assert.Equal(t, "Greeter_SayHello", hello.Get.OperationID)
+		assert.Equal(t, "name", hello.Get.Parameters[0].Value.Name)
+		assert.Equal(t, "#/components/schemas/helloworld.HelloReply",
+			hello.Get.Responses.Get(http.StatusOK).Value.Content.Get("application/json").Schema.Ref)
+
+		require.NotNil(t, doc.Paths["/v1/hello"])
+		assert.Equal(t, "Greeter_SayHello1", doc.Paths["/v1/hello"].Post.OperationID)
+
+		features := doc.Paths["/v1/features/{lo.latitude}/{lo.longitude}"]
+		require.NotNil(t, features)
+		require.NotNil(t, features.Get)
+		assert.NotNil(t, features.Get.Responses.Get(http.StatusOK).Value.Content.Get(textEventStream))
+
+		require.NotNil(t, doc.Paths["/routeguide.RouteGuide/GetFeature"])
+		assert.Nil(t, doc.Paths["/routeguide.RouteGuide/RecordRoute"], "client streaming methods aren't transcoded")
+		assert.Contains(t, doc.Components.Schemas, "routeguide.Feature")
+	})
+}
@@ -0,0 +1,174 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// grpcWebTrailerFlag marks the frame carrying the trailers at the end of gRPC-Web response bodies.
+	grpcWebTrailerFlag = 0x80
+)
+
+var (
+	// grpcWebAllowedHeaders are the request headers of gRPC-Web clients, allowed by CORS.
+	grpcWebAllowedHeaders = []string{header.ContentType, "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"}
+	// grpcWebExposedHeaders are the response headers gRPC-Web clients read the status of calls from.
+	grpcWebExposedHeaders = []string{grpcStatusHeader, grpcMessageHeader, "Grpc-Status-Details-Bin"}
+	// corsDefaultAllowedHeaders are the headers allowed by CORS when an API doesn't list any.
+	corsDefaultAllowedHeaders = []string{"Origin", "Accept", header.ContentType, "X-Requested-With"}
+)
+
+// grpcWebCall is a gRPC-Web request bridged to a native gRPC call.
+type grpcWebCall struct {
+	contentType string
+	text        bool
+	// status is the grpc-status of the upstream response, recorded in analytics.
+	status string
+}
+
+// GRPCWebMiddleware bridges gRPC-Web requests to native gRPC, decoding base64 bodies of grpc-web-text requests.
+type GRPCWebMiddleware struct {
+	*BaseMiddleware
+}
+
+func (m *GRPCWebMiddleware) Name() string {
+	return "GRPCWebMiddleware"
+}
+
+func (m *GRPCWebMiddleware) EnabledForSpec() bool {
+	return m.Spec.GRPC.Enabled && m.Spec.GRPC.Web.Enabled
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *GRPCWebMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	contentType := r.Header.Get(header.ContentType)
+	if !isGRPCWebContentType(contentType) {
+		return nil, http.StatusOK
+	}
+
+	call := &grpcWebCall{
+		contentType: contentType,
+		text:        strings.HasPrefix(contentType, grpcWebTextContentType),
+	}
+
+	subtype := strings.TrimPrefix(contentType, grpcWebContentType)
+	if call.text {
+		subtype = strings.TrimPrefix(contentType, grpcWebTextContentType)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err, http.StatusBadRequest
+		}
+		r.Body.Close()
+
+		if body, err = decodeGRPCWebText(body); err != nil {
+			m.Logger().WithError(err).Debug("Couldn't decode gRPC-Web text request")
+			return err, http.StatusBadRequest
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.Header.Set(header.ContentLength, strconv.Itoa(len(body)))
+	}
+
+	r.Header.Set(header.ContentType, grpcContentType+subtype)
+	r.Header.Set("Te", "trailers")
+	r.Header.Del("X-Grpc-Web")
+
+	ctxSetGRPCWebCall(r, call)
+	return nil, http.StatusOK
+}
+
+func isGRPCWebContentType(contentType string) bool {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	for _, t := range []string{grpcWebContentType, grpcWebTextContentType} {
+		if contentType == t || strings.HasPrefix(contentType, t+"+") {
+			return true
+		}
+	}
+	return false
+}
+
+// isGRPCWebRequest checks if the request is a gRPC-Web request, before or after it's bridged.
+func isGRPCWebRequest(r *http.Request) bool {
+	return ctxGetGRPCWebCall(r) != nil || isGRPCWebContentType(r.Header.Get(header.ContentType))
+}
+
+// decodeGRPCWebText decodes a base64 request body. Clients may send the body in separately encoded,
+// padded, chunks.
+func decodeGRPCWebText(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+
+	var out []byte
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '=')
+		if end < 0 {
+			end = len(data)
+		}
+		for end < len(data) && data[end] == '=' {
+			end++
+		}
+
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(end))
+		n, err := base64.StdEncoding.Decode(decoded, data[:end])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, decoded[:n]...)
+		data = data[end:]
+	}
+	return out, nil
+}
+
+// writeGRPCWebError writes a gateway error as a trailers-only gRPC-Web response, with the status in headers.
+// The response is filled for analytics.
+func writeGRPCWebError(w http.ResponseWriter, response *http.Response, contentType, errMsg string, errCode int) {
+	status := strconv.Itoa(int(grpcCodeFromHTTP(errCode)))
+	message := encodeGRPCMessage(errMsg)
+
+	w.Header().Set(header.ContentType, contentType)
+	w.Header().Set(grpcStatusHeader, status)
+	w.Header().Set(grpcMessageHeader, message)
+	w.WriteHeader(http.StatusOK)
+
+	response.StatusCode = http.StatusOK
+	response.Header = http.Header{}
+	response.Header.Set(header.ContentType, contentType)
+	response.Header.Set(grpcStatusHeader, status)
+	response.Header.Set(grpcMessageHeader, message)
+}
+
+// grpcWebCORSHeaders adds the headers used by gRPC-Web clients to the allowed and exposed CORS headers.
+func grpcWebCORSHeaders(allowed, exposed []string) ([]string, []string) {
+	if len(allowed) == 0 {
+		allowed = corsDefaultAllowedHeaders
+	}
+	return appendMissingHeaders(allowed, grpcWebAllowedHeaders), appendMissingHeaders(exposed, grpcWebExposedHeaders)
+}
+
+func appendMissingHeaders(headers, extra []string) []string {
+	result := append([]string(nil), headers...)
+	for _, h := range extra {
+		found := false
+		for _, existing := range headers {
+			if existing == "*" || strings.EqualFold(existing, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, h)
+		}
+	}
+	return result
+}
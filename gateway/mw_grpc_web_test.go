@@ -0,0 +1,188 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbexample "google.golang.org/grpc/examples/helloworld/helloworld"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestDecodeGRPCWebText(t *testing.T) {
+	// chunks encoded separately keep their padding
+	body := base64.StdEncoding.EncodeToString([]byte("ab")) + base64.StdEncoding.EncodeToString([]byte("cde"))
+
+	decoded, err := decodeGRPCWebText([]byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, "abcde", string(decoded))
+
+	_, err = decodeGRPCWebText([]byte("not base64!"))
+	assert.Error(t, err)
+}
+
+func TestGRPCWebTextEncoder(t *testing.T) {
+	var (
+		encoder grpcWebTextEncoder
+		out     []byte
+	)
+	for _, chunk := range []string{"a", "bcde", "fg"} {
+		out = append(out, encoder.encode([]byte(chunk), false)...)
+	}
+	out = append(out, encoder.encode(nil, true)...)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("abcdefg")), string(out))
+}
+
+func TestGRPCWebCORSHeaders(t *testing.T) {
+	allowed, exposed := grpcWebCORSHeaders(nil, []string{"grpc-status"})
+	assert.Equal(t, []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"}, allowed)
+	assert.Equal(t, []string{"grpc-status", "Grpc-Message", "Grpc-Status-Details-Bin"}, exposed)
+
+	allowed, _ = grpcWebCORSHeaders([]string{"*"}, nil)
+	assert.Equal(t, []string{"*"}, allowed)
+}
+
+// readGRPCWebResponse splits a gRPC-Web response body into its messages and trailers.
+func readGRPCWebResponse(t *testing.T, body []byte) (messages [][]byte, trailers string) {
+	t.Helper()
+
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), grpcFrameHeaderSize)
+		flag := body[0]
+		body[0] = 0
+
+		message, rest, ok, err := readGRPCFrame(body)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		if flag == grpcWebTrailerFlag {
+			trailers = string(message)
+		} else {
+			messages = append(messages, message)
+		}
+		body = rest
+	}
+	return messages, trailers
+}
+
+func TestGRPC_Web(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	target, s := startGRPCServerH2C(t, setupHelloSVC)
+	defer target.Close()
+	defer s.Stop()
+
+	loadAPI := func(fn func(spec *APISpec)) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.UseKeylessAccess = true
+			spec.Proxy.TargetURL = toTarget(t, "h2c", target)
+			spec.GRPC.Enabled = true
+			spec.GRPC.Web.Enabled = true
+			spec.CORS.Enable = true
+			spec.CORS.AllowedOrigins = []string{"https://app.example.com"}
+			fn(spec)
+		})
+	}
+
+	request, err := proto.Marshal(&pbexample.HelloRequest{Name: "Tyk"})
+	require.NoError(t, err)
+	frame := frameGRPCMessage(request)
+
+	checkReply := func(t *testing.T, body []byte) {
+		t.Helper()
+
+		messages, trailers := readGRPCWebResponse(t, body)
+		require.Len(t, messages, 1)
+
+		var reply pbexample.HelloReply
+		require.NoError(t, proto.Unmarshal(messages[0], &reply))
+		assert.Equal(t, "Hello Tyk", reply.Message)
+		assert.Contains(t, trailers, "grpc-status: 0\r\n")
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {})
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodPost,
+			Path:   "/helloworld.Greeter/SayHello",
+			Data:   frame,
+			Headers: map[string]string{
+				"Content-Type": "application/grpc-web+proto",
+				"X-Grpc-Web":   "1",
+				"Origin":       "https://app.example.com",
+			},
+			Code: http.StatusOK,
+			HeadersMatch: map[string]string{
+				"Content-Type":                  "application/grpc-web+proto",
+				"Access-Control-Expose-Headers": "Grpc-Status, Grpc-Message, Grpc-Status-Details-Bin",
+			},
+			BodyMatchFunc: func(body []byte) bool {
+				checkReply(t, body)
+				return true
+			},
+		})
+	})
+
+	t.Run("text", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {})
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method:       http.MethodPost,
+			Path:         "/helloworld.Greeter/SayHello",
+			Data:         base64.StdEncoding.EncodeToString(frame),
+			Headers:      map[string]string{"Content-Type": "application/grpc-web-text"},
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{"Content-Type": "application/grpc-web-text"},
+			BodyMatchFunc: func(body []byte) bool {
+				decoded, err := base64.StdEncoding.DecodeString(string(body))
+				require.NoError(t, err)
+				checkReply(t, decoded)
+				return true
+			},
+		})
+	})
+
+	t.Run("gateway error", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {
+			spec.GRPC.AllowedMethods = []string{"/helloworld.Greeter/SayBye"}
+		})
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method:  http.MethodPost,
+			Path:    "/helloworld.Greeter/SayHello",
+			Data:    frame,
+			Headers: map[string]string{"Content-Type": "application/grpc-web+proto"},
+			Code:    http.StatusOK,
+			HeadersMatch: map[string]string{
+				"Content-Type":   "application/grpc-web+proto",
+				grpcStatusHeader: "7",
+			},
+		})
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		loadAPI(func(spec *APISpec) {})
+
+		res, err := ts.Run(t, test.TestCase{
+			Method: http.MethodOptions,
+			Path:   "/helloworld.Greeter/SayHello",
+			Headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  http.MethodPost,
+				"Access-Control-Request-Headers": "content-type,x-grpc-web,x-user-agent",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, strings.ToLower(res.Header.Get("Access-Control-Allow-Headers")), "x-grpc-web")
+	})
+}
@@ -181,48 +181,20 @@ func (d *DynamicMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reques
 
 	// Run the middleware
 	middlewareClassname := d.MiddlewareClassName
-	if d.Spec.JSVM.VM == nil {
+	if !d.Spec.JSVM.Ready() {
 		logger.WithError(err).Error("JSVM isn't enabled, check your gateway settings")
 		return errors.New("Middleware error"), 500
 	}
-	vm := d.Spec.JSVM.VM.Copy()
-	vm.Interrupt = make(chan func(), 1)
 	logger.Debug("Running: ", middlewareClassname)
-	// buffered, leaving no chance of a goroutine leak since the
-	// spawned goroutine will send 0 or 1 values.
-	ret := make(chan otto.Value, 1)
-	errRet := make(chan error, 1)
-	go func() {
-		defer func() {
-			// the VM executes the panic func that gets it
-			// to stop, so we must recover here to not crash
-			// the whole Go program.
-			recover()
-		}()
-		returnRaw, err := vm.Run(middlewareClassname + `.DoProcessRequest(` + string(requestAsJson) + `, ` + string(sessionAsJson) + `, ` + specAsJson + `);`)
-		ret <- returnRaw
-		errRet <- err
-	}()
-	var returnRaw otto.Value
-	t := time.NewTimer(d.Spec.JSVM.Timeout)
-	select {
-	case returnRaw = <-ret:
-		if err := <-errRet; err != nil {
-			logger.WithError(err).Error("Failed to run JS middleware")
-			return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
-		}
-		t.Stop()
-	case <-t.C:
-		t.Stop()
+	returnDataStr, err := d.Spec.JSVM.Call(middlewareClassname + `.DoProcessRequest(` + string(requestAsJson) + `, ` + string(sessionAsJson) + `, ` + specAsJson + `);`)
+	if errors.Is(err, errJSVMTimeout) {
 		logger.Error("JS middleware timed out after ", d.Spec.JSVM.Timeout)
-		vm.Interrupt <- func() {
-			// only way to stop the VM is to send it a func
-			// that panics.
-			panic("stop")
-		}
 		return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
 	}
-	returnDataStr, _ := returnRaw.ToString()
+	if err != nil {
+		logger.WithError(err).Error("Failed to run JS middleware")
+		return errors.New(http.StatusText(http.StatusInternalServerError)), http.StatusInternalServerError
+	}
 
 	// Decode the return object
 	newRequestData := VMReturnObject{}
@@ -328,45 +300,63 @@ func mapStrsToIfaces(m map[string]string) map[string]interface{} {
 type JSVM struct {
 	Spec    *APISpec
 	VM      *otto.Otto `json:"-"`
+	Engine  apidef.JSVMEngine
 	Timeout time.Duration
 	Log     *logrus.Entry  `json:"-"` // logger used by the JS code
 	RawLog  *logrus.Logger `json:"-"` // logger used by `rawlog` func to avoid formatting
 	Gw      *Gateway       `json:"-"`
+
+	goja *gojaVM
 }
 
 const defaultJSVMTimeout = 5
 
+var errJSVMTimeout = errors.New("JSVM execution timed out")
+
 // Init creates the JSVM with the core library and sets up a default
-// timeout.
+// timeout. The engine is taken from the API, falling back to the gateway configuration.
 func (j *JSVM) Init(spec *APISpec, logger *logrus.Entry, gw *Gateway) {
-	vm := otto.New()
 	j.Gw = gw
 	logger = logger.WithField("prefix", "jsvm")
 
-	// Init TykJS namespace, constructors etc.
-	if _, err := vm.Run(coreJS); err != nil {
-		logger.WithError(err).Error("Could not load TykJS")
-		return
+	j.Engine = gw.GetConfig().JSVMEngine
+	if spec != nil && spec.JSVMEngine != "" {
+		j.Engine = spec.JSVMEngine
 	}
 
-	// Load user's TykJS on top, if any
-	if path := gw.GetConfig().TykJSPath; path != "" {
-		f, err := os.Open(path)
-		if err == nil {
-			_, err = vm.Run(f)
-			f.Close()
+	if j.Engine == apidef.GojaJSVMEngine {
+		if !j.initGoja(logger) {
+			return
+		}
+		j.Spec = spec
+	} else {
+		vm := otto.New()
+
+		// Init TykJS namespace, constructors etc.
+		if _, err := vm.Run(coreJS); err != nil {
+			logger.WithError(err).Error("Could not load TykJS")
+			return
+		}
+
+		// Load user's TykJS on top, if any
+		if path := gw.GetConfig().TykJSPath; path != "" {
+			f, err := os.Open(path)
+			if err == nil {
+				_, err = vm.Run(f)
+				f.Close()
 
-			if err != nil {
-				logger.WithError(err).Error("Could not load user's TykJS")
+				if err != nil {
+					logger.WithError(err).Error("Could not load user's TykJS")
+				}
 			}
 		}
-	}
 
-	j.VM = vm
-	j.Spec = spec
+		j.VM = vm
+		j.Spec = spec
 
-	// Add environment API
-	j.LoadTykJSApi()
+		// Add environment API
+		j.LoadTykJSApi()
+	}
 
 	if jsvmTimeout := gw.GetConfig().JSVMTimeout; jsvmTimeout <= 0 {
 		j.Timeout = time.Duration(defaultJSVMTimeout) * time.Second
@@ -380,6 +370,79 @@ func (j *JSVM) Init(spec *APISpec, logger *logrus.Entry, gw *Gateway) {
 	j.RawLog = rawLog
 }
 
+// Ready returns true if the JSVM was initialised.
+func (j *JSVM) Ready() bool {
+	return j.VM != nil || j.goja != nil
+}
+
+// Load runs src in the VM, making the middleware or functions it declares available to calls.
+func (j *JSVM) Load(name string, src string) error {
+	if j.goja != nil {
+		return j.goja.load(name, src)
+	}
+
+	_, err := j.VM.Run(src)
+	return err
+}
+
+// Call evaluates expr and returns its result as a string. otto evaluates it in an isolated copy
+// of the VM. goja evaluates it in a pooled runtime and resets its global variables afterwards,
+// while changes made to the objects they reference persist.
+// The evaluation is interrupted and errJSVMTimeout returned once the timeout elapses.
+func (j *JSVM) Call(expr string) (string, error) {
+	if j.goja != nil {
+		return j.callGoja(expr)
+	}
+
+	vm := j.VM.Copy()
+	vm.Interrupt = make(chan func(), 1)
+	// buffered, leaving no chance of a goroutine leak since the
+	// spawned goroutine will send 0 or 1 values.
+	ret := make(chan otto.Value, 1)
+	errRet := make(chan error, 1)
+	go func() {
+		defer func() {
+			// the VM executes the panic func that gets it
+			// to stop, so we must recover here to not crash
+			// the whole Go program.
+			recover()
+		}()
+		returnRaw, err := vm.Run(expr)
+		ret <- returnRaw
+		errRet <- err
+	}()
+	var returnRaw otto.Value
+	t := time.NewTimer(j.Timeout)
+	select {
+	case returnRaw = <-ret:
+		t.Stop()
+		if err := <-errRet; err != nil {
+			return "", err
+		}
+	case <-t.C:
+		t.Stop()
+		vm.Interrupt <- func() {
+			// only way to stop the VM is to send it a func
+			// that panics.
+			panic("stop")
+		}
+		return "", errJSVMTimeout
+	}
+
+	return returnRaw.ToString()
+}
+
+// Exec runs expr for its side effects. otto runs it in the shared VM, goja in a pooled runtime.
+func (j *JSVM) Exec(expr string) error {
+	if j.goja != nil {
+		_, err := j.callGoja(expr)
+		return err
+	}
+
+	_, err := j.VM.Run(expr)
+	return err
+}
+
 func (j *JSVM) DeInit() {
 	j.Spec = nil
 	j.Log = nil
@@ -399,15 +462,14 @@ func (j *JSVM) LoadJSPaths(paths []string, prefix string) {
 			continue
 		}
 		j.Log.Info("Loading JS File: ", mwPath)
-		f, err := os.Open(mwPath)
+		src, err := os.ReadFile(mwPath)
 		if err != nil {
 			j.Log.WithError(err).Error("Failed to open JS middleware file")
 			continue
 		}
-		if _, err := j.VM.Run(f); err != nil {
+		if err := j.Load(mwPath, string(src)); err != nil {
 			j.Log.WithError(err).Error("Failed to load JS middleware")
 		}
-		f.Close()
 	}
 }
 
@@ -434,225 +496,235 @@ type TykJSHttpResponse struct {
 func (j *JSVM) LoadTykJSApi() {
 	// Enable a log
 	j.VM.Set("log", func(call otto.FunctionCall) otto.Value {
-		j.Log.WithFields(logrus.Fields{
-			"type": "log-msg",
-		}).Info(call.Argument(0).String())
+		j.log(call.Argument(0).String())
 		return otto.Value{}
 	})
 	j.VM.Set("rawlog", func(call otto.FunctionCall) otto.Value {
-		j.RawLog.Print(call.Argument(0).String() + "\n")
+		j.rawlog(call.Argument(0).String())
 		return otto.Value{}
 	})
 
 	// these two needed for non-utf8 bodies
 	j.VM.Set("b64dec", func(call otto.FunctionCall) otto.Value {
-		in := call.Argument(0).String()
-		out, err := base64.StdEncoding.DecodeString(in)
-
-		// Fallback to RawStdEncoding:
-		if err != nil {
-			out, err = base64.RawStdEncoding.DecodeString(in)
-			if err != nil {
-				j.Log.WithError(err).Error("Failed to base64 decode")
-				return otto.Value{}
-			}
-		}
-		returnVal, err := j.VM.ToValue(string(out))
+		out, err := j.b64dec(call.Argument(0).String())
 		if err != nil {
-			j.Log.WithError(err).Error("Failed to base64 decode")
 			return otto.Value{}
 		}
-		return returnVal
+		return j.ottoValue(out, "Failed to base64 decode")
 	})
 	j.VM.Set("b64enc", func(call otto.FunctionCall) otto.Value {
-		in := []byte(call.Argument(0).String())
-		out := base64.StdEncoding.EncodeToString(in)
-		returnVal, err := j.VM.ToValue(out)
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to base64 encode")
-			return otto.Value{}
-		}
-		return returnVal
+		out := base64.StdEncoding.EncodeToString([]byte(call.Argument(0).String()))
+		return j.ottoValue(out, "Failed to base64 encode")
 	})
 
 	j.VM.Set("rawb64dec", func(call otto.FunctionCall) otto.Value {
-		in := call.Argument(0).String()
-		out, err := base64.RawStdEncoding.DecodeString(in)
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to base64 decode")
-			return otto.Value{}
-		}
-		returnVal, err := j.VM.ToValue(string(out))
+		out, err := j.rawb64dec(call.Argument(0).String())
 		if err != nil {
-			j.Log.WithError(err).Error("Failed to base64 decode")
 			return otto.Value{}
 		}
-		return returnVal
+		return j.ottoValue(out, "Failed to base64 decode")
 	})
 	j.VM.Set("rawb64enc", func(call otto.FunctionCall) otto.Value {
-		in := []byte(call.Argument(0).String())
-		out := base64.RawStdEncoding.EncodeToString(in)
-		returnVal, err := j.VM.ToValue(out)
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to base64 encode")
-			return otto.Value{}
-		}
-		return returnVal
+		out := base64.RawStdEncoding.EncodeToString([]byte(call.Argument(0).String()))
+		return j.ottoValue(out, "Failed to base64 encode")
 	})
-	ignoreCanonical := j.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey
+
 	// Enable the creation of HTTP Requsts
 	j.VM.Set("TykMakeHttpRequest", func(call otto.FunctionCall) otto.Value {
-		jsonHRO := call.Argument(0).String()
-		if jsonHRO == "undefined" {
-			// Nope, return nothing
+		out, ok := j.makeHTTPRequest(call.Argument(0).String())
+		if !ok {
 			return otto.Value{}
 		}
-		hro := TykJSHttpRequest{}
-		if err := json.Unmarshal([]byte(jsonHRO), &hro); err != nil {
-			j.Log.WithError(err).Error("JSVM: Failed to deserialise HTTP Request object")
+		return j.ottoValue(out, "Failed to encode return value")
+	})
+
+	// Expose Setters and Getters in the REST API for a key:
+	j.VM.Set("TykGetKeyData", func(call otto.FunctionCall) otto.Value {
+		out := j.getKeyData(call.Argument(0).String(), call.Argument(1).String())
+		return j.ottoValue(out, "Failed to encode return value")
+	})
+
+	j.VM.Set("TykSetKeyData", func(call otto.FunctionCall) otto.Value {
+		j.setKeyData(call.Argument(0).String(), call.Argument(1).String(), call.Argument(2).String())
+		return otto.Value{}
+	})
+
+	// Batch request method
+	j.VM.Set("TykBatchRequest", func(call otto.FunctionCall) otto.Value {
+		out, ok := j.batchRequest(call.Argument(0).String())
+		if !ok {
 			return otto.Value{}
 		}
+		return j.ottoValue(out, "Failed to encode return value")
+	})
 
-		// Make the request
-		domain := hro.Domain
-		data := url.Values{}
-		for k, v := range hro.FormData {
-			data.Set(k, v)
-		}
+	j.VM.Run(tykJSApi)
+}
 
-		u, _ := url.ParseRequestURI(domain + hro.Resource)
-		urlStr := u.String() // "https://api.com/user/"
+func (j *JSVM) ottoValue(value string, errMessage string) otto.Value {
+	returnVal, err := j.VM.ToValue(value)
+	if err != nil {
+		j.Log.WithError(err).Error(errMessage)
+		return otto.Value{}
+	}
+	return returnVal
+}
 
-		var d string
-		if hro.Body != "" {
-			d = hro.Body
-		} else if len(hro.FormData) > 0 {
-			d = data.Encode()
-		}
+// The functions below implement the Tyk JS API independently of the engine running it.
 
-		r, _ := http.NewRequest(hro.Method, urlStr, nil)
+func (j *JSVM) log(message string) {
+	j.Log.WithFields(logrus.Fields{
+		"type": "log-msg",
+	}).Info(message)
+}
 
-		if d != "" {
-			r, _ = http.NewRequest(hro.Method, urlStr, strings.NewReader(d))
-		}
+func (j *JSVM) rawlog(message string) {
+	j.RawLog.Print(message + "\n")
+}
 
-		for k, v := range hro.Headers {
-			setCustomHeader(r.Header, k, v, ignoreCanonical)
-		}
-		r.Close = true
+func (j *JSVM) b64dec(in string) (string, error) {
+	out, err := base64.StdEncoding.DecodeString(in)
 
-		maxSSLVersion := j.Gw.GetConfig().ProxySSLMaxVersion
-		if j.Spec.Proxy.Transport.SSLMaxVersion > 0 {
-			maxSSLVersion = j.Spec.Proxy.Transport.SSLMaxVersion
+	// Fallback to RawStdEncoding:
+	if err != nil {
+		out, err = base64.RawStdEncoding.DecodeString(in)
+		if err != nil {
+			j.Log.WithError(err).Error("Failed to base64 decode")
+			return "", err
 		}
+	}
+	return string(out), nil
+}
 
-		tr := &http.Transport{TLSClientConfig: &tls.Config{
-			MaxVersion: maxSSLVersion,
-		}}
+func (j *JSVM) rawb64dec(in string) (string, error) {
+	out, err := base64.RawStdEncoding.DecodeString(in)
+	if err != nil {
+		j.Log.WithError(err).Error("Failed to base64 decode")
+		return "", err
+	}
+	return string(out), nil
+}
 
-		if cert := j.Gw.getUpstreamCertificate(r.Host, j.Spec); cert != nil {
-			tr.TLSClientConfig.Certificates = []tls.Certificate{*cert}
-		}
+func (j *JSVM) makeHTTPRequest(jsonHRO string) (string, bool) {
+	if jsonHRO == "undefined" {
+		// Nope, return nothing
+		return "", false
+	}
+	hro := TykJSHttpRequest{}
+	if err := json.Unmarshal([]byte(jsonHRO), &hro); err != nil {
+		j.Log.WithError(err).Error("JSVM: Failed to deserialise HTTP Request object")
+		return "", false
+	}
 
-		if j.Gw.GetConfig().ProxySSLInsecureSkipVerify {
-			tr.TLSClientConfig.InsecureSkipVerify = true
-		}
+	// Make the request
+	domain := hro.Domain
+	data := url.Values{}
+	for k, v := range hro.FormData {
+		data.Set(k, v)
+	}
 
-		if j.Spec.Proxy.Transport.SSLInsecureSkipVerify {
-			tr.TLSClientConfig.InsecureSkipVerify = true
-		}
+	u, _ := url.ParseRequestURI(domain + hro.Resource)
+	urlStr := u.String() // "https://api.com/user/"
 
-		tr.DialTLS = j.Gw.customDialTLSCheck(j.Spec, tr.TLSClientConfig)
+	var d string
+	if hro.Body != "" {
+		d = hro.Body
+	} else if len(hro.FormData) > 0 {
+		d = data.Encode()
+	}
 
-		tr.Proxy = proxyFromAPI(j.Spec)
+	r, _ := http.NewRequest(hro.Method, urlStr, nil)
 
-		// using new Client each time should be ok, since we closing connection every time
-		client := &http.Client{Transport: tr}
-		resp, err := client.Do(r)
-		if err != nil {
-			j.Log.WithError(err).Error("Request failed")
-			return otto.Value{}
-		}
+	if d != "" {
+		r, _ = http.NewRequest(hro.Method, urlStr, strings.NewReader(d))
+	}
 
-		body, _ := ioutil.ReadAll(resp.Body)
-		bodyStr := string(body)
-		tykResp := TykJSHttpResponse{
-			Code:        resp.StatusCode,
-			Body:        bodyStr,
-			Headers:     resp.Header,
-			CodeComp:    resp.StatusCode,
-			BodyComp:    bodyStr,
-			HeadersComp: resp.Header,
-		}
+	ignoreCanonical := j.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey
+	for k, v := range hro.Headers {
+		setCustomHeader(r.Header, k, v, ignoreCanonical)
+	}
+	r.Close = true
 
-		retAsStr, _ := json.Marshal(tykResp)
-		returnVal, err := j.VM.ToValue(string(retAsStr))
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to encode return value")
-			return otto.Value{}
-		}
+	maxSSLVersion := j.Gw.GetConfig().ProxySSLMaxVersion
+	if j.Spec.Proxy.Transport.SSLMaxVersion > 0 {
+		maxSSLVersion = j.Spec.Proxy.Transport.SSLMaxVersion
+	}
 
-		return returnVal
-	})
+	tr := &http.Transport{TLSClientConfig: &tls.Config{
+		MaxVersion: maxSSLVersion,
+	}}
 
-	// Expose Setters and Getters in the REST API for a key:
-	j.VM.Set("TykGetKeyData", func(call otto.FunctionCall) otto.Value {
-		apiKey := call.Argument(0).String()
-		apiId := call.Argument(1).String()
+	if cert := j.Gw.getUpstreamCertificate(r.Host, j.Spec); cert != nil {
+		tr.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+	}
 
-		obj, _ := j.Gw.handleGetDetail(apiKey, apiId, "", false)
-		bs, _ := json.Marshal(obj)
+	if j.Gw.GetConfig().ProxySSLInsecureSkipVerify {
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
 
-		returnVal, err := j.VM.ToValue(string(bs))
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to encode return value")
-			return otto.Value{}
-		}
+	if j.Spec.Proxy.Transport.SSLInsecureSkipVerify {
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
 
-		return returnVal
-	})
+	tr.DialTLS = j.Gw.customDialTLSCheck(j.Spec, tr.TLSClientConfig)
 
-	j.VM.Set("TykSetKeyData", func(call otto.FunctionCall) otto.Value {
-		apiKey := call.Argument(0).String()
-		encoddedSession := call.Argument(1).String()
-		suppressReset := call.Argument(2).String()
+	tr.Proxy = proxyFromAPI(j.Spec)
 
-		newSession := user.SessionState{}
-		err := json.Unmarshal([]byte(encoddedSession), &newSession)
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to decode the sesison data")
-			return otto.Value{}
-		}
+	// using new Client each time should be ok, since we closing connection every time
+	client := &http.Client{Transport: tr}
+	resp, err := client.Do(r)
+	if err != nil {
+		j.Log.WithError(err).Error("Request failed")
+		return "", false
+	}
 
-		j.Gw.doAddOrUpdate(apiKey, &newSession, suppressReset == "1", false)
-		return otto.Value{}
-	})
+	body, _ := ioutil.ReadAll(resp.Body)
+	bodyStr := string(body)
+	tykResp := TykJSHttpResponse{
+		Code:        resp.StatusCode,
+		Body:        bodyStr,
+		Headers:     resp.Header,
+		CodeComp:    resp.StatusCode,
+		BodyComp:    bodyStr,
+		HeadersComp: resp.Header,
+	}
 
-	// Batch request method
-	unsafeBatchHandler := BatchRequestHandler{Gw: j.Gw}
-	j.VM.Set("TykBatchRequest", func(call otto.FunctionCall) otto.Value {
-		requestSet := call.Argument(0).String()
-		j.Log.Debug("Batch input is: ", requestSet)
-		bs, err := unsafeBatchHandler.ManualBatchRequest([]byte(requestSet))
-		if err != nil {
-			j.Log.WithError(err).Error("Batch request error")
-			return otto.Value{}
-		}
+	retAsStr, _ := json.Marshal(tykResp)
+	return string(retAsStr), true
+}
 
-		returnVal, err := j.VM.ToValue(string(bs))
-		if err != nil {
-			j.Log.WithError(err).Error("Failed to encode return value")
-			return otto.Value{}
-		}
+func (j *JSVM) getKeyData(apiKey, apiID string) string {
+	obj, _ := j.Gw.handleGetDetail(apiKey, apiID, "", false)
+	bs, _ := json.Marshal(obj)
+	return string(bs)
+}
 
-		return returnVal
-	})
+func (j *JSVM) setKeyData(apiKey, encodedSession, suppressReset string) {
+	newSession := user.SessionState{}
+	err := json.Unmarshal([]byte(encodedSession), &newSession)
+	if err != nil {
+		j.Log.WithError(err).Error("Failed to decode the sesison data")
+		return
+	}
 
-	j.VM.Run(`function TykJsResponse(response, session_meta) {
-		return JSON.stringify({Response: response, SessionMeta: session_meta})
-	}`)
+	j.Gw.doAddOrUpdate(apiKey, &newSession, suppressReset == "1", false)
 }
 
+func (j *JSVM) batchRequest(requestSet string) (string, bool) {
+	j.Log.Debug("Batch input is: ", requestSet)
+	unsafeBatchHandler := BatchRequestHandler{Gw: j.Gw}
+	bs, err := unsafeBatchHandler.ManualBatchRequest([]byte(requestSet))
+	if err != nil {
+		j.Log.WithError(err).Error("Batch request error")
+		return "", false
+	}
+	return string(bs), true
+}
+
+const tykJSApi = `function TykJsResponse(response, session_meta) {
+	return JSON.stringify({Response: response, SessionMeta: session_meta})
+}`
+
 const coreJS = `
 var TykJS = {
 	TykMiddleware: {
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -1049,3 +1050,162 @@ func testJSVM_Auth(t *testing.T, hashKeys bool) {
 		},
 	}...)
 }
+
+func TestJSVMGoja(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	newMiddleware := func(t *testing.T, timeout time.Duration, js string) *DynamicMiddleware {
+		t.Helper()
+
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{JSVMEngine: apidef.GojaJSVMEngine}}
+		jsvm := JSVM{}
+		jsvm.Init(spec, logrus.NewEntry(log), ts.Gw)
+		assert.Equal(t, apidef.GojaJSVMEngine, jsvm.Engine)
+		assert.Nil(t, jsvm.VM)
+		if timeout > 0 {
+			jsvm.Timeout = timeout
+		}
+
+		if err := jsvm.Load("mid.js", js); err != nil {
+			t.Fatalf("failed to set up js plugin: %v", err)
+		}
+		spec.JSVM = jsvm
+
+		return &DynamicMiddleware{
+			BaseMiddleware:      &BaseMiddleware{Spec: spec, Gw: ts.Gw},
+			MiddlewareClassName: "gojaMid",
+			Pre:                 true,
+		}
+	}
+
+	t.Run("ES2020", func(t *testing.T) {
+		dynMid := newMiddleware(t, 0, `
+const gojaMid = new TykJS.TykMiddleware.NewMiddleware({})
+
+class Greeter {
+	greet(name) {
+		return `+"`hello ${name}`"+`
+	}
+}
+
+gojaMid.NewProcessRequest((request, session) => {
+	let name = request.Headers?.Name?.[0] ?? "world"
+	request.Body = new Greeter().greet(name) + " " + b64dec(b64enc("foô"))
+	request.SetHeaders = {...request.SetHeaders, "X-Engine": "goja"}
+	return gojaMid.ReturnData(request, session.meta_data)
+});`)
+
+		req := httptest.NewRequest(http.MethodGet, "/foo", strings.NewReader("body"))
+		err, code := dynMid.ProcessRequest(nil, req, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, "hello world foô", string(body))
+		assert.Equal(t, "goja", req.Header.Get("X-Engine"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		dynMid := newMiddleware(t, 10*time.Millisecond, `
+const gojaMid = new TykJS.TykMiddleware.NewMiddleware({})
+
+gojaMid.NewProcessRequest((request, session) => {
+	for (;;) {}
+});`)
+
+		req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+		done := make(chan int)
+		go func() {
+			_, code := dynMid.ProcessRequest(nil, req, nil)
+			done <- code
+		}()
+		select {
+		case code := <-done:
+			assert.Equal(t, http.StatusInternalServerError, code)
+		case <-time.After(time.Second):
+			t.Fatal("js vm wasn't interrupted after its timeout")
+		}
+	})
+
+	t.Run("pooled runtimes", func(t *testing.T) {
+		dynMid := newMiddleware(t, 0, `
+const gojaMid = new TykJS.TykMiddleware.NewMiddleware({})
+
+gojaMid.NewProcessRequest((request, session) => {
+	request.SetHeaders = {"X-First": _.first(["underscore", "otto"])}
+	return gojaMid.ReturnData(request, session.meta_data)
+});`)
+		jsvm := &dynMid.Spec.JSVM
+
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			err, code := dynMid.ProcessRequest(nil, req, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "underscore", req.Header.Get("X-First"))
+		}
+
+		// code loaded later runs once in runtimes that were already initialised
+		assert.NoError(t, jsvm.Load("later.js", `const later = "loaded"`))
+		for i := 0; i < 3; i++ {
+			out, err := jsvm.Call(`later`)
+			assert.NoError(t, err)
+			assert.Equal(t, "loaded", out)
+		}
+
+		// a failed call doesn't affect the following ones
+		_, err := jsvm.Call(`undefinedFunc()`)
+		assert.Error(t, err)
+		out, err := jsvm.Call(`later`)
+		assert.NoError(t, err)
+		assert.Equal(t, "loaded", out)
+	})
+
+	t.Run("global state", func(t *testing.T) {
+		dynMid := newMiddleware(t, 0, `
+var count = 0
+function next() {
+	count++
+	leaked = count
+	return count
+}`)
+		jsvm := &dynMid.Spec.JSVM
+
+		for i := 0; i < 3; i++ {
+			out, err := jsvm.Call(`next()`)
+			assert.NoError(t, err)
+			assert.Equal(t, "1", out)
+		}
+
+		out, err := jsvm.Call(`typeof leaked`)
+		assert.NoError(t, err)
+		assert.Equal(t, "undefined", out)
+	})
+
+	t.Run("virtual endpoint", func(t *testing.T) {
+		const js = `
+const testVirtData = (request, session, config) => TykJsResponse({
+	Body: JSON.stringify({engine: config.config_data?.engine ?? "unknown"}),
+	Code: 200
+}, session.meta_data)`
+
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.JSVMEngine = apidef.GojaJSVMEngine
+			spec.ConfigData = map[string]interface{}{"engine": "goja"}
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.UseExtendedPaths = true
+				v.ExtendedPaths.Virtual = []apidef.VirtualMeta{{
+					ResponseFunctionName: "testVirtData",
+					FunctionSourceType:   apidef.UseBlob,
+					FunctionSourceURI:    base64.StdEncoding.EncodeToString([]byte(js)),
+					Path:                 "/virt",
+					Method:               http.MethodGet,
+				}}
+			})
+		})
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/virt", Code: http.StatusOK, BodyMatch: `{"engine":"goja"}`})
+	})
+}
@@ -813,6 +813,9 @@ func (gw *Gateway) generateSessionFromPolicy(policyID, orgID string, enforceOrg
 	session.ThrottleInterval = policy.ThrottleInterval
 	session.ThrottleRetryLimit = policy.ThrottleRetryLimit
 	session.MaxQueryDepth = policy.MaxQueryDepth
+	session.MaxQueryCost = policy.MaxQueryCost
+	session.MaxSubscriptions = policy.MaxSubscriptions
+	session.MaxSubscriptionLifetime = policy.MaxSubscriptionLifetime
 	session.QuotaMax = policy.QuotaMax
 	session.QuotaRenewalRate = policy.QuotaRenewalRate
 	session.AccessRights = make(map[string]user.AccessDefinition)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/internal/event"
 	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/user"
 )
 
 // RateLimitAndQuotaCheck will check the incomming request and key whether it is within it's quota and
@@ -42,6 +43,23 @@ func (k *RateLimitAndQuotaCheck) handleQuotaFailure(r *http.Request, token strin
 	return errors.New("Quota exceeded"), http.StatusForbidden
 }
 
+// rateLimitKeys returns the keys the rate limit and quota of the session are counted on. Quotas use the
+// default key unless a rate_limit_pattern is set in the session metadata.
+func (k *BaseMiddleware) rateLimitKeys(r *http.Request, session *user.SessionState) (rateLimitKey, quotaKey string) {
+	rateLimitKey = ctxGetAuthToken(r)
+
+	if pattern, found := session.MetaData["rate_limit_pattern"]; found {
+		if patternString, ok := pattern.(string); ok && patternString != "" {
+			if customKeyValue := k.Gw.ReplaceTykVariables(r, patternString, false); customKeyValue != "" {
+				rateLimitKey = customKeyValue
+				quotaKey = customKeyValue
+			}
+		}
+	}
+
+	return rateLimitKey, quotaKey
+}
+
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
 func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	if ctxGetRequestStatus(r) == StatusOkAndIgnore {
@@ -54,17 +72,7 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 	}
 
 	session := ctxGetSession(r)
-	rateLimitKey := ctxGetAuthToken(r)
-	quotaKey := ""
-
-	if pattern, found := session.MetaData["rate_limit_pattern"]; found {
-		if patternString, ok := pattern.(string); ok && patternString != "" {
-			if customKeyValue := k.Gw.ReplaceTykVariables(r, patternString, false); customKeyValue != "" {
-				rateLimitKey = customKeyValue
-				quotaKey = customKeyValue
-			}
-		}
-	}
+	rateLimitKey, quotaKey := k.rateLimitKeys(r, session)
 
 	storeRef := k.Gw.GlobalSessionManager.Store()
 	reason := k.Gw.SessionLimiter.ForwardMessage(
@@ -44,7 +44,8 @@ func (m *RedisCacheMiddleware) Init() {
 }
 
 func (m *RedisCacheMiddleware) EnabledForSpec() bool {
-	return m.Spec.CacheOptions.EnableCache
+	// GraphQL aware caching replaces the generic body based cache
+	return m.Spec.CacheOptions.EnableCache && !isGraphQLCacheEnabled(m.Spec)
 }
 
 func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string, regex string, additionalKeyFromHeaders string) (string, error) {
@@ -186,7 +187,6 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		token = request.RealIP(r)
 	}
 
-	var retBlob string
 	key, err := m.CreateCheckSum(r, token, cacheKeyRegex, m.getCacheKeyFromHeaders(r))
 	if err != nil {
 		m.Logger().Debug("Error creating checksum. Skipping cache check")
@@ -213,7 +213,13 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		timeout:                timeout,
 	})
 
-	retBlob, err = m.store.GetKey(key)
+	return m.serveCachedResponse(w, r, key, t1)
+}
+
+// serveCachedResponse writes the response stored under key, if any. It returns mwStatusRespond
+// when the response was served from cache, so the rest of the chain is skipped.
+func (m *RedisCacheMiddleware) serveCachedResponse(w http.ResponseWriter, r *http.Request, key string, t1 time.Time) (error, int) {
+	retBlob, err := m.store.GetKey(key)
 	if err != nil {
 		// Record not found, continue with the middleware chain
 		return nil, http.StatusOK
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/net/html/charset"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
 )
 
 func WrappedCharsetReader(s string, i io.Reader) (io.Reader, error) {
@@ -55,6 +56,23 @@ func transformBody(r *http.Request, tmeta *TransformSpec, t *TransformMiddleware
 	body, _ := ioutil.ReadAll(r.Body)
 	defer r.Body.Close()
 
+	if tmeta.TemplateData.Conversion.Enabled {
+		converted, contentType, err := convertBody(body, tmeta.TemplateData.Input, tmeta.TemplateData.Conversion)
+		if err != nil {
+			// keep the original body so the request can still be proxied
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			nopCloseRequestBody(r)
+			return err
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(converted))
+		r.ContentLength = int64(len(converted))
+		r.Header.Set(header.ContentType, contentType)
+		nopCloseRequestBody(r)
+
+		return nil
+	}
+
 	// Put into an interface:
 	bodyData := make(map[string]interface{})
 
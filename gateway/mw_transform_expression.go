@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/expression"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// TransformExpressionSpec holds a transform expression compiled at API load time.
+type TransformExpressionSpec struct {
+	apidef.TransformExpressionMeta
+	Program *expression.Program
+}
+
+// TransformExpressionMiddleware rewrites request bodies with CEL expressions.
+type TransformExpressionMiddleware struct {
+	*BaseMiddleware
+}
+
+func (t *TransformExpressionMiddleware) Name() string {
+	return "TransformExpressionMiddleware"
+}
+
+func (t *TransformExpressionMiddleware) EnabledForSpec() bool {
+	for _, version := range t.Spec.VersionData.Versions {
+		for _, meta := range version.ExtendedPaths.TransformExpression {
+			if !meta.Disabled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (t *TransformExpressionMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	vInfo, _ := t.Spec.Version(r)
+	versionPaths := t.Spec.RxPaths[vInfo.Name]
+
+	found, meta := t.Spec.CheckSpecMatchesStatus(r, versionPaths, TransformedExpression)
+	if !found {
+		return nil, http.StatusOK
+	}
+
+	logger := t.Logger().WithFields(logrus.Fields{
+		"prefix": "inbound-transform-expression",
+		"path":   r.URL.Path,
+	})
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		logger.WithError(err).Error("Failed to read request body")
+		return err, http.StatusBadRequest
+	}
+
+	bodyObj, err := decodeExpressionBody(body)
+	if err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		logger.WithError(err).Error("Failed to decode request body")
+		return err, http.StatusUnsupportedMediaType
+	}
+
+	in := expression.Input{
+		Body:    bodyObj,
+		Headers: flattenHeaders(r.Header),
+		Context: ctxGetData(r),
+		Session: sessionMetaData(ctxGetSession(r)),
+	}
+
+	transformed, err := meta.(*TransformExpressionSpec).Program.Eval(in)
+	if err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		logger.WithError(err).Error("Failed to transform request body")
+		return err, http.StatusInternalServerError
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(transformed))
+	r.ContentLength = int64(len(transformed))
+	r.Header.Set(header.ContentType, header.ApplicationJSON)
+
+	return nil, http.StatusOK
+}
+
+// decodeExpressionBody decodes a JSON body for expression evaluation. Empty bodies decode to nil.
+func decodeExpressionBody(body []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var bodyObj interface{}
+	if err := json.Unmarshal(body, &bodyObj); err != nil {
+		return nil, fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	return bodyObj, nil
+}
+
+// flattenHeaders keeps the first value of every header, keyed by its canonical name.
+func flattenHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name := range h {
+		headers[http.CanonicalHeaderKey(name)] = h.Get(name)
+	}
+	return headers
+}
+
+func sessionMetaData(session *user.SessionState) map[string]interface{} {
+	if session == nil {
+		return nil
+	}
+	return session.MetaData
+}
@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestTransformExpression(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.UseKeylessAccess = false
+		spec.EnableContextVars = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.TransformExpression = []apidef.TransformExpressionMeta{
+				{
+					Path:       "/request",
+					Method:     http.MethodPost,
+					Expression: `{"user": body.name, "tenant": headers["X-Tenant"], "tier": session.tier, "path": context.path}`,
+				},
+				{
+					Path:       "/invalid",
+					Method:     http.MethodPost,
+					Expression: `unknown.name`,
+				},
+			}
+			v.ExtendedPaths.TransformExpressionResponse = []apidef.TransformExpressionMeta{{
+				Path:       "/response",
+				Method:     http.MethodGet,
+				Expression: `{"status": status, "method": body.Method, "tier": session.tier}`,
+			}}
+		})
+	})[0]
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.MetaData = map[string]interface{}{"tier": "gold"}
+		s.AccessRights = map[string]user.AccessDefinition{api.APIID: {APIID: api.APIID}}
+	})
+
+	headers := map[string]string{header.Authorization: key, "X-Tenant": "acme"}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Method: http.MethodPost, Path: "/request", Headers: headers, Data: `{"name":"tyk"}`, Code: http.StatusOK,
+			BodyMatch: `"Body":"{\\"path\\":\\"/request\\",\\"tenant\\":\\"acme\\",\\"tier\\":\\"gold\\",\\"user\\":\\"tyk\\"}"`,
+		},
+		{
+			Method: http.MethodPost, Path: "/request", Headers: headers, Data: `not json`, Code: http.StatusUnsupportedMediaType,
+		},
+		{
+			// invalid expressions are skipped at load time
+			Method: http.MethodPost, Path: "/invalid", Headers: headers, Data: `{"name":"tyk"}`, Code: http.StatusOK,
+			BodyMatch: `"Body":"{\\"name\\":\\"tyk\\"}"`,
+		},
+		{
+			Method: http.MethodGet, Path: "/response", Headers: headers, Code: http.StatusOK,
+			BodyMatch:    `{"method":"GET","status":200,"tier":"gold"}`,
+			HeadersMatch: map[string]string{header.ContentType: header.ApplicationJSON},
+		},
+	}...)
+}
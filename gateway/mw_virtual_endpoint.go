@@ -16,7 +16,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/robertkrimen/otto"
 	_ "github.com/robertkrimen/otto/underscore"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
@@ -62,11 +61,11 @@ func (d *VirtualEndpoint) Name() string {
 func (gw *Gateway) preLoadVirtualMetaCode(meta *apidef.VirtualMeta, j *JSVM) {
 	// the only call site uses (&foo, &bar) so meta and j won't be
 	// nil.
-	var src interface{}
+	var src []byte
 	switch meta.FunctionSourceType {
 	case apidef.UseFile:
 		j.Log.Debug("Loading JS Endpoint File: ", meta.FunctionSourceURI)
-		f, err := os.Open(meta.FunctionSourceURI)
+		f, err := os.ReadFile(meta.FunctionSourceURI)
 		if err != nil {
 			j.Log.WithError(err).Error("Failed to open Endpoint JS")
 			return
@@ -89,7 +88,7 @@ func (gw *Gateway) preLoadVirtualMetaCode(meta *apidef.VirtualMeta, j *JSVM) {
 		j.Log.Error("Type must be either file or blob (base64)!")
 		return
 	}
-	if _, err := j.VM.Run(src); err != nil {
+	if err := j.Load(meta.ResponseFunctionName, string(src)); err != nil {
 		j.Log.WithError(err).Error("Could not load virtual endpoint JS")
 	}
 }
@@ -175,43 +174,15 @@ func (d *VirtualEndpoint) ServeHTTPForCache(w http.ResponseWriter, r *http.Reque
 
 	// Run the middleware
 
-	vm := d.Spec.JSVM.VM.Copy()
-	vm.Interrupt = make(chan func(), 1)
 	d.Logger().Debug("Running: ", vmeta.ResponseFunctionName)
-	// buffered, leaving no chance of a goroutine leak since the
-	// spawned goroutine will send 0 or 1 values.
-	ret := make(chan otto.Value, 1)
-	errRet := make(chan error, 1)
-	go func() {
-		defer func() {
-			// the VM executes the panic func that gets it
-			// to stop, so we must recover here to not crash
-			// the whole Go program.
-			recover()
-		}()
-		returnRaw, err := vm.Run(vmeta.ResponseFunctionName + `(` + string(requestAsJson) + `, ` + string(sessionAsJson) + `, ` + specAsJson + `);`)
-		ret <- returnRaw
-		errRet <- err
-	}()
-	var returnRaw otto.Value
-	t := time.NewTimer(d.Spec.JSVM.Timeout)
-	select {
-	case returnRaw = <-ret:
-		if err := <-errRet; err != nil {
-			return nil, fmt.Errorf("Failed to run JS middleware: %w", err)
-		}
-		t.Stop()
-	case <-t.C:
-		t.Stop()
+	returnDataStr, err := d.Spec.JSVM.Call(vmeta.ResponseFunctionName + `(` + string(requestAsJson) + `, ` + string(sessionAsJson) + `, ` + specAsJson + `);`)
+	if errors.Is(err, errJSVMTimeout) {
 		d.Logger().Error("JS middleware timed out after ", d.Spec.JSVM.Timeout)
-		vm.Interrupt <- func() {
-			// only way to stop the VM is to send it a func
-			// that panics.
-			panic("stop")
-		}
 		return nil, fmt.Errorf("JS middleware timed out after %s", d.Spec.JSVM.Timeout)
 	}
-	returnDataStr, _ := returnRaw.ToString()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to run JS middleware: %w", err)
+	}
 
 	// Decode the return object
 	newResponseData := VMResponseObject{}
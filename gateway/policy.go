@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 
@@ -34,6 +35,14 @@ type DBAccessDefinition struct {
 
 	// Endpoints contains endpoint rate limit settings.
 	Endpoints user.Endpoints `json:"endpoints,omitempty"`
+
+	// ResponseFields contains response field filtering settings.
+	ResponseFields user.ResponseFieldRules `json:"response_fields"`
+
+	// FieldArgumentRules restrict GraphQL fields to the allowed values of their arguments.
+	FieldArgumentRules []user.FieldArgumentRule `json:"field_argument_rules,omitempty"`
+	// MaskRestrictedFields resolves restricted GraphQL fields to null instead of rejecting the operation.
+	MaskRestrictedFields bool `json:"mask_restricted_fields,omitempty"`
 }
 
 func (d *DBAccessDefinition) ToRegularAD() user.AccessDefinition {
@@ -47,6 +56,9 @@ func (d *DBAccessDefinition) ToRegularAD() user.AccessDefinition {
 		DisableIntrospection: d.DisableIntrospection,
 		FieldAccessRights:    d.FieldAccessRights,
 		Endpoints:            d.Endpoints,
+		ResponseFields:       d.ResponseFields,
+		FieldArgumentRules:   d.FieldArgumentRules,
+		MaskRestrictedFields: d.MaskRestrictedFields,
 	}
 
 	if d.Limit != nil {
@@ -116,6 +128,17 @@ func LoadPoliciesFromDir(dir string) (map[string]user.Policy, error) {
 	return policies, nil
 }
 
+// changedPolicies returns the IDs of the policies that were updated or removed.
+func changedPolicies(previous, current map[string]user.Policy) []string {
+	var changed []string
+	for id, policy := range previous {
+		if updated, ok := current[id]; !ok || !reflect.DeepEqual(policy, updated) {
+			changed = append(changed, id)
+		}
+	}
+	return changed
+}
+
 // LoadPoliciesFromDashboard will connect and download Policies from a Tyk Dashboard instance.
 func (gw *Gateway) LoadPoliciesFromDashboard(endpoint, secret string, allowExplicit bool) (map[string]user.Policy, error) {
 
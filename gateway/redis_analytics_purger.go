@@ -55,4 +55,8 @@ func (r *RedisPurger) PurgeCache() {
 			r.Store.SetExp(analyticsKey, int64(expireAfter))
 		}
 	}
+
+	if exp, _ := r.Store.GetExp(graphQLAnalyticsKeyName); exp == -1 {
+		r.Store.SetExp(graphQLAnalyticsKeyName, int64(expireAfter))
+	}
 }
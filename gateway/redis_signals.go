@@ -179,6 +179,7 @@ func (gw *Gateway) handleKeySpaceEventCacheFlush(payload string) {
 
 		gw.RPCGlobalCache.Delete("apikey-" + key)
 		gw.SessionCache.Delete(key)
+		gw.graphQLSubscriptions.TerminateKey(key)
 	}
 }
 
@@ -42,7 +42,7 @@ func (h *ResponseCacheMiddleware) HandleError(rw http.ResponseWriter, req *http.
 }
 
 func (m *ResponseCacheMiddleware) EnabledForSpec() bool {
-	return m.Spec.CacheOptions.EnableCache
+	return m.Spec.CacheOptions.EnableCache || isGraphQLCacheEnabled(m.Spec)
 }
 
 func (m *ResponseCacheMiddleware) getTimeTTL(cacheTTL int64) int64 {
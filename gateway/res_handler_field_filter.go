@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/cache"
+	"github.com/TykTechnologies/tyk/internal/redact"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ResponseFieldFilterMiddleware removes and masks fields of JSON responses according to
+// the response field rules of the key's access definition for the API.
+type ResponseFieldFilterMiddleware struct {
+	BaseTykResponseHandler
+}
+
+const (
+	// responseFieldRulesCacheTTL is the number of seconds compiled response field rules are cached for.
+	responseFieldRulesCacheTTL = 600
+	// responseFieldRulesCacheCleanup is the number of seconds between evictions of expired rules.
+	responseFieldRulesCacheCleanup = 60
+)
+
+func (h *ResponseFieldFilterMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *ResponseFieldFilterMiddleware) Name() string {
+	return "ResponseFieldFilterMiddleware"
+}
+
+func (h *ResponseFieldFilterMiddleware) Enabled() bool {
+	return !h.Spec.UseKeylessAccess
+}
+
+func (h *ResponseFieldFilterMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ResponseFieldFilterMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseFieldFilterMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	if ses == nil {
+		return nil
+	}
+
+	accessDef, ok := ses.AccessRights[h.Spec.APIID]
+	if !ok || accessDef.ResponseFields.IsEmpty() {
+		return nil
+	}
+
+	if !strings.Contains(res.Header.Get(header.ContentType), "json") {
+		return nil
+	}
+
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "outbound-field-filter",
+		"api_id": h.Spec.APIID,
+		"path":   req.URL.Path,
+	})
+
+	respBody := respBodyReader(req, res)
+	body, _ := ioutil.ReadAll(respBody)
+	defer respBody.Close()
+
+	rules, err := h.compileRules(accessDef.ResponseFields)
+	var filtered []byte
+	if err == nil {
+		filtered, err = filterResponseFields(body, rules)
+	}
+	if err != nil {
+		// never let fields through that the key is not allowed to see
+		logger.WithError(err).Error("Failed to filter response fields")
+		res.StatusCode = http.StatusInternalServerError
+		res.Status = http.StatusText(http.StatusInternalServerError)
+		filtered = []byte(`{"error":"` + http.StatusText(http.StatusInternalServerError) + `"}`)
+	}
+
+	setResponseBody(res, *bytes.NewBuffer(filtered))
+	return nil
+}
+
+// compileRules returns the compiled rules of the rule set, compiling them on first use.
+// The rules are cached on the API spec.
+func (h *ResponseFieldFilterMiddleware) compileRules(fields user.ResponseFieldRules) (redact.Rules, error) {
+	key, err := json.Marshal(fields)
+	if err != nil {
+		return redact.Rules{}, err
+	}
+
+	rulesCache := h.Spec.responseFieldRules
+	if rulesCache == nil {
+		return redact.CompileRules(fields.Allowed, fields.Denied, fields.Masked)
+	}
+
+	if rules, ok := rulesCache.Get(string(key)); ok {
+		return rules.(redact.Rules), nil
+	}
+
+	rules, err := redact.CompileRules(fields.Allowed, fields.Denied, fields.Masked)
+	if err != nil {
+		return redact.Rules{}, err
+	}
+
+	rulesCache.Set(string(key), rules, cache.DefaultExpiration)
+	return rules, nil
+}
+
+// filterResponseFields applies the rules to a JSON body. Numbers are decoded as json.Number
+// so they are written back unchanged, whatever their precision.
+func filterResponseFields(body []byte, rules redact.Rules) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+
+	return json.Marshal(rules.Apply(doc))
+}
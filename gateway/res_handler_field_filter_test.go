@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestResponseFieldFilter(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ContentType, header.ApplicationJSON)
+		_, _ = w.Write([]byte(`{"id":"u-1","account":9007199254740993,"internal_id":42,"email":"dev@tyk.io","orders":[{"id":"o-1","supplier_id":"s-1"}]}`))
+	}))
+	defer upstream.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = false
+	})[0]
+
+	createKey := func(fields user.ResponseFieldRules) string {
+		_, key := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{api.APIID: {
+				APIID:          api.APIID,
+				ResponseFields: fields,
+			}}
+		})
+		return key
+	}
+
+	unrestricted := createKey(user.ResponseFieldRules{})
+	partner := createKey(user.ResponseFieldRules{
+		Denied: []string{"$.internal_id", "$.orders[*].supplier_id"},
+		Masked: []string{"$.email"},
+	})
+	allowList := createKey(user.ResponseFieldRules{Allowed: []string{"$.id"}})
+	invalid := createKey(user.ResponseFieldRules{Denied: []string{"internal_id"}})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Path: "/", Headers: map[string]string{header.Authorization: unrestricted}, Code: http.StatusOK,
+			BodyMatch: `"internal_id":42`,
+		},
+		{
+			Path: "/", Headers: map[string]string{header.Authorization: partner}, Code: http.StatusOK,
+			BodyMatch: `{"account":9007199254740993,"email":"\*\*\*\*","id":"u-1","orders":\[{"id":"o-1"}\]}`,
+		},
+		{
+			Path: "/", Headers: map[string]string{header.Authorization: allowList}, Code: http.StatusOK,
+			BodyMatch: `^{"id":"u-1"}$`,
+		},
+		{
+			Path: "/", Headers: map[string]string{header.Authorization: invalid}, Code: http.StatusInternalServerError,
+			BodyNotMatch: `internal_id`,
+		},
+	}...)
+}
@@ -19,6 +19,10 @@ type ResponseGoPluginMiddleware struct {
 	SymbolName string // function symbol to look up
 	logger     *logrus.Entry
 	ResHandler func(rw http.ResponseWriter, res *http.Response, req *http.Request)
+	// ChunkHandler is loaded instead of ResHandler for streaming plugins, it's called for each chunk of
+	// the response body as it's sent to the client.
+	ChunkHandler func(res *http.Response, req *http.Request, chunk []byte, last bool) ([]byte, error)
+	StreamBody   bool
 }
 
 func (h ResponseGoPluginMiddleware) Base() *BaseTykResponseHandler {
@@ -33,13 +37,14 @@ func (h *ResponseGoPluginMiddleware) Init(c interface{}, spec *APISpec) error {
 	h.Spec = spec
 	h.Path = c.(apidef.MiddlewareDefinition).Path
 	h.SymbolName = c.(apidef.MiddlewareDefinition).Name
+	h.StreamBody = c.(apidef.MiddlewareDefinition).StreamBody
 
 	h.logger = log.WithFields(logrus.Fields{
 		"mwPath":       h.Path,
 		"mwSymbolName": h.SymbolName,
 	})
 
-	if h.ResHandler != nil {
+	if h.ResHandler != nil || h.ChunkHandler != nil {
 		h.logger.Info("Go-plugin middleware is already initialized")
 		// noop
 		return nil
@@ -54,6 +59,15 @@ func (h *ResponseGoPluginMiddleware) Init(c interface{}, spec *APISpec) error {
 		h.Path = newPath
 	}
 
+	if h.StreamBody {
+		if h.ChunkHandler, err = goplugin.GetResponseChunkHandler(h.Path, h.SymbolName); err != nil {
+			h.logger.WithError(err).Error("Could not load Go-plugin")
+			return err
+		}
+		h.logger.Infof("Loaded Go streaming response plugin: %s", h.SymbolName)
+		return nil
+	}
+
 	// try to load plugin
 	if h.ResHandler, err = goplugin.GetResponseHandler(h.Path, h.SymbolName); err != nil {
 		h.logger.WithError(err).Error("Could not load Go-plugin")
@@ -69,6 +83,11 @@ func (h *ResponseGoPluginMiddleware) HandleError(rw http.ResponseWriter, req *ht
 }
 
 func (h *ResponseGoPluginMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	if h.ChunkHandler != nil {
+		h.streamGoPluginResponse(res, req)
+		return nil
+	}
+
 	err := h.HandleGoPluginResponse(w, res, req)
 	if err != nil {
 		return err
@@ -120,3 +139,26 @@ func (h *ResponseGoPluginMiddleware) HandleGoPluginResponse(w http.ResponseWrite
 	}
 	return nil
 }
+
+// streamGoPluginResponse passes the response body through the chunk handler as it's sent to the client.
+// Headers were already sent by then, so streaming plugins can only change the body.
+func (h *ResponseGoPluginMiddleware) streamGoPluginResponse(res *http.Response, req *http.Request) {
+	// Inject definition into response context
+	ctx.SetDefinition(req, h.Spec.APIDefinition)
+
+	streamResponseBody(res, func(chunk []byte, last bool) (out []byte, err error) {
+		// make sure tyk recover in case Go-plugin function panics
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("%v", e)
+				h.logger.WithError(err).Error("Recovered from panic while running Go-plugin streaming middleware func")
+			}
+		}()
+
+		out, err = h.ChunkHandler(res, req, chunk, last)
+		if err != nil {
+			h.logger.WithError(err).Error("Go-plugin streaming middleware func failed, aborting response")
+		}
+		return out, err
+	})
+}
@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"slices"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// GraphQLMaskedFieldsMiddleware resolves the restricted fields removed from the operation by
+// GraphQLGranularAccessMiddleware to null, adding an error entry for each of them.
+type GraphQLMaskedFieldsMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) Name() string {
+	return "GraphQLMaskedFieldsMiddleware"
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) Enabled() bool {
+	return h.Spec.GraphQL.Enabled && !h.Spec.UseKeylessAccess
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *GraphQLMaskedFieldsMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	fields := ctxGetGraphQLMaskedFields(req)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	respBody := respBodyReader(req, res)
+	body, _ := ioutil.ReadAll(respBody)
+	defer respBody.Close()
+
+	var gqlResponse map[string]json.RawMessage
+	var data interface{}
+	if err := json.Unmarshal(body, &gqlResponse); err != nil || len(gqlResponse["data"]) == 0 {
+		setResponseBody(res, *bytes.NewBuffer(body))
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(gqlResponse["data"]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		setResponseBody(res, *bytes.NewBuffer(body))
+		return nil
+	}
+
+	var errs []interface{}
+	if raw, ok := gqlResponse["errors"]; ok {
+		_ = json.Unmarshal(raw, &errs)
+	}
+
+	for _, field := range fields {
+		for _, path := range maskResponseField(data, field.Path, nil) {
+			errs = append(errs, map[string]interface{}{
+				"message": field.Message,
+				"path":    path,
+			})
+		}
+	}
+
+	var err error
+	if gqlResponse["data"], err = json.Marshal(data); err == nil && len(errs) > 0 {
+		gqlResponse["errors"], err = json.Marshal(errs)
+	}
+	if err == nil {
+		body, err = json.Marshal(gqlResponse)
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to mask restricted GraphQL fields")
+	}
+
+	setResponseBody(res, *bytes.NewBuffer(body))
+	return nil
+}
+
+// maskResponseField sets the field at path to null in every object of the response data, descending into lists.
+// It returns the response paths of the masked fields, including list indices.
+func maskResponseField(value interface{}, path []string, location []interface{}) (masked [][]interface{}) {
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			masked = append(masked, maskResponseField(item, path, append(slices.Clone(location), i))...)
+		}
+	case map[string]interface{}:
+		location = append(slices.Clone(location), path[0])
+		if len(path) == 1 {
+			v[path[0]] = nil
+			return [][]interface{}{location}
+		}
+		return maskResponseField(v[path[0]], path[1:], location)
+	}
+	return masked
+}
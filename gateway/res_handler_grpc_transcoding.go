@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const textEventStream = "text/event-stream"
+
+// GRPCTranscodingResponseMiddleware turns the gRPC responses of transcoded calls into JSON. Server streaming
+// methods are sent as server-sent events, with an `error` event when the call fails once streaming.
+type GRPCTranscodingResponseMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (h *GRPCTranscodingResponseMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *GRPCTranscodingResponseMiddleware) Name() string {
+	return "GRPCTranscodingResponseMiddleware"
+}
+
+func (h *GRPCTranscodingResponseMiddleware) Enabled() bool {
+	return h.Spec.GRPC.Enabled && h.Spec.GRPC.Transcoding.Enabled
+}
+
+func (h *GRPCTranscodingResponseMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *GRPCTranscodingResponseMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *GRPCTranscodingResponseMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	call := ctxGetGRPCTranscodedCall(req)
+	if call == nil || !strings.HasPrefix(res.Header.Get(header.ContentType), grpcContentType) {
+		return nil
+	}
+
+	// trailers-only responses carry the status in headers, they're failed calls
+	if call.route.method.IsStreamingServer() && res.Header.Get(grpcStatusHeader) == "" {
+		h.streamEvents(call, res)
+		return nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	code, message := grpcResponseStatus(res)
+	call.status = strconv.Itoa(int(code))
+	res.Trailer = nil
+	if err != nil {
+		code, message = codes.Unavailable, err.Error()
+	}
+
+	if code == codes.OK {
+		data, _, ok, err := readGRPCFrame(body)
+		if err == nil && !ok {
+			err = errGRPCIncompleteResponse
+		}
+
+		var out []byte
+		if err == nil {
+			out, err = call.route.responseJSON(data)
+		}
+
+		if err == nil {
+			setGRPCTranscodedBody(res, http.StatusOK, header.ApplicationJSON, out)
+			return nil
+		}
+
+		log.WithFields(logrus.Fields{
+			"prefix": "grpc-transcoding",
+			"api_id": h.Spec.APIID,
+			"method": call.route.grpcMethod,
+		}).WithError(err).Error("Couldn't transcode gRPC response")
+		code, message = codes.Internal, err.Error()
+	}
+
+	setGRPCTranscodedBody(res, httpStatusFromGRPC(code), header.ApplicationJSON, grpcErrorJSON(code, message))
+	return nil
+}
+
+// streamEvents sends each message of a server streaming call as an event, as it's received.
+func (h *GRPCTranscodingResponseMiddleware) streamEvents(call *grpcTranscodedCall, res *http.Response) {
+	res.Header.Set(header.ContentType, textEventStream)
+	res.Header.Set(header.CacheControl, "no-cache")
+
+	var buf []byte
+	streamResponseBody(res, func(chunk []byte, last bool) ([]byte, error) {
+		buf = append(buf, chunk...)
+
+		var out []byte
+		for {
+			data, rest, ok, err := readGRPCFrame(buf)
+			if err != nil {
+				return out, err
+			}
+			if !ok {
+				break
+			}
+			buf = rest
+
+			event, err := call.route.responseJSON(data)
+			if err != nil {
+				return out, err
+			}
+			out = appendServerSentEvent(out, "", event)
+		}
+
+		if last {
+			code, message := grpcResponseStatus(res)
+			call.status = strconv.Itoa(int(code))
+			res.Trailer = nil
+			if code != codes.OK {
+				out = appendServerSentEvent(out, "error", grpcErrorJSON(code, message))
+			}
+		}
+		return out, nil
+	})
+}
+
+func appendServerSentEvent(out []byte, event string, data []byte) []byte {
+	if event != "" {
+		out = append(out, "event: "+event+"\n"...)
+	}
+	out = append(out, "data: "...)
+	out = append(out, data...)
+	return append(out, "\n\n"...)
+}
+
+// setGRPCTranscodedBody replaces a gRPC response with an HTTP one.
+func setGRPCTranscodedBody(res *http.Response, code int, contentType string, body []byte) {
+	res.StatusCode = code
+	res.Status = strconv.Itoa(code) + " " + http.StatusText(code)
+	res.Header.Del(grpcStatusHeader)
+	res.Header.Del(grpcMessageHeader)
+	res.Header.Set(header.ContentType, contentType)
+	res.Header.Set(header.ContentLength, strconv.Itoa(len(body)))
+	res.ContentLength = int64(len(body))
+	res.Body = io.NopCloser(bytes.NewReader(body))
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// GRPCWebResponseMiddleware turns the responses of bridged gRPC-Web calls into gRPC-Web responses. Messages are
+// streamed as received, the trailers are sent in a last frame of the body, base64 encoded for grpc-web-text calls.
+type GRPCWebResponseMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (h *GRPCWebResponseMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *GRPCWebResponseMiddleware) Name() string {
+	return "GRPCWebResponseMiddleware"
+}
+
+func (h *GRPCWebResponseMiddleware) Enabled() bool {
+	return h.Spec.GRPC.Enabled && h.Spec.GRPC.Web.Enabled
+}
+
+func (h *GRPCWebResponseMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *GRPCWebResponseMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *GRPCWebResponseMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	call := ctxGetGRPCWebCall(req)
+	if call == nil || !strings.HasPrefix(res.Header.Get(header.ContentType), grpcContentType) {
+		return nil
+	}
+
+	res.Header.Set(header.ContentType, call.contentType)
+
+	// trailers-only responses carry the status in headers, as gRPC-Web expects
+	if status := res.Header.Get(grpcStatusHeader); status != "" {
+		call.status = status
+		return nil
+	}
+
+	var encoder grpcWebTextEncoder
+	streamResponseBody(res, func(chunk []byte, last bool) ([]byte, error) {
+		out := chunk
+		if last {
+			call.status = res.Trailer.Get(grpcStatusHeader)
+			out = grpcWebTrailerFrame(res.Trailer)
+			res.Trailer = nil
+		}
+
+		if call.text {
+			out = encoder.encode(out, last)
+		}
+		return out, nil
+	})
+	return nil
+}
+
+// grpcWebTrailerFrame encodes trailers as the last frame of a gRPC-Web response body.
+func grpcWebTrailerFrame(trailer http.Header) []byte {
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			sb.WriteString(strings.ToLower(k) + ": " + v + "\r\n")
+		}
+	}
+
+	frame := make([]byte, grpcFrameHeaderSize+sb.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:grpcFrameHeaderSize], uint32(sb.Len()))
+	copy(frame[grpcFrameHeaderSize:], sb.String())
+	return frame
+}
+
+// grpcWebTextEncoder base64 encodes a streamed body, holding back bytes until they fill a group of 3,
+// so the body is a single base64 string.
+type grpcWebTextEncoder struct {
+	rest []byte
+}
+
+func (e *grpcWebTextEncoder) encode(data []byte, last bool) []byte {
+	data = append(e.rest, data...)
+
+	n := len(data)
+	if !last {
+		n -= n % 3
+	}
+	e.rest = append([]byte(nil), data[n:]...)
+
+	out := make([]byte, base64.StdEncoding.EncodedLen(n))
+	base64.StdEncoding.Encode(out, data[:n])
+	return out
+}
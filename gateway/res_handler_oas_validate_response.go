@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ValidateResponse validates upstream responses of OAS APIs against the response definitions
+// of the matched operation, when enabled for the operation in the Tyk extension.
+type ValidateResponse struct {
+	BaseTykResponseHandler
+}
+
+func (h *ValidateResponse) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *ValidateResponse) Name() string {
+	return "ValidateResponse"
+}
+
+func (h *ValidateResponse) Enabled() bool {
+	if !h.Spec.IsOAS {
+		return false
+	}
+
+	middleware := h.Spec.OAS.GetTykExtension().Middleware
+	if middleware == nil {
+		return false
+	}
+
+	for _, operation := range middleware.Operations {
+		if operation.ValidateResponse != nil && operation.ValidateResponse.Enabled {
+			h.Spec.HasValidateResponse = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *ValidateResponse) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ValidateResponse) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ValidateResponse) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	operation := ctxGetOperation(req)
+	if operation == nil {
+		return nil
+	}
+
+	validateResponse := operation.ValidateResponse
+	if validateResponse == nil || !validateResponse.Enabled {
+		return nil
+	}
+
+	// streams are already being sent to the client and can't be validated as a whole
+	if httputil.IsStreamingResponse(res) {
+		return nil
+	}
+
+	respBody := respBodyReader(req, res)
+	body, err := ioutil.ReadAll(respBody)
+	respBody.Close()
+
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "validate-response",
+		"api_id": h.Spec.APIID,
+		"path":   req.URL.Path,
+		"method": req.Method,
+		"status": res.StatusCode,
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to read upstream response")
+		replaceWithBadGateway(res, http.StatusText(http.StatusBadGateway))
+		return nil
+	}
+
+	// restore the body before validation, so it's sent unchanged unless blocked
+	setResponseBody(res, *bytes.NewBuffer(body))
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: operation.pathParams,
+			Route:      operation.route,
+		},
+		Status: res.StatusCode,
+		Header: res.Header,
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	}
+	input.SetBodyBytes(body)
+
+	err = openapi3filter.ValidateResponse(req.Context(), input)
+	if err == nil {
+		return nil
+	}
+
+	logger.WithError(err).Warning("Upstream response failed validation.")
+
+	if validateResponse.Mode == oas.ValidateResponseModeEvent || validateResponse.Mode == oas.ValidateResponseModeBlock {
+		h.Spec.FireEvent(EventResponseValidationFailed, EventResponseValidationFailedMeta{
+			EventMetaDefault: EventMetaDefault{
+				Message:            "Upstream response failed validation",
+				OriginatingRequest: EncodeRequestToEvent(req),
+			},
+			Path:       req.URL.Path,
+			Method:     req.Method,
+			APIID:      h.Spec.APIID,
+			StatusCode: res.StatusCode,
+			Reason:     err.Error(),
+		})
+	}
+
+	if validateResponse.Mode == oas.ValidateResponseModeBlock {
+		res.Body.Close()
+		replaceWithBadGateway(res, "Upstream response failed validation")
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+const testOASForValidateResponse = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "validate-response",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/product": {
+      "get": {
+        "operationId": "productget",
+        "responses": {
+          "200": {
+            "description": "",
+            "headers": {
+              "X-Request-Id": {
+                "required": true,
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "required": ["name"],
+                  "properties": {
+                    "name": {
+                      "type": "string"
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "servers": [
+    {
+      "url": "/"
+    }
+  ]
+}`
+
+func TestValidateResponse(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const operationID = "productget"
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ContentType, header.ApplicationJSON)
+		switch r.URL.Query().Get("case") {
+		case "body":
+			w.Header().Set("X-Request-Id", "1")
+			_, _ = w.Write([]byte(`{"name":123}`))
+		case "header":
+			_, _ = w.Write([]byte(`{"name":"product"}`))
+		case "status":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"name":"product"}`))
+		default:
+			w.Header().Set("X-Request-Id", "1")
+			_, _ = w.Write([]byte(`{"name":"product"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	oasDoc, err := openapi3.NewLoader().LoadFromData([]byte(testOASForValidateResponse))
+	require.NoError(t, err)
+
+	loadAPI := func(mode string) *APISpec {
+		oasAPI := oas.OAS{T: *oasDoc}
+		oasAPI.SetTykExtension(&oas.XTykAPIGateway{
+			Middleware: &oas.Middleware{
+				Operations: oas.Operations{
+					operationID: {
+						ValidateResponse: &oas.ValidateResponse{Enabled: true, Mode: mode},
+					},
+				},
+			},
+		})
+		require.NoError(t, oasAPI.Validate(context.Background()))
+
+		var def apidef.APIDefinition
+		oasAPI.ExtractTo(&def)
+
+		api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.VersionData = def.VersionData
+			spec.OAS = oasAPI
+			spec.IsOAS = true
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+		})[0]
+
+		return ts.Gw.getApiSpec(api.APIID)
+	}
+
+	t.Run("log", func(t *testing.T) {
+		loadAPI(oas.ValidateResponseModeLog)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/product", Code: http.StatusOK, BodyMatch: `"name":"product"`},
+			{Path: "/product?case=body", Code: http.StatusOK, BodyMatch: `"name":123`},
+			{Path: "/product?case=header", Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("event", func(t *testing.T) {
+		spec := loadAPI(oas.ValidateResponseModeEvent)
+
+		events := make(chan config.EventMessage, 1)
+		spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+			EventResponseValidationFailed: {&testEventHandler{func(em config.EventMessage) { events <- em }}},
+		}
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/product?case=body", Code: http.StatusOK, BodyMatch: `"name":123`})
+
+		select {
+		case em := <-events:
+			meta, ok := em.Meta.(EventResponseValidationFailedMeta)
+			require.True(t, ok)
+			assert.Equal(t, spec.APIID, meta.APIID)
+			assert.Equal(t, http.MethodGet, meta.Method)
+			assert.Equal(t, http.StatusOK, meta.StatusCode)
+			assert.NotEmpty(t, meta.Reason)
+		case <-time.After(time.Second):
+			t.Fatal("ResponseValidationFailed event was not fired")
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		loadAPI(oas.ValidateResponseModeBlock)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/product", Code: http.StatusOK, BodyMatch: `"name":"product"`},
+			{Path: "/product?case=body", Code: http.StatusBadGateway, BodyMatch: `Upstream response failed validation`},
+			{Path: "/product?case=header", Code: http.StatusBadGateway, BodyMatch: `Upstream response failed validation`},
+			{Path: "/product?case=status", Code: http.StatusBadGateway, BodyMatch: `Upstream response failed validation`},
+		}...)
+	})
+}
@@ -0,0 +1,193 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	// responseSizeExceededTag is added to analytics records of responses over their size limit.
+	responseSizeExceededTag = "response-size-exceeded"
+	// responseContentTypeRejectedTag is added to analytics records of responses with a disallowed content type.
+	responseContentTypeRejectedTag = "response-content-type-rejected"
+)
+
+var errResponseTooLarge = errors.New("upstream response is too large")
+
+// ResponseSizeLimitMiddleware enforces per endpoint limits on the size and content type of upstream
+// responses. Offending responses are replaced with a 502, streamed responses are truncated.
+type ResponseSizeLimitMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (h *ResponseSizeLimitMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *ResponseSizeLimitMiddleware) Name() string {
+	return "ResponseSizeLimitMiddleware"
+}
+
+func (h *ResponseSizeLimitMiddleware) Enabled() bool {
+	for _, version := range h.Spec.VersionData.Versions {
+		for _, meta := range version.ExtendedPaths.ResponseSizeLimit {
+			if !meta.Disabled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *ResponseSizeLimitMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ResponseSizeLimitMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseSizeLimitMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	versionInfo, _ := h.Spec.Version(req)
+	versionPaths := h.Spec.RxPaths[versionInfo.Name]
+	found, meta := h.Spec.CheckSpecMatchesStatus(req, versionPaths, ResponseSizeLimit)
+	if !found {
+		return nil
+	}
+
+	rmeta := meta.(*apidef.ResponseSizeMeta)
+	logger := log.WithFields(logrus.Fields{
+		"prefix": "response-size-limit",
+		"api_id": h.Spec.APIID,
+		"path":   req.URL.Path,
+	})
+
+	if !responseContentTypeAllowed(res, rmeta.AllowedContentTypes) {
+		logger.WithField("content_type", res.Header.Get(header.ContentType)).Warning("Upstream responded with a disallowed content type, blocked.")
+		ctxSetResponseLimitExceeded(req, responseContentTypeRejectedTag)
+		replaceWithBadGateway(res, "Upstream response content type is not allowed")
+		return nil
+	}
+
+	if rmeta.SizeLimit <= 0 {
+		return nil
+	}
+
+	if res.ContentLength > rmeta.SizeLimit {
+		logger.WithFields(logrus.Fields{"size": res.ContentLength, "limit": rmeta.SizeLimit}).Warning("Upstream response is too large, blocked.")
+		ctxSetResponseLimitExceeded(req, responseSizeExceededTag)
+		res.Body.Close()
+		replaceWithBadGateway(res, "Upstream response is too large")
+		return nil
+	}
+
+	// streams are already being sent to the client, they can only be cut short
+	if httputil.IsStreamingResponse(res) {
+		res.Body = &limitedResponseBody{
+			ReadCloser: res.Body,
+			remaining:  rmeta.SizeLimit,
+			onExceeded: func() {
+				logger.WithField("limit", rmeta.SizeLimit).Warning("Upstream response stream is too large, truncated.")
+				ctxSetResponseLimitExceeded(req, responseSizeExceededTag)
+			},
+		}
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, rmeta.SizeLimit+1))
+	res.Body.Close()
+	if err != nil {
+		logger.WithError(err).Error("Failed to read upstream response")
+		replaceWithBadGateway(res, http.StatusText(http.StatusBadGateway))
+		return nil
+	}
+
+	if int64(len(body)) > rmeta.SizeLimit {
+		logger.WithField("limit", rmeta.SizeLimit).Warning("Upstream response is too large, blocked.")
+		ctxSetResponseLimitExceeded(req, responseSizeExceededTag)
+		replaceWithBadGateway(res, "Upstream response is too large")
+		return nil
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// responseContentTypeAllowed checks the response media type against the allowed ones.
+// Entries may use a wildcard subtype, like `text/*`. Responses without a body are always allowed.
+func responseContentTypeAllowed(res *http.Response, allowed []string) bool {
+	if len(allowed) == 0 || res.ContentLength == 0 || res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotModified {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get(header.ContentType))
+	if err != nil {
+		return false
+	}
+
+	for _, allowedType := range allowed {
+		allowedType = strings.ToLower(strings.TrimSpace(allowedType))
+		if allowedType == mediaType {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(allowedType, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replaceWithBadGateway replaces the upstream response with a 502 error.
+func replaceWithBadGateway(res *http.Response, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+
+	res.StatusCode = http.StatusBadGateway
+	res.Status = strconv.Itoa(http.StatusBadGateway) + " " + http.StatusText(http.StatusBadGateway)
+	res.Header.Del(header.ContentEncoding)
+	res.Header.Set(header.ContentType, header.ApplicationJSON)
+	res.Header.Set(header.ContentLength, strconv.Itoa(len(body)))
+	res.ContentLength = int64(len(body))
+	res.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+// limitedResponseBody fails reads once more than remaining bytes were read.
+type limitedResponseBody struct {
+	io.ReadCloser
+	remaining  int64
+	onExceeded func()
+}
+
+func (l *limitedResponseBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, errResponseTooLarge
+	}
+
+	// read one extra byte to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		l.onExceeded()
+		return n + int(l.remaining), errResponseTooLarge
+	}
+
+	return n, err
+}
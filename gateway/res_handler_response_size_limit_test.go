@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestResponseSizeLimit(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set(header.ContentType, header.ApplicationXML)
+			_, _ = w.Write([]byte(`<ok/>`))
+		case "/chunked":
+			w.Header().Set(header.ContentType, header.ApplicationJSON)
+			// flushing forces a chunked response without content length
+			_, _ = w.Write([]byte(`{"data":"`))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("a", 100) + `"}`))
+		default:
+			w.Header().Set(header.ContentType, "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"data":"` + strings.Repeat("a", 100) + `"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.ResponseSizeLimit = []apidef.ResponseSizeMeta{
+				{Path: "/small", Method: http.MethodGet, SizeLimit: 10},
+				{Path: "/large", Method: http.MethodGet, SizeLimit: 1000, AllowedContentTypes: []string{"application/json"}},
+				{Path: "/chunked", Method: http.MethodGet, SizeLimit: 10},
+				{Path: "/xml", Method: http.MethodGet, AllowedContentTypes: []string{"application/json", "text/*"}},
+			}
+		})
+	})
+
+	ts.Gw.Analytics.Flush()
+	ts.Gw.Analytics.Store.GetAndDeleteSet(analyticsKeyName)
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/small", Code: http.StatusBadGateway, BodyMatch: `Upstream response is too large`},
+		{Path: "/large", Code: http.StatusOK, BodyMatch: `"data"`},
+		{Path: "/chunked", Code: http.StatusBadGateway, BodyMatch: `Upstream response is too large`},
+		{Path: "/xml", Code: http.StatusBadGateway, BodyMatch: `content type is not allowed`},
+	}...)
+
+	ts.Gw.Analytics.Flush()
+	results := ts.Gw.Analytics.Store.GetAndDeleteSet(analyticsKeyName)
+	require.Len(t, results, 4)
+
+	tagged := map[string]string{}
+	for _, result := range results {
+		var record analytics.AnalyticsRecord
+		require.NoError(t, ts.Gw.Analytics.analyticsSerializer.Decode([]byte(result.(string)), &record))
+		for _, tag := range record.Tags {
+			if tag == responseSizeExceededTag || tag == responseContentTypeRejectedTag {
+				tagged[record.Path] = tag
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"/small":   responseSizeExceededTag,
+		"/chunked": responseSizeExceededTag,
+		"/xml":     responseContentTypeRejectedTag,
+	}, tagged)
+}
+
+func TestLimitedResponseBody(t *testing.T) {
+	var exceeded int
+	newBody := func(content string, limit int64) io.Reader {
+		return &limitedResponseBody{
+			ReadCloser: io.NopCloser(strings.NewReader(content)),
+			remaining:  limit,
+			onExceeded: func() { exceeded++ },
+		}
+	}
+
+	out, err := io.ReadAll(newBody("12345", 5))
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", string(out))
+	assert.Equal(t, 0, exceeded)
+
+	out, err = io.ReadAll(newBody("123456", 5))
+	assert.ErrorIs(t, err, errResponseTooLarge)
+	assert.Equal(t, "12345", string(out))
+	assert.Equal(t, 1, exceeded)
+}
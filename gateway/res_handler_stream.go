@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+)
+
+// streamChunkSize is the largest upstream body chunk passed to streaming response hooks.
+const streamChunkSize = 32 * 1024
+
+// responseChunkFunc processes a chunk of a streamed response body, returning the data to send in its place.
+// Returning no data drops the chunk. It's called a last time, with last set and no chunk, once the upstream
+// body is exhausted, so data held back by the hook can be flushed.
+type responseChunkFunc func(chunk []byte, last bool) ([]byte, error)
+
+// streamingBody passes a response body through a responseChunkFunc as it's read, so hooks can modify
+// large or long-lived (SSE) responses without buffering them.
+type streamingBody struct {
+	body   io.ReadCloser
+	handle responseChunkFunc
+
+	buf []byte
+	out []byte
+	err error
+}
+
+// streamResponseBody replaces the response body with a streamingBody. The resulting length is unknown,
+// so the response is sent chunked.
+func streamResponseBody(res *http.Response, handle responseChunkFunc) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return
+	}
+
+	res.Body = &streamingBody{
+		body:   res.Body,
+		handle: handle,
+		buf:    make([]byte, streamChunkSize),
+	}
+	res.ContentLength = -1
+	res.Header.Del("Content-Length")
+}
+
+func (s *streamingBody) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		n, err := s.body.Read(s.buf)
+		if n > 0 {
+			out, handleErr := s.handle(s.buf[:n], false)
+			if handleErr != nil {
+				s.err = handleErr
+				continue
+			}
+			s.out = out
+		}
+
+		switch {
+		case err == io.EOF:
+			out, handleErr := s.handle(nil, true)
+			s.out = append(s.out, out...)
+			s.err = io.EOF
+			if handleErr != nil {
+				s.err = handleErr
+			}
+		case err != nil:
+			s.err = err
+		}
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+func (s *streamingBody) Close() error {
+	return s.body.Close()
+}
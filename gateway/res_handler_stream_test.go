@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func testStreamedResponse(body string, handle responseChunkFunc) *http.Response {
+	res := &http.Response{
+		Header:        http.Header{"Content-Length": []string{"1"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	streamResponseBody(res, handle)
+	return res
+}
+
+func TestStreamResponseBody(t *testing.T) {
+	large := strings.Repeat("a", 3*streamChunkSize+10)
+
+	t.Run("transform", func(t *testing.T) {
+		var chunks int
+		res := testStreamedResponse(large, func(chunk []byte, last bool) ([]byte, error) {
+			if !last {
+				chunks++
+			}
+			return bytes.ToUpper(chunk), nil
+		})
+
+		assert.Equal(t, int64(-1), res.ContentLength)
+		assert.Empty(t, res.Header.Get("Content-Length"))
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToUpper(large), string(body))
+		assert.Equal(t, 4, chunks)
+	})
+
+	t.Run("drop and flush", func(t *testing.T) {
+		var held []byte
+		res := testStreamedResponse(large, func(chunk []byte, last bool) ([]byte, error) {
+			held = append(held, chunk...)
+			if last {
+				return []byte("len=" + strconv.Itoa(len(held))), nil
+			}
+			return nil, nil
+		})
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "len="+strconv.Itoa(len(large)), string(body))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		res := testStreamedResponse(large, func(chunk []byte, last bool) ([]byte, error) {
+			return nil, errAbort
+		})
+
+		_, err := io.ReadAll(res.Body)
+		assert.ErrorIs(t, err, errAbort)
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{}, Body: http.NoBody}
+		streamResponseBody(res, func(chunk []byte, last bool) ([]byte, error) {
+			return nil, errors.New("unexpected call")
+		})
+		assert.Equal(t, http.NoBody, res.Body)
+	})
+}
+
+type testStreamingPluginServer struct {
+	testGRPCPluginServer
+
+	mu     sync.Mutex
+	phases []string
+}
+
+func (s *testStreamingPluginServer) Dispatch(_ context.Context, object *coprocess.Object) (*coprocess.Object, error) {
+	phase := object.Spec[coprocessStreamKey]
+	s.mu.Lock()
+	s.phases = append(s.phases, phase)
+	s.mu.Unlock()
+
+	switch phase {
+	case coprocessStreamHeaders:
+		object.Response.Headers["X-Streamed"] = "true"
+		if object.Request.Headers["X-Buffer"] != "" {
+			object.Spec[coprocessStreamKey] = coprocessStreamBuffer
+		}
+	case coprocessStreamChunk, coprocessStreamBuffer:
+		object.Response.RawBody = bytes.ToUpper(object.Response.RawBody)
+	case coprocessStreamEnd:
+		object.Response.RawBody = []byte("<end>")
+	}
+
+	return object, nil
+}
+
+func TestCoProcessStreamingResponseHook(t *testing.T) {
+	server := &testStreamingPluginServer{}
+
+	ts := StartTest(nil, TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess:     true,
+			CoProcessGRPCServer: startTestGRPCPluginServer(t, server, nil),
+		},
+	})
+	defer ts.Close()
+	defer func() {
+		if d, ok := loadedDrivers[apidef.GrpcDriver].(*GRPCDispatcher); ok {
+			d.Close()
+		}
+		delete(loadedDrivers, apidef.GrpcDriver)
+	}()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("streamed body"))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/stream/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CustomMiddleware = apidef.MiddlewareSection{
+			Driver:   apidef.GrpcDriver,
+			Response: []apidef.MiddlewareDefinition{{Name: "response", StreamBody: true}},
+		}
+	})
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:         "/stream/",
+		Code:         http.StatusOK,
+		BodyMatch:    "^STREAMED BODY<end>$",
+		HeadersMatch: map[string]string{"X-Streamed": "true"},
+	})
+	assert.Equal(t, []string{coprocessStreamHeaders, coprocessStreamChunk, coprocessStreamEnd}, server.phases)
+
+	server.phases = nil
+	_, _ = ts.Run(t, test.TestCase{
+		Path:         "/stream/",
+		Headers:      map[string]string{"X-Buffer": "true"},
+		Code:         http.StatusOK,
+		BodyMatch:    "^STREAMED BODY$",
+		HeadersMatch: map[string]string{"X-Streamed": "true"},
+	})
+	assert.Equal(t, []string{coprocessStreamHeaders, coprocessStreamBuffer}, server.phases)
+}
@@ -116,6 +116,19 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 	body, _ := ioutil.ReadAll(respBody)
 	defer respBody.Close()
 
+	if tmeta.TemplateData.Conversion.Enabled {
+		converted, contentType, err := convertBody(body, tmeta.TemplateData.Input, tmeta.TemplateData.Conversion)
+		if err != nil {
+			logger.WithError(err).Error("Failed to convert response body")
+			converted = body
+		} else {
+			res.Header.Set(header.ContentType, contentType)
+		}
+
+		setResponseBody(res, *bytes.NewBuffer(converted))
+		return nil
+	}
+
 	// Put into an interface:
 	bodyData := make(map[string]interface{})
 	switch tmeta.TemplateData.Input {
@@ -174,13 +187,17 @@ func (r *ResponseTransformMiddleware) HandleResponse(rw http.ResponseWriter, res
 		logger.WithError(err).Error("Failed to apply template to request")
 	}
 
-	// Re-compress if original upstream response was compressed
+	setResponseBody(res, bodyBuffer)
+
+	return nil
+}
+
+// setResponseBody replaces the response body, re-compressing it if the original upstream response was compressed.
+func setResponseBody(res *http.Response, bodyBuffer bytes.Buffer) {
 	encoding := res.Header.Get("Content-Encoding")
 	bodyBuffer = compressBuffer(bodyBuffer, encoding)
 
 	res.ContentLength = int64(bodyBuffer.Len())
 	res.Header.Set("Content-Length", strconv.Itoa(bodyBuffer.Len()))
 	res.Body = ioutil.NopCloser(&bodyBuffer)
-
-	return nil
 }
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/expression"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// ResponseTransformExpressionMiddleware rewrites response bodies with CEL expressions.
+type ResponseTransformExpressionMiddleware struct {
+	BaseTykResponseHandler
+}
+
+func (h *ResponseTransformExpressionMiddleware) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+func (h *ResponseTransformExpressionMiddleware) Name() string {
+	return "ResponseTransformExpressionMiddleware"
+}
+
+func (h *ResponseTransformExpressionMiddleware) Enabled() bool {
+	for _, version := range h.Spec.VersionData.Versions {
+		for _, meta := range version.ExtendedPaths.TransformExpressionResponse {
+			if !meta.Disabled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *ResponseTransformExpressionMiddleware) Init(c interface{}, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+func (h *ResponseTransformExpressionMiddleware) HandleError(rw http.ResponseWriter, req *http.Request) {
+}
+
+func (h *ResponseTransformExpressionMiddleware) HandleResponse(rw http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	versionInfo, _ := h.Spec.Version(req)
+	versionPaths := h.Spec.RxPaths[versionInfo.Name]
+	found, meta := h.Spec.CheckSpecMatchesStatus(req, versionPaths, TransformedExpressionResponse)
+	if !found {
+		return nil
+	}
+
+	logger := log.WithFields(logrus.Fields{
+		"prefix":      "outbound-transform-expression",
+		"server_name": h.Spec.Proxy.TargetURL,
+		"api_id":      h.Spec.APIID,
+		"path":        req.URL.Path,
+	})
+
+	respBody := respBodyReader(req, res)
+	body, _ := ioutil.ReadAll(respBody)
+	defer respBody.Close()
+
+	// the original body is returned to the client untouched when the transform fails
+	transformed := body
+
+	bodyObj, err := decodeExpressionBody(body)
+	if err != nil {
+		logger.WithError(err).Error("Failed to decode response body")
+		setResponseBody(res, *bytes.NewBuffer(transformed))
+		return nil
+	}
+
+	in := expression.Input{
+		Body:    bodyObj,
+		Headers: flattenHeaders(res.Header),
+		Context: ctxGetData(req),
+		Session: sessionMetaData(ses),
+		Status:  res.StatusCode,
+	}
+
+	if out, err := meta.(*TransformExpressionSpec).Program.Eval(in); err != nil {
+		logger.WithError(err).Error("Failed to transform response body")
+	} else {
+		transformed = out
+		res.Header.Set(header.ContentType, header.ApplicationJSON)
+	}
+
+	setResponseBody(res, *bytes.NewBuffer(transformed))
+	return nil
+}
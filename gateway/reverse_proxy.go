@@ -1354,6 +1354,8 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	inres.StatusCode = res.StatusCode
 	inres.ContentLength = res.ContentLength
 	p.HandleResponse(rw, res, ses)
+	// trailers are read along with the body, such as the grpc-status of gRPC responses
+	inres.Trailer = res.Trailer
 	return ProxyResponse{UpstreamLatency: upstreamLatency, Response: inres}
 }
 
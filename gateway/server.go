@@ -158,6 +158,8 @@ type Gateway struct {
 	policiesMu   sync.RWMutex
 	policiesByID map[string]user.Policy
 
+	graphQLSubscriptions *graphQLSubscriptionRegistry
+
 	dnsCacheManager dnscache.IDnsCacheManager
 
 	consulKVStore kv.Store
@@ -244,6 +246,8 @@ func NewGateway(config config.Config, ctx context.Context) *Gateway {
 
 	gw.policiesByID = map[string]user.Policy{}
 
+	gw.graphQLSubscriptions = newGraphQLSubscriptionRegistry()
+
 	// reload
 	gw.reloadQueue = make(chan func())
 	// only for tests
@@ -570,6 +574,7 @@ func (gw *Gateway) syncPolicies() (count int, err error) {
 	gw.policiesMu.Lock()
 	defer gw.policiesMu.Unlock()
 	if len(pols) > 0 {
+		gw.graphQLSubscriptions.TerminatePolicies(changedPolicies(gw.policiesByID, pols))
 		gw.policiesByID = pols
 	}
 
@@ -699,6 +704,13 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 	r.HandleFunc("/oauth/tokens", gw.oAuthTokensHandler).Methods(http.MethodDelete)
 
 	r.HandleFunc("/schema", gw.schemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/plugins/go", gw.goPluginsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/apis/{apiID}/graphql/trusted-documents", gw.trustedDocumentsHandler).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	r.HandleFunc("/apis/{apiID}/graphql/schema/versions", gw.graphQLSchemaVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/apis/{apiID}/graphql/schema/versions/{version}", gw.graphQLSchemaVersionHandler).Methods(http.MethodGet)
+	r.HandleFunc("/apis/{apiID}/graphql/schema/diff", gw.graphQLSchemaDiffHandler).Methods(http.MethodGet)
+	r.HandleFunc("/graphql/subscriptions", gw.graphQLSubscriptionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/graphql/subscriptions/{subscriptionID}", gw.graphQLSubscriptionHandler).Methods(http.MethodDelete)
 
 	mainLog.Debug("Loaded API Endpoints")
 }
@@ -892,7 +904,14 @@ func (gw *Gateway) createResponseMiddlewareChain(spec *APISpec, responseFuncs []
 		responseMWChain []TykResponseHandler
 		baseHandler     = BaseTykResponseHandler{Spec: spec, Gw: gw}
 	)
+	gw.responseMWAppendEnabled(&responseMWChain, &GRPCWebResponseMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &GRPCTranscodingResponseMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &ResponseSizeLimitMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &ValidateResponse{BaseTykResponseHandler: baseHandler})
 	gw.responseMWAppendEnabled(&responseMWChain, &ResponseTransformMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &ResponseTransformExpressionMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &GraphQLMaskedFieldsMiddleware{BaseTykResponseHandler: baseHandler})
+	gw.responseMWAppendEnabled(&responseMWChain, &ResponseFieldFilterMiddleware{BaseTykResponseHandler: baseHandler})
 
 	headerInjector := &HeaderInjector{BaseTykResponseHandler: baseHandler}
 	headerInjectorAdded := gw.responseMWAppendEnabled(&responseMWChain, headerInjector)
@@ -45,6 +45,9 @@ const (
 
 	// SentinelRateLimitKeyPostfix is appended to the rate limiting key to combine into a sentinel key.
 	SentinelRateLimitKeyPostfix = ".BLOCKED"
+
+	// GraphQLCostRateLimitKeyPostfix is appended to the rate limiting key of APIs rate limiting GraphQL operations by cost.
+	GraphQLCostRateLimitKeyPostfix = "graphql-cost"
 )
 
 // SessionLimiter is the rate limiter for the API, use ForwardMessage() to
@@ -183,19 +186,26 @@ func (l *SessionLimiter) limitRedis(r *http.Request, session *user.SessionState,
 	return l.doRollingWindowWrite(r, session, rateLimiterKey, apiLimit, dryRun)
 }
 
-func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, dryRun bool) bool {
-	currRate := apiLimit.Rate
-	per := apiLimit.Per
-
-	tokenValue := uint(l.drlManager.CurrentTokenValue())
-
-	// DRL will always overflow with more servers on low rates
-	cost := uint(currRate * float64(l.drlManager.RequestTokenValue))
-	if cost < tokenValue {
-		cost = tokenValue
+// drlSuffices checks if the leaky bucket algorithm of the distributed rate limiter suffices for the rate, so
+// there is no need to strain redis at all. That's the case on a single gateway or when the rate is high enough
+// for the gateways.
+func (l *SessionLimiter) drlSuffices(apiLimit *user.APILimit) bool {
+	var n float64
+	if l.drlManager.Servers != nil {
+		n = float64(l.drlManager.Servers.Count())
+	}
+	cost := apiLimit.Rate / apiLimit.Per
+	c := l.config.DRLThreshold
+	if c == 0 {
+		// defaults to 5
+		c = 5
 	}
 
-	userBucket, err := l.bucketStore.Create(bucketKey, cost, time.Duration(per)*time.Second)
+	return n <= 1 || n*c < cost
+}
+
+func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, dryRun bool) bool {
+	userBucket, tokenValue, err := l.drlBucket(bucketKey, apiLimit)
 	if err != nil {
 		log.Error("Failed to create bucket!")
 		return true
@@ -215,6 +225,35 @@ func (l *SessionLimiter) limitDRL(bucketKey string, apiLimit *user.APILimit, dry
 	return false
 }
 
+// limitDRLCost is limitDRL for requests consuming allowance equal to their cost.
+func (l *SessionLimiter) limitDRLCost(bucketKey string, apiLimit *user.APILimit, cost uint) bool {
+	userBucket, tokenValue, err := l.drlBucket(bucketKey, apiLimit)
+	if err != nil {
+		log.Error("Failed to create bucket!")
+		return true
+	}
+
+	_, err = userBucket.Add(tokenValue * cost)
+	return err != nil
+}
+
+// drlBucket returns the bucket of the distributed rate limiter and the token value a request takes from it.
+func (l *SessionLimiter) drlBucket(bucketKey string, apiLimit *user.APILimit) (leakybucket.Bucket, uint, error) {
+	currRate := apiLimit.Rate
+	per := apiLimit.Per
+
+	tokenValue := uint(l.drlManager.CurrentTokenValue())
+
+	// DRL will always overflow with more servers on low rates
+	cost := uint(currRate * float64(l.drlManager.RequestTokenValue))
+	if cost < tokenValue {
+		cost = tokenValue
+	}
+
+	userBucket, err := l.bucketStore.Create(bucketKey, cost, time.Duration(per)*time.Second)
+	return userBucket, tokenValue, err
+}
+
 func (sfr sessionFailReason) String() string {
 	switch sfr {
 	case sessionFailNone:
@@ -334,21 +373,7 @@ func (l *SessionLimiter) ForwardMessage(r *http.Request, session *user.SessionSt
 				return sessionFailRateLimit
 			}
 		default:
-			var n float64
-			if l.drlManager.Servers != nil {
-				n = float64(l.drlManager.Servers.Count())
-			}
-			cost := apiLimit.Rate / apiLimit.Per
-			c := l.config.DRLThreshold
-			if c == 0 {
-				// defaults to 5
-				c = 5
-			}
-
-			if n <= 1 || n*c < cost {
-				// If we have 1 server, there is no need to strain redis at all the leaky
-				// bucket algorithm will suffice.
-
+			if l.drlSuffices(apiLimit) {
 				bucketKey := limiterKey + ":" + session.LastUpdated
 				if useCustomKey {
 					bucketKey = limiterKey
@@ -379,6 +404,57 @@ func (l *SessionLimiter) ForwardMessage(r *http.Request, session *user.SessionSt
 
 }
 
+// ForwardGraphQLCost consumes rate limit allowance equal to the cost of a GraphQL operation, for APIs rate
+// limiting by cost. The rate of the key is the cost allowed per period, counted by the configured rate limiter.
+func (l *SessionLimiter) ForwardGraphQLCost(r *http.Request, session *user.SessionState, rateLimitKey string, useCustomKey bool, api *APISpec, cost int) sessionFailReason {
+	accessDef, allowanceScope, err := GetAccessDefinitionByAPIIDOrSession(session, api)
+	if err != nil {
+		log.WithField("apiID", api.APIID).Debugf("[RATE] %s", err.Error())
+		return sessionFailRateLimit
+	}
+
+	apiLimit := accessDef.Limit
+
+	// If rate is -1 or 0, it means unlimited and no need for rate limiting.
+	if apiLimit.Rate <= 0 || apiLimit.Per <= 0 {
+		return sessionFailNone
+	}
+
+	limiterKey := rate.Prefix(rate.LimiterKey(session, allowanceScope, rateLimitKey, useCustomKey), GraphQLCostRateLimitKeyPostfix)
+
+	if l.limiterStorage == nil || l.drlLimitsCost(&apiLimit) {
+		bucketKey := limiterKey + ":" + session.LastUpdated
+		if useCustomKey {
+			bucketKey = limiterKey
+		}
+
+		if l.limitDRLCost(bucketKey, &apiLimit, uint(cost)) {
+			return sessionFailRateLimit
+		}
+		return sessionFailNone
+	}
+
+	limiter := rate.CostLimiter(l.config, l.limiterStorage)
+	if err := limiter(r.Context(), limiterKey, int64(cost), apiLimit.Rate, apiLimit.Per); err != nil {
+		if errors.Is(err, rate.ErrLimitExhausted) {
+			return sessionFailRateLimit
+		}
+		log.WithError(err).Error("error consuming GraphQL cost rate limit")
+	}
+
+	return sessionFailNone
+}
+
+// drlLimitsCost checks if the cost of GraphQL operations is rate limited by the distributed rate limiter, which
+// is the case when no other rate limiter is configured and the rate is high enough for the gateways.
+func (l *SessionLimiter) drlLimitsCost(apiLimit *user.APILimit) bool {
+	if _, ok := rate.LimiterKind(l.config); ok || l.config.EnableSentinelRateLimiter || l.config.EnableRedisRollingLimiter {
+		return false
+	}
+
+	return l.drlSuffices(apiLimit)
+}
+
 // RedisQuotaExceeded returns true if the request should be blocked as over quota.
 func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, session *user.SessionState, quotaKey, scope string, limit *user.APILimit, store storage.Handler, hashKeys bool) bool {
 	logger := log.WithFields(logrus.Fields{
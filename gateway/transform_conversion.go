@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/clbanning/mxj"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	// mxjAttrPrefix is the prefix mxj uses for XML attributes in decoded maps.
+	mxjAttrPrefix = "-"
+
+	defaultConversionRootElement = "root"
+	defaultConversionArrayItem   = "item"
+)
+
+// convertBody converts a JSON body to XML or a XML body to JSON, depending on the input type.
+// It returns the converted body and the content type that matches it.
+func convertBody(body []byte, input apidef.RequestInputType, conv apidef.BodyConversion) ([]byte, string, error) {
+	switch input {
+	case apidef.RequestJSON:
+		out, err := convertJSONToXML(body, conv)
+		return out, header.ApplicationXML, err
+	case apidef.RequestXML:
+		out, err := convertXMLToJSON(body, conv)
+		return out, header.ApplicationJSON, err
+	default:
+		return nil, "", fmt.Errorf("unsupported request input type: %v", input)
+	}
+}
+
+func convertJSONToXML(body []byte, conv apidef.BodyConversion) ([]byte, error) {
+	var data interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		// json.Number keeps large integers from being rendered in exponent notation
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+		}
+	}
+
+	var root map[string]interface{}
+	switch v := renameAttributeKeys(data, conversionAttrPrefix(conv), mxjAttrPrefix).(type) {
+	case map[string]interface{}:
+		root = v
+	case nil:
+		root = map[string]interface{}{}
+	case []interface{}:
+		root = map[string]interface{}{defaultConversionArrayItem: v}
+	default:
+		root = map[string]interface{}{"#text": v}
+	}
+
+	for prefix, uri := range conv.Namespaces {
+		attr := mxjAttrPrefix + "xmlns"
+		if prefix != "" {
+			attr += ":" + prefix
+		}
+		root[attr] = uri
+	}
+
+	rootElement := conv.RootElement
+	if rootElement == "" {
+		rootElement = defaultConversionRootElement
+	}
+
+	out, err := mxj.Map(root).Xml(rootElement)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+func convertXMLToJSON(body []byte, conv apidef.BodyConversion) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return []byte("{}"), nil
+	}
+
+	mxj.XmlCharsetReader = WrappedCharsetReader
+	m, err := mxj.NewMapXml(body, conv.CastValues)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling XML: %w", err)
+	}
+
+	var data interface{} = map[string]interface{}(m)
+	dropNamespaceDeclarations(data, conv.Namespaces)
+
+	for _, path := range conv.ArrayPaths {
+		forceArrayAtPath(data, strings.Split(path, "."))
+	}
+
+	data = renameAttributeKeys(data, mxjAttrPrefix, conversionAttrPrefix(conv))
+
+	return json.Marshal(data)
+}
+
+func conversionAttrPrefix(conv apidef.BodyConversion) string {
+	if conv.AttributePrefix == "" {
+		return mxjAttrPrefix
+	}
+	return conv.AttributePrefix
+}
+
+// renameAttributeKeys replaces the attribute prefix of all object keys, recursively.
+func renameAttributeKeys(data interface{}, from, to string) interface{} {
+	if from == to {
+		return data
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if strings.HasPrefix(key, from) {
+				key = to + strings.TrimPrefix(key, from)
+			}
+			renamed[key] = renameAttributeKeys(value, from, to)
+		}
+		return renamed
+	case []interface{}:
+		for i := range v {
+			v[i] = renameAttributeKeys(v[i], from, to)
+		}
+	}
+
+	return data
+}
+
+// dropNamespaceDeclarations removes xmlns attributes. mxj strips namespace prefixes from element
+// names, so the declarations carry no information for JSON consumers.
+func dropNamespaceDeclarations(data interface{}, namespaces map[string]string) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		delete(v, mxjAttrPrefix+"xmlns")
+		for prefix := range namespaces {
+			if prefix != "" {
+				delete(v, mxjAttrPrefix+prefix)
+			}
+		}
+		for _, value := range v {
+			dropNamespaceDeclarations(value, namespaces)
+		}
+	case []interface{}:
+		for _, value := range v {
+			dropNamespaceDeclarations(value, namespaces)
+		}
+	}
+}
+
+// forceArrayAtPath wraps the value found at path into an array unless it already is one.
+// Arrays found along the path are traversed element by element.
+func forceArrayAtPath(data interface{}, path []string) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return
+		}
+
+		value, ok := v[path[0]]
+		if !ok {
+			return
+		}
+
+		if len(path) == 1 {
+			if _, isArray := value.([]interface{}); !isArray {
+				v[path[0]] = []interface{}{value}
+			}
+			return
+		}
+
+		forceArrayAtPath(value, path[1:])
+	case []interface{}:
+		for _, value := range v {
+			forceArrayAtPath(value, path)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestConvertBody(t *testing.T) {
+	t.Run("json to xml", func(t *testing.T) {
+		conv := apidef.BodyConversion{
+			Enabled:     true,
+			RootElement: "soap:Envelope",
+			Namespaces:  map[string]string{"soap": "http://schemas.xmlsoap.org/soap/envelope/"},
+		}
+
+		out, contentType, err := convertBody([]byte(`{"id":12345678901,"user":{"-lang":"en","name":"Tyk"}}`), apidef.RequestJSON, conv)
+		require.NoError(t, err)
+		assert.Equal(t, header.ApplicationXML, contentType)
+		assert.Contains(t, string(out), `<?xml version="1.0" encoding="UTF-8"?>`)
+		assert.Contains(t, string(out), `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`)
+		assert.Contains(t, string(out), `<id>12345678901</id>`)
+		assert.Contains(t, string(out), `<user lang="en"><name>Tyk</name></user>`)
+	})
+
+	t.Run("json array to xml", func(t *testing.T) {
+		out, _, err := convertBody([]byte(`[1,2]`), apidef.RequestJSON, apidef.BodyConversion{Enabled: true})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `<root><item>1</item><item>2</item></root>`)
+	})
+
+	t.Run("custom attribute prefix", func(t *testing.T) {
+		out, _, err := convertBody([]byte(`{"user":{"@lang":"en"}}`), apidef.RequestJSON, apidef.BodyConversion{Enabled: true, AttributePrefix: "@"})
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `<user lang="en"`)
+
+		out, _, err = convertBody([]byte(`<user lang="en"><name>Tyk</name></user>`), apidef.RequestXML, apidef.BodyConversion{Enabled: true, AttributePrefix: "@"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"user":{"@lang":"en","name":"Tyk"}}`, string(out))
+	})
+
+	t.Run("xml to json", func(t *testing.T) {
+		in := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+	<soap:Body>
+		<items><item><id>1</id><active>true</active></item></items>
+	</soap:Body>
+</soap:Envelope>`
+
+		conv := apidef.BodyConversion{
+			Enabled:    true,
+			Namespaces: map[string]string{"soap": "http://schemas.xmlsoap.org/soap/envelope/"},
+			ArrayPaths: []string{"Envelope.Body.items.item"},
+			CastValues: true,
+		}
+
+		out, contentType, err := convertBody([]byte(in), apidef.RequestXML, conv)
+		require.NoError(t, err)
+		assert.Equal(t, header.ApplicationJSON, contentType)
+		assert.JSONEq(t, `{"Envelope":{"Body":{"items":{"item":[{"id":1,"active":true}]}}}}`, string(out))
+
+		conv.CastValues = false
+		out, _, err = convertBody([]byte(in), apidef.RequestXML, conv)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Envelope":{"Body":{"items":{"item":[{"id":"1","active":"true"}]}}}}`, string(out))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, _, err := convertBody([]byte(`{`), apidef.RequestJSON, apidef.BodyConversion{Enabled: true})
+		assert.Error(t, err)
+
+		_, _, err = convertBody([]byte(`<a>`), apidef.RequestXML, apidef.BodyConversion{Enabled: true})
+		assert.Error(t, err)
+	})
+}
+
+func TestTransformBodyConversion(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ContentType, header.ApplicationXML)
+		if r.URL.Path == "/soap" {
+			// echo the converted request body
+			_, _ = io.Copy(w, r.Body)
+			return
+		}
+		_, _ = w.Write([]byte(`<response><status>ok</status></response>`))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.Transform = []apidef.TemplateMeta{{
+				Path:   "/soap",
+				Method: http.MethodPost,
+				TemplateData: apidef.TemplateData{
+					Input:      apidef.RequestJSON,
+					Conversion: apidef.BodyConversion{Enabled: true, RootElement: "request"},
+				},
+			}}
+			v.ExtendedPaths.TransformResponse = []apidef.TemplateMeta{{
+				Path:   "/xml",
+				Method: http.MethodGet,
+				TemplateData: apidef.TemplateData{
+					Input:      apidef.RequestXML,
+					Conversion: apidef.BodyConversion{Enabled: true},
+				},
+			}}
+		})
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodPost, Path: "/soap", Data: `{"name":"Tyk"}`, Code: http.StatusOK, BodyMatch: `<request><name>Tyk</name></request>`},
+		{Method: http.MethodGet, Path: "/xml", Code: http.StatusOK, BodyMatch: `{"response":{"status":"ok"}}`, HeadersMatch: map[string]string{header.ContentType: header.ApplicationJSON}},
+	}...)
+}
@@ -5,20 +5,12 @@ package goplugin
 
 import (
 	"errors"
-	"plugin"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
 )
 
 func GetAnalyticsHandler(path string, symbol string) (func(record *analytics.AnalyticsRecord), error) {
-	// try to load plugin
-	loadedPlugin, err := plugin.Open(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// try to lookup function symbol
-	funcSymbol, err := loadedPlugin.Lookup(symbol)
+	version, funcSymbol, err := getSymbol(path, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -29,5 +21,8 @@ func GetAnalyticsHandler(path string, symbol string) (func(record *analytics.Ana
 		return nil, errors.New("could not cast function symbol to AnalyticsPlugin function")
 	}
 
-	return pluginHandler, nil
+	return func(record *analytics.AnalyticsRecord) {
+		defer version.track()()
+		pluginHandler(record)
+	}, nil
 }
@@ -9,24 +9,41 @@ import (
 	"plugin"
 )
 
-func GetSymbol(modulePath string, symbol string) (interface{}, error) {
-	// try to load plugin
+func openPlugin(modulePath string) (symbolLookup, error) {
 	loadedPlugin, err := plugin.Open(modulePath)
 	if err != nil {
 		return nil, err
 	}
 
+	return func(symbol string) (interface{}, error) {
+		return loadedPlugin.Lookup(symbol)
+	}, nil
+}
+
+// getSymbol looks up a symbol of the plugin, loading new builds of the file alongside the previous ones.
+func getSymbol(modulePath string, symbol string) (*pluginVersion, interface{}, error) {
+	// try to load plugin
+	version, err := loaded.open(modulePath, openPlugin)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// try to lookup function symbol
-	funcSymbol, err := loadedPlugin.Lookup(symbol)
+	funcSymbol, err := loaded.lookupSymbol(version, symbol)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return funcSymbol, nil
+	return version, funcSymbol, nil
+}
+
+func GetSymbol(modulePath string, symbol string) (interface{}, error) {
+	_, funcSymbol, err := getSymbol(modulePath, symbol)
+	return funcSymbol, err
 }
 
 func GetHandler(modulePath string, symbol string) (http.HandlerFunc, error) {
-	funcSymbol, err := GetSymbol(modulePath, symbol)
+	version, funcSymbol, err := getSymbol(modulePath, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -37,11 +54,14 @@ func GetHandler(modulePath string, symbol string) (http.HandlerFunc, error) {
 		return nil, errors.New("could not cast function symbol to http.HandlerFunc")
 	}
 
-	return pluginHandler, nil
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer version.track()()
+		pluginHandler(w, r)
+	}, nil
 }
 
 func GetResponseHandler(modulePath string, symbol string) (func(rw http.ResponseWriter, res *http.Response, req *http.Request), error) {
-	funcSymbol, err := GetSymbol(modulePath, symbol)
+	version, funcSymbol, err := getSymbol(modulePath, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -52,5 +72,26 @@ func GetResponseHandler(modulePath string, symbol string) (func(rw http.Response
 		return nil, errors.New("could not cast function symbol to TykResponseHandler")
 	}
 
-	return respPluginHandler, nil
+	return func(rw http.ResponseWriter, res *http.Response, req *http.Request) {
+		defer version.track()()
+		respPluginHandler(rw, res, req)
+	}, nil
+}
+
+func GetResponseChunkHandler(modulePath string, symbol string) (func(res *http.Response, req *http.Request, chunk []byte, last bool) ([]byte, error), error) {
+	version, funcSymbol, err := getSymbol(modulePath, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// try to cast symbol to real func
+	chunkHandler, ok := funcSymbol.(func(res *http.Response, req *http.Request, chunk []byte, last bool) ([]byte, error))
+	if !ok {
+		return nil, errors.New("could not cast function symbol to TykResponseChunkHandler")
+	}
+
+	return func(res *http.Response, req *http.Request, chunk []byte, last bool) ([]byte, error) {
+		defer version.track()()
+		return chunkHandler(res, req, chunk, last)
+	}, nil
 }
@@ -23,3 +23,7 @@ func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 func GetResponseHandler(path string, symbol string) (func(rw http.ResponseWriter, res *http.Response, req *http.Request), error) {
 	return nil, fmt.Errorf(errNotImplemented, "GetResponseHandler")
 }
+
+func GetResponseChunkHandler(path string, symbol string) (func(res *http.Response, req *http.Request, chunk []byte, last bool) ([]byte, error), error) {
+	return nil, fmt.Errorf(errNotImplemented, "GetResponseChunkHandler")
+}
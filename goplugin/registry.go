@@ -0,0 +1,222 @@
+package goplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// versionLength is the number of hex characters of the file checksum used as plugin version.
+const versionLength = 12
+
+// ErrPluginPathLoaded is returned when a new build of a plugin has the plugin path of a loaded build. The Go runtime
+// identifies plugins by their plugin path, so each build has to set a unique one, e.g. with
+// `go build -buildmode=plugin -ldflags=-pluginpath=<name>-<version>`.
+var ErrPluginPathLoaded = errors.New("a plugin with the same plugin path is already loaded, " +
+	"build each version with a unique -ldflags=-pluginpath")
+
+// LoadedPlugin describes a version of a Go plugin loaded by the gateway. Go plugins can't be unloaded,
+// so every version stays in memory until the gateway restarts.
+type LoadedPlugin struct {
+	// Path is the plugin file the version was loaded for.
+	Path string `json:"path"`
+	// Version is the prefix of the SHA-256 checksum of the plugin file.
+	Version string `json:"version"`
+	// LoadedPath is the file the version was opened from, a versioned copy of Path for new builds.
+	LoadedPath string    `json:"loaded_path"`
+	LoadedAt   time.Time `json:"loaded_at"`
+	Symbols    []string  `json:"symbols"`
+	// Active is set for the latest version of Path, older versions only serve requests in flight.
+	Active   bool  `json:"active"`
+	InFlight int64 `json:"in_flight"`
+	// FileSize is the size of the plugin file mapped in memory.
+	FileSize int64 `json:"file_size"`
+	// HeapDelta is the heap growth measured while opening the plugin, including its init functions.
+	HeapDelta int64 `json:"heap_delta"`
+}
+
+// symbolLookup looks up a symbol of an opened plugin.
+type symbolLookup func(symbol string) (interface{}, error)
+
+type pluginVersion struct {
+	LoadedPlugin
+
+	lookup   symbolLookup
+	inflight atomic.Int64
+}
+
+// track counts a call to the version, returning the func to call once done.
+func (v *pluginVersion) track() func() {
+	v.inflight.Add(1)
+	return func() {
+		v.inflight.Add(-1)
+	}
+}
+
+// registry keeps the plugin versions loaded by the gateway. Plugins are cached by file path, so a new build
+// written over a loaded plugin file is opened from a versioned copy, alongside the previous version.
+// The Go runtime only opens the new build if its plugin path differs from the loaded builds, see ErrPluginPathLoaded.
+type registry struct {
+	mu sync.Mutex
+	// dir holds the versioned copies, a private temporary directory is created on first use when empty.
+	dir      string
+	versions map[string]*pluginVersion // by file checksum
+	active   map[string]*pluginVersion // by plugin path
+	opened   map[string]bool           // files already opened
+}
+
+func newRegistry(dir string) *registry {
+	return &registry{
+		dir:      dir,
+		versions: map[string]*pluginVersion{},
+		active:   map[string]*pluginVersion{},
+		opened:   map[string]bool{},
+	}
+}
+
+var loaded = newRegistry("")
+
+// LoadedPlugins returns the plugin versions loaded by the gateway, sorted by path and load time.
+func LoadedPlugins() []LoadedPlugin {
+	return loaded.list()
+}
+
+// open returns the version of the plugin file at path, opening it unless the same build was already loaded.
+func (r *registry) open(path string, open func(string) (symbolLookup, error)) (*pluginVersion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.versions[checksum]; ok {
+		if previous := r.active[path]; previous != v {
+			log.WithField("path", path).Infof("Switching Go-plugin to loaded version %s", v.Version)
+		}
+		r.active[path] = v
+		return v, nil
+	}
+
+	version := checksum[:versionLength]
+	loadedPath := path
+	if r.opened[path] {
+		if loadedPath, err = r.copyVersion(path, version, data); err != nil {
+			return nil, err
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	lookup, err := open(loadedPath)
+	if err != nil {
+		if loadedPath != path && strings.Contains(err.Error(), "plugin already loaded") {
+			return nil, fmt.Errorf("%w: %s", ErrPluginPathLoaded, path)
+		}
+		return nil, err
+	}
+
+	runtime.ReadMemStats(&after)
+
+	v := &pluginVersion{
+		LoadedPlugin: LoadedPlugin{
+			Path:       path,
+			Version:    version,
+			LoadedPath: loadedPath,
+			LoadedAt:   time.Now(),
+			FileSize:   int64(len(data)),
+		},
+		lookup: lookup,
+	}
+	if after.HeapAlloc > before.HeapAlloc {
+		v.HeapDelta = int64(after.HeapAlloc - before.HeapAlloc)
+	}
+
+	if previous, ok := r.active[path]; ok {
+		log.WithField("path", path).Infof("Loaded Go-plugin version %s, version %s will stay in memory",
+			version, previous.Version)
+	}
+
+	r.opened[path] = true
+	r.opened[loadedPath] = true
+	r.versions[checksum] = v
+	r.active[path] = v
+	return v, nil
+}
+
+// copyVersion writes the plugin build to a file named after its version, so it's not served from the cache.
+func (r *registry) copyVersion(path, version string, data []byte) (string, error) {
+	if r.dir == "" {
+		dir, err := os.MkdirTemp("", "tyk-goplugins-")
+		if err != nil {
+			return "", err
+		}
+		r.dir = dir
+	}
+
+	if err := os.MkdirAll(r.dir, 0o700); err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ".so")
+	versionPath := filepath.Join(r.dir, fmt.Sprintf("%s_%s.so", name, version))
+	if err := os.WriteFile(versionPath, data, 0o600); err != nil {
+		return "", err
+	}
+	return versionPath, nil
+}
+
+// lookupSymbol looks up a symbol of the version, recording it for the loaded plugins report.
+func (r *registry) lookupSymbol(v *pluginVersion, symbol string) (interface{}, error) {
+	funcSymbol, err := v.lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range v.Symbols {
+		if s == symbol {
+			return funcSymbol, nil
+		}
+	}
+	v.Symbols = append(v.Symbols, symbol)
+	return funcSymbol, nil
+}
+
+func (r *registry) list() []LoadedPlugin {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plugins := make([]LoadedPlugin, 0, len(r.versions))
+	for _, v := range r.versions {
+		p := v.LoadedPlugin
+		p.Symbols = append([]string(nil), v.Symbols...)
+		p.Active = r.active[v.Path] == v
+		p.InFlight = v.inflight.Load()
+		plugins = append(plugins, p)
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		if plugins[i].Path != plugins[j].Path {
+			return plugins[i].Path < plugins[j].Path
+		}
+		return plugins[i].LoadedAt.Before(plugins[j].LoadedAt)
+	})
+	return plugins
+}
@@ -0,0 +1,136 @@
+package goplugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOpener records the opened files, serving symbols holding the content of the opened file.
+type fakeOpener struct {
+	opened []string
+}
+
+func (o *fakeOpener) open(path string) (symbolLookup, error) {
+	o.opened = append(o.opened, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(symbol string) (interface{}, error) {
+		if symbol != "Handler" {
+			return nil, errors.New("symbol not found")
+		}
+		return string(data), nil
+	}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.so")
+	r := newRegistry(filepath.Join(dir, "versions"))
+	opener := &fakeOpener{}
+
+	build := func(content string) *pluginVersion {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(pluginPath, []byte(content), 0o600))
+		v, err := r.open(pluginPath, opener.open)
+		require.NoError(t, err)
+		return v
+	}
+
+	v1 := build("v1")
+	assert.Equal(t, pluginPath, v1.LoadedPath)
+	assert.Len(t, v1.Version, versionLength)
+	assert.Equal(t, int64(2), v1.FileSize)
+
+	t.Run("same build", func(t *testing.T) {
+		assert.Same(t, v1, build("v1"))
+		assert.Equal(t, []string{pluginPath}, opener.opened)
+	})
+
+	v2 := build("v2")
+
+	t.Run("new build", func(t *testing.T) {
+		assert.NotEqual(t, v1.Version, v2.Version)
+		assert.Equal(t, filepath.Join(dir, "versions", "plugin_"+v2.Version+".so"), v2.LoadedPath)
+		assert.Equal(t, []string{pluginPath, v2.LoadedPath}, opener.opened)
+
+		symbol, err := r.lookupSymbol(v2, "Handler")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", symbol)
+
+		_, err = r.lookupSymbol(v2, "Missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		done := v1.track()
+
+		plugins := r.list()
+		require.Len(t, plugins, 2)
+		assert.Equal(t, v1.Version, plugins[0].Version)
+		assert.False(t, plugins[0].Active)
+		assert.Equal(t, int64(1), plugins[0].InFlight)
+		assert.Equal(t, v2.Version, plugins[1].Version)
+		assert.True(t, plugins[1].Active)
+		assert.Equal(t, []string{"Handler"}, plugins[1].Symbols)
+
+		done()
+		assert.Zero(t, r.list()[0].InFlight)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		assert.Same(t, v1, build("v1"))
+		assert.Len(t, opener.opened, 2)
+
+		plugins := r.list()
+		assert.True(t, plugins[0].Active)
+		assert.False(t, plugins[1].Active)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := r.open(filepath.Join(dir, "missing.so"), opener.open)
+		assert.Error(t, err)
+	})
+}
+
+func TestRegistry_PluginPathLoaded(t *testing.T) {
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "plugin.so")
+	r := newRegistry("")
+	t.Cleanup(func() {
+		os.RemoveAll(r.dir)
+	})
+
+	opened := false
+	open := func(path string) (symbolLookup, error) {
+		if opened {
+			return nil, errors.New(`plugin.Open("` + path + `"): plugin already loaded`)
+		}
+		opened = true
+		return nil, nil
+	}
+
+	require.NoError(t, os.WriteFile(pluginPath, []byte("v1"), 0o600))
+	_, err := r.open(pluginPath, open)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(pluginPath, []byte("v2"), 0o600))
+	_, err = r.open(pluginPath, open)
+	assert.ErrorIs(t, err, ErrPluginPathLoaded)
+
+	// versions are copied to a private directory
+	assert.True(t, strings.HasPrefix(filepath.Base(r.dir), "tyk-goplugins-"))
+	info, err := os.Stat(r.dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+}
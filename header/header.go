@@ -14,6 +14,7 @@ const (
 	Expires                 = "Expires"
 	Connection              = "Connection"
 	WWWAuthenticate         = "WWW-Authenticate"
+	Allow                   = "Allow"
 )
 
 const (
@@ -21,6 +22,9 @@ const (
 	ApplicationJSON = "application/json"
 	ApplicationXML  = "application/xml"
 	TextXML         = "text/xml"
+
+	ApplicationGraphQLResponseJSON = "application/graphql-response+json"
+	MultipartMixed                 = "multipart/mixed"
 )
 
 const (
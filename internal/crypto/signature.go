@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/TykTechnologies/goverify"
+)
+
+// ErrInvalidSignature is returned when a signature doesn't match the signed data.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// LoadSigner loads a PEM encoded RSA, ECDSA or Ed25519 private key from path.
+// RSA and ECDSA keys sign the SHA-256 digest of the data, Ed25519 keys sign the data itself,
+// producing the same signatures as `cosign sign-blob`.
+func LoadSigner(path string) (goverify.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return goverify.LoadPrivateKeyFromString(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsaSigner{k}, nil
+	case ed25519.PrivateKey:
+		return ed25519Signer{k}, nil
+	}
+	return goverify.LoadPrivateKeyFromString(string(data))
+}
+
+// LoadVerifier loads a PEM encoded RSA, ECDSA or Ed25519 public key from path,
+// verifying signatures created by LoadSigner keys.
+func LoadVerifier(path string) (goverify.Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	if block.Type == "PUBLIC KEY" {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			return ecdsaVerifier{k}, nil
+		case ed25519.PublicKey:
+			return ed25519Verifier{k}, nil
+		}
+	}
+	return goverify.LoadPublicKeyFromString(string(data))
+}
+
+type ecdsaSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s ecdsaSigner) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+}
+
+type ecdsaVerifier struct {
+	key *ecdsa.PublicKey
+}
+
+func (v ecdsaVerifier) Verify(data []byte, sig []byte) error {
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(v.key, digest[:], sig) {
+		return fmt.Errorf("ecdsa: %w", ErrInvalidSignature)
+	}
+	return nil
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (s ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, data), nil
+}
+
+type ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+func (v ed25519Verifier) Verify(data []byte, sig []byte) error {
+	if !ed25519.Verify(v.key, data, sig) {
+		return fmt.Errorf("ed25519: %w", ErrInvalidSignature)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatures(t *testing.T) {
+	data := []byte("bundle contents")
+
+	for _, keyType := range []string{"rsa", "ecdsa", "ed25519"} {
+		t.Run(keyType, func(t *testing.T) {
+			privatePath, publicPath := GenerateSigningKeyPair(t, t.TempDir(), keyType)
+
+			signer, err := LoadSigner(privatePath)
+			require.NoError(t, err)
+			verifier, err := LoadVerifier(publicPath)
+			require.NoError(t, err)
+
+			sig, err := signer.Sign(data)
+			require.NoError(t, err)
+
+			assert.NoError(t, verifier.Verify(data, sig))
+			assert.Error(t, verifier.Verify([]byte("tampered contents"), sig))
+		})
+	}
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := LoadVerifier("testdata/missing.pub")
+		assert.Error(t, err)
+	})
+}
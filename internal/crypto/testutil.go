@@ -2,6 +2,10 @@ package crypto
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,6 +14,8 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -251,3 +257,41 @@ func decodeRootCertAndKey(rootCertPEM, rootKeyPEM []byte) (*x509.Certificate, *r
 
 	return rootCert, rootKey, nil
 }
+
+// GenerateSigningKeyPair generates a private and public key of the given type ("rsa", "ecdsa" or "ed25519")
+// for testing purposes and writes them as PEM files to dir.
+//
+// Returns:
+// - string: The path of the private key, usable with LoadSigner.
+// - string: The path of the public key, usable with LoadVerifier.
+func GenerateSigningKeyPair(tb testing.TB, dir, keyType string) (string, string) {
+	tb.Helper()
+
+	var (
+		privateKey crypto.Signer
+		err        error
+	)
+	switch keyType {
+	case "rsa":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ecdsa":
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		tb.Fatalf("unsupported key type %q", keyType)
+	}
+	assert.NoError(tb, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(tb, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	assert.NoError(tb, err)
+
+	privatePath := filepath.Join(dir, keyType+".key")
+	publicPath := filepath.Join(dir, keyType+".pub")
+	assert.NoError(tb, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600))
+	assert.NoError(tb, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600))
+
+	return privatePath, publicPath
+}
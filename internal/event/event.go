@@ -40,6 +40,8 @@ const (
 	TokenUpdated Event = "TokenUpdated"
 	// TokenDeleted is the event triggered when a token is deleted.
 	TokenDeleted Event = "TokenDeleted"
+	// ResponseValidationFailed is the event triggered when an upstream response does not match the OAS response definitions.
+	ResponseValidationFailed Event = "ResponseValidationFailed"
 )
 
 // Rate limiter events
@@ -0,0 +1,141 @@
+// Package expression implements the CEL based body transformation engine.
+//
+// Expressions are compiled once, when the API definition is loaded, and
+// evaluated on every matching request or response. The result of an
+// expression replaces the body and is serialised as JSON.
+package expression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Variables available to expressions.
+const (
+	// VarBody holds the decoded JSON body, or null when the body is empty.
+	VarBody = "body"
+	// VarHeaders holds the request headers, or the response headers for response transforms.
+	VarHeaders = "headers"
+	// VarContext holds the request context variables.
+	VarContext = "context"
+	// VarSession holds the metadata of the session that authenticated the request.
+	VarSession = "session"
+	// VarStatus holds the upstream status code for response transforms, zero otherwise.
+	VarStatus = "status"
+)
+
+// costLimit bounds the work a single evaluation is allowed to do.
+const costLimit = 1000000
+
+// ErrEmptyExpression is returned when compiling an empty expression.
+var ErrEmptyExpression = errors.New("expression is empty")
+
+var structValueType = reflect.TypeOf(&structpb.Value{})
+
+// Input carries the request or response data an expression is evaluated against.
+type Input struct {
+	Body    interface{}
+	Headers map[string]string
+	Context map[string]interface{}
+	Session map[string]interface{}
+	Status  int
+}
+
+// Program is a compiled expression, safe for concurrent use.
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+// Source returns the expression the program was compiled from.
+func (p *Program) Source() string {
+	return p.source
+}
+
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(VarBody, cel.DynType),
+		cel.Variable(VarHeaders, cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable(VarContext, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(VarSession, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(VarStatus, cel.IntType),
+		ext.Strings(),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Lists(),
+		ext.Sets(),
+	)
+}
+
+// Compile parses and type-checks an expression. Errors carry the position of the
+// offending token so they can be reported back to the API author.
+func Compile(source string) (*Program, error) {
+	if source == "" {
+		return nil, ErrEmptyExpression
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %w", issues.Err())
+	}
+
+	program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize), cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	return &Program{source: source, program: program}, nil
+}
+
+// Eval evaluates the program against in and returns the result encoded as JSON.
+func (p *Program) Eval(in Input) ([]byte, error) {
+	activation := map[string]interface{}{
+		VarBody:    in.Body,
+		VarHeaders: nonNilStrings(in.Headers),
+		VarContext: nonNil(in.Context),
+		VarSession: nonNil(in.Session),
+		VarStatus:  in.Status,
+	}
+
+	out, _, err := p.program.Eval(activation)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating expression: %w", err)
+	}
+
+	if types.IsUnknownOrError(out) {
+		return nil, fmt.Errorf("error evaluating expression: %v", out)
+	}
+
+	value, err := out.ConvertToNative(structValueType)
+	if err != nil {
+		return nil, fmt.Errorf("expression result is not JSON serialisable: %w", err)
+	}
+
+	return json.Marshal(value.(*structpb.Value).AsInterface())
+}
+
+func nonNil(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+func nonNilStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
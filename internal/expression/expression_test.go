@@ -0,0 +1,76 @@
+package expression_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/expression"
+)
+
+func TestCompile(t *testing.T) {
+	_, err := expression.Compile("")
+	assert.ErrorIs(t, err, expression.ErrEmptyExpression)
+
+	_, err = expression.Compile(`{"a": body.`)
+	assert.ErrorContains(t, err, "invalid expression")
+
+	_, err = expression.Compile(`unknown_var.field`)
+	assert.ErrorContains(t, err, "undeclared reference to 'unknown_var'")
+
+	p, err := expression.Compile(`body`)
+	require.NoError(t, err)
+	assert.Equal(t, "body", p.Source())
+}
+
+func TestProgram_Eval(t *testing.T) {
+	in := expression.Input{
+		Body:    map[string]interface{}{"user": map[string]interface{}{"name": "tyk", "age": 10.0}},
+		Headers: map[string]string{"X-Tenant": "acme"},
+		Context: map[string]interface{}{"request_id": "abc"},
+		Session: map[string]interface{}{"tier": "gold"},
+		Status:  201,
+	}
+
+	testCases := []struct {
+		name   string
+		expr   string
+		result string
+	}{
+		{"reshape body", `{"name": body.user.name.upperAscii(), "adult": body.user.age >= 18.0}`, `{"name":"TYK","adult":false}`},
+		{"headers", `{"tenant": headers["X-Tenant"]}`, `{"tenant":"acme"}`},
+		{"context and session", `{"id": context.request_id, "tier": session.tier}`, `{"id":"abc","tier":"gold"}`},
+		{"status", `{"created": status == 201}`, `{"created":true}`},
+		{"optional session field", `{"plan": "plan" in session ? session.plan : "free"}`, `{"plan":"free"}`},
+		{"list", `[1, 2].map(x, x * 2)`, `[2,4]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := expression.Compile(tc.expr)
+			require.NoError(t, err)
+
+			out, err := p.Eval(in)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.result, string(out))
+		})
+	}
+
+	t.Run("runtime error", func(t *testing.T) {
+		p, err := expression.Compile(`body.missing.field`)
+		require.NoError(t, err)
+
+		_, err = p.Eval(in)
+		assert.ErrorContains(t, err, "error evaluating expression")
+	})
+
+	t.Run("nil input", func(t *testing.T) {
+		p, err := expression.Compile(`{"has_body": body != null, "headers": size(headers)}`)
+		require.NoError(t, err)
+
+		out, err := p.Eval(expression.Input{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"has_body":false,"headers":0}`, string(out))
+	})
+}
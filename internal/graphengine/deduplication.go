@@ -0,0 +1,152 @@
+package graphengine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// perRequestHeaders are unique to each upstream request and don't make requests different.
+var perRequestHeaders = map[string]bool{
+	"Traceparent":    true,
+	"Tracestate":     true,
+	"X-Request-Id":   true,
+	"Content-Length": true,
+}
+
+type deduplicationContextKey struct{}
+
+// withRequestDeduplication marks the upstream requests of the execution as safe to deduplicate. Only the requests
+// made to resolve queries are, mutations must reach the upstream once per operation.
+func withRequestDeduplication(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deduplicationContextKey{}, true)
+}
+
+func requestDeduplicationEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(deduplicationContextKey{}).(bool)
+	return enabled
+}
+
+// inFlightRequests deduplicates identical upstream requests: while a request is in flight, the identical requests
+// wait for its response instead of being sent to the upstream.
+type inFlightRequests struct {
+	mu       sync.Mutex
+	requests map[string]*inFlightRequest
+}
+
+type inFlightRequest struct {
+	done      chan struct{}
+	followers int
+	response  *http.Response
+	body      []byte
+	err       error
+}
+
+func newInFlightRequests() *inFlightRequests {
+	return &inFlightRequests{requests: make(map[string]*inFlightRequest)}
+}
+
+func (f *inFlightRequests) roundTrip(next http.RoundTripper, request *http.Request) (*http.Response, error) {
+	if !requestDeduplicationEnabled(request.Context()) {
+		return next.RoundTrip(request)
+	}
+
+	key, err := inFlightRequestKey(request)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	inFlight, ok := f.requests[key]
+	if ok {
+		inFlight.followers++
+	} else {
+		inFlight = &inFlightRequest{done: make(chan struct{})}
+		f.requests[key] = inFlight
+		// the upstream request is shared, it must not fail when the request that sent it is canceled
+		go f.send(next, key, inFlight, request.WithContext(context.WithoutCancel(request.Context())))
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-inFlight.done:
+		return inFlight.responseFor(request)
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	}
+}
+
+func (f *inFlightRequests) send(next http.RoundTripper, key string, inFlight *inFlightRequest, request *http.Request) {
+	inFlight.response, inFlight.err = next.RoundTrip(request)
+	if inFlight.err == nil {
+		inFlight.body, inFlight.err = io.ReadAll(inFlight.response.Body)
+		_ = inFlight.response.Body.Close()
+	}
+
+	f.mu.Lock()
+	delete(f.requests, key)
+	f.mu.Unlock()
+	close(inFlight.done)
+}
+
+// followers returns the number of requests waiting for the response of an identical request.
+func (f *inFlightRequests) followers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	followers := 0
+	for _, inFlight := range f.requests {
+		followers += inFlight.followers
+	}
+	return followers
+}
+
+// responseFor returns a copy of the response with its own body, as each request consumes the body of its response.
+func (r *inFlightRequest) responseFor(request *http.Request) (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	response := *r.response
+	response.Header = r.response.Header.Clone()
+	response.Body = io.NopCloser(bytes.NewReader(r.body))
+	response.ContentLength = int64(len(r.body))
+	response.Request = request
+	return &response, nil
+}
+
+// inFlightRequestKey returns the key identifying the request, made of its method, URL, headers and body.
+func inFlightRequestKey(request *http.Request) (string, error) {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, request.Method+" "+request.URL.String()+"\n")
+
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		if !perRequestHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range request.Header[name] {
+			_, _ = io.WriteString(hash, name+": "+value+"\n")
+		}
+	}
+
+	if request.Body != nil && request.Body != http.NoBody {
+		body, err := io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		_, _ = hash.Write(body)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
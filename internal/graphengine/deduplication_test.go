@@ -0,0 +1,188 @@
+package graphengine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+type blockingRoundTripper struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&b.calls, 1)
+	<-b.release
+
+	body, _ := io.ReadAll(request.Body)
+	recorder := httptest.NewRecorder()
+	_, _ = recorder.Write(body)
+	return recorder.Result(), nil
+}
+
+func TestInFlightRequests(t *testing.T) {
+	send := func(t *testing.T, ctx context.Context, bodies ...string) (*blockingRoundTripper, []string) {
+		t.Helper()
+
+		next := &blockingRoundTripper{release: make(chan struct{})}
+		inFlight := newInFlightRequests()
+
+		responses := make([]string, len(bodies))
+		var wg sync.WaitGroup
+		for i, body := range bodies {
+			wg.Add(1)
+			go func(i int, body string) {
+				defer wg.Done()
+				request, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://upstream/graphql", strings.NewReader(body))
+				require.NoError(t, err)
+
+				response, err := inFlight.roundTrip(next, request)
+				require.NoError(t, err)
+				defer response.Body.Close()
+
+				content, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				responses[i] = string(content)
+			}(i, body)
+		}
+
+		// every request is either sent or waiting for an identical one before the upstream responds
+		assert.Eventually(t, func() bool {
+			return int(atomic.LoadInt32(&next.calls))+inFlight.followers() == len(bodies)
+		}, time.Second, time.Millisecond)
+		close(next.release)
+		wg.Wait()
+
+		return next, responses
+	}
+
+	t.Run("should send identical requests once", func(t *testing.T) {
+		next, responses := send(t, withRequestDeduplication(context.Background()), "{ a }", "{ a }", "{ a }")
+		assert.Equal(t, int32(1), next.calls)
+		assert.Equal(t, []string{"{ a }", "{ a }", "{ a }"}, responses)
+	})
+
+	t.Run("should send different requests", func(t *testing.T) {
+		next, responses := send(t, withRequestDeduplication(context.Background()), "{ a }", "{ b }")
+		assert.Equal(t, int32(2), next.calls)
+		assert.Equal(t, []string{"{ a }", "{ b }"}, responses)
+	})
+
+	t.Run("should send every request when not enabled", func(t *testing.T) {
+		next, responses := send(t, context.Background(), "{ a }", "{ a }")
+		assert.Equal(t, int32(2), next.calls)
+		assert.Equal(t, []string{"{ a }", "{ a }"}, responses)
+	})
+
+	t.Run("should not fail waiting requests when the sending request is canceled", func(t *testing.T) {
+		next := &blockingRoundTripper{release: make(chan struct{})}
+		inFlight := newInFlightRequests()
+
+		leaderCtx, cancel := context.WithCancel(withRequestDeduplication(context.Background()))
+		leader, err := http.NewRequestWithContext(leaderCtx, http.MethodPost, "http://upstream/graphql", strings.NewReader("{ a }"))
+		require.NoError(t, err)
+
+		leaderErr := make(chan error)
+		go func() {
+			_, err := inFlight.roundTrip(next, leader)
+			leaderErr <- err
+		}()
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&next.calls) == 1 }, time.Second, time.Millisecond)
+
+		follower, err := http.NewRequestWithContext(withRequestDeduplication(context.Background()), http.MethodPost, "http://upstream/graphql", strings.NewReader("{ a }"))
+		require.NoError(t, err)
+
+		followerResponse := make(chan string)
+		go func() {
+			response, err := inFlight.roundTrip(next, follower)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			content, _ := io.ReadAll(response.Body)
+			followerResponse <- string(content)
+		}()
+		assert.Eventually(t, func() bool { return inFlight.followers() == 1 }, time.Second, time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		close(next.release)
+		assert.Equal(t, "{ a }", <-followerResponse)
+		assert.Equal(t, int32(1), next.calls)
+	})
+}
+
+func TestEngineV2_Deduplication(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"hello": "world"}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	apiDefinition := newTestApiDefinitionV2(apidef.GraphQLExecutionModeExecutionEngine, upstream.URL)
+	apiDefinition.GraphQL.Deduplication.Enabled = true
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	engine, err := NewEngineV2(EngineV2Options{
+		Logger:          logger,
+		ApiDefinition:   apiDefinition,
+		HttpClient:      &http.Client{},
+		StreamingClient: &http.Client{},
+		Injections: EngineV2Injections{
+			ContextRetrieveRequest: func(r *http.Request) *graphql.Request {
+				return &graphql.Request{Query: "query { hello }"}
+			},
+		},
+	})
+	require.NoError(t, err)
+	transport := engine.reverseProxyPreHandler.(*reverseProxyPreHandlerV1).transport
+
+	const clients = 5
+	bodies := make([]string, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, _, err := engine.HandleReverseProxy(ReverseProxyParams{
+				RoundTripper: http.DefaultTransport,
+				OutRequest:   httptest.NewRequest(http.MethodPost, "/", nil),
+				NeedsEngine:  true,
+			})
+			require.NoError(t, err)
+			body, _ := io.ReadAll(res.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	// every client request is either sent upstream or waiting for an identical one
+	assert.Eventually(t, func() bool {
+		return int(atomic.LoadInt32(&calls))+transport.inFlightRequests.followers() == clients
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		assert.Equal(t, `{"data":{"hello":"world"}}`, body)
+	}
+}
@@ -97,8 +97,7 @@ func NewEngineV1(options EngineV1Options) (*EngineV1, error) {
 	reverseProxyPreHandler := &reverseProxyPreHandlerV1{
 		ctxRetrieveGraphQLRequest: options.Injections.ContextRetrieveRequest,
 		apiDefinition:             options.ApiDefinition,
-		httpClient:                options.HttpClient,
-		newReusableBodyReadCloser: options.Injections.NewReusableBodyReadCloser,
+		transport:                 newEngineTransport(options.ApiDefinition, options.HttpClient, options.Injections.NewReusableBodyReadCloser),
 	}
 
 	return &EngineV1{
@@ -130,13 +129,25 @@ func (e *EngineV1) Cancel() {
 }
 
 func (e *EngineV1) ProcessAndStoreGraphQLRequest(w http.ResponseWriter, r *http.Request) (err error, statusCode int) {
+	body, err := graphQLRequestBody(r)
+	if err != nil {
+		e.logger.Debug("error while reading GraphQL request from query string", abstractlogger.Error(err))
+		return err, http.StatusBadRequest
+	}
+
 	var gqlRequest graphql.Request
-	err = graphql.UnmarshalRequest(r.Body, &gqlRequest)
+	err = graphql.UnmarshalRequest(body, &gqlRequest)
 	if err != nil {
 		e.logger.Debug("error while unmarshalling GraphQL request", abstractlogger.Error(err))
 		return err, http.StatusBadRequest
 	}
 
+	if r.Method == http.MethodGet {
+		if operationType, err := gqlRequest.OperationType(); err == nil && operationType == graphql.OperationTypeMutation {
+			return rejectMutationOverGET(w)
+		}
+	}
+
 	e.ctxStoreRequestFunc(r, &gqlRequest)
 	return e.graphqlRequestProcessor.ProcessRequest(r.Context(), w, r)
 }
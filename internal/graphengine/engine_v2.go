@@ -33,6 +33,7 @@ type EngineV2Injections struct {
 	BeforeFetchHook           resolve.BeforeFetchHook
 	AfterFetchHook            resolve.AfterFetchHook
 	WebsocketOnBeforeStart    graphql.WebsocketBeforeStartHook
+	WebsocketExecutorPool     WebsocketExecutorPoolV1Func
 	ContextStoreRequest       ContextStoreRequestV1Func
 	ContextRetrieveRequest    ContextRetrieveRequestV1Func
 	NewReusableBodyReadCloser NewReusableBodyReadCloserFunc
@@ -62,6 +63,9 @@ type EngineV2 struct {
 	newReusableBodyReadCloser NewReusableBodyReadCloserFunc
 	seekReadCloser            SeekReadCloserFunc
 	tykVariableReplacer       TykVariableReplacer
+	websocketExecutorPool     WebsocketExecutorPoolV1Func
+	// incrementalDirectives is true when the schema defines the @defer or @stream directive.
+	incrementalDirectives bool
 }
 
 type EngineV2Options struct {
@@ -130,8 +134,7 @@ func NewEngineV2(options EngineV2Options) (*EngineV2, error) {
 	reverseProxyPreHandler := &reverseProxyPreHandlerV1{
 		ctxRetrieveGraphQLRequest: options.Injections.ContextRetrieveRequest,
 		apiDefinition:             options.ApiDefinition,
-		httpClient:                options.HttpClient,
-		newReusableBodyReadCloser: options.Injections.NewReusableBodyReadCloser,
+		transport:                 newEngineTransport(options.ApiDefinition, options.HttpClient, options.Injections.NewReusableBodyReadCloser),
 	}
 
 	engineV2 := &EngineV2{
@@ -155,6 +158,8 @@ func NewEngineV2(options EngineV2Options) (*EngineV2, error) {
 		newReusableBodyReadCloser: options.Injections.NewReusableBodyReadCloser,
 		seekReadCloser:            options.Injections.SeekReadCloser,
 		tykVariableReplacer:       options.Injections.TykVariableReplacer,
+		websocketExecutorPool:     options.Injections.WebsocketExecutorPool,
+		incrementalDirectives:     schemaDefinesIncrementalDelivery(parsedSchema.Document()),
 	}
 
 	if engineV2.OpenTelemetry == nil {
@@ -206,13 +211,32 @@ func (e *EngineV2) Cancel() {
 }
 
 func (e *EngineV2) ProcessAndStoreGraphQLRequest(w http.ResponseWriter, r *http.Request) (err error, statusCode int) {
+	body, err := graphQLRequestBody(r)
+	if err != nil {
+		e.logger.Debug("error while reading GraphQL request from query string", abstractlogger.Error(err))
+		return err, http.StatusBadRequest
+	}
+
 	var gqlRequest graphql.Request
-	err = graphql.UnmarshalRequest(r.Body, &gqlRequest)
+	err = graphql.UnmarshalRequest(body, &gqlRequest)
 	if err != nil {
 		e.logger.Debug("error while unmarshalling GraphQL request", abstractlogger.Error(err))
 		return err, http.StatusBadRequest
 	}
 
+	if !isProxyOnly(e.ApiDefinition) && !e.incrementalDirectives {
+		if query, delivery := newIncrementalDelivery(gqlRequest.Query, gqlRequest.OperationName, gqlRequest.Variables); delivery != nil {
+			gqlRequest.Query = query
+			ctxSetIncrementalDelivery(r, delivery)
+		}
+	}
+
+	if r.Method == http.MethodGet {
+		if operationType, err := gqlRequest.OperationType(); err == nil && operationType == graphql.OperationTypeMutation {
+			return rejectMutationOverGET(w)
+		}
+	}
+
 	e.ctxStoreRequestFunc(r, &gqlRequest)
 	if e.OpenTelemetry.Enabled && e.ApiDefinition.DetailedTracing {
 		ctx, span := e.OpenTelemetry.TracerProvider.Tracer().Start(r.Context(), "GraphqlMiddleware Validation")
@@ -280,6 +304,14 @@ func (e *EngineV2) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphql.R
 	isProxyOnly := isProxyOnly(e.ApiDefinition)
 	span := otel.SpanFromContext(outreq.Context())
 	reqCtx := otel.ContextWithSpan(context.Background(), span)
+	if tracker := graphqlinternal.DataSourceTrackerFromContext(outreq.Context()); tracker != nil {
+		reqCtx = graphqlinternal.ContextWithDataSourceTracker(reqCtx, tracker)
+	}
+	if e.ApiDefinition.GraphQL.Deduplication.Enabled {
+		if operationType, err := gqlRequest.OperationType(); err == nil && operationType == graphql.OperationTypeQuery {
+			reqCtx = withRequestDeduplication(reqCtx)
+		}
+	}
 	if isProxyOnly {
 		reqCtx = SetProxyOnlyContextValue(reqCtx, outreq)
 	}
@@ -306,7 +338,7 @@ func (e *EngineV2) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphql.R
 
 	httpStatus := http.StatusOK
 	header := make(http.Header)
-	header.Set("Content-Type", "application/json")
+	header.Set("Content-Type", responseContentType(outreq))
 
 	if isProxyOnly {
 		proxyOnlyCtx := GetProxyOnlyContextValue(reqCtx)
@@ -329,6 +361,11 @@ func (e *EngineV2) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphql.R
 			}
 
 		}
+	} else if delivery := ctxGetIncrementalDelivery(outreq); delivery != nil && acceptsMultipartMixed(outreq) {
+		body := delivery.multipartMixedBody(resultWriter.Bytes())
+		resultWriter.Reset()
+		_, _ = resultWriter.Write(body)
+		header.Set("Content-Type", multipartMixedContentType)
 	}
 	res = resultWriter.AsHTTPResponse(httpStatus, header)
 	return
@@ -385,6 +422,9 @@ func (e *EngineV2) handoverWebSocketConnectionToGraphQLExecutionEngine(params *R
 		initialRequestContext,
 		subscription.WithExecutorV2HeaderModifier(e.gqlTools.headerModifier(params.OutRequest, upstreamHeaders, e.tykVariableReplacer)),
 	)
+	if e.websocketExecutorPool != nil {
+		executorPool = e.websocketExecutorPool(params.OutRequest, conn, executorPool)
+	}
 
 	go gqlwebsocket.Handle(
 		done,
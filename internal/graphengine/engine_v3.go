@@ -32,10 +32,14 @@ type EngineV3 struct {
 	newReusableBodyReadCloser NewReusableBodyReadCloserFunc
 	seekReadCloser            SeekReadCloserFunc
 	tykVariableReplacer       TykVariableReplacer
+	websocketExecutorPool     WebsocketExecutorPoolV2Func
+	// incrementalDirectives is true when the schema defines the @defer or @stream directive.
+	incrementalDirectives bool
 }
 
 type EngineV3Injections struct {
 	WebsocketOnBeforeStart    graphqlv2.WebsocketBeforeStartHook
+	WebsocketExecutorPool     WebsocketExecutorPoolV2Func
 	ContextStoreRequest       ContextStoreRequestV2Func
 	ContextRetrieveRequest    ContextRetrieveRequestV2Func
 	NewReusableBodyReadCloser NewReusableBodyReadCloserFunc
@@ -110,8 +114,7 @@ func NewEngineV3(options EngineV3Options) (*EngineV3, error) {
 	reverseProxyPreHandler := &reverseProxyPreHandlerV2{
 		ctxRetrieveGraphQLRequest: options.Injections.ContextRetrieveRequest,
 		apiDefinition:             options.ApiDefinition,
-		httpClient:                options.HttpClient,
-		newReusableBodyReadCloser: options.Injections.NewReusableBodyReadCloser,
+		transport:                 newEngineTransport(options.ApiDefinition, options.HttpClient, options.Injections.NewReusableBodyReadCloser),
 	}
 
 	engine := EngineV3{
@@ -126,9 +129,11 @@ func NewEngineV3(options EngineV3Options) (*EngineV3, error) {
 		gqlTools:               gqlTools,
 		tykVariableReplacer:    options.Injections.TykVariableReplacer,
 		seekReadCloser:         options.Injections.SeekReadCloser,
+		websocketExecutorPool:  options.Injections.WebsocketExecutorPool,
 		contextCancel:          cancel,
 		complexityChecker:      complexityChecker,
 		granularAccessChecker:  granularAccessChecker,
+		incrementalDirectives:  schemaDefinesIncrementalDelivery(parsedSchema.Document()),
 	}
 
 	if engine.openTelemetry == nil {
@@ -180,13 +185,32 @@ func (e *EngineV3) Cancel() {
 }
 
 func (e *EngineV3) ProcessAndStoreGraphQLRequest(w http.ResponseWriter, r *http.Request) (err error, statusCode int) {
+	body, err := graphQLRequestBody(r)
+	if err != nil {
+		e.logger.Debug("error while reading GraphQL request from query string", abstractlogger.Error(err))
+		return err, http.StatusBadRequest
+	}
+
 	var gqlRequest graphqlv2.Request
-	err = graphqlv2.UnmarshalRequest(r.Body, &gqlRequest)
+	err = graphqlv2.UnmarshalRequest(body, &gqlRequest)
 	if err != nil {
 		e.logger.Debug("error while unmarshalling GraphQL request", abstractlogger.Error(err))
 		return err, http.StatusBadRequest
 	}
 
+	if !isProxyOnly(e.apiDefinition) && !e.incrementalDirectives {
+		if query, delivery := newIncrementalDelivery(gqlRequest.Query, gqlRequest.OperationName, gqlRequest.Variables); delivery != nil {
+			gqlRequest.Query = query
+			ctxSetIncrementalDelivery(r, delivery)
+		}
+	}
+
+	if r.Method == http.MethodGet {
+		if operationType, err := gqlRequest.OperationType(); err == nil && operationType == graphqlv2.OperationTypeMutation {
+			return rejectMutationOverGET(w)
+		}
+	}
+
 	e.ctxStoreRequestFunc(r, &gqlRequest)
 	if e.openTelemetry.Enabled && e.apiDefinition.DetailedTracing {
 		ctx, span := e.openTelemetry.TracerProvider.Tracer().Start(r.Context(), "GraphqlMiddleware Validation")
@@ -211,7 +235,7 @@ func (e *EngineV3) ProcessRequest(ctx context.Context, w http.ResponseWriter, r
 	}
 
 	if normalizationResult.Errors != nil && normalizationResult.Errors.Count() > 0 {
-		return writeGraphQLError(e.logger, w, normalizationResult.Errors)
+		return writeGraphQLError(e.logger, w, r, normalizationResult.Errors)
 	}
 
 	validationResult, err := gqlRequest.ValidateForSchema(e.schema)
@@ -221,7 +245,7 @@ func (e *EngineV3) ProcessRequest(ctx context.Context, w http.ResponseWriter, r
 	}
 
 	if validationResult.Errors != nil && validationResult.Errors.Count() > 0 {
-		return writeGraphQLError(e.logger, w, validationResult.Errors)
+		return writeGraphQLError(e.logger, w, r, validationResult.Errors)
 	}
 
 	inputValidationResult, err := gqlRequest.ValidateInput(e.schema)
@@ -230,7 +254,7 @@ func (e *EngineV3) ProcessRequest(ctx context.Context, w http.ResponseWriter, r
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	}
 	if inputValidationResult.Errors != nil && inputValidationResult.Errors.Count() > 0 {
-		return writeGraphQLError(e.logger, w, inputValidationResult.Errors)
+		return writeGraphQLError(e.logger, w, r, inputValidationResult.Errors)
 	}
 	return nil, http.StatusOK
 }
@@ -13,6 +13,7 @@ import (
 	graphqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 	subscriptionv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
 	gqlwebsocketv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription/websocket"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 	"github.com/TykTechnologies/tyk/internal/otel"
 )
 
@@ -76,6 +77,9 @@ func (e *EngineV3) handoverWebSocketConnectionToGraphQLExecutionEngine(params *R
 		initialRequestContext,
 		subscriptionv2.WithExecutorV2HeaderModifier(e.gqlTools.headerModifier(params.OutRequest, upstreamHeaders, e.tykVariableReplacer)),
 	)
+	if e.websocketExecutorPool != nil {
+		executorPool = e.websocketExecutorPool(params.OutRequest, conn, executorPool)
+	}
 
 	go gqlwebsocketv2.Handle(
 		done,
@@ -103,6 +107,14 @@ func (e *EngineV3) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphqlv2
 	isProxyOnly := isProxyOnly(e.apiDefinition)
 	span := otel.SpanFromContext(outreq.Context())
 	reqCtx := otel.ContextWithSpan(context.Background(), span)
+	if tracker := graphqlinternal.DataSourceTrackerFromContext(outreq.Context()); tracker != nil {
+		reqCtx = graphqlinternal.ContextWithDataSourceTracker(reqCtx, tracker)
+	}
+	if e.apiDefinition.GraphQL.Deduplication.Enabled {
+		if operationType, err := gqlRequest.OperationType(); err == nil && operationType == graphqlv2.OperationTypeQuery {
+			reqCtx = withRequestDeduplication(reqCtx)
+		}
+	}
 	if isProxyOnly {
 		reqCtx = SetProxyOnlyContextValue(reqCtx, outreq)
 	}
@@ -127,7 +139,7 @@ func (e *EngineV3) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphqlv2
 
 	httpStatus := http.StatusOK
 	header := make(http.Header)
-	header.Set("Content-Type", "application/json")
+	header.Set("Content-Type", responseContentType(outreq))
 
 	if isProxyOnly {
 		proxyOnlyCtx := GetProxyOnlyContextValue(reqCtx)
@@ -150,6 +162,11 @@ func (e *EngineV3) handoverRequestToGraphQLExecutionEngine(gqlRequest *graphqlv2
 			}
 
 		}
+	} else if delivery := ctxGetIncrementalDelivery(outreq); delivery != nil && acceptsMultipartMixed(outreq) {
+		body := delivery.multipartMixedBody(resultWriter.Bytes())
+		resultWriter.Reset()
+		_, _ = resultWriter.Write(body)
+		header.Set("Content-Type", multipartMixedContentType)
 	}
 	res = resultWriter.AsHTTPResponse(httpStatus, header)
 	return
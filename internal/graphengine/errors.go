@@ -10,4 +10,6 @@ var (
 	GraphQLDepthLimitExceededErr = errors.New("depth limit exceeded")
 	ErrIntrospectionDisabled     = errors.New("introspection is disabled")
 	ErrUnknownReverseProxyType   = errors.New("unknown reverse proxy type")
+	ErrMutationOverGET           = errors.New("mutations are only allowed in POST requests")
+	ErrInvalidQueryStringRequest = errors.New("invalid GraphQL request in query string")
 )
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 
 	"github.com/buger/jsonparser"
@@ -14,6 +15,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/pkg/execution/datasource"
 	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
 	"github.com/TykTechnologies/graphql-go-tools/pkg/postprocess"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/subscription"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	internalgraphql "github.com/TykTechnologies/tyk/internal/graphql"
@@ -30,6 +32,9 @@ const (
 type ContextRetrieveRequestV1Func func(r *http.Request) *graphql.Request
 type ContextStoreRequestV1Func func(r *http.Request, gqlRequest *graphql.Request)
 
+// WebsocketExecutorPoolV1Func can wrap the executor pool of a websocket connection, e.g. to track its operations.
+type WebsocketExecutorPoolV1Func func(r *http.Request, conn net.Conn, executorPool subscription.ExecutorPool) subscription.ExecutorPool
+
 type createExecutionEngineV1Params struct {
 	logger              abstractlogger.Logger
 	apiDef              *apidef.APIDefinition
@@ -213,7 +218,7 @@ func (g *graphqlRequestProcessorV1) ProcessRequest(ctx context.Context, w http.R
 	}
 
 	if normalizationResult.Errors != nil && normalizationResult.Errors.Count() > 0 {
-		return writeGraphQLError(g.logger, w, normalizationResult.Errors)
+		return writeGraphQLError(g.logger, w, r, normalizationResult.Errors)
 	}
 
 	validationResult, err := gqlRequest.ValidateForSchema(g.schema)
@@ -223,7 +228,7 @@ func (g *graphqlRequestProcessorV1) ProcessRequest(ctx context.Context, w http.R
 	}
 
 	if validationResult.Errors != nil && validationResult.Errors.Count() > 0 {
-		return writeGraphQLError(g.logger, w, validationResult.Errors)
+		return writeGraphQLError(g.logger, w, r, validationResult.Errors)
 	}
 
 	inputValidationResult, err := gqlRequest.ValidateInput(g.schema)
@@ -232,7 +237,7 @@ func (g *graphqlRequestProcessorV1) ProcessRequest(ctx context.Context, w http.R
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	}
 	if inputValidationResult.Errors != nil && inputValidationResult.Errors.Count() > 0 {
-		return writeGraphQLError(g.logger, w, inputValidationResult.Errors)
+		return writeGraphQLError(g.logger, w, r, inputValidationResult.Errors)
 	}
 	return nil, http.StatusOK
 }
@@ -259,7 +264,7 @@ func (g *graphqlRequestProcessorWithOTelV1) ProcessRequest(ctx context.Context,
 		g.logger.Error("error while normalizing GraphqlRequest", abstractlogger.Error(err))
 		var reqErr graphql.RequestErrors
 		if errors.As(err, &reqErr) {
-			return writeGraphQLError(g.logger, w, reqErr)
+			return writeGraphQLError(g.logger, w, r, reqErr)
 		}
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	}
@@ -270,7 +275,7 @@ func (g *graphqlRequestProcessorWithOTelV1) ProcessRequest(ctx context.Context,
 		g.logger.Error("error while validating GraphQL request", abstractlogger.Error(err))
 		var reqErr graphql.RequestErrors
 		if errors.As(err, &reqErr) {
-			return writeGraphQLError(g.logger, w, reqErr)
+			return writeGraphQLError(g.logger, w, r, reqErr)
 		}
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	}
@@ -281,7 +286,7 @@ func (g *graphqlRequestProcessorWithOTelV1) ProcessRequest(ctx context.Context,
 		g.logger.Error("error while validating variables for request", abstractlogger.Error(err))
 		var reqErr graphql.RequestErrors
 		if errors.As(err, &reqErr) {
-			return writeGraphQLError(g.logger, w, reqErr)
+			return writeGraphQLError(g.logger, w, r, reqErr)
 		}
 		return ProxyingRequestFailedErr, http.StatusInternalServerError
 	}
@@ -481,17 +486,11 @@ func (g *granularAccessCheckerV1) writeErrorResponse(w io.Writer, providedErr er
 type reverseProxyPreHandlerV1 struct {
 	ctxRetrieveGraphQLRequest ContextRetrieveRequestV1Func
 	apiDefinition             *apidef.APIDefinition
-	httpClient                *http.Client
-	newReusableBodyReadCloser NewReusableBodyReadCloserFunc
+	transport                 *GraphQLEngineTransport
 }
 
 func (r *reverseProxyPreHandlerV1) PreHandle(params ReverseProxyParams) (reverseProxyType ReverseProxyType, err error) {
-	r.httpClient.Transport = NewGraphQLEngineTransport(
-		DetermineGraphQLEngineTransportType(r.apiDefinition),
-		params.RoundTripper,
-		r.newReusableBodyReadCloser,
-		params.HeadersConfig,
-	)
+	r.transport.setUpstream(params.RoundTripper, params.HeadersConfig)
 
 	switch {
 	case params.IsCORSPreflight:
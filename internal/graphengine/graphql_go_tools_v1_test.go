@@ -998,16 +998,16 @@ func newTestGranularAccessCheckerV1(t *testing.T) *granularAccessCheckerV1 {
 }
 
 func newTestReverseProxyPreHandlerV1(_ *testing.T, executionMode apidef.GraphQLExecutionMode) *reverseProxyPreHandlerV1 {
-	return &reverseProxyPreHandlerV1{
-		apiDefinition: &apidef.APIDefinition{
-			GraphQL: apidef.GraphQLConfig{
-				Enabled:       true,
-				ExecutionMode: executionMode,
-			},
+	apiDefinition := &apidef.APIDefinition{
+		GraphQL: apidef.GraphQLConfig{
+			Enabled:       true,
+			ExecutionMode: executionMode,
 		},
-		httpClient: &http.Client{},
-		newReusableBodyReadCloser: func(closer io.ReadCloser) (io.ReadCloser, error) {
+	}
+	return &reverseProxyPreHandlerV1{
+		apiDefinition: apiDefinition,
+		transport: newEngineTransport(apiDefinition, &http.Client{}, func(closer io.ReadCloser) (io.ReadCloser, error) {
 			return closer, nil
-		},
+		}),
 	}
 }
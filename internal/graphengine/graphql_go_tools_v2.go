@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 
 	"github.com/jensneuse/abstractlogger"
@@ -17,6 +18,7 @@ import (
 	graphqlv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/introspection"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+	subscriptionv2 "github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
 
 	"github.com/TykTechnologies/tyk/apidef"
 )
@@ -24,6 +26,9 @@ import (
 type ContextRetrieveRequestV2Func func(r *http.Request) *graphqlv2.Request
 type ContextStoreRequestV2Func func(r *http.Request, gqlRequest *graphqlv2.Request)
 
+// WebsocketExecutorPoolV2Func can wrap the executor pool of a websocket connection, e.g. to track its operations.
+type WebsocketExecutorPoolV2Func func(r *http.Request, conn net.Conn, executorPool subscriptionv2.ExecutorPool) subscriptionv2.ExecutorPool
+
 type graphqlGoToolsV2 struct{}
 
 func (g graphqlGoToolsV2) parseSchema(schema string) (*graphqlv2.Schema, error) {
@@ -116,17 +121,11 @@ func (g graphqlGoToolsV2) returnErrorsFromUpstream(proxyOnlyCtx *GraphQLProxyOnl
 type reverseProxyPreHandlerV2 struct {
 	ctxRetrieveGraphQLRequest ContextRetrieveRequestV2Func
 	apiDefinition             *apidef.APIDefinition
-	httpClient                *http.Client
-	newReusableBodyReadCloser NewReusableBodyReadCloserFunc
+	transport                 *GraphQLEngineTransport
 }
 
 func (r *reverseProxyPreHandlerV2) PreHandle(params ReverseProxyParams) (reverseProxyType ReverseProxyType, err error) {
-	r.httpClient.Transport = NewGraphQLEngineTransport(
-		DetermineGraphQLEngineTransportType(r.apiDefinition),
-		params.RoundTripper,
-		r.newReusableBodyReadCloser,
-		params.HeadersConfig,
-	)
+	r.transport.setUpstream(params.RoundTripper, params.HeadersConfig)
 
 	switch {
 	case params.IsCORSPreflight:
@@ -163,16 +163,16 @@ func TestReverseProxyPreHandlerV2_PreHandle(t *testing.T) {
 }
 
 func newTestReverseProxyPreHandlerV2(t *testing.T) *reverseProxyPreHandlerV2 {
-	return &reverseProxyPreHandlerV2{
-		apiDefinition: &apidef.APIDefinition{
-			GraphQL: apidef.GraphQLConfig{
-				Enabled:       true,
-				ExecutionMode: apidef.GraphQLExecutionModeProxyOnly,
-			},
+	apiDefinition := &apidef.APIDefinition{
+		GraphQL: apidef.GraphQLConfig{
+			Enabled:       true,
+			ExecutionMode: apidef.GraphQLExecutionModeProxyOnly,
 		},
-		httpClient: &http.Client{},
-		newReusableBodyReadCloser: func(closer io.ReadCloser) (io.ReadCloser, error) {
+	}
+	return &reverseProxyPreHandlerV2{
+		apiDefinition: apiDefinition,
+		transport: newEngineTransport(apiDefinition, &http.Client{}, func(closer io.ReadCloser) (io.ReadCloser, error) {
 			return closer, nil
-		},
+		}),
 	}
 }
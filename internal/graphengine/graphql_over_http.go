@@ -0,0 +1,624 @@
+package graphengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	multipartMixedContentType = header.MultipartMixed + `; boundary="-"; deferSpec=20220824`
+	multipartPartHeader       = "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"
+	multipartEnd              = "\r\n-----\r\n"
+)
+
+// GraphQLRequestFromQueryString returns the JSON body of the GraphQL request sent in the query string of a GET
+// request, as described by the GraphQL over HTTP specification. It returns nil when the query string has no operation,
+// which is sent as a query or as a persisted query extension.
+func GraphQLRequestFromQueryString(values url.Values) ([]byte, error) {
+	if !values.Has("query") && !values.Has("extensions") {
+		return nil, nil
+	}
+
+	request := map[string]interface{}{
+		"query": values.Get("query"),
+	}
+	if operationName := values.Get("operationName"); operationName != "" {
+		request["operationName"] = operationName
+	}
+	for _, key := range []string{"variables", "extensions"} {
+		value := values.Get(key)
+		if value == "" {
+			continue
+		}
+		if !json.Valid([]byte(value)) {
+			return nil, ErrInvalidQueryStringRequest
+		}
+		request[key] = json.RawMessage(value)
+	}
+
+	return json.Marshal(request)
+}
+
+// graphQLRequestBody returns the body of the GraphQL request. The operation of a GET request is read from its query
+// string and becomes the body of the request, so it is handled like the body of a POST request from there on.
+func graphQLRequestBody(r *http.Request) (io.Reader, error) {
+	if r.Method != http.MethodGet {
+		return r.Body, nil
+	}
+
+	body, err := GraphQLRequestFromQueryString(r.URL.Query())
+	if err != nil || body == nil {
+		return r.Body, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return bytes.NewReader(body), nil
+}
+
+// rejectMutationOverGET responds to a mutation sent in a GET request, which must not change the state of the server.
+func rejectMutationOverGET(w http.ResponseWriter) (error, int) {
+	w.Header().Set(header.Allow, http.MethodPost)
+	return ErrMutationOverGET, http.StatusMethodNotAllowed
+}
+
+type acceptedMediaType struct {
+	mediaType string
+	quality   float64
+}
+
+func acceptedMediaTypes(r *http.Request) []acceptedMediaType {
+	var mediaTypes []acceptedMediaType
+	for _, accept := range r.Header.Values(header.Accept) {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			mediaTypes = append(mediaTypes, acceptedMediaType{mediaType: mediaType, quality: quality})
+		}
+	}
+	return mediaTypes
+}
+
+// responseContentType returns the content type of the GraphQL response negotiated from the Accept header of the
+// request. Clients which don't ask for application/graphql-response+json keep getting application/json.
+func responseContentType(r *http.Request) string {
+	contentType, quality := header.ApplicationJSON, 0.0
+	for _, accepted := range acceptedMediaTypes(r) {
+		switch accepted.mediaType {
+		case header.ApplicationGraphQLResponseJSON, header.ApplicationJSON:
+			// on equal quality the media type listed first wins
+			if accepted.quality > quality {
+				contentType, quality = accepted.mediaType, accepted.quality
+			}
+		}
+	}
+
+	if contentType == header.ApplicationGraphQLResponseJSON {
+		return contentType + "; charset=utf-8"
+	}
+	return contentType
+}
+
+func acceptsMultipartMixed(r *http.Request) bool {
+	for _, accepted := range acceptedMediaTypes(r) {
+		if accepted.mediaType == header.MultipartMixed && accepted.quality > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// incrementalDelivery describes how the result of an operation using @defer and @stream is split into the payloads of
+// a multipart/mixed response. This is compatibility framing only, for clients which require multipart/mixed responses
+// to these directives: the execution engine doesn't resolve them, the whole operation is executed at once and all the
+// payloads are written together once the result is complete. Nothing is delivered earlier than without the directives.
+type incrementalDelivery struct {
+	deferred []deferredFragment
+	streamed []streamedField
+}
+
+type deferredFragment struct {
+	label string
+	// depth is the number of deferred fragments the fragment is nested in.
+	depth int
+	// path is made of the response keys of the objects the fragment applies to.
+	path   []string
+	fields []string
+	// initial holds the response keys delivered before the fragment, by the payload it is nested in.
+	initial map[string]bool
+}
+
+type streamedField struct {
+	label        string
+	path         []string
+	initialCount int
+}
+
+type incrementalDeliveryContextKey struct{}
+
+func ctxSetIncrementalDelivery(r *http.Request, delivery *incrementalDelivery) {
+	*r = *r.WithContext(context.WithValue(r.Context(), incrementalDeliveryContextKey{}, delivery))
+}
+
+func ctxGetIncrementalDelivery(r *http.Request) *incrementalDelivery {
+	delivery, _ := r.Context().Value(incrementalDeliveryContextKey{}).(*incrementalDelivery)
+	return delivery
+}
+
+// newIncrementalDelivery returns the incremental delivery of the operation and the query without the @defer and
+// @stream directives, for schemas which don't define them. It returns nil when the query doesn't use the directives.
+func newIncrementalDelivery(query, operationName string, variables []byte) (string, *incrementalDelivery) {
+	if !strings.Contains(query, "@defer") && !strings.Contains(query, "@stream") {
+		return query, nil
+	}
+
+	document, report := astparser.ParseGraphqlDocumentString(query)
+	if report.HasErrors() {
+		// the invalid query is reported by the validation
+		return query, nil
+	}
+
+	analyzer := &incrementalDeliveryAnalyzer{
+		document:  &document,
+		variables: variables,
+		delivery:  &incrementalDelivery{},
+		fragments: make(map[string]int),
+		visiting:  make(map[string]bool),
+	}
+	for ref := range document.FragmentDefinitions {
+		analyzer.fragments[document.FragmentDefinitionNameString(ref)] = document.FragmentDefinitions[ref].SelectionSet
+	}
+	for _, node := range document.RootNodes {
+		if node.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if operationName == "" || document.OperationDefinitionNameString(node.Ref) == operationName {
+			analyzer.selectionSet(document.OperationDefinitions[node.Ref].SelectionSet, nil, 0)
+			break
+		}
+	}
+
+	if !analyzer.removeDirectives() {
+		return query, nil
+	}
+
+	printed, err := astprinter.PrintString(&document, nil)
+	if err != nil {
+		return query, nil
+	}
+	return printed, analyzer.delivery
+}
+
+// schemaDefinesIncrementalDelivery returns true when the schema defines the @defer or @stream directive. The operations
+// of such schemas are executed with their directives, which the upstreams resolve.
+func schemaDefinesIncrementalDelivery(schema []byte) bool {
+	document, report := astparser.ParseGraphqlDocumentBytes(schema)
+	if report.HasErrors() {
+		return false
+	}
+
+	for ref := range document.DirectiveDefinitions {
+		switch document.DirectiveDefinitionNameString(ref) {
+		case "defer", "stream":
+			return true
+		}
+	}
+	return false
+}
+
+type incrementalDeliveryAnalyzer struct {
+	document  *ast.Document
+	variables []byte
+	delivery  *incrementalDelivery
+	fragments map[string]int
+	visiting  map[string]bool
+}
+
+// selectionSet collects the deferred fragments and streamed fields of the selection set of an operation or a field.
+func (a *incrementalDeliveryAnalyzer) selectionSet(set int, path []string, depth int) {
+	var fields []string
+	start := len(a.delivery.deferred)
+	a.collect(set, path, depth, &fields)
+	a.setInitialFields(start, path, fields)
+}
+
+func (a *incrementalDeliveryAnalyzer) collect(set int, path []string, depth int, fields *[]string) {
+	for _, ref := range a.document.SelectionSets[set].SelectionRefs {
+		selection := a.document.Selections[ref]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			field := a.document.Fields[selection.Ref]
+			key := a.document.FieldAliasOrNameString(selection.Ref)
+			fieldPath := append(append([]string{}, path...), key)
+			*fields = append(*fields, key)
+
+			if directive, ok := a.directive(field.Directives.Refs, "stream"); ok && a.enabled(directive) {
+				a.delivery.streamed = append(a.delivery.streamed, streamedField{
+					label:        a.stringArgument(directive, "label"),
+					path:         fieldPath,
+					initialCount: a.intArgument(directive, "initialCount"),
+				})
+			}
+			if field.HasSelections {
+				a.selectionSet(field.SelectionSet, fieldPath, depth)
+			}
+		case ast.SelectionKindInlineFragment:
+			fragment := a.document.InlineFragments[selection.Ref]
+			if fragment.HasSelections {
+				a.fragment(fragment.SelectionSet, fragment.Directives.Refs, path, depth, fields)
+			}
+		case ast.SelectionKindFragmentSpread:
+			name := a.document.FragmentSpreadNameString(selection.Ref)
+			set, ok := a.fragments[name]
+			if !ok || a.visiting[name] {
+				continue
+			}
+			a.visiting[name] = true
+			a.fragment(set, a.document.FragmentSpreads[selection.Ref].Directives.Refs, path, depth, fields)
+			delete(a.visiting, name)
+		}
+	}
+}
+
+func (a *incrementalDeliveryAnalyzer) fragment(set int, directives []int, path []string, depth int, fields *[]string) {
+	directive, ok := a.directive(directives, "defer")
+	if !ok || !a.enabled(directive) {
+		a.collect(set, path, depth, fields)
+		return
+	}
+
+	var deferredFields []string
+	start := len(a.delivery.deferred)
+	a.collect(set, path, depth+1, &deferredFields)
+	a.setInitialFields(start, path, deferredFields)
+
+	a.delivery.deferred = append(a.delivery.deferred, deferredFragment{
+		label:  a.stringArgument(directive, "label"),
+		depth:  depth,
+		path:   path,
+		fields: deferredFields,
+	})
+}
+
+// setInitialFields sets the fields delivered before the deferred fragments found at the path since start.
+func (a *incrementalDeliveryAnalyzer) setInitialFields(start int, path []string, fields []string) {
+	initial := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		initial[field] = true
+	}
+
+	for i := start; i < len(a.delivery.deferred); i++ {
+		fragment := &a.delivery.deferred[i]
+		if fragment.initial == nil && strings.Join(fragment.path, ".") == strings.Join(path, ".") {
+			fragment.initial = initial
+		}
+	}
+}
+
+func (a *incrementalDeliveryAnalyzer) directive(refs []int, name string) (int, bool) {
+	for _, ref := range refs {
+		if a.document.DirectiveNameString(ref) == name {
+			return ref, true
+		}
+	}
+	return -1, false
+}
+
+func (a *incrementalDeliveryAnalyzer) enabled(directive int) bool {
+	value, ok := a.document.DirectiveArgumentValueByName(directive, []byte("if"))
+	if !ok {
+		return true
+	}
+
+	switch value.Kind {
+	case ast.ValueKindBoolean:
+		return bool(a.document.BooleanValue(value.Ref))
+	case ast.ValueKindVariable:
+		enabled, err := jsonparser.GetBoolean(a.variables, a.document.VariableValueNameString(value.Ref))
+		return err != nil || enabled
+	}
+	return true
+}
+
+func (a *incrementalDeliveryAnalyzer) stringArgument(directive int, name string) string {
+	value, ok := a.document.DirectiveArgumentValueByName(directive, []byte(name))
+	if !ok {
+		return ""
+	}
+
+	switch value.Kind {
+	case ast.ValueKindString:
+		return a.document.StringValueContentString(value.Ref)
+	case ast.ValueKindVariable:
+		str, _ := jsonparser.GetString(a.variables, a.document.VariableValueNameString(value.Ref))
+		return str
+	}
+	return ""
+}
+
+func (a *incrementalDeliveryAnalyzer) intArgument(directive int, name string) int {
+	value, ok := a.document.DirectiveArgumentValueByName(directive, []byte(name))
+	if !ok {
+		return 0
+	}
+
+	switch value.Kind {
+	case ast.ValueKindInteger:
+		return int(a.document.IntValueAsInt(value.Ref))
+	case ast.ValueKindVariable:
+		i, _ := jsonparser.GetInt(a.variables, a.document.VariableValueNameString(value.Ref))
+		return int(i)
+	}
+	return 0
+}
+
+// removeDirectives removes the @defer and @stream directives from the document and reports whether it had any.
+func (a *incrementalDeliveryAnalyzer) removeDirectives() (removed bool) {
+	remove := func(refs []int) []int {
+		kept := refs[:0]
+		for _, ref := range refs {
+			switch a.document.DirectiveNameString(ref) {
+			case "defer", "stream":
+				removed = true
+			default:
+				kept = append(kept, ref)
+			}
+		}
+		return kept
+	}
+
+	for i := range a.document.Fields {
+		a.document.Fields[i].Directives.Refs = remove(a.document.Fields[i].Directives.Refs)
+		a.document.Fields[i].HasDirectives = len(a.document.Fields[i].Directives.Refs) > 0
+	}
+	for i := range a.document.InlineFragments {
+		a.document.InlineFragments[i].Directives.Refs = remove(a.document.InlineFragments[i].Directives.Refs)
+		a.document.InlineFragments[i].HasDirectives = len(a.document.InlineFragments[i].Directives.Refs) > 0
+	}
+	for i := range a.document.FragmentSpreads {
+		a.document.FragmentSpreads[i].Directives.Refs = remove(a.document.FragmentSpreads[i].Directives.Refs)
+		a.document.FragmentSpreads[i].HasDirectives = len(a.document.FragmentSpreads[i].Directives.Refs) > 0
+	}
+	return removed
+}
+
+type incrementalResult struct {
+	Data  json.RawMessage `json:"data,omitempty"`
+	Items json.RawMessage `json:"items,omitempty"`
+	Path  []interface{}   `json:"path"`
+	Label string          `json:"label,omitempty"`
+}
+
+type subsequentPayload struct {
+	Incremental []incrementalResult `json:"incremental"`
+	HasNext     bool                `json:"hasNext"`
+}
+
+// multipartMixedBody splits the result of the operation into the initial payload and a subsequent payload with the
+// deferred fragments, from the outermost to the innermost, followed by the streamed list items.
+func (d *incrementalDelivery) multipartMixedBody(result []byte) []byte {
+	initial, results := d.split(result)
+
+	var body bytes.Buffer
+	body.WriteString(multipartPartHeader)
+	body.Write(initial)
+	if len(results) > 0 {
+		payload, err := json.Marshal(subsequentPayload{Incremental: results})
+		if err == nil {
+			body.WriteString(multipartPartHeader)
+			body.Write(payload)
+		}
+	}
+	body.WriteString(multipartEnd)
+	return body.Bytes()
+}
+
+func (d *incrementalDelivery) split(result []byte) (initial []byte, results []incrementalResult) {
+	single := func() ([]byte, []incrementalResult) {
+		initial, _ := jsonparser.Set(result, []byte("false"), "hasNext")
+		return initial, nil
+	}
+
+	data, dataType, _, err := jsonparser.Get(result, "data")
+	if err != nil || dataType != jsonparser.Object {
+		return single()
+	}
+
+	// the streamed items are cut from the lists first, so the deferred fragments only deliver the initial items
+	var streamed []incrementalResult
+	for _, field := range d.streamed {
+		parents := locateJSON(data, jsonLocation{}, field.path[:len(field.path)-1])
+		key := field.path[len(field.path)-1]
+		for _, parent := range parents {
+			list, listType, _, err := jsonparser.Get(parent.value, key)
+			if err != nil || listType != jsonparser.Array {
+				continue
+			}
+
+			items := jsonArrayItems(list)
+			if len(items) <= field.initialCount {
+				continue
+			}
+			at := parent.child(key)
+			for i := field.initialCount; i < len(items); i++ {
+				streamed = append(streamed, incrementalResult{
+					Items: append(append([]byte("["), items[i]...), ']'),
+					Path:  at.index(i).path,
+					Label: field.label,
+				})
+			}
+			kept := append([]byte("["), bytes.Join(items[:field.initialCount], []byte(","))...)
+			if data, err = jsonparser.Set(data, append(kept, ']'), at.keys...); err != nil {
+				return single()
+			}
+		}
+	}
+
+	// the innermost fragments are cut first, so the fragments they are nested in don't deliver their fields
+	deferred := make([][]incrementalResult, len(d.deferred))
+	order := make([]int, len(d.deferred))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return d.deferred[order[i]].depth > d.deferred[order[j]].depth
+	})
+
+	for start := 0; start < len(order); {
+		depth := d.deferred[order[start]].depth
+		end := start
+		var cut [][]string
+		for ; end < len(order) && d.deferred[order[end]].depth == depth; end++ {
+			fragment := d.deferred[order[end]]
+			for _, object := range locateJSON(data, jsonLocation{}, fragment.path) {
+				fields, removed := pickJSONFields(object.value, fragment.fields, fragment.initial)
+				deferred[order[end]] = append(deferred[order[end]], incrementalResult{
+					Data:  fields,
+					Path:  object.path,
+					Label: fragment.label,
+				})
+				for _, key := range removed {
+					cut = append(cut, object.child(key).keys)
+				}
+			}
+		}
+		// sibling fragments may select the same fields, so the fields are cut once all of them are picked
+		for _, keys := range cut {
+			data = jsonparser.Delete(data, keys...)
+		}
+		start = end
+	}
+
+	// the outermost fragments are delivered first, as the innermost ones are delivered into them
+	sort.SliceStable(order, func(i, j int) bool {
+		return d.deferred[order[i]].depth < d.deferred[order[j]].depth ||
+			(d.deferred[order[i]].depth == d.deferred[order[j]].depth && order[i] < order[j])
+	})
+	for _, i := range order {
+		results = append(results, deferred[i]...)
+	}
+	results = append(results, streamed...)
+	if len(results) == 0 {
+		return single()
+	}
+
+	if initial, err = jsonparser.Set(result, data, "data"); err != nil {
+		return single()
+	}
+	if initial, err = jsonparser.Set(initial, []byte("true"), "hasNext"); err != nil {
+		return single()
+	}
+	return initial, results
+}
+
+type jsonLocation struct {
+	// path is the path of the location in the GraphQL response.
+	path []interface{}
+	// keys is the path of the location for jsonparser.
+	keys  []string
+	value []byte
+}
+
+func (l jsonLocation) child(key string) jsonLocation {
+	return jsonLocation{
+		path: append(append([]interface{}{}, l.path...), key),
+		keys: append(append([]string{}, l.keys...), key),
+	}
+}
+
+func (l jsonLocation) index(i int) jsonLocation {
+	return jsonLocation{
+		path: append(append([]interface{}{}, l.path...), i),
+		keys: append(append([]string{}, l.keys...), "["+strconv.Itoa(i)+"]"),
+	}
+}
+
+// locateJSON returns the objects found at the path of response keys, going through the items of the lists.
+func locateJSON(value []byte, at jsonLocation, path []string) []jsonLocation {
+	_, valueType, _, err := jsonparser.Get(value)
+	if err != nil {
+		return nil
+	}
+
+	switch valueType {
+	case jsonparser.Array:
+		var locations []jsonLocation
+		for i, item := range jsonArrayItems(value) {
+			locations = append(locations, locateJSON(item, at.index(i), path)...)
+		}
+		return locations
+	case jsonparser.Object:
+		if len(path) == 0 {
+			at.value = value
+			return []jsonLocation{at}
+		}
+		child, _, _, err := jsonparser.Get(value, path[0])
+		if err != nil {
+			return nil
+		}
+		return locateJSON(child, at.child(path[0]), path[1:])
+	}
+	return nil
+}
+
+func jsonArrayItems(list []byte) (items [][]byte) {
+	_, _ = jsonparser.ArrayEach(list, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		items = append(items, rawJSONValue(value, dataType))
+	})
+	return items
+}
+
+// pickJSONFields returns the object made of the fields of the object and the fields not delivered before.
+func pickJSONFields(object []byte, fields []string, initial map[string]bool) (picked []byte, removed []string) {
+	selected := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	var members [][]byte
+	_ = jsonparser.ObjectEach(object, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		if !selected[string(key)] {
+			return nil
+		}
+		members = append(members, append(append([]byte(`"`), key...), append([]byte(`":`), rawJSONValue(value, dataType)...)...))
+		if !initial[string(key)] {
+			removed = append(removed, string(key))
+		}
+		return nil
+	})
+
+	picked = append([]byte("{"), bytes.Join(members, []byte(","))...)
+	return append(picked, '}'), removed
+}
+
+func rawJSONValue(value []byte, dataType jsonparser.ValueType) []byte {
+	if dataType == jsonparser.String {
+		return append(append([]byte(`"`), value...), '"')
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+package graphengine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLRequestFromQueryString(t *testing.T) {
+	t.Run("should build the request of the query string", func(t *testing.T) {
+		values := url.Values{
+			"query":         {"query Hello($name: String) { hello(name: $name) }"},
+			"operationName": {"Hello"},
+			"variables":     {`{"name":"tyk"}`},
+		}
+
+		body, err := GraphQLRequestFromQueryString(values)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"query":"query Hello($name: String) { hello(name: $name) }","operationName":"Hello","variables":{"name":"tyk"}}`, string(body))
+	})
+
+	t.Run("should return nil without an operation", func(t *testing.T) {
+		body, err := GraphQLRequestFromQueryString(url.Values{"foo": {"bar"}})
+		require.NoError(t, err)
+		assert.Nil(t, body)
+	})
+
+	t.Run("should reject invalid variables", func(t *testing.T) {
+		_, err := GraphQLRequestFromQueryString(url.Values{"query": {"{ hello }"}, "variables": {"{"}})
+		assert.ErrorIs(t, err, ErrInvalidQueryStringRequest)
+	})
+}
+
+func TestResponseContentType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "no accept header", accept: "", expected: "application/json"},
+		{name: "any media type", accept: "*/*", expected: "application/json"},
+		{name: "graphql response", accept: "application/graphql-response+json", expected: "application/graphql-response+json; charset=utf-8"},
+		{name: "first listed wins", accept: "application/json, application/graphql-response+json", expected: "application/json"},
+		{name: "highest quality wins", accept: "application/json;q=0.9, application/graphql-response+json", expected: "application/graphql-response+json; charset=utf-8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Accept", tc.accept)
+			assert.Equal(t, tc.expected, responseContentType(r))
+		})
+	}
+}
+
+func TestIncrementalDelivery(t *testing.T) {
+	t.Run("should not change queries without incremental delivery", func(t *testing.T) {
+		query, delivery := newIncrementalDelivery("{ hello }", "", nil)
+		assert.Equal(t, "{ hello }", query)
+		assert.Nil(t, delivery)
+	})
+
+	t.Run("should remove disabled directives", func(t *testing.T) {
+		query, delivery := newIncrementalDelivery(`query($defer: Boolean) { hello ... @defer(if: $defer) { world } }`, "", []byte(`{"defer":false}`))
+		require.NotNil(t, delivery)
+		assert.NotContains(t, query, "@defer")
+		assert.Empty(t, delivery.deferred)
+
+		body := string(delivery.multipartMixedBody([]byte(`{"data":{"hello":"a","world":"b"}}`)))
+		assert.Equal(t, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+
+			`{"data":{"hello":"a","world":"b"},"hasNext":false}`+
+			"\r\n-----\r\n", body)
+	})
+
+	t.Run("should split deferred fragments and streamed fields", func(t *testing.T) {
+		query, delivery := newIncrementalDelivery(`
+			query Countries {
+				country {
+					code
+					... @defer(label: "details") { name capital ... @defer { currency } }
+				}
+				languages @stream(initialCount: 1) { code }
+			}`, "Countries", nil)
+		require.NotNil(t, delivery)
+		assert.NotContains(t, query, "@defer")
+		assert.NotContains(t, query, "@stream")
+
+		result := []byte(`{"data":{"country":{"code":"DE","name":"Germany","capital":"Berlin","currency":"EUR"},"languages":[{"code":"de"},{"code":"en"}]}}`)
+		body := string(delivery.multipartMixedBody(result))
+		assert.Equal(t, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+
+			`{"data":{"country":{"code":"DE"},"languages":[{"code":"de"}]},"hasNext":true}`+
+			"\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+
+			`{"incremental":[`+
+			`{"data":{"name":"Germany","capital":"Berlin"},"path":["country"],"label":"details"},`+
+			`{"data":{"currency":"EUR"},"path":["country"]},`+
+			`{"items":[{"code":"en"}],"path":["languages",1]}`+
+			`],"hasNext":false}`+
+			"\r\n-----\r\n", body)
+	})
+
+	t.Run("should deliver errors with the initial payload", func(t *testing.T) {
+		_, delivery := newIncrementalDelivery(`{ hello ... @defer { world } }`, "", nil)
+		require.NotNil(t, delivery)
+
+		body := string(delivery.multipartMixedBody([]byte(`{"errors":[{"message":"failed"}],"data":null}`)))
+		assert.Equal(t, "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"+
+			`{"errors":[{"message":"failed"}],"data":null,"hasNext":false}`+
+			"\r\n-----\r\n", body)
+	})
+}
+
+func TestSchemaDefinesIncrementalDelivery(t *testing.T) {
+	assert.False(t, schemaDefinesIncrementalDelivery([]byte(`type Query { hello: String }`)))
+	assert.True(t, schemaDefinesIncrementalDelivery([]byte(`directive @defer(label: String, if: Boolean! = true) on FRAGMENT_SPREAD | INLINE_FRAGMENT
+		type Query { hello: String }`)))
+	assert.True(t, schemaDefinesIncrementalDelivery([]byte(`directive @stream(initialCount: Int = 0) on FIELD type Query { hello: [String] }`)))
+}
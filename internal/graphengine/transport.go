@@ -4,16 +4,24 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	graphqlinternal "github.com/TykTechnologies/tyk/internal/graphql"
 )
 
 type NewReusableBodyReadCloserFunc func(io.ReadCloser) (io.ReadCloser, error)
 type SeekReadCloserFunc func(io.ReadCloser) (io.ReadCloser, error)
 
 type GraphQLEngineTransport struct {
-	originalTransport         http.RoundTripper
 	transportType             GraphQLEngineTransportType
 	newReusableBodyReadCloser NewReusableBodyReadCloserFunc
-	headersConfig             ReverseProxyHeadersConfig
+	inFlightRequests          *inFlightRequests
+
+	mu                sync.RWMutex
+	originalTransport http.RoundTripper
+	headersConfig     ReverseProxyHeadersConfig
 }
 
 func NewGraphQLEngineTransport(
@@ -27,28 +35,80 @@ func NewGraphQLEngineTransport(
 		transportType:             transportType,
 		newReusableBodyReadCloser: newReusableBodyReadCloser,
 		headersConfig:             headersConfig,
+		inFlightRequests:          newInFlightRequests(),
+	}
+	return transport
+}
+
+// newEngineTransport installs a GraphQLEngineTransport on the HTTP client of an engine. The transport is
+// shared by all requests to the API, so identical upstream requests of concurrent clients are deduplicated.
+func newEngineTransport(apiDefinition *apidef.APIDefinition, httpClient *http.Client, newReusableBodyReadCloser NewReusableBodyReadCloserFunc) *GraphQLEngineTransport {
+	var originalTransport http.RoundTripper
+	if httpClient != nil {
+		originalTransport = httpClient.Transport
+	}
+
+	transport := NewGraphQLEngineTransport(
+		DetermineGraphQLEngineTransportType(apiDefinition),
+		originalTransport,
+		newReusableBodyReadCloser,
+		ReverseProxyHeadersConfig{},
+	)
+
+	if httpClient != nil {
+		httpClient.Transport = transport
 	}
 	return transport
 }
 
+// setUpstream sets the transport used to reach the upstreams and the headers configuration of the API.
+// The engine's transport is shared by all requests to the API, so it's updated rather than replaced.
+func (g *GraphQLEngineTransport) setUpstream(originalTransport http.RoundTripper, headersConfig ReverseProxyHeadersConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if originalTransport != nil {
+		g.originalTransport = originalTransport
+	}
+	g.headersConfig = headersConfig
+}
+
+func (g *GraphQLEngineTransport) upstream() (http.RoundTripper, ReverseProxyHeadersConfig) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.originalTransport == nil {
+		return http.DefaultTransport, g.headersConfig
+	}
+	return g.originalTransport, g.headersConfig
+}
+
 func (g *GraphQLEngineTransport) RoundTrip(request *http.Request) (res *http.Response, err error) {
+	started := time.Now()
+	defer func() {
+		graphqlinternal.TrackDataSourceRequest(request, "", started, res, err)
+	}()
+
+	originalTransport, headersConfig := g.upstream()
+
 	switch g.transportType {
 	case GraphQLEngineTransportTypeProxyOnly:
 		val := GetProxyOnlyContextValue(request.Context())
 		if val != nil {
-			return g.handleProxyOnly(val, request)
+			return g.handleProxyOnly(originalTransport, headersConfig, val, request)
 		}
 	}
 
-	return g.originalTransport.RoundTrip(request)
+	return g.inFlightRequests.roundTrip(originalTransport, request)
 }
 
-func (g *GraphQLEngineTransport) handleProxyOnly(proxyOnlyValues *GraphQLProxyOnlyContextValues, request *http.Request) (*http.Response, error) {
-	request.Method = proxyOnlyValues.forwardedRequest.Method
-	g.setProxyOnlyHeaders(proxyOnlyValues, request)
-	g.applyRequestHeadersRewriteRules(request)
+func (g *GraphQLEngineTransport) handleProxyOnly(originalTransport http.RoundTripper, headersConfig ReverseProxyHeadersConfig, proxyOnlyValues *GraphQLProxyOnlyContextValues, request *http.Request) (*http.Response, error) {
+	// the operation of a GET request is sent in the body of the upstream request
+	if proxyOnlyValues.forwardedRequest.Method != http.MethodGet {
+		request.Method = proxyOnlyValues.forwardedRequest.Method
+	}
+	setProxyOnlyHeaders(headersConfig, proxyOnlyValues, request)
+	applyRequestHeadersRewriteRules(headersConfig, request)
 
-	response, err := g.originalTransport.RoundTrip(request)
+	response, err := g.inFlightRequests.roundTrip(originalTransport, request)
 	if err != nil {
 		return nil, err
 	}
@@ -77,8 +137,8 @@ func (g *GraphQLEngineTransport) handleProxyOnly(proxyOnlyValues *GraphQLProxyOn
 	return response, err
 }
 
-func (g *GraphQLEngineTransport) applyRequestHeadersRewriteRules(r *http.Request) {
-	if len(g.headersConfig.ProxyOnly.RequestHeadersRewrite) == 0 {
+func applyRequestHeadersRewriteRules(headersConfig ReverseProxyHeadersConfig, r *http.Request) {
+	if len(headersConfig.ProxyOnly.RequestHeadersRewrite) == 0 {
 		// There is no request rewrite rule, quit early.
 		return
 	}
@@ -91,7 +151,7 @@ func (g *GraphQLEngineTransport) applyRequestHeadersRewriteRules(r *http.Request
 		// different value, the value gets overwritten to the defined value before
 		// hitting the upstream.
 
-		rewriteRule, ok := g.headersConfig.ProxyOnly.RequestHeadersRewrite[key]
+		rewriteRule, ok := headersConfig.ProxyOnly.RequestHeadersRewrite[key]
 		if !ok {
 			return false // key not exists, not apply the rule
 		}
@@ -113,7 +173,7 @@ func (g *GraphQLEngineTransport) applyRequestHeadersRewriteRules(r *http.Request
 		// If header key is defined in request_headers_rewrite and remove is set
 		// to true and client sends a request with the same header key but different value,
 		// the headers gets removed completely before hitting the upstream.
-		rewriteRule, ok := g.headersConfig.ProxyOnly.RequestHeadersRewrite[key]
+		rewriteRule, ok := headersConfig.ProxyOnly.RequestHeadersRewrite[key]
 		if !ok {
 			return false // key not exists, not apply the rule
 		}
@@ -152,7 +212,7 @@ func (g *GraphQLEngineTransport) applyRequestHeadersRewriteRules(r *http.Request
 	// If header key/value is defined in request_headers_rewrite and remove is
 	// set to false and client sends a request that does not have the same header key,
 	// the header key/value gets added before hitting the upstream.
-	for headerKey, rewriteRule := range g.headersConfig.ProxyOnly.RequestHeadersRewrite {
+	for headerKey, rewriteRule := range headersConfig.ProxyOnly.RequestHeadersRewrite {
 		if rewriteRule.Remove {
 			continue
 		}
@@ -163,7 +223,7 @@ func (g *GraphQLEngineTransport) applyRequestHeadersRewriteRules(r *http.Request
 	}
 }
 
-func (g *GraphQLEngineTransport) setProxyOnlyHeaders(proxyOnlyValues *GraphQLProxyOnlyContextValues, r *http.Request) {
+func setProxyOnlyHeaders(headersConfig ReverseProxyHeadersConfig, proxyOnlyValues *GraphQLProxyOnlyContextValues, r *http.Request) {
 	for forwardedHeaderKey, forwardedHeaderValues := range proxyOnlyValues.forwardedRequest.Header {
 		if proxyOnlyValues.ignoreForwardedHeaders[forwardedHeaderKey] {
 			continue
@@ -173,7 +233,7 @@ func (g *GraphQLEngineTransport) setProxyOnlyHeaders(proxyOnlyValues *GraphQLPro
 			exitingHeaderValue := r.Header.Get(forwardedHeaderKey)
 			// Prioritize consumer's header value when immutable headers are turned on.
 			// Delete the header from request_headers add the consumer's value. See TT-11990 and TT-12190.
-			if g.headersConfig.ProxyOnly.UseImmutableHeaders && exitingHeaderValue != "" {
+			if headersConfig.ProxyOnly.UseImmutableHeaders && exitingHeaderValue != "" {
 				r.Header.Del(forwardedHeaderKey)
 			}
 			r.Header.Add(forwardedHeaderKey, forwardedHeaderValue)
@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astvisitor"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+const (
+	cacheControlDirectiveName = "cacheControl"
+	cacheControlMaxAgeArg     = "maxAge"
+)
+
+// ErrOperationNotCacheable is returned for operations that must never be served from cache.
+var ErrOperationNotCacheable = errors.New("only query operations can be cached")
+
+// CacheableOperation describes a normalised GraphQL query that can be cached.
+type CacheableOperation struct {
+	// Hash is a stable hash of the normalised operation and its variables.
+	Hash string
+	// MaxAge is the lowest @cacheControl(maxAge) hint found on the selected types and fields.
+	MaxAge int64
+	// HasMaxAge is true when at least one @cacheControl hint applies to the operation.
+	HasMaxAge bool
+}
+
+// OperationCacheKeyer builds cache keys for GraphQL operations against a single schema.
+type OperationCacheKeyer struct {
+	schema *ast.Document
+}
+
+// NewOperationCacheKeyer parses the schema once so it can be reused for every request.
+func NewOperationCacheKeyer(schema string) (*OperationCacheKeyer, error) {
+	sh, err := graphql.NewSchemaFromString(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDoc, report := astparser.ParseGraphqlDocumentBytes(sh.Document())
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	return &OperationCacheKeyer{schema: &schemaDoc}, nil
+}
+
+// CacheableOperation normalises the request so that formatting, literal arguments and
+// variable ordering don't influence the resulting hash. It returns ErrOperationNotCacheable
+// for mutations and subscriptions.
+func (k *OperationCacheKeyer) CacheableOperation(gqlRequest *graphql.Request, withCacheControl bool) (*CacheableOperation, error) {
+	operation, report := astparser.ParseGraphqlDocumentString(gqlRequest.Query)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	opType, err := operationType(&operation, gqlRequest.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	if opType != ast.OperationTypeQuery {
+		return nil, ErrOperationNotCacheable
+	}
+
+	operation.Input.Variables = gqlRequest.Variables
+	if len(operation.Input.Variables) == 0 {
+		operation.Input.Variables = []byte("{}")
+	}
+
+	normalizer := astnormalization.NewWithOpts(
+		astnormalization.WithExtractVariables(),
+		astnormalization.WithRemoveFragmentDefinitions(),
+		astnormalization.WithRemoveUnusedVariables(),
+	)
+	if gqlRequest.OperationName != "" {
+		normalizer.NormalizeNamedOperation(&operation, k.schema, []byte(gqlRequest.OperationName), &report)
+	} else {
+		normalizer.NormalizeOperation(&operation, k.schema, &report)
+	}
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	printed, err := astprinter.PrintString(&operation, k.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := canonicalVariables(operation.Input.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(gqlRequest.OperationName))
+	h.Write([]byte{0})
+	h.Write([]byte(printed))
+	h.Write([]byte{0})
+	h.Write(variables)
+
+	result := &CacheableOperation{
+		Hash: hex.EncodeToString(h.Sum(nil)),
+	}
+
+	if withCacheControl {
+		result.MaxAge, result.HasMaxAge, err = k.cacheControlMaxAge(&operation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (k *OperationCacheKeyer) cacheControlMaxAge(operation *ast.Document) (int64, bool, error) {
+	walker := astvisitor.NewWalker(48)
+	visitor := &cacheControlVisitor{
+		Walker:     &walker,
+		definition: k.schema,
+		maxAge:     math.MaxInt64,
+	}
+	walker.RegisterEnterFieldVisitor(visitor)
+
+	var report operationreport.Report
+	walker.Walk(operation, k.schema, &report)
+	if report.HasErrors() {
+		return 0, false, report
+	}
+
+	if !visitor.found {
+		return 0, false, nil
+	}
+	return visitor.maxAge, true, nil
+}
+
+// cacheControlVisitor collects the lowest maxAge hint of all selected fields and their return types.
+type cacheControlVisitor struct {
+	*astvisitor.Walker
+
+	definition *ast.Document
+	maxAge     int64
+	found      bool
+}
+
+func (v *cacheControlVisitor) EnterField(ref int) {
+	fieldDefinitionRef, ok := v.FieldDefinition(ref)
+	if !ok {
+		return
+	}
+
+	v.applyDirectives(v.definition.FieldDefinitions[fieldDefinitionRef].Directives.Refs)
+	v.applyDirectives(v.definition.NodeDirectives(v.definition.FieldDefinitionTypeNode(fieldDefinitionRef)))
+}
+
+func (v *cacheControlVisitor) applyDirectives(refs []int) {
+	for _, ref := range refs {
+		if v.definition.DirectiveNameString(ref) != cacheControlDirectiveName {
+			continue
+		}
+
+		value, ok := v.definition.DirectiveArgumentValueByName(ref, []byte(cacheControlMaxAgeArg))
+		if !ok || value.Kind != ast.ValueKindInteger {
+			continue
+		}
+
+		maxAge := v.definition.IntValueAsInt(value.Ref)
+		if maxAge < v.maxAge {
+			v.maxAge = maxAge
+		}
+		v.found = true
+	}
+}
+
+func operationType(operation *ast.Document, operationName string) (ast.OperationType, error) {
+	for _, rootNode := range operation.RootNodes {
+		if rootNode.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+
+		if operationName != "" && operation.OperationDefinitionNameString(rootNode.Ref) != operationName {
+			continue
+		}
+
+		return operation.OperationDefinitions[rootNode.Ref].OperationType, nil
+	}
+
+	return ast.OperationTypeUnknown, errors.New("operation not found in request")
+}
+
+// canonicalVariables re-encodes the variables so that object keys are sorted.
+func canonicalVariables(variables []byte) ([]byte, error) {
+	if len(variables) == 0 {
+		return nil, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(variables, &decoded); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(decoded)
+}
@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+)
+
+const cacheControlSchema = `
+directive @cacheControl(maxAge: Int) on FIELD_DEFINITION | OBJECT
+
+type Query {
+  user(id: ID!): User
+  countries: [Country] @cacheControl(maxAge: 300)
+}
+
+type Mutation {
+  updateUser(id: ID!, name: String): User
+}
+
+type User @cacheControl(maxAge: 30) {
+  id: ID
+  name: String
+  friends(first: Int): [User]
+}
+
+type Country {
+  code: String
+  name: String @cacheControl(maxAge: 120)
+}
+`
+
+func TestOperationCacheKeyer_CacheableOperation(t *testing.T) {
+	keyer, err := NewOperationCacheKeyer(cacheControlSchema)
+	require.NoError(t, err)
+
+	hashOf := func(t *testing.T, req graphql.Request) string {
+		t.Helper()
+		op, err := keyer.CacheableOperation(&req, false)
+		require.NoError(t, err)
+		return op.Hash
+	}
+
+	t.Run("formatting differences produce the same hash", func(t *testing.T) {
+		a := hashOf(t, graphql.Request{Query: `{ user(id: "1") { id name } }`})
+		b := hashOf(t, graphql.Request{Query: "query {\n  user(id: \"1\") {\n    id\n    name\n  }\n}"})
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("variable order does not matter", func(t *testing.T) {
+		query := `query ($id: ID!, $first: Int) { user(id: $id) { friends(first: $first) { id } } }`
+		a := hashOf(t, graphql.Request{Query: query, Variables: []byte(`{"id":"1","first":2}`)})
+		b := hashOf(t, graphql.Request{Query: query, Variables: []byte(`{"first":2,"id":"1"}`)})
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("different variables produce different hashes", func(t *testing.T) {
+		query := `query ($id: ID!) { user(id: $id) { id } }`
+		a := hashOf(t, graphql.Request{Query: query, Variables: []byte(`{"id":"1"}`)})
+		b := hashOf(t, graphql.Request{Query: query, Variables: []byte(`{"id":"2"}`)})
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("mutations are not cacheable", func(t *testing.T) {
+		_, err := keyer.CacheableOperation(&graphql.Request{Query: `mutation { updateUser(id: "1") { id } }`}, false)
+		assert.ErrorIs(t, err, ErrOperationNotCacheable)
+	})
+
+	t.Run("named operation selects the operation type", func(t *testing.T) {
+		query := `query Q { countries { code } } mutation M { updateUser(id: "1") { id } }`
+		_, err := keyer.CacheableOperation(&graphql.Request{Query: query, OperationName: "M"}, false)
+		assert.ErrorIs(t, err, ErrOperationNotCacheable)
+
+		_, err = keyer.CacheableOperation(&graphql.Request{Query: query, OperationName: "Q"}, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("cache control hints", func(t *testing.T) {
+		op, err := keyer.CacheableOperation(&graphql.Request{Query: `{ countries { code } }`}, true)
+		require.NoError(t, err)
+		assert.True(t, op.HasMaxAge)
+		assert.Equal(t, int64(300), op.MaxAge)
+
+		op, err = keyer.CacheableOperation(&graphql.Request{Query: `{ countries { code name } }`}, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(120), op.MaxAge)
+
+		op, err = keyer.CacheableOperation(&graphql.Request{Query: `{ countries { code } user(id: "1") { id } }`}, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(30), op.MaxAge)
+	})
+
+	t.Run("no cache control hints", func(t *testing.T) {
+		op, err := keyer.CacheableOperation(&graphql.Request{Query: `{ user(id: "1") { id } }`}, false)
+		require.NoError(t, err)
+		assert.False(t, op.HasMaxAge)
+	})
+}
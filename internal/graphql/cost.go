@@ -0,0 +1,330 @@
+package graphql
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astvisitor"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const (
+	costDirectiveName           = "cost"
+	costWeightArg               = "weight"
+	listSizeDirectiveName       = "listSize"
+	listSizeAssumedSizeArg      = "assumedSize"
+	listSizeSlicingArgumentsArg = "slicingArguments"
+
+	// compositeTypeWeight is the default weight of fields returning objects, interfaces and unions.
+	compositeTypeWeight = 1
+	// maxQueryCost caps costs, so huge list sizes don't overflow.
+	maxQueryCost = math.MaxInt32
+)
+
+// QueryCostCalculator estimates the cost of GraphQL operations against a single schema, before they are executed.
+//
+// The cost of a field is the weight of its return type, or the configured field weight, plus the cost of
+// its selections. Fields returning lists multiply that by the list size, read from the slicing arguments
+// of the field or assumed. Fragments on different types are all counted, so the cost is an upper bound.
+type QueryCostCalculator struct {
+	schema          *ast.Document
+	typeCosts       map[string]int
+	fieldCosts      map[string]apidef.GraphQLFieldCost
+	defaultListSize int
+}
+
+// NewQueryCostCalculator parses the schema once, reading @cost and @listSize directives, and merges them
+// with the configured weights.
+func NewQueryCostCalculator(schema string, conf apidef.GraphQLCostAnalysisConfig) (*QueryCostCalculator, error) {
+	sh, err := graphql.NewSchemaFromString(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDoc, report := astparser.ParseGraphqlDocumentBytes(sh.Document())
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	c := &QueryCostCalculator{
+		schema:          &schemaDoc,
+		typeCosts:       make(map[string]int),
+		fieldCosts:      make(map[string]apidef.GraphQLFieldCost),
+		defaultListSize: conf.DefaultListSize,
+	}
+	c.readDirectives()
+
+	for typeName, weight := range conf.TypeCosts {
+		c.typeCosts[typeName] = weight
+	}
+	for coordinate, fieldCost := range conf.FieldCosts {
+		c.fieldCosts[coordinate] = fieldCost
+	}
+
+	return c, nil
+}
+
+func (c *QueryCostCalculator) readDirectives() {
+	for _, node := range c.schema.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition,
+			ast.NodeKindScalarTypeDefinition, ast.NodeKindEnumTypeDefinition:
+		default:
+			continue
+		}
+
+		typeName := c.schema.NodeNameString(node)
+		for _, ref := range c.schema.NodeDirectives(node) {
+			if c.schema.DirectiveNameString(ref) != costDirectiveName {
+				continue
+			}
+			if weight, ok := c.directiveIntArgument(ref, costWeightArg); ok {
+				c.typeCosts[typeName] = weight
+			}
+		}
+
+		if node.Kind != ast.NodeKindObjectTypeDefinition && node.Kind != ast.NodeKindInterfaceTypeDefinition {
+			continue
+		}
+
+		for _, fieldRef := range c.schema.NodeFieldDefinitions(node) {
+			coordinate := typeName + "." + c.schema.FieldDefinitionNameString(fieldRef)
+			fieldCost, found := c.fieldDirectives(fieldRef)
+			if found {
+				c.fieldCosts[coordinate] = fieldCost
+			}
+		}
+	}
+}
+
+func (c *QueryCostCalculator) fieldDirectives(fieldRef int) (fieldCost apidef.GraphQLFieldCost, found bool) {
+	for _, ref := range c.schema.FieldDefinitions[fieldRef].Directives.Refs {
+		switch c.schema.DirectiveNameString(ref) {
+		case costDirectiveName:
+			if weight, ok := c.directiveIntArgument(ref, costWeightArg); ok {
+				fieldCost.Weight = weight
+				found = true
+			}
+		case listSizeDirectiveName:
+			if size, ok := c.directiveIntArgument(ref, listSizeAssumedSizeArg); ok {
+				fieldCost.AssumedSize = size
+				found = true
+			}
+
+			value, ok := c.schema.DirectiveArgumentValueByName(ref, []byte(listSizeSlicingArgumentsArg))
+			if !ok || value.Kind != ast.ValueKindList {
+				continue
+			}
+			for _, valueRef := range c.schema.ListValues[value.Ref].Refs {
+				if item := c.schema.Values[valueRef]; item.Kind == ast.ValueKindString {
+					fieldCost.SlicingArguments = append(fieldCost.SlicingArguments, c.schema.StringValueContentString(item.Ref))
+					found = true
+				}
+			}
+		}
+	}
+	return fieldCost, found
+}
+
+// directiveIntArgument reads an integer argument, the cost specification also allows weights as strings.
+func (c *QueryCostCalculator) directiveIntArgument(directiveRef int, name string) (int, bool) {
+	value, ok := c.schema.DirectiveArgumentValueByName(directiveRef, []byte(name))
+	if !ok {
+		return 0, false
+	}
+
+	switch value.Kind {
+	case ast.ValueKindInteger:
+		return int(c.schema.IntValueAsInt(value.Ref)), true
+	case ast.ValueKindString:
+		n, err := strconv.Atoi(c.schema.StringValueContentString(value.Ref))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// Cost returns the estimated cost of the requested operation.
+func (c *QueryCostCalculator) Cost(gqlRequest *graphql.Request) (int, error) {
+	operation, report := astparser.ParseGraphqlDocumentString(gqlRequest.Query)
+	if report.HasErrors() {
+		return 0, report
+	}
+
+	operation.Input.Variables = gqlRequest.Variables
+	if len(operation.Input.Variables) == 0 {
+		operation.Input.Variables = []byte("{}")
+	}
+
+	// extracting variables turns all slicing arguments into variables, with defaults applied
+	normalizer := astnormalization.NewWithOpts(
+		astnormalization.WithExtractVariables(),
+		astnormalization.WithRemoveFragmentDefinitions(),
+	)
+	if gqlRequest.OperationName != "" {
+		normalizer.NormalizeNamedOperation(&operation, c.schema, []byte(gqlRequest.OperationName), &report)
+	} else {
+		normalizer.NormalizeOperation(&operation, c.schema, &report)
+	}
+	if report.HasErrors() {
+		return 0, report
+	}
+
+	walker := astvisitor.NewWalker(48)
+	visitor := &costVisitor{
+		Walker:        &walker,
+		calculator:    c,
+		operation:     &operation,
+		operationName: gqlRequest.OperationName,
+		multipliers:   []int{1},
+	}
+	walker.RegisterEnterOperationVisitor(visitor)
+	walker.RegisterEnterFieldVisitor(visitor)
+	walker.RegisterLeaveFieldVisitor(visitor)
+
+	walker.Walk(&operation, c.schema, &report)
+	if report.HasErrors() {
+		return 0, report
+	}
+
+	return visitor.cost, nil
+}
+
+// costVisitor sums the cost of the selected fields. multipliers holds the product of the list sizes
+// of the enclosing fields.
+type costVisitor struct {
+	*astvisitor.Walker
+
+	calculator    *QueryCostCalculator
+	operation     *ast.Document
+	operationName string
+	multipliers   []int
+	cost          int
+}
+
+func (v *costVisitor) EnterOperationDefinition(ref int) {
+	if v.operationName != "" && v.operation.OperationDefinitionNameString(ref) != v.operationName {
+		v.SkipNode()
+	}
+}
+
+func (v *costVisitor) EnterField(ref int) {
+	multiplier := v.multipliers[len(v.multipliers)-1]
+
+	definitionRef, ok := v.FieldDefinition(ref)
+	if !ok {
+		// __typename and other fields without definition are free
+		v.multipliers = append(v.multipliers, multiplier)
+		return
+	}
+
+	schema := v.calculator.schema
+	coordinate := schema.NodeNameString(v.EnclosingTypeDefinition) + "." + v.operation.FieldNameString(ref)
+	fieldCost := v.calculator.fieldCosts[coordinate]
+	typeRef := schema.FieldDefinitionType(definitionRef)
+
+	weight := v.calculator.typeWeight(schema.ResolveTypeNameString(typeRef))
+	if fieldCost.Weight != 0 {
+		weight = fieldCost.Weight
+	}
+
+	size := 1
+	if schema.TypeIsList(typeRef) {
+		size = v.listSize(ref, fieldCost)
+	}
+
+	multiplier = saturatingMul(multiplier, size)
+	v.cost = saturatingAdd(v.cost, saturatingMul(multiplier, weight))
+	v.multipliers = append(v.multipliers, multiplier)
+}
+
+func (v *costVisitor) LeaveField(ref int) {
+	v.multipliers = v.multipliers[:len(v.multipliers)-1]
+}
+
+// listSize returns the largest non-negative value of the slicing arguments set on the field, or the assumed size
+// of the list.
+func (v *costVisitor) listSize(fieldRef int, fieldCost apidef.GraphQLFieldCost) int {
+	size, found := 0, false
+	for _, name := range fieldCost.SlicingArguments {
+		argRef, ok := v.operation.FieldArgument(fieldRef, []byte(name))
+		if !ok {
+			continue
+		}
+
+		value := v.operation.ArgumentValue(argRef)
+		var n int64
+		switch value.Kind {
+		case ast.ValueKindInteger:
+			n = v.operation.IntValueAsInt(value.Ref)
+		case ast.ValueKindVariable:
+			var err error
+			n, err = jsonparser.GetInt(v.operation.Input.Variables, v.operation.VariableValueNameString(value.Ref))
+			if err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		// negative sizes are invalid, so the list counts with its assumed size
+		if n < 0 {
+			continue
+		}
+
+		found = true
+		if int(n) > size {
+			size = int(n)
+		}
+	}
+
+	switch {
+	case found:
+		return size
+	case fieldCost.AssumedSize > 0:
+		return fieldCost.AssumedSize
+	case v.calculator.defaultListSize > 0:
+		return v.calculator.defaultListSize
+	}
+	return 1
+}
+
+func (c *QueryCostCalculator) typeWeight(typeName string) int {
+	if weight, ok := c.typeCosts[typeName]; ok {
+		return weight
+	}
+
+	node, ok := c.schema.Index.FirstNodeByNameStr(typeName)
+	if !ok {
+		return 0
+	}
+
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition:
+		return compositeTypeWeight
+	}
+	return 0
+}
+
+func saturatingMul(a, b int) int {
+	if a < 0 || b < 0 {
+		return a * b
+	}
+	if b != 0 && a > maxQueryCost/b {
+		return maxQueryCost
+	}
+	return a * b
+}
+
+func saturatingAdd(a, b int) int {
+	if a > maxQueryCost-b {
+		return maxQueryCost
+	}
+	return a + b
+}
@@ -0,0 +1,116 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const costSchema = `
+directive @cost(weight: Int!) on FIELD_DEFINITION | OBJECT
+directive @listSize(assumedSize: Int, slicingArguments: [String!]) on FIELD_DEFINITION
+
+type Query {
+  user(id: ID!): User
+  users(first: Int, last: Int): [User] @listSize(slicingArguments: ["first", "last"])
+  countries: [Country] @listSize(assumedSize: 250)
+  search(term: String): [SearchResult]
+}
+
+type User {
+  id: ID
+  name: String
+  friends(first: Int = 10): [User] @listSize(slicingArguments: ["first"])
+  avatar: String @cost(weight: 5)
+}
+
+type Country @cost(weight: 2) {
+  code: String
+  name: String
+}
+
+union SearchResult = User | Country
+`
+
+func TestQueryCostCalculator_Cost(t *testing.T) {
+	calculator, err := NewQueryCostCalculator(costSchema, apidef.GraphQLCostAnalysisConfig{})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		query     string
+		variables string
+		operation string
+		cost      int
+	}{
+		{name: "scalars are free", query: `{ user(id: "1") { id name } }`, cost: 1},
+		{name: "field weight", query: `{ user(id: "1") { avatar } }`, cost: 6},
+		{name: "slicing argument", query: `{ users(first: 100) { id } }`, cost: 100},
+		{name: "largest slicing argument", query: `{ users(first: 5, last: 20) { id } }`, cost: 20},
+		{name: "slicing argument from variables", query: `query ($n: Int) { users(first: $n) { id } }`, variables: `{"n":7}`, cost: 7},
+		{name: "default argument value", query: `{ user(id: "1") { friends { id } } }`, cost: 11},
+		{name: "nested lists multiply", query: `{ users(first: 10) { friends(first: 10) { avatar } } }`, cost: 10 + 100 + 500},
+		{name: "type weight and assumed size", query: `{ countries { name } }`, cost: 500},
+		{name: "lists without size count once", query: `{ search(term: "a") { ... on User { id } ... on Country { name } } }`, cost: 1},
+		{name: "fragments", query: `{ users(first: 2) { ...F } } fragment F on User { avatar }`, cost: 2 + 10},
+		{name: "named operation", query: `query A { countries { name } } query B { user(id: "1") { id } }`, operation: "B", cost: 1},
+		{name: "typename is free", query: `{ __typename }`, cost: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cost, err := calculator.Cost(&graphql.Request{Query: tc.query, Variables: []byte(tc.variables), OperationName: tc.operation})
+			require.NoError(t, err)
+			assert.Equal(t, tc.cost, cost)
+		})
+	}
+
+	t.Run("configuration takes precedence over directives", func(t *testing.T) {
+		calculator, err := NewQueryCostCalculator(costSchema, apidef.GraphQLCostAnalysisConfig{
+			TypeCosts: map[string]int{"Country": 1},
+			FieldCosts: map[string]apidef.GraphQLFieldCost{
+				"Query.countries": {AssumedSize: 10},
+				"Query.search":    {Weight: 3},
+			},
+			DefaultListSize: 4,
+		})
+		require.NoError(t, err)
+
+		cost, err := calculator.Cost(&graphql.Request{Query: `{ countries { name } search(term: "a") { ... on User { id } } }`})
+		require.NoError(t, err)
+		assert.Equal(t, 10+12, cost)
+	})
+
+	t.Run("negative slicing arguments use the assumed size", func(t *testing.T) {
+		calculator, err := NewQueryCostCalculator(costSchema, apidef.GraphQLCostAnalysisConfig{DefaultListSize: 4})
+		require.NoError(t, err)
+
+		cost, err := calculator.Cost(&graphql.Request{Query: `{ users(first: -5) { id } }`})
+		require.NoError(t, err)
+		assert.Equal(t, 4, cost)
+
+		cost, err = calculator.Cost(&graphql.Request{Query: `{ users(first: -5, last: 3) { id } }`})
+		require.NoError(t, err)
+		assert.Equal(t, 3, cost)
+
+		cost, err = calculator.Cost(&graphql.Request{Query: `query ($n: Int) { users(first: 2) { friends(first: $n) { id } } }`, Variables: []byte(`{"n":-1}`)})
+		require.NoError(t, err)
+		assert.Equal(t, 2+8, cost)
+	})
+
+	t.Run("huge lists are capped", func(t *testing.T) {
+		cost, err := calculator.Cost(&graphql.Request{Query: `{ users(first: 1000000) { friends(first: 1000000) { friends(first: 1000000) { id } } } }`})
+		require.NoError(t, err)
+		assert.Equal(t, maxQueryCost, cost)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := calculator.Cost(&graphql.Request{Query: `{ users(`})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,46 @@
+// Package datasource implements the gRPC and SQL data sources of Universal Data Graph.
+//
+// Both are built on the REST data source of the engine: its fetch configuration renders the field arguments into
+// the request body, and the HTTP client of the data source sends the request through a transport which makes the
+// gRPC call or runs the SQL query and returns the result as a JSON response.
+package datasource
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// Fetch is the request of the REST data source sent to the transport of a gRPC or SQL data source.
+type Fetch struct {
+	URL    string
+	Method string
+	Body   string
+	Header map[string]string
+}
+
+func jsonResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(http.StatusOK),
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{header.ContentType: {header.ApplicationJSON}, header.ContentLength: {strconv.Itoa(len(body))}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	return io.ReadAll(req.Body)
+}
@@ -0,0 +1,297 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+var (
+	ErrGRPCInvalidMethod     = errors.New("grpc method must be in the format package.Service/Method")
+	ErrGRPCStreamingMethod   = errors.New("grpc streaming methods are not supported")
+	ErrGRPCMissingTarget     = errors.New("grpc target is missing")
+	ErrGRPCInvalidDescriptor = errors.New("invalid grpc descriptors")
+)
+
+// grpcConns holds the connections of the gRPC data sources, so they are shared by all APIs and aren't
+// reopened on every reload.
+var grpcConns sync.Map
+
+// GRPC returns the fetch configuration and the HTTP client of a gRPC data source. The request body rendered
+// from the request template is the JSON encoded request message, the response is the JSON encoded response message.
+func GRPC(conf apidef.GraphQLEngineDataSourceConfigGRPC) (*http.Client, Fetch, error) {
+	if conf.Target == "" {
+		return nil, Fetch{}, ErrGRPCMissingTarget
+	}
+
+	service, method, ok := splitGRPCMethod(conf.Method)
+	if !ok {
+		return nil, Fetch{}, ErrGRPCInvalidMethod
+	}
+
+	transport := &grpcTransport{
+		target:  conf.Target,
+		useTLS:  conf.UseTLS,
+		service: service,
+		method:  method,
+		headers: conf.Headers,
+	}
+
+	if conf.Descriptors != "" {
+		files, err := descriptorSetFiles(conf.Descriptors)
+		if err != nil {
+			return nil, Fetch{}, err
+		}
+
+		if transport.descriptor, err = findMethod(files, service, method); err != nil {
+			return nil, Fetch{}, err
+		}
+	}
+
+	fetch := Fetch{
+		URL:    "grpc://" + conf.Target + "/" + conf.Method,
+		Method: http.MethodPost,
+		Body:   conf.Request,
+		Header: conf.Headers,
+	}
+
+	return &http.Client{Transport: transport}, fetch, nil
+}
+
+func splitGRPCMethod(fullMethod string) (service, method string, ok bool) {
+	service, method, ok = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+func descriptorSetFiles(encoded string) (*protoregistry.Files, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGRPCInvalidDescriptor, err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGRPCInvalidDescriptor, err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGRPCInvalidDescriptor, err)
+	}
+	return files, nil
+}
+
+func findMethod(files *protoregistry.Files, service, method string) (protoreflect.MethodDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("grpc service %s: %w", service, err)
+	}
+
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a grpc service", service)
+	}
+
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(method))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("grpc method %s not found in service %s", method, service)
+	}
+
+	if methodDescriptor.IsStreamingClient() || methodDescriptor.IsStreamingServer() {
+		return nil, ErrGRPCStreamingMethod
+	}
+
+	return methodDescriptor, nil
+}
+
+type grpcTransport struct {
+	target  string
+	useTLS  bool
+	service string
+	method  string
+	headers map[string]string
+
+	mu         sync.Mutex
+	descriptor protoreflect.MethodDescriptor
+}
+
+func (t *grpcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := t.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := t.methodDescriptor(req.Context(), conn)
+	if err != nil {
+		return nil, err
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, in); err != nil {
+			return nil, fmt.Errorf("invalid grpc request: %w", err)
+		}
+	}
+
+	md := metadata.MD{}
+	for key := range t.headers {
+		if values := req.Header.Values(key); len(values) > 0 {
+			md.Set(key, values...)
+		}
+	}
+
+	out := dynamicpb.NewMessage(method.Output())
+	ctx := metadata.NewOutgoingContext(req.Context(), md)
+	if err := conn.Invoke(ctx, "/"+t.service+"/"+t.method, in, out); err != nil {
+		return nil, err
+	}
+
+	data, err := (protojson.MarshalOptions{EmitUnpopulated: true}).Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResponse(req, data), nil
+}
+
+func (t *grpcTransport) conn() (*grpc.ClientConn, error) {
+	key := fmt.Sprintf("%s|%t", t.target, t.useTLS)
+	if conn, ok := grpcConns.Load(key); ok {
+		return conn.(*grpc.ClientConn), nil
+	}
+
+	creds := insecure.NewCredentials()
+	if t.useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpc.NewClient(t.target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, loaded := grpcConns.LoadOrStore(key, conn); loaded {
+		_ = conn.Close()
+		return existing.(*grpc.ClientConn), nil
+	}
+	return conn, nil
+}
+
+// methodDescriptor returns the descriptor of the method, resolving it with server reflection the first time
+// when no descriptors were uploaded.
+func (t *grpcTransport) methodDescriptor(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.descriptor != nil {
+		return t.descriptor, nil
+	}
+
+	files, err := reflectFiles(ctx, conn, t.service)
+	if err != nil {
+		return nil, fmt.Errorf("grpc reflection: %w", err)
+	}
+
+	if t.descriptor, err = findMethod(files, t.service, t.method); err != nil {
+		return nil, err
+	}
+	return t.descriptor, nil
+}
+
+// reflectFiles fetches the file defining the service and its dependencies from the reflection service of the server.
+// Dependencies the server doesn't return, like the well known types, are taken from the registered files.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}
+
+	var pending []string
+	for request != nil {
+		if err := stream.Send(request); err != nil {
+			return nil, err
+		}
+
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if errResponse := response.GetErrorResponse(); errResponse != nil {
+			return nil, errors.New(errResponse.GetErrorMessage())
+		}
+
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var file descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(raw, &file); err != nil {
+				return nil, err
+			}
+
+			files[file.GetName()] = &file
+			pending = append(pending, file.GetDependency()...)
+		}
+
+		request = nil
+		for len(pending) > 0 && request == nil {
+			name := pending[0]
+			pending = pending[1:]
+			if _, ok := files[name]; ok {
+				continue
+			}
+
+			if registered, err := protoregistry.GlobalFiles.FindFileByPath(name); err == nil {
+				files[name] = protodesc.ToFileDescriptorProto(registered)
+				pending = append(pending, files[name].GetDependency()...)
+				continue
+			}
+
+			request = &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+
+	return protodesc.NewFiles(set)
+}
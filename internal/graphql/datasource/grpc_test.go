@@ -0,0 +1,136 @@
+package datasource
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+type testHealthServer struct {
+	*health.Server
+	metadata chan metadata.MD
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.metadata <- md
+	return s.Server.Check(ctx, req)
+}
+
+func startGRPCServer(t *testing.T, withReflection bool) (string, *testHealthServer) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := &testHealthServer{Server: health.NewServer(), metadata: make(chan metadata.MD, 1)}
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	if withReflection {
+		reflection.Register(server)
+	}
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String(), healthServer
+}
+
+func grpcFetch(t *testing.T, conf apidef.GraphQLEngineDataSourceConfigGRPC) string {
+	t.Helper()
+
+	client, fetch, err := GRPC(conf)
+	require.NoError(t, err)
+	assert.Equal(t, "grpc://"+conf.Target+"/"+conf.Method, fetch.URL)
+
+	req, err := http.NewRequest(fetch.Method, fetch.URL, strings.NewReader(fetch.Body))
+	require.NoError(t, err)
+	for key, value := range fetch.Header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestGRPC(t *testing.T) {
+	t.Run("server reflection", func(t *testing.T) {
+		target, server := startGRPCServer(t, true)
+
+		body := grpcFetch(t, apidef.GraphQLEngineDataSourceConfigGRPC{
+			Target:  target,
+			Method:  "grpc.health.v1.Health/Check",
+			Request: `{"service": "users"}`,
+			Headers: map[string]string{"X-Tenant": "acme"},
+		})
+
+		assert.JSONEq(t, `{"status": "SERVING"}`, body)
+		assert.Equal(t, []string{"acme"}, (<-server.metadata).Get("x-tenant"))
+	})
+
+	t.Run("uploaded descriptors", func(t *testing.T) {
+		target, server := startGRPCServer(t, false)
+
+		set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+		}}
+		raw, err := proto.Marshal(set)
+		require.NoError(t, err)
+
+		body := grpcFetch(t, apidef.GraphQLEngineDataSourceConfigGRPC{
+			Target:      target,
+			Method:      "grpc.health.v1.Health/Check",
+			Request:     `{"service": "users", "unknownField": 1}`,
+			Descriptors: base64.StdEncoding.EncodeToString(raw),
+		})
+		<-server.metadata
+
+		assert.JSONEq(t, `{"status": "SERVING"}`, body)
+	})
+
+	t.Run("invalid configs", func(t *testing.T) {
+		_, _, err := GRPC(apidef.GraphQLEngineDataSourceConfigGRPC{Method: "grpc.health.v1.Health/Check"})
+		assert.ErrorIs(t, err, ErrGRPCMissingTarget)
+
+		_, _, err = GRPC(apidef.GraphQLEngineDataSourceConfigGRPC{Target: "localhost:1", Method: "Check"})
+		assert.ErrorIs(t, err, ErrGRPCInvalidMethod)
+
+		_, _, err = GRPC(apidef.GraphQLEngineDataSourceConfigGRPC{Target: "localhost:1", Method: "grpc.health.v1.Health/Check", Descriptors: "not base64"})
+		assert.ErrorIs(t, err, ErrGRPCInvalidDescriptor)
+
+		set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+		}}
+		raw, err := proto.Marshal(set)
+		require.NoError(t, err)
+
+		_, _, err = GRPC(apidef.GraphQLEngineDataSourceConfigGRPC{Target: "localhost:1", Method: "grpc.health.v1.Health/Watch", Descriptors: base64.StdEncoding.EncodeToString(raw)})
+		assert.ErrorIs(t, err, ErrGRPCStreamingMethod)
+	})
+}
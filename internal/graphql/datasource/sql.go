@@ -0,0 +1,230 @@
+package datasource
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	// database drivers of the SQL data source
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+var (
+	ErrSQLUnsupportedDriver = errors.New("unsupported sql driver")
+	ErrSQLQueryNotReadOnly  = errors.New("sql query must be a SELECT statement")
+)
+
+var sqlDrivers = map[string]string{
+	"postgres":   "postgres",
+	"postgresql": "postgres",
+	"sqlite":     "sqlite",
+}
+
+// databases holds the connection pools of the SQL data sources, so they are shared by all APIs and aren't
+// reopened on every reload.
+var databases sync.Map
+
+// SQL returns the fetch configuration and the HTTP client of a SQL data source. The result is a list of rows,
+// or the first row when single is true, with the values keyed by the column names.
+func SQL(conf apidef.GraphQLEngineDataSourceConfigSQL, single bool) (*http.Client, Fetch, error) {
+	driver, ok := sqlDrivers[strings.ToLower(conf.Driver)]
+	if !ok {
+		return nil, Fetch{}, fmt.Errorf("%w: %q", ErrSQLUnsupportedDriver, conf.Driver)
+	}
+
+	if !isReadOnlyQuery(conf.Query) {
+		return nil, Fetch{}, ErrSQLQueryNotReadOnly
+	}
+
+	arguments := conf.Arguments
+	if arguments == nil {
+		arguments = []string{}
+	}
+
+	// the arguments are bound as strings, the database converts them to the types of the placeholders
+	body, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, Fetch{}, err
+	}
+
+	transport := &sqlTransport{
+		driver: driver,
+		dsn:    conf.ConnectionString,
+		query:  conf.Query,
+		single: single,
+	}
+
+	fetch := Fetch{
+		URL:    "sql://" + driver,
+		Method: http.MethodPost,
+		Body:   string(body),
+	}
+
+	return &http.Client{Transport: transport}, fetch, nil
+}
+
+// SingleRow reports whether the first root field of a SQL data source returns an object rather than a list,
+// so the data source returns only the first row.
+func SingleRow(schema string, rootFields []apidef.GraphQLTypeFields) bool {
+	if len(rootFields) == 0 || len(rootFields[0].Fields) == 0 {
+		return false
+	}
+
+	doc, report := astparser.ParseGraphqlDocumentString(schema)
+	if report.HasErrors() {
+		return false
+	}
+
+	for _, node := range doc.RootNodes {
+		if doc.NodeNameString(node) != rootFields[0].Type {
+			continue
+		}
+
+		for _, ref := range doc.NodeFieldDefinitions(node) {
+			if doc.FieldDefinitionNameString(ref) == rootFields[0].Fields[0] {
+				return !doc.TypeIsList(doc.FieldDefinitionType(ref))
+			}
+		}
+	}
+
+	return false
+}
+
+// isReadOnlyQuery accepts SELECT statements and common table expressions.
+func isReadOnlyQuery(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH":
+		return true
+	}
+	return false
+}
+
+type sqlTransport struct {
+	driver string
+	dsn    string
+	query  string
+	single bool
+}
+
+func (t *sqlTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var arguments []string
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &arguments); err != nil {
+			return nil, fmt.Errorf("invalid sql arguments: %w", err)
+		}
+	}
+
+	db, err := t.db()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := t.queryRows(req, db, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{} = rows
+	if t.single {
+		result = nil
+		if len(rows) > 0 {
+			result = rows[0]
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResponse(req, data), nil
+}
+
+// queryRows runs the query in a read-only transaction, which is rolled back so nothing it did is kept even when
+// the driver doesn't enforce read-only transactions.
+func (t *sqlTransport) queryRows(req *http.Request, db *sql.DB, arguments []string) ([]map[string]interface{}, error) {
+	tx, err := db.BeginTx(req.Context(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	args := make([]interface{}, len(arguments))
+	for i := range arguments {
+		args[i] = arguments[i]
+	}
+
+	rows, err := tx.QueryContext(req.Context(), t.query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (t *sqlTransport) db() (*sql.DB, error) {
+	key := t.driver + "\x00" + t.dsn
+	if db, ok := databases.Load(key); ok {
+		return db.(*sql.DB), nil
+	}
+
+	db, err := sql.Open(t.driver, t.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, loaded := databases.LoadOrStore(key, db); loaded {
+		_ = db.Close()
+		return existing.(*sql.DB), nil
+	}
+	return db, nil
+}
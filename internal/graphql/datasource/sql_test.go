@@ -0,0 +1,80 @@
+package datasource
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func TestSQL(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "users.db")
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER, name TEXT, active BOOLEAN); INSERT INTO users VALUES (1, 'Ada', true), (2, 'Grace', false)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	query := func(t *testing.T, conf apidef.GraphQLEngineDataSourceConfigSQL, single bool) string {
+		t.Helper()
+
+		conf.Driver = "sqlite"
+		conf.ConnectionString = dsn
+		client, fetch, err := SQL(conf, single)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(fetch.Method, fetch.URL, strings.NewReader(fetch.Body))
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("rows", func(t *testing.T) {
+		body := query(t, apidef.GraphQLEngineDataSourceConfigSQL{Query: "SELECT id, name FROM users ORDER BY id"}, false)
+		assert.JSONEq(t, `[{"id": 1, "name": "Ada"}, {"id": 2, "name": "Grace"}]`, body)
+	})
+
+	t.Run("single row", func(t *testing.T) {
+		body := query(t, apidef.GraphQLEngineDataSourceConfigSQL{Query: "SELECT name FROM users WHERE id = ?", Arguments: []string{"2"}}, true)
+		assert.JSONEq(t, `{"name": "Grace"}`, body)
+
+		body = query(t, apidef.GraphQLEngineDataSourceConfigSQL{Query: "SELECT name FROM users WHERE id = ?", Arguments: []string{"3"}}, true)
+		assert.Equal(t, "null", body)
+	})
+
+	t.Run("read-only queries", func(t *testing.T) {
+		body := query(t, apidef.GraphQLEngineDataSourceConfigSQL{Query: "WITH active AS (SELECT * FROM users WHERE active) SELECT id FROM active"}, true)
+		assert.JSONEq(t, `{"id": 1}`, body)
+
+		_, _, err := SQL(apidef.GraphQLEngineDataSourceConfigSQL{Driver: "sqlite", Query: "DELETE FROM users"}, false)
+		assert.ErrorIs(t, err, ErrSQLQueryNotReadOnly)
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		_, _, err := SQL(apidef.GraphQLEngineDataSourceConfigSQL{Driver: "mysql", Query: "SELECT 1"}, false)
+		assert.ErrorIs(t, err, ErrSQLUnsupportedDriver)
+	})
+}
+
+func TestSingleRow(t *testing.T) {
+	schema := `type Query { user(id: Int!): User users: [User!]! } type User { id: Int }`
+
+	assert.True(t, SingleRow(schema, []apidef.GraphQLTypeFields{{Type: "Query", Fields: []string{"user"}}}))
+	assert.False(t, SingleRow(schema, []apidef.GraphQLTypeFields{{Type: "Query", Fields: []string{"users"}}}))
+	assert.False(t, SingleRow(schema, nil))
+}
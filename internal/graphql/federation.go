@@ -0,0 +1,1297 @@
+package graphql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/federation/sdlmerge"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// Codes of the composition errors, following the codes of the Federation 2 composition rules.
+const (
+	CompositionErrorInvalidGraphQL                  = "INVALID_GRAPHQL"
+	CompositionErrorInvalidLink                     = "INVALID_LINK_DIRECTIVE_USAGE"
+	CompositionErrorInvalidFieldSharing             = "INVALID_FIELD_SHARING"
+	CompositionErrorOverrideFromSelf                = "OVERRIDE_FROM_SELF_ERROR"
+	CompositionErrorOverrideSourceHasOverride       = "OVERRIDE_SOURCE_HAS_OVERRIDE"
+	CompositionErrorExternalMissingOnBase           = "EXTERNAL_MISSING_ON_BASE"
+	CompositionErrorRequiresInvalidFields           = "REQUIRES_INVALID_FIELDS"
+	CompositionErrorRequiresFieldsMissingExternal   = "REQUIRES_FIELDS_MISSING_EXTERNAL"
+	CompositionErrorProvidesInvalidFields           = "PROVIDES_INVALID_FIELDS"
+	CompositionErrorProvidesFieldsMissingExternal   = "PROVIDES_FIELDS_MISSING_EXTERNAL"
+	CompositionErrorInterfaceObjectUsage            = "INTERFACE_OBJECT_USAGE_ERROR"
+	CompositionErrorInterfaceKeyNotOnImplementation = "INTERFACE_KEY_NOT_ON_IMPLEMENTATION"
+	CompositionErrorReferencedInaccessible          = "REFERENCED_INACCESSIBLE"
+	CompositionErrorMergeFailure                    = "MERGE_FAILURE"
+)
+
+// The federation directives the engine plans subgraph requests with are kept in the subgraph SDLs,
+// the ones only used for composition are removed.
+const (
+	federationKeyDirective             = "key"
+	federationExternalDirective        = "external"
+	federationRequiresDirective        = "requires"
+	federationProvidesDirective        = "provides"
+	federationExtendsDirective         = "extends"
+	federationShareableDirective       = "shareable"
+	federationOverrideDirective        = "override"
+	federationInaccessibleDirective    = "inaccessible"
+	federationInterfaceObjectDirective = "interfaceObject"
+	linkDirective                      = "link"
+)
+
+var (
+	federationDirectives = []string{
+		federationKeyDirective, federationExternalDirective, federationRequiresDirective, federationProvidesDirective,
+		federationExtendsDirective, federationShareableDirective, federationOverrideDirective,
+		federationInaccessibleDirective, federationInterfaceObjectDirective,
+		"tag", "composeDirective", "authenticated", "requiresScopes", "policy",
+	}
+
+	compositionDirectives = []string{
+		federationShareableDirective, federationOverrideDirective, federationInaccessibleDirective,
+		federationInterfaceObjectDirective, "tag", "composeDirective", "authenticated", "requiresScopes", "policy",
+		linkDirective,
+	}
+
+	// federationSpecTypes are the types subgraphs define for the arguments of the federation directives.
+	federationSpecTypes = map[string]struct{}{
+		"_FieldSet": {}, "FieldSet": {}, "federation__FieldSet": {}, "federation__Scope": {},
+		"federation__Policy": {}, "link__Import": {}, "link__Purpose": {},
+	}
+
+	federationLinkURL      = regexp.MustCompile(`/federation/v(\d+)\.(\d+)$`)
+	schemaExtensionKeyword = regexp.MustCompile(`\bextend\s+schema\b`)
+)
+
+// CompositionError is a rule violation found while composing the subgraphs of a supergraph.
+type CompositionError struct {
+	Code     string
+	Subgraph string
+	Message  string
+}
+
+func (e CompositionError) Error() string {
+	if e.Subgraph == "" {
+		return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%s] subgraph %q: %s", e.Code, e.Subgraph, e.Message)
+}
+
+// CompositionErrors are all the errors found composing a supergraph.
+type CompositionErrors []CompositionError
+
+func (e CompositionErrors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// SupergraphComposition is the result of composing the subgraphs of a supergraph.
+type SupergraphComposition struct {
+	// SupergraphSDL is the schema exposed to clients, without the types and fields marked @inaccessible.
+	SupergraphSDL string
+	// SubgraphSDLs are the subgraph SDLs rewritten to the Federation 1 form the engine plans requests with, in the
+	// order of the subgraphs of the config. They're empty for subgraphs without SDL.
+	SubgraphSDLs []string
+}
+
+// ComposeSupergraph composes the subgraphs of a supergraph when any of them opts into Federation 2 with @link.
+// It returns nil when all subgraphs are Federation 1 subgraphs, whose SDLs the engine merges as they are.
+//
+// The errors are CompositionErrors, which hold every rule violation found.
+func ComposeSupergraph(conf apidef.GraphQLSupergraphConfig) (*SupergraphComposition, error) {
+	var (
+		subgraphs []*federationSubgraph
+		errs      CompositionErrors
+		v2        bool
+	)
+
+	for i, entity := range conf.Subgraphs {
+		if entity.SDL == "" {
+			continue
+		}
+
+		name := entity.Name
+		if name == "" {
+			name = entity.APIID
+		}
+
+		subgraph, err := parseFederationSubgraph(i, name, entity.SDL)
+		if err != nil {
+			// errors are only reported for supergraphs composed here, the engine reports them for the others
+			v2 = v2 || strings.Contains(entity.SDL, "/federation/v")
+			errs = append(errs, *err)
+			continue
+		}
+
+		v2 = v2 || subgraph.v2
+		subgraphs = append(subgraphs, subgraph)
+	}
+
+	if !v2 {
+		return nil, nil
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	c := newFederationComposer(subgraphs)
+	if errs := c.validate(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	composition := &SupergraphComposition{SubgraphSDLs: make([]string, len(conf.Subgraphs))}
+	sdls := make([]string, 0, len(subgraphs))
+	for _, subgraph := range subgraphs {
+		sdl, err := c.engineSDL(subgraph)
+		if err != nil {
+			return nil, CompositionErrors{{Code: CompositionErrorMergeFailure, Subgraph: subgraph.name, Message: err.Error()}}
+		}
+
+		composition.SubgraphSDLs[subgraph.index] = sdl
+		sdls = append(sdls, sdl)
+	}
+
+	merged, err := sdlmerge.MergeSDLs(sdls...)
+	if err != nil {
+		return nil, CompositionErrors{{Code: CompositionErrorMergeFailure, Message: err.Error()}}
+	}
+
+	composition.SupergraphSDL, err = c.removeInaccessible(merged)
+	if err != nil {
+		return nil, CompositionErrors{{Code: CompositionErrorMergeFailure, Message: err.Error()}}
+	}
+
+	return composition, nil
+}
+
+type federationSubgraph struct {
+	index int
+	name  string
+	doc   *ast.Document
+	v2    bool
+	// directives maps the names the subgraph uses for federation directives, as imported by @link, to their names
+	// in the federation spec.
+	directives map[string]string
+
+	types             map[string]*federationType
+	inaccessibleTypes map[string]struct{}
+}
+
+type federationType struct {
+	name            string
+	nodes           []ast.Node
+	isInterface     bool
+	definition      bool
+	shareable       bool
+	interfaceObject bool
+	keys            []federationKey
+	implements      []string
+	fields          map[string]*federationField
+}
+
+type federationKey struct {
+	directive  int
+	fieldSet   string
+	resolvable bool
+}
+
+type federationField struct {
+	ref           int
+	returnType    string
+	argumentTypes []string
+	external      bool
+	shareable     bool
+	inaccessible  bool
+	override      string
+	requires      string
+	provides      string
+}
+
+func (t *federationType) isEntity() bool {
+	return len(t.keys) > 0
+}
+
+// isStub reports whether the type only references an entity, with all its keys marked resolvable: false.
+func (t *federationType) isStub() bool {
+	for _, key := range t.keys {
+		if key.resolvable {
+			return false
+		}
+	}
+	return len(t.keys) > 0
+}
+
+func (t *federationType) isKeyField(name string) bool {
+	for _, key := range t.keys {
+		for _, field := range fieldSetFields(key.fieldSet) {
+			if field == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *federationType) externalFields() int {
+	count := 0
+	for _, field := range t.fields {
+		if field.external {
+			count++
+		}
+	}
+	return count
+}
+
+func parseFederationSubgraph(index int, name, sdl string) (*federationSubgraph, *CompositionError) {
+	doc, report := astparser.ParseGraphqlDocumentString(closeSchemaExtensions(sdl))
+	if report.HasErrors() {
+		return nil, &CompositionError{Code: CompositionErrorInvalidGraphQL, Subgraph: name, Message: report.Error()}
+	}
+
+	s := &federationSubgraph{
+		index:             index,
+		name:              name,
+		doc:               &doc,
+		directives:        map[string]string{},
+		types:             map[string]*federationType{},
+		inaccessibleTypes: map[string]struct{}{},
+	}
+
+	if err := s.readLink(); err != nil {
+		return nil, err
+	}
+
+	s.renameDirectives()
+	s.collectTypes()
+
+	return s, nil
+}
+
+// readLink detects the @link to the federation spec of Federation 2 subgraphs and the names its directives are
+// imported with.
+func (s *federationSubgraph) readLink() *CompositionError {
+	doc := s.doc
+	for _, node := range doc.RootNodes {
+		var directives ast.DirectiveList
+		switch node.Kind {
+		case ast.NodeKindSchemaDefinition:
+			directives = doc.SchemaDefinitions[node.Ref].Directives
+		case ast.NodeKindSchemaExtension:
+			directives = doc.SchemaExtensions[node.Ref].Directives
+		default:
+			continue
+		}
+
+		for _, ref := range directives.Refs {
+			if doc.DirectiveNameString(ref) != linkDirective {
+				continue
+			}
+
+			url, _ := directiveStringArgument(doc, ref, "url")
+			version := federationLinkURL.FindStringSubmatch(url)
+			if version == nil {
+				continue
+			}
+
+			if version[1] != "2" {
+				return &CompositionError{
+					Code:     CompositionErrorInvalidLink,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf("unsupported federation version v%s.%s in @link", version[1], version[2]),
+				}
+			}
+
+			s.v2 = true
+
+			namespace := "federation"
+			if as, ok := directiveStringArgument(doc, ref, "as"); ok {
+				namespace = strings.TrimPrefix(as, "@")
+			}
+			for _, directive := range federationDirectives {
+				s.directives[namespace+"__"+directive] = directive
+			}
+
+			imports, ok := doc.DirectiveArgumentValueByName(ref, []byte("import"))
+			if !ok || imports.Kind != ast.ValueKindList {
+				continue
+			}
+
+			for _, valueRef := range doc.ListValues[imports.Ref].Refs {
+				name, as := linkImport(doc, doc.Value(valueRef))
+				if strings.HasPrefix(name, "@") {
+					s.directives[strings.TrimPrefix(as, "@")] = strings.TrimPrefix(name, "@")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// renameDirectives gives the imported federation directives their names in the federation spec.
+func (s *federationSubgraph) renameDirectives() {
+	doc := s.doc
+	for ref := range doc.Directives {
+		canonical, ok := s.directives[doc.DirectiveNameString(ref)]
+		if ok && canonical != doc.DirectiveNameString(ref) {
+			doc.Directives[ref].Name = doc.Input.AppendInputString(canonical)
+		}
+	}
+}
+
+func (s *federationSubgraph) collectTypes() {
+	doc := s.doc
+	for _, node := range doc.RootNodes {
+		if directives := rootNodeDirectives(doc, node); directives != nil && directives.HasDirectiveByName(doc, federationInaccessibleDirective) {
+			s.inaccessibleTypes[doc.NodeNameString(node)] = struct{}{}
+		}
+
+		parts, ok := fieldedTypeParts(doc, node)
+		if !ok {
+			continue
+		}
+
+		name := doc.NodeNameString(node)
+		t, ok := s.types[name]
+		if !ok {
+			t = &federationType{name: name, fields: map[string]*federationField{}}
+			s.types[name] = t
+		}
+
+		t.nodes = append(t.nodes, node)
+		t.isInterface = node.Kind == ast.NodeKindInterfaceTypeDefinition || node.Kind == ast.NodeKindInterfaceTypeExtension
+		t.definition = t.definition || node.Kind == ast.NodeKindObjectTypeDefinition || node.Kind == ast.NodeKindInterfaceTypeDefinition
+
+		for _, ref := range parts.directives.Refs {
+			switch doc.DirectiveNameString(ref) {
+			case federationKeyDirective:
+				fieldSet, _ := directiveStringArgument(doc, ref, "fields")
+				resolvable, ok := directiveBooleanArgument(doc, ref, "resolvable")
+				t.keys = append(t.keys, federationKey{directive: ref, fieldSet: fieldSet, resolvable: !ok || resolvable})
+			case federationShareableDirective:
+				t.shareable = true
+			case federationInterfaceObjectDirective:
+				t.interfaceObject = true
+			}
+		}
+
+		if parts.implements != nil {
+			for _, ref := range parts.implements.Refs {
+				t.implements = append(t.implements, doc.ResolveTypeNameString(ref))
+			}
+		}
+
+		for _, ref := range parts.fields.Refs {
+			t.fields[doc.FieldDefinitionNameString(ref)] = s.collectField(ref)
+		}
+	}
+}
+
+func (s *federationSubgraph) collectField(ref int) *federationField {
+	doc := s.doc
+	definition := doc.FieldDefinitions[ref]
+	field := &federationField{ref: ref, returnType: doc.ResolveTypeNameString(definition.Type)}
+
+	for _, argRef := range definition.ArgumentsDefinition.Refs {
+		field.argumentTypes = append(field.argumentTypes, doc.ResolveTypeNameString(doc.InputValueDefinitions[argRef].Type))
+	}
+
+	for _, directiveRef := range definition.Directives.Refs {
+		switch doc.DirectiveNameString(directiveRef) {
+		case federationExternalDirective:
+			field.external = true
+		case federationShareableDirective:
+			field.shareable = true
+		case federationInaccessibleDirective:
+			field.inaccessible = true
+		case federationOverrideDirective:
+			field.override, _ = directiveStringArgument(doc, directiveRef, "from")
+		case federationRequiresDirective:
+			field.requires, _ = directiveStringArgument(doc, directiveRef, "fields")
+		case federationProvidesDirective:
+			field.provides, _ = directiveStringArgument(doc, directiveRef, "fields")
+		}
+	}
+
+	return field
+}
+
+// federationComposer holds what's known about the types across all subgraphs.
+type federationComposer struct {
+	subgraphs []*federationSubgraph
+
+	// owners are the subgraphs keeping the definition of entities, the others extend them.
+	owners map[string]*federationSubgraph
+	// overrides maps the fields moved by @override to the subgraph they're removed from.
+	overrides          map[string]string
+	implementations    map[string][]string
+	inaccessibleTypes  map[string]struct{}
+	inaccessibleFields map[string]struct{}
+}
+
+func newFederationComposer(subgraphs []*federationSubgraph) *federationComposer {
+	c := &federationComposer{
+		subgraphs:          subgraphs,
+		owners:             map[string]*federationSubgraph{},
+		overrides:          map[string]string{},
+		implementations:    map[string][]string{},
+		inaccessibleTypes:  map[string]struct{}{},
+		inaccessibleFields: map[string]struct{}{},
+	}
+
+	ownerExternals := map[string]int{}
+	for _, s := range subgraphs {
+		for name := range s.inaccessibleTypes {
+			c.inaccessibleTypes[name] = struct{}{}
+		}
+
+		for _, name := range sortedTypeNames(s) {
+			t := s.types[name]
+			for _, iface := range t.implements {
+				if !slices.Contains(c.implementations[iface], t.name) {
+					c.implementations[iface] = append(c.implementations[iface], t.name)
+				}
+			}
+
+			for fieldName, field := range t.fields {
+				if field.override != "" {
+					c.overrides[t.name+"."+fieldName] = field.override
+				}
+				if field.inaccessible {
+					c.inaccessibleFields[t.name+"."+fieldName] = struct{}{}
+				}
+			}
+
+			// the entity is defined by the first subgraph with the fewest @external fields, which is usually the
+			// one the entity originates from
+			if t.isInterface || t.interfaceObject || !t.isEntity() || !t.definition || t.isStub() {
+				continue
+			}
+			if owner, ok := ownerExternals[t.name]; !ok || t.externalFields() < owner {
+				ownerExternals[t.name] = t.externalFields()
+				c.owners[t.name] = s
+			}
+		}
+	}
+
+	return c
+}
+
+func (c *federationComposer) validate() CompositionErrors {
+	var errs CompositionErrors
+	errs = append(errs, c.validateOverrides()...)
+	errs = append(errs, c.validateFieldSharing()...)
+	errs = append(errs, c.validateExternals()...)
+	errs = append(errs, c.validateInterfaceObjects()...)
+	errs = append(errs, c.validateInaccessible()...)
+	return errs
+}
+
+func (c *federationComposer) validateOverrides() CompositionErrors {
+	var errs CompositionErrors
+	c.eachField(func(s *federationSubgraph, t *federationType, name string, field *federationField) {
+		if field.override == "" {
+			return
+		}
+
+		if field.override == s.name {
+			errs = append(errs, CompositionError{
+				Code:     CompositionErrorOverrideFromSelf,
+				Subgraph: s.name,
+				Message:  fmt.Sprintf(`Source and destination subgraphs "%s" are the same for overridden field "%s.%s"`, s.name, t.name, name),
+			})
+			return
+		}
+
+		source := c.subgraph(field.override)
+		if source == nil {
+			return
+		}
+		if sourceType, ok := source.types[t.name]; ok {
+			if sourceField, ok := sourceType.fields[name]; ok && sourceField.override != "" {
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorOverrideSourceHasOverride,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`Field "%s.%s" is overridden from subgraph "%s", which also overrides it`, t.name, name, source.name),
+				})
+			}
+		}
+	})
+	return errs
+}
+
+// validateFieldSharing checks that fields resolved by more than one subgraph are marked @shareable by all of them.
+// Key fields and the fields of Federation 1 subgraphs are always shareable.
+func (c *federationComposer) validateFieldSharing() CompositionErrors {
+	type resolver struct {
+		subgraph  string
+		shareable bool
+	}
+
+	resolvers := map[string][]resolver{}
+	var names []string
+	c.eachField(func(s *federationSubgraph, t *federationType, name string, field *federationField) {
+		if t.isInterface || t.interfaceObject || field.external || c.isOverriddenIn(s, t.name, name) {
+			return
+		}
+
+		coordinate := t.name + "." + name
+		if _, ok := resolvers[coordinate]; !ok {
+			names = append(names, coordinate)
+		}
+		resolvers[coordinate] = append(resolvers[coordinate], resolver{
+			subgraph:  s.name,
+			shareable: !s.v2 || field.shareable || t.shareable || t.isKeyField(name),
+		})
+	})
+
+	var errs CompositionErrors
+	for _, coordinate := range names {
+		if len(resolvers[coordinate]) < 2 {
+			continue
+		}
+
+		var subgraphs, nonShareable []string
+		for _, r := range resolvers[coordinate] {
+			subgraphs = append(subgraphs, fmt.Sprintf("%q", r.subgraph))
+			if !r.shareable {
+				nonShareable = append(nonShareable, r.subgraph)
+			}
+		}
+
+		if len(nonShareable) > 0 {
+			errs = append(errs, CompositionError{
+				Code:     CompositionErrorInvalidFieldSharing,
+				Subgraph: nonShareable[0],
+				Message: fmt.Sprintf(`Non-shareable field "%s" is resolved from multiple subgraphs: it is resolved from subgraphs %s and defined as non-shareable in subgraph "%s"`,
+					coordinate, strings.Join(subgraphs, ", "), nonShareable[0]),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateExternals checks @external fields are defined by another subgraph, and the field sets of @requires and
+// @provides in Federation 2 subgraphs.
+func (c *federationComposer) validateExternals() CompositionErrors {
+	var errs CompositionErrors
+	c.eachField(func(s *federationSubgraph, t *federationType, name string, field *federationField) {
+		if field.external && !t.interfaceObject && !c.isResolvedOutside(s, t.name, name) {
+			errs = append(errs, CompositionError{
+				Code:     CompositionErrorExternalMissingOnBase,
+				Subgraph: s.name,
+				Message:  fmt.Sprintf(`Field "%s.%s" is marked @external on all the subgraphs in which it is listed`, t.name, name),
+			})
+		}
+
+		if !s.v2 {
+			return
+		}
+
+		for _, required := range fieldSetFields(field.requires) {
+			requiredField, ok := t.fields[required]
+			switch {
+			case !ok:
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorRequiresInvalidFields,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`On field "%s.%s", for @requires(fields: "%s"): Cannot query field "%s" on type "%s"`, t.name, name, field.requires, required, t.name),
+				})
+			case !requiredField.external:
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorRequiresFieldsMissingExternal,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`On field "%s.%s", for @requires(fields: "%s"): field "%s.%s" should not be part of a @requires since it is already provided by this subgraph (it is not marked @external)`, t.name, name, field.requires, t.name, required),
+				})
+			}
+		}
+
+		if field.provides == "" {
+			return
+		}
+
+		provided := s.types[field.returnType]
+		for _, providedName := range fieldSetFields(field.provides) {
+			var providedField *federationField
+			if provided != nil {
+				providedField = provided.fields[providedName]
+			}
+
+			switch {
+			case providedField == nil:
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorProvidesInvalidFields,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`On field "%s.%s", for @provides(fields: "%s"): Cannot query field "%s" on type "%s"`, t.name, name, field.provides, providedName, field.returnType),
+				})
+			case !providedField.external:
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorProvidesFieldsMissingExternal,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`On field "%s.%s", for @provides(fields: "%s"): field "%s.%s" should not be part of a @provides since it is already provided by this subgraph (it is not marked @external)`, t.name, name, field.provides, field.returnType, providedName),
+				})
+			}
+		}
+	})
+	return errs
+}
+
+func (c *federationComposer) validateInterfaceObjects() CompositionErrors {
+	var errs CompositionErrors
+	for _, s := range c.subgraphs {
+		for _, name := range sortedTypeNames(s) {
+			t := s.types[name]
+
+			if t.isInterface && t.isEntity() {
+				for _, implementation := range c.implementations[t.name] {
+					if it, ok := s.types[implementation]; ok && !it.isEntity() {
+						errs = append(errs, CompositionError{
+							Code:     CompositionErrorInterfaceKeyNotOnImplementation,
+							Subgraph: s.name,
+							Message:  fmt.Sprintf(`Key on interface type "%s" is missing on implementation type "%s"`, t.name, implementation),
+						})
+					}
+				}
+			}
+
+			if !t.interfaceObject {
+				continue
+			}
+
+			if !t.isEntity() {
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorInterfaceObjectUsage,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`The @interfaceObject directive can only be applied to entity types but type "%s" has no @key`, t.name),
+				})
+				continue
+			}
+
+			if iface := c.entityInterface(t.name); iface == nil {
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorInterfaceObjectUsage,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`Type "%s" is declared with @interfaceObject but no subgraph defines an interface "%s" with a @key`, t.name, t.name),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// validateInaccessible checks that fields which stay in the supergraph don't use types marked @inaccessible.
+func (c *federationComposer) validateInaccessible() CompositionErrors {
+	var errs CompositionErrors
+	c.eachField(func(s *federationSubgraph, t *federationType, name string, field *federationField) {
+		if c.isInaccessible(t.name, name) || field.external {
+			return
+		}
+
+		for _, typeName := range append([]string{field.returnType}, field.argumentTypes...) {
+			if _, ok := c.inaccessibleTypes[typeName]; ok {
+				errs = append(errs, CompositionError{
+					Code:     CompositionErrorReferencedInaccessible,
+					Subgraph: s.name,
+					Message:  fmt.Sprintf(`Type "%s" is @inaccessible but is referenced by "%s.%s", which is in the API schema`, typeName, t.name, name),
+				})
+			}
+		}
+	})
+	return errs
+}
+
+// engineSDL rewrites the subgraph to the Federation 1 form: entities are defined by a single subgraph and extended
+// by the others with @external key fields, fields moved by @override are removed from their source and types marked
+// @interfaceObject extend the interface and its implementations.
+//
+// Entity representations sent for an @interfaceObject carry the __typename of the implementation.
+func (c *federationComposer) engineSDL(s *federationSubgraph) (string, error) {
+	doc := s.doc
+
+	for _, name := range sortedTypeNames(s) {
+		t := s.types[name]
+
+		for fieldName, field := range t.fields {
+			if c.isOverriddenIn(s, t.name, fieldName) {
+				s.removeField(t, field.ref)
+				delete(t.fields, fieldName)
+			}
+		}
+
+		for _, key := range t.keys {
+			removeDirectiveArgument(doc, key.directive, "resolvable")
+		}
+
+		switch {
+		case t.interfaceObject:
+			c.extendInterface(s, t)
+		case !t.isInterface && c.owners[t.name] != nil && c.owners[t.name] != s:
+			c.extendEntity(s, t)
+		}
+	}
+
+	s.removeLink()
+	s.removeCompositionDirectives()
+
+	return astprinter.PrintString(doc, nil)
+}
+
+// extendEntity turns the definitions of an entity owned by another subgraph into extensions.
+func (c *federationComposer) extendEntity(s *federationSubgraph, t *federationType) {
+	doc := s.doc
+
+	for i, node := range t.nodes {
+		if node.Kind != ast.NodeKindObjectTypeDefinition {
+			continue
+		}
+
+		ref := doc.AddObjectTypeDefinitionExtension(ast.ObjectTypeExtension{ObjectTypeDefinition: doc.ObjectTypeDefinitions[node.Ref]})
+		extension := ast.Node{Kind: ast.NodeKindObjectTypeExtension, Ref: ref}
+		s.replaceRootNode(node, extension)
+		t.nodes[i] = extension
+	}
+
+	if !t.isEntity() && len(t.nodes) > 0 {
+		owner := c.owners[t.name].types[t.name]
+		for _, key := range owner.keys {
+			if key.resolvable {
+				s.addKey(t, key.fieldSet)
+				break
+			}
+		}
+	}
+
+	stub := t.isStub()
+	for name, field := range t.fields {
+		if stub || t.isKeyField(name) {
+			s.markExternal(field)
+		}
+	}
+}
+
+// extendInterface turns an @interfaceObject into extensions of the interface and of all its implementations.
+func (c *federationComposer) extendInterface(s *federationSubgraph, t *federationType) {
+	doc := s.doc
+
+	for name, field := range t.fields {
+		if t.isKeyField(name) {
+			s.markExternal(field)
+		}
+	}
+
+	for i, node := range t.nodes {
+		var object ast.ObjectTypeDefinition
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition:
+			object = doc.ObjectTypeDefinitions[node.Ref]
+		case ast.NodeKindObjectTypeExtension:
+			object = doc.ObjectTypeExtensions[node.Ref].ObjectTypeDefinition
+		default:
+			continue
+		}
+
+		// the interface already has the key fields, which are only needed by the implementations
+		fields := filterRefs(object.FieldsDefinition.Refs, func(ref int) bool {
+			return !t.isKeyField(doc.FieldDefinitionNameString(ref))
+		})
+
+		ref := doc.AddInterfaceTypeExtension(ast.InterfaceTypeExtension{InterfaceTypeDefinition: ast.InterfaceTypeDefinition{
+			Name:                object.Name,
+			HasDirectives:       object.HasDirectives,
+			Directives:          object.Directives,
+			HasFieldDefinitions: len(fields) > 0,
+			FieldsDefinition:    ast.FieldDefinitionList{Refs: fields},
+		}})
+		extension := ast.Node{Kind: ast.NodeKindInterfaceTypeExtension, Ref: ref}
+		s.replaceRootNode(node, extension)
+		t.nodes[i] = extension
+
+		for _, implementation := range c.implementations[t.name] {
+			ref := doc.AddObjectTypeDefinitionExtension(ast.ObjectTypeExtension{ObjectTypeDefinition: ast.ObjectTypeDefinition{
+				Name:                doc.Input.AppendInputString(implementation),
+				HasDirectives:       object.HasDirectives,
+				Directives:          ast.DirectiveList{Refs: append([]int(nil), object.Directives.Refs...)},
+				HasFieldDefinitions: object.HasFieldDefinitions,
+				FieldsDefinition:    ast.FieldDefinitionList{Refs: append([]int(nil), object.FieldsDefinition.Refs...)},
+			}})
+			doc.AddRootNode(ast.Node{Kind: ast.NodeKindObjectTypeExtension, Ref: ref})
+		}
+	}
+}
+
+// removeInaccessible removes the types and fields marked @inaccessible from the merged supergraph SDL.
+func (c *federationComposer) removeInaccessible(sdl string) (string, error) {
+	if len(c.inaccessibleTypes) == 0 && len(c.inaccessibleFields) == 0 {
+		return sdl, nil
+	}
+
+	doc, report := astparser.ParseGraphqlDocumentString(sdl)
+	if report.HasErrors() {
+		return "", report
+	}
+
+	rootNodes := make([]ast.Node, 0, len(doc.RootNodes))
+	for _, node := range doc.RootNodes {
+		name := doc.NodeNameString(node)
+		if _, ok := c.inaccessibleTypes[name]; ok {
+			continue
+		}
+
+		if parts, ok := fieldedTypeParts(&doc, node); ok {
+			parts.fields.Refs = filterRefs(parts.fields.Refs, func(ref int) bool {
+				return !c.isInaccessible(name, doc.FieldDefinitionNameString(ref))
+			})
+			*parts.hasFields = len(parts.fields.Refs) > 0
+
+			if parts.implements != nil {
+				parts.implements.Refs = filterRefs(parts.implements.Refs, func(ref int) bool {
+					_, ok := c.inaccessibleTypes[doc.ResolveTypeNameString(ref)]
+					return !ok
+				})
+			}
+		}
+
+		if node.Kind == ast.NodeKindUnionTypeDefinition {
+			union := &doc.UnionTypeDefinitions[node.Ref]
+			union.UnionMemberTypes.Refs = filterRefs(union.UnionMemberTypes.Refs, func(ref int) bool {
+				_, ok := c.inaccessibleTypes[doc.ResolveTypeNameString(ref)]
+				return !ok
+			})
+			union.HasUnionMemberTypes = len(union.UnionMemberTypes.Refs) > 0
+		}
+
+		rootNodes = append(rootNodes, node)
+	}
+	doc.RootNodes = rootNodes
+
+	return astprinter.PrintString(&doc, nil)
+}
+
+func (c *federationComposer) eachField(fn func(s *federationSubgraph, t *federationType, name string, field *federationField)) {
+	for _, s := range c.subgraphs {
+		for _, typeName := range sortedTypeNames(s) {
+			t := s.types[typeName]
+			for _, name := range sortedFieldNames(t) {
+				fn(s, t, name, t.fields[name])
+			}
+		}
+	}
+}
+
+func (c *federationComposer) subgraph(name string) *federationSubgraph {
+	for _, s := range c.subgraphs {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *federationComposer) isOverriddenIn(s *federationSubgraph, typeName, fieldName string) bool {
+	source, ok := c.overrides[typeName+"."+fieldName]
+	return ok && source == s.name && s.types[typeName].fields[fieldName].override == ""
+}
+
+func (c *federationComposer) isInaccessible(typeName, fieldName string) bool {
+	_, ok := c.inaccessibleFields[typeName+"."+fieldName]
+	return ok
+}
+
+// isResolvedOutside reports whether a subgraph other than s resolves the field.
+func (c *federationComposer) isResolvedOutside(s *federationSubgraph, typeName, fieldName string) bool {
+	for _, other := range c.subgraphs {
+		if other == s {
+			continue
+		}
+		if t, ok := other.types[typeName]; ok {
+			if field, ok := t.fields[fieldName]; ok && !field.external {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *federationComposer) entityInterface(name string) *federationType {
+	for _, s := range c.subgraphs {
+		if t, ok := s.types[name]; ok && t.isInterface && t.definition && t.isEntity() {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *federationSubgraph) replaceRootNode(old, new ast.Node) {
+	for i := range s.doc.RootNodes {
+		if s.doc.RootNodes[i] == old {
+			s.doc.UpdateRootNode(i, new.Ref, new.Kind)
+			return
+		}
+	}
+}
+
+func (s *federationSubgraph) removeField(t *federationType, fieldRef int) {
+	for _, node := range t.nodes {
+		parts, _ := fieldedTypeParts(s.doc, node)
+		parts.fields.Refs = filterRefs(parts.fields.Refs, func(ref int) bool {
+			return ref != fieldRef
+		})
+		*parts.hasFields = len(parts.fields.Refs) > 0
+	}
+}
+
+func (s *federationSubgraph) markExternal(field *federationField) {
+	if field.external {
+		return
+	}
+
+	definition := &s.doc.FieldDefinitions[field.ref]
+	definition.Directives.Refs = append(definition.Directives.Refs, s.doc.ImportDirective(federationExternalDirective, nil))
+	definition.HasDirectives = true
+	field.external = true
+}
+
+func (s *federationSubgraph) addKey(t *federationType, fieldSet string) {
+	doc := s.doc
+	value := ast.Value{Kind: ast.ValueKindString, Ref: doc.ImportStringValue([]byte(fieldSet), false)}
+	directive := doc.ImportDirective(federationKeyDirective, []int{doc.ImportArgument("fields", value)})
+
+	parts, _ := fieldedTypeParts(doc, t.nodes[0])
+	parts.directives.Refs = append(parts.directives.Refs, directive)
+	*parts.hasDirectives = true
+	t.keys = append(t.keys, federationKey{directive: directive, fieldSet: fieldSet, resolvable: true})
+}
+
+// removeLink removes the @link to the federation spec and the definitions of the directives and types it imports.
+func (s *federationSubgraph) removeLink() {
+	doc := s.doc
+
+	definitions := map[string]struct{}{linkDirective: {}}
+	for local, canonical := range s.directives {
+		definitions[local] = struct{}{}
+		definitions[canonical] = struct{}{}
+	}
+	if s.v2 {
+		for _, directive := range federationDirectives {
+			definitions[directive] = struct{}{}
+		}
+	}
+
+	rootNodes := make([]ast.Node, 0, len(doc.RootNodes))
+	for _, node := range doc.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindSchemaDefinition, ast.NodeKindSchemaExtension:
+			var schema *ast.SchemaDefinition
+			if node.Kind == ast.NodeKindSchemaExtension {
+				schema = &doc.SchemaExtensions[node.Ref].SchemaDefinition
+			} else {
+				schema = &doc.SchemaDefinitions[node.Ref]
+			}
+
+			removeDirectives(doc, &schema.Directives, &schema.HasDirectives, linkDirective)
+			if !schema.HasDirectives && len(schema.RootOperationTypeDefinitions.Refs) == 0 {
+				continue
+			}
+		case ast.NodeKindDirectiveDefinition:
+			if _, ok := definitions[doc.DirectiveDefinitionNameString(node.Ref)]; ok {
+				continue
+			}
+		case ast.NodeKindScalarTypeDefinition, ast.NodeKindEnumTypeDefinition:
+			if _, ok := federationSpecTypes[doc.NodeNameString(node)]; ok {
+				continue
+			}
+		}
+
+		rootNodes = append(rootNodes, node)
+	}
+	doc.RootNodes = rootNodes
+}
+
+// removeCompositionDirectives removes the directives the engine doesn't know about.
+func (s *federationSubgraph) removeCompositionDirectives() {
+	doc := s.doc
+	names := compositionDirectives
+
+	for i := range doc.ObjectTypeDefinitions {
+		removeDirectives(doc, &doc.ObjectTypeDefinitions[i].Directives, &doc.ObjectTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.ObjectTypeExtensions {
+		removeDirectives(doc, &doc.ObjectTypeExtensions[i].Directives, &doc.ObjectTypeExtensions[i].HasDirectives, names...)
+	}
+	for i := range doc.InterfaceTypeDefinitions {
+		removeDirectives(doc, &doc.InterfaceTypeDefinitions[i].Directives, &doc.InterfaceTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.InterfaceTypeExtensions {
+		removeDirectives(doc, &doc.InterfaceTypeExtensions[i].Directives, &doc.InterfaceTypeExtensions[i].HasDirectives, names...)
+	}
+	for i := range doc.FieldDefinitions {
+		removeDirectives(doc, &doc.FieldDefinitions[i].Directives, &doc.FieldDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.InputValueDefinitions {
+		removeDirectives(doc, &doc.InputValueDefinitions[i].Directives, &doc.InputValueDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.InputObjectTypeDefinitions {
+		removeDirectives(doc, &doc.InputObjectTypeDefinitions[i].Directives, &doc.InputObjectTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.ScalarTypeDefinitions {
+		removeDirectives(doc, &doc.ScalarTypeDefinitions[i].Directives, &doc.ScalarTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.UnionTypeDefinitions {
+		removeDirectives(doc, &doc.UnionTypeDefinitions[i].Directives, &doc.UnionTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.EnumTypeDefinitions {
+		removeDirectives(doc, &doc.EnumTypeDefinitions[i].Directives, &doc.EnumTypeDefinitions[i].HasDirectives, names...)
+	}
+	for i := range doc.EnumValueDefinitions {
+		removeDirectives(doc, &doc.EnumValueDefinitions[i].Directives, &doc.EnumValueDefinitions[i].HasDirectives, names...)
+	}
+}
+
+// fieldedType gives access to the parts of object and interface type definitions and extensions.
+type fieldedType struct {
+	fields        *ast.FieldDefinitionList
+	hasFields     *bool
+	directives    *ast.DirectiveList
+	hasDirectives *bool
+	implements    *ast.TypeList
+}
+
+func fieldedTypeParts(doc *ast.Document, node ast.Node) (fieldedType, bool) {
+	var object *ast.ObjectTypeDefinition
+	var iface *ast.InterfaceTypeDefinition
+
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		object = &doc.ObjectTypeDefinitions[node.Ref]
+	case ast.NodeKindObjectTypeExtension:
+		object = &doc.ObjectTypeExtensions[node.Ref].ObjectTypeDefinition
+	case ast.NodeKindInterfaceTypeDefinition:
+		iface = &doc.InterfaceTypeDefinitions[node.Ref]
+	case ast.NodeKindInterfaceTypeExtension:
+		iface = &doc.InterfaceTypeExtensions[node.Ref].InterfaceTypeDefinition
+	default:
+		return fieldedType{}, false
+	}
+
+	if object != nil {
+		return fieldedType{
+			fields:        &object.FieldsDefinition,
+			hasFields:     &object.HasFieldDefinitions,
+			directives:    &object.Directives,
+			hasDirectives: &object.HasDirectives,
+			implements:    &object.ImplementsInterfaces,
+		}, true
+	}
+
+	return fieldedType{
+		fields:        &iface.FieldsDefinition,
+		hasFields:     &iface.HasFieldDefinitions,
+		directives:    &iface.Directives,
+		hasDirectives: &iface.HasDirectives,
+		implements:    &iface.ImplementsInterfaces,
+	}, true
+}
+
+func rootNodeDirectives(doc *ast.Document, node ast.Node) *ast.DirectiveList {
+	switch node.Kind {
+	case ast.NodeKindScalarTypeDefinition:
+		return &doc.ScalarTypeDefinitions[node.Ref].Directives
+	case ast.NodeKindUnionTypeDefinition:
+		return &doc.UnionTypeDefinitions[node.Ref].Directives
+	case ast.NodeKindEnumTypeDefinition:
+		return &doc.EnumTypeDefinitions[node.Ref].Directives
+	case ast.NodeKindInputObjectTypeDefinition:
+		return &doc.InputObjectTypeDefinitions[node.Ref].Directives
+	}
+
+	if parts, ok := fieldedTypeParts(doc, node); ok {
+		return parts.directives
+	}
+	return nil
+}
+
+func removeDirectives(doc *ast.Document, list *ast.DirectiveList, has *bool, names ...string) {
+	list.Refs = filterRefs(list.Refs, func(ref int) bool {
+		return !slices.Contains(names, doc.DirectiveNameString(ref))
+	})
+	*has = len(list.Refs) > 0
+}
+
+func removeDirectiveArgument(doc *ast.Document, directive int, name string) {
+	arguments := &doc.Directives[directive].Arguments
+	arguments.Refs = filterRefs(arguments.Refs, func(ref int) bool {
+		return doc.ArgumentNameString(ref) != name
+	})
+	doc.Directives[directive].HasArguments = len(arguments.Refs) > 0
+}
+
+func directiveStringArgument(doc *ast.Document, directive int, name string) (string, bool) {
+	value, ok := doc.DirectiveArgumentValueByName(directive, []byte(name))
+	if !ok || value.Kind != ast.ValueKindString {
+		return "", false
+	}
+	return doc.StringValueContentString(value.Ref), true
+}
+
+func directiveBooleanArgument(doc *ast.Document, directive int, name string) (bool, bool) {
+	value, ok := doc.DirectiveArgumentValueByName(directive, []byte(name))
+	if !ok || value.Kind != ast.ValueKindBoolean {
+		return false, false
+	}
+	return bool(doc.BooleanValue(value.Ref)), true
+}
+
+// linkImport returns the name of an element imported by @link and the name it's imported as, e.g. "@key" or
+// { name: "@key", as: "@primaryKey" }.
+func linkImport(doc *ast.Document, value ast.Value) (name, as string) {
+	switch value.Kind {
+	case ast.ValueKindString:
+		name = doc.StringValueContentString(value.Ref)
+		return name, name
+	case ast.ValueKindObject:
+		for _, ref := range doc.ObjectValues[value.Ref].Refs {
+			fieldValue := doc.ObjectFieldValue(ref)
+			if fieldValue.Kind != ast.ValueKindString {
+				continue
+			}
+			switch doc.ObjectFieldNameString(ref) {
+			case "name":
+				name = doc.StringValueContentString(fieldValue.Ref)
+			case "as":
+				as = doc.StringValueContentString(fieldValue.Ref)
+			}
+		}
+		if as == "" {
+			as = name
+		}
+	}
+	return name, as
+}
+
+// fieldSetFields returns the names of the top level fields of a field set, e.g. "id organization { id }".
+func fieldSetFields(fieldSet string) []string {
+	var fields []string
+	depth := 0
+	for _, token := range strings.Fields(strings.NewReplacer("{", " { ", "}", " } ").Replace(fieldSet)) {
+		switch token {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		default:
+			if depth == 0 {
+				fields = append(fields, token)
+			}
+		}
+	}
+	return fields
+}
+
+// closeSchemaExtensions adds an empty body to schema extensions without root operation types, such as
+// `extend schema @link(...)`, as the parser expects one.
+func closeSchemaExtensions(sdl string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range schemaExtensionKeyword.FindAllStringIndex(sdl, -1) {
+		end := skipDirectives(sdl, loc[1])
+		out.WriteString(sdl[last:end])
+		if !strings.HasPrefix(strings.TrimLeftFunc(sdl[end:], unicode.IsSpace), "{") {
+			out.WriteString(" {}")
+		}
+		last = end
+	}
+	out.WriteString(sdl[last:])
+	return out.String()
+}
+
+// skipDirectives returns the position after the directives starting at i.
+func skipDirectives(s string, i int) int {
+	for {
+		j := skipIgnored(s, i)
+		if j >= len(s) || s[j] != '@' {
+			return i
+		}
+
+		j++
+		for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+			j++
+		}
+
+		if k := skipIgnored(s, j); k < len(s) && s[k] == '(' {
+			j = skipArguments(s, k)
+		}
+		i = j
+	}
+}
+
+func skipIgnored(s string, i int) int {
+	for i < len(s) && (unicode.IsSpace(rune(s[i])) || s[i] == ',') {
+		i++
+	}
+	return i
+}
+
+func skipArguments(s string, i int) int {
+	depth := 0
+	inString := false
+	for ; i < len(s); i++ {
+		switch {
+		case inString && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inString = !inString
+		case inString:
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+func sortedTypeNames(s *federationSubgraph) []string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedFieldNames(t *federationType) []string {
+	names := make([]string, 0, len(t.fields))
+	for name := range t.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func filterRefs(refs []int, keep func(ref int) bool) []int {
+	filtered := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		if keep(ref) {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
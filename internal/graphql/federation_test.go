@@ -0,0 +1,166 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+const federationV2Link = `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key", "@external", "@requires", "@provides", "@shareable", "@override", "@interfaceObject", {name: "@inaccessible", as: "@hidden"}])
+`
+
+func supergraphConfig(sdls ...string) apidef.GraphQLSupergraphConfig {
+	names := []string{"products", "inventory", "reviews", "media", "ratings"}
+
+	var conf apidef.GraphQLSupergraphConfig
+	for i, sdl := range sdls {
+		conf.Subgraphs = append(conf.Subgraphs, apidef.GraphQLSubgraphEntity{Name: names[i], SDL: sdl})
+	}
+	return conf
+}
+
+func TestComposeSupergraph(t *testing.T) {
+	t.Run("federation 1 subgraphs are merged as they are", func(t *testing.T) {
+		composition, err := ComposeSupergraph(supergraphConfig(
+			`type Query { topProducts: [Product] } type Product @key(fields: "upc") { upc: String! }`,
+			`extend type Product @key(fields: "upc") { upc: String! @external inStock: Boolean }`,
+		))
+		require.NoError(t, err)
+		assert.Nil(t, composition)
+	})
+
+	t.Run("federation 2 subgraphs", func(t *testing.T) {
+		composition, err := ComposeSupergraph(supergraphConfig(
+			federationV2Link+`type Query { topProducts: [Product] }
+			type Product @key(fields: "upc") { upc: String! name: String! price: Int! @shareable weight: Int inStock: Boolean internalCode: String @hidden }`,
+			federationV2Link+`type Product @key(fields: "upc") { upc: String! weight: Int @external price: Int! @shareable shippingEstimate: Int @requires(fields: "weight") inStock: Boolean @override(from: "products") }`,
+			`type Review { body: String! product: Product! } extend type Product @key(fields: "upc") { upc: String! @external reviews: [Review] }`,
+			federationV2Link+`type Query { media: [Media] } interface Media @key(fields: "id") { id: ID! title: String } type Book implements Media @key(fields: "id") { id: ID! title: String }`,
+			federationV2Link+`type Media @key(fields: "id") @interfaceObject { id: ID! rating: Int }`,
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			`type Query {topProducts: [Product]} type Product @key(fields: "upc") {upc: String! name: String! price: Int! weight: Int internalCode: String}`,
+			`extend type Product @key(fields: "upc") {upc: String! @external weight: Int @external price: Int! shippingEstimate: Int @requires(fields: "weight") inStock: Boolean}`,
+			`type Review {body: String! product: Product!} extend type Product @key(fields: "upc") {upc: String! @external reviews: [Review]}`,
+			`type Query {media: [Media]} interface Media @key(fields: "id") {id: ID! title: String} type Book implements Media @key(fields: "id") {id: ID! title: String}`,
+			`extend interface Media @key(fields: "id") {rating: Int} extend type Book @key(fields: "id") {id: ID! @external rating: Int}`,
+		}, composition.SubgraphSDLs)
+
+		assert.Equal(t, `type Query {topProducts: [Product] media: [Media]} `+
+			`type Product {upc: String! name: String! price: Int! weight: Int shippingEstimate: Int inStock: Boolean reviews: [Review]} `+
+			`type Review {body: String! product: Product!} `+
+			`interface Media {id: ID! title: String rating: Int} `+
+			`type Book implements Media {id: ID! title: String rating: Int}`, composition.SupergraphSDL)
+	})
+
+	t.Run("stub entities", func(t *testing.T) {
+		composition, err := ComposeSupergraph(supergraphConfig(
+			federationV2Link+`type Query { product: Product } type Product @key(fields: "upc") { upc: String! name: String }`,
+			federationV2Link+`type Query { reviews: [Review] } type Review { product: Product } type Product @key(fields: "upc", resolvable: false) { upc: String! }`,
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, `type Query {reviews: [Review]} type Review {product: Product} extend type Product @key(fields: "upc") {upc: String! @external}`, composition.SubgraphSDLs[1])
+	})
+
+	testCases := []struct {
+		name string
+		sdls []string
+		code string
+	}{
+		{
+			name: "invalid link version",
+			sdls: []string{`extend schema @link(url: "https://specs.apollo.dev/federation/v3.0", import: ["@key"]) type Query { a: String }`},
+			code: CompositionErrorInvalidLink,
+		},
+		{
+			name: "field resolved by several subgraphs",
+			sdls: []string{
+				federationV2Link + `type Query { product: Product } type Product @key(fields: "upc") { upc: String! name: String }`,
+				federationV2Link + `type Product @key(fields: "upc") { upc: String! name: String }`,
+			},
+			code: CompositionErrorInvalidFieldSharing,
+		},
+		{
+			name: "override from self",
+			sdls: []string{federationV2Link + `type Query { a: String @override(from: "products") }`},
+			code: CompositionErrorOverrideFromSelf,
+		},
+		{
+			name: "override source has override",
+			sdls: []string{
+				federationV2Link + `type Query { a: String @override(from: "inventory") }`,
+				federationV2Link + `type Query { a: String @override(from: "products") }`,
+			},
+			code: CompositionErrorOverrideSourceHasOverride,
+		},
+		{
+			name: "external missing on base",
+			sdls: []string{
+				federationV2Link + `type Query { product: Product } type Product @key(fields: "upc") { upc: String! }`,
+				federationV2Link + `type Product @key(fields: "upc") { upc: String! weight: Int @external }`,
+			},
+			code: CompositionErrorExternalMissingOnBase,
+		},
+		{
+			name: "requires unknown field",
+			sdls: []string{federationV2Link + `type Query { product: Product } type Product @key(fields: "upc") { upc: String! estimate: Int @requires(fields: "weight") }`},
+			code: CompositionErrorRequiresInvalidFields,
+		},
+		{
+			name: "requires local field",
+			sdls: []string{federationV2Link + `type Query { product: Product } type Product @key(fields: "upc") { upc: String! weight: Int estimate: Int @requires(fields: "weight") }`},
+			code: CompositionErrorRequiresFieldsMissingExternal,
+		},
+		{
+			name: "provides unknown field",
+			sdls: []string{federationV2Link + `type Query { product: Product @provides(fields: "name") } type Product @key(fields: "upc") { upc: String! }`},
+			code: CompositionErrorProvidesInvalidFields,
+		},
+		{
+			name: "provides local field",
+			sdls: []string{federationV2Link + `type Query { product: Product @provides(fields: "name") } type Product @key(fields: "upc") { upc: String! name: String }`},
+			code: CompositionErrorProvidesFieldsMissingExternal,
+		},
+		{
+			name: "interface object without interface",
+			sdls: []string{federationV2Link + `type Query { media: Media } type Media @key(fields: "id") @interfaceObject { id: ID! }`},
+			code: CompositionErrorInterfaceObjectUsage,
+		},
+		{
+			name: "interface key not on implementation",
+			sdls: []string{federationV2Link + `type Query { media: Media } interface Media @key(fields: "id") { id: ID! } type Book implements Media { id: ID! }`},
+			code: CompositionErrorInterfaceKeyNotOnImplementation,
+		},
+		{
+			name: "inaccessible type referenced",
+			sdls: []string{federationV2Link + `type Query { secret: Secret } type Secret @hidden { a: String }`},
+			code: CompositionErrorReferencedInaccessible,
+		},
+		{
+			name: "invalid graphql",
+			sdls: []string{federationV2Link + `type Query {`},
+			code: CompositionErrorInvalidGraphQL,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ComposeSupergraph(supergraphConfig(tc.sdls...))
+
+			var errs CompositionErrors
+			require.ErrorAs(t, err, &errs)
+
+			codes := make([]string, len(errs))
+			for i := range errs {
+				codes[i] = errs[i].Code
+			}
+			assert.Contains(t, codes, tc.code)
+		})
+	}
+}
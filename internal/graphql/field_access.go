@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astvisitor"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const (
+	allFields     = "*"
+	typenameField = "__typename"
+)
+
+// FieldAccessRules are the restrictions of an access definition applying to the fields of operations.
+type FieldAccessRules struct {
+	// AllowedTypes, when not empty, allows only the listed fields.
+	AllowedTypes []graphql.Type
+	// RestrictedTypes restricts the listed fields, it's ignored when AllowedTypes is set.
+	RestrictedTypes []graphql.Type
+	// ArgumentRules restrict fields to the allowed values of their arguments.
+	ArgumentRules []user.FieldArgumentRule
+}
+
+// RestrictedField is a field of an operation the rules don't allow.
+type RestrictedField struct {
+	// Path holds the response keys leading to the field, including the key of the field.
+	Path    []string
+	Message string
+}
+
+// FieldAccessResult is the result of checking an operation against field access rules.
+type FieldAccessResult struct {
+	Restricted []RestrictedField
+	// Request is the operation without the restricted fields. It's only set when fields were removed.
+	Request *graphql.Request
+}
+
+// FieldAccessChecker checks operations against field access rules for a single schema.
+type FieldAccessChecker struct {
+	schema *ast.Document
+}
+
+// NewFieldAccessChecker parses the schema once so it can be reused for every request.
+func NewFieldAccessChecker(schema string) (*FieldAccessChecker, error) {
+	sh, err := graphql.NewSchemaFromString(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDoc, report := astparser.ParseGraphqlDocumentBytes(sh.Document())
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	return &FieldAccessChecker{schema: &schemaDoc}, nil
+}
+
+// Check returns the fields of the operation the rules don't allow. When remove is set and fields are
+// restricted, the result also holds the operation without them, so they can be resolved to null.
+func (c *FieldAccessChecker) Check(gqlRequest *graphql.Request, rules FieldAccessRules, remove bool) (*FieldAccessResult, error) {
+	operation, report := astparser.ParseGraphqlDocumentString(gqlRequest.Query)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	operation.Input.Variables = gqlRequest.Variables
+	if len(operation.Input.Variables) == 0 {
+		operation.Input.Variables = []byte("{}")
+	}
+
+	// fragments are inlined, so restricted fields can be removed where they are selected
+	normalizer := astnormalization.NewWithOpts(
+		astnormalization.WithExtractVariables(),
+		astnormalization.WithRemoveFragmentDefinitions(),
+	)
+	if gqlRequest.OperationName != "" {
+		normalizer.NormalizeNamedOperation(&operation, c.schema, []byte(gqlRequest.OperationName), &report)
+	} else {
+		normalizer.NormalizeOperation(&operation, c.schema, &report)
+	}
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	walker := astvisitor.NewWalker(48)
+	visitor := &fieldAccessVisitor{
+		Walker:        &walker,
+		schema:        c.schema,
+		operation:     &operation,
+		operationName: gqlRequest.OperationName,
+		allowed:       fieldLookup(rules.AllowedTypes),
+		restricted:    fieldLookup(rules.RestrictedTypes),
+		argumentRules: rules.ArgumentRules,
+	}
+	walker.RegisterEnterOperationVisitor(visitor)
+	walker.RegisterEnterFieldVisitor(visitor)
+
+	walker.Walk(&operation, c.schema, &report)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	result := &FieldAccessResult{Restricted: visitor.restrictedFields}
+	if !remove || len(visitor.removals) == 0 {
+		return result, nil
+	}
+
+	c.removeFields(&operation, visitor.removals)
+
+	// variables only used by removed fields would fail validation
+	astnormalization.NewWithOpts(astnormalization.WithRemoveUnusedVariables()).NormalizeOperation(&operation, c.schema, &report)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	query, err := astprinter.PrintString(&operation, c.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Request = &graphql.Request{
+		OperationName: gqlRequest.OperationName,
+		Variables:     operation.Input.Variables,
+		Query:         query,
+	}
+	return result, nil
+}
+
+// removeFields removes the fields from their selection sets. Selection sets left empty select __typename
+// instead, so the operation stays valid.
+func (c *FieldAccessChecker) removeFields(operation *ast.Document, removals []fieldRemoval) {
+	for _, removal := range removals {
+		operation.RemoveNodeFromSelectionSet(removal.selectionSet, ast.Node{Kind: ast.NodeKindField, Ref: removal.field})
+	}
+
+	for _, removal := range removals {
+		if len(operation.SelectionSets[removal.selectionSet].SelectionRefs) != 0 {
+			continue
+		}
+
+		typename := operation.AddField(ast.Field{Name: operation.Input.AppendInputString(typenameField)})
+		operation.AddSelection(removal.selectionSet, ast.Selection{Kind: ast.SelectionKindField, Ref: typename.Ref})
+	}
+}
+
+func fieldLookup(types []graphql.Type) map[string][]string {
+	lookup := make(map[string][]string, len(types))
+	for _, t := range types {
+		lookup[t.Name] = append(lookup[t.Name], t.Fields...)
+	}
+	return lookup
+}
+
+type fieldRemoval struct {
+	selectionSet int
+	field        int
+}
+
+// fieldAccessVisitor collects the restricted fields. Their selections aren't visited, as they won't be resolved.
+type fieldAccessVisitor struct {
+	*astvisitor.Walker
+
+	schema        *ast.Document
+	operation     *ast.Document
+	operationName string
+	allowed       map[string][]string
+	restricted    map[string][]string
+	argumentRules []user.FieldArgumentRule
+
+	restrictedFields []RestrictedField
+	removals         []fieldRemoval
+}
+
+func (v *fieldAccessVisitor) EnterOperationDefinition(ref int) {
+	if v.operationName != "" && v.operation.OperationDefinitionNameString(ref) != v.operationName {
+		v.SkipNode()
+	}
+}
+
+func (v *fieldAccessVisitor) EnterField(ref int) {
+	// __typename and introspection fields aren't restricted
+	if _, ok := v.FieldDefinition(ref); !ok {
+		return
+	}
+
+	typeName := v.schema.NodeNameString(v.EnclosingTypeDefinition)
+	fieldName := v.operation.FieldNameString(ref)
+
+	message := v.restriction(ref, typeName, fieldName)
+	if message == "" {
+		return
+	}
+
+	var path []string
+	for _, ancestor := range v.Ancestors {
+		if ancestor.Kind == ast.NodeKindField {
+			path = append(path, v.operation.FieldAliasOrNameString(ancestor.Ref))
+		}
+	}
+	path = append(path, v.operation.FieldAliasOrNameString(ref))
+
+	v.restrictedFields = append(v.restrictedFields, RestrictedField{Path: path, Message: message})
+
+	if selectionSet := v.Ancestors[len(v.Ancestors)-1]; selectionSet.Kind == ast.NodeKindSelectionSet {
+		v.removals = append(v.removals, fieldRemoval{selectionSet: selectionSet.Ref, field: ref})
+	}
+
+	v.SkipNode()
+}
+
+// restriction returns why the field is restricted, or an empty string if it's allowed.
+func (v *fieldAccessVisitor) restriction(ref int, typeName, fieldName string) string {
+	if len(v.allowed) > 0 {
+		fields := v.allowed[typeName]
+		if !slices.Contains(fields, allFields) && !slices.Contains(fields, fieldName) {
+			return fmt.Sprintf("field: %s is restricted on type: %s", fieldName, typeName)
+		}
+	} else if fields := v.restricted[typeName]; slices.Contains(fields, allFields) || slices.Contains(fields, fieldName) {
+		return fmt.Sprintf("field: %s is restricted on type: %s", fieldName, typeName)
+	}
+
+	for _, rule := range v.argumentRules {
+		if rule.TypeName != typeName || rule.FieldName != fieldName {
+			continue
+		}
+
+		value, ok := v.argumentValue(ref, rule.ArgumentName)
+		if !ok || !slices.Contains(rule.AllowedValues, value) {
+			return fmt.Sprintf("argument: %s of field: %s is restricted on type: %s", rule.ArgumentName, fieldName, typeName)
+		}
+	}
+
+	return ""
+}
+
+// argumentValue returns the value of the argument, strings unquoted. Null and missing arguments have no value.
+func (v *fieldAccessVisitor) argumentValue(fieldRef int, name string) (string, bool) {
+	argRef, ok := v.operation.FieldArgument(fieldRef, []byte(name))
+	if !ok {
+		return "", false
+	}
+
+	var (
+		raw      []byte
+		dataType jsonparser.ValueType
+		err      error
+	)
+
+	value := v.operation.ArgumentValue(argRef)
+	if value.Kind == ast.ValueKindVariable {
+		raw, dataType, _, err = jsonparser.Get(v.operation.Input.Variables, v.operation.VariableValueNameString(value.Ref))
+	} else if raw, err = v.operation.ValueToJSON(value); err == nil {
+		raw, dataType, _, err = jsonparser.Get(raw)
+	}
+
+	if err != nil || dataType == jsonparser.Null {
+		return "", false
+	}
+
+	if dataType == jsonparser.String {
+		str, err := jsonparser.ParseString(raw)
+		return str, err == nil
+	}
+	return string(raw), true
+}
@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+
+	"github.com/TykTechnologies/tyk/user"
+)
+
+const fieldAccessSchema = `
+type Query {
+  orders(tenantId: ID!, status: Status): [Order]
+  me: User
+}
+
+enum Status { OPEN CLOSED }
+
+type Order {
+  id: ID
+  total: Float
+  customer: User
+}
+
+type User {
+  name: String
+  email: String
+}
+`
+
+func TestFieldAccessChecker_Check(t *testing.T) {
+	checker, err := NewFieldAccessChecker(fieldAccessSchema)
+	require.NoError(t, err)
+
+	tenantRule := FieldAccessRules{
+		ArgumentRules: []user.FieldArgumentRule{
+			{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"t1"}},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		query      string
+		variables  string
+		rules      FieldAccessRules
+		restricted []RestrictedField
+	}{
+		{name: "no rules", query: `{ me { email } }`},
+		{name: "allowed argument literal", query: `{ orders(tenantId: "t1") { id } }`, rules: tenantRule},
+		{name: "allowed argument variable", query: `query ($t: ID!) { orders(tenantId: $t) { id } }`, variables: `{"t":"t1"}`, rules: tenantRule},
+		{
+			name:  "restricted argument value",
+			query: `{ mine: orders(tenantId: "t2") { id } me { name } }`,
+			rules: tenantRule,
+			restricted: []RestrictedField{
+				{Path: []string{"mine"}, Message: "argument: tenantId of field: orders is restricted on type: Query"},
+			},
+		},
+		{
+			name:  "enum argument",
+			query: `{ orders(tenantId: "t1", status: CLOSED) { id } }`,
+			rules: FieldAccessRules{ArgumentRules: []user.FieldArgumentRule{
+				{TypeName: "Query", FieldName: "orders", ArgumentName: "status", AllowedValues: []string{"OPEN"}},
+			}},
+			restricted: []RestrictedField{
+				{Path: []string{"orders"}, Message: "argument: status of field: orders is restricted on type: Query"},
+			},
+		},
+		{
+			name:  "restricted types in fragments",
+			query: `{ orders(tenantId: "t1") { id customer { ...U } } } fragment U on User { name email }`,
+			rules: FieldAccessRules{RestrictedTypes: []graphql.Type{{Name: "User", Fields: []string{"email"}}}},
+			restricted: []RestrictedField{
+				{Path: []string{"orders", "customer", "email"}, Message: "field: email is restricted on type: User"},
+			},
+		},
+		{
+			name:  "allowed types",
+			query: `{ me { name email } }`,
+			rules: FieldAccessRules{AllowedTypes: []graphql.Type{{Name: "Query", Fields: []string{"*"}}, {Name: "User", Fields: []string{"name"}}}},
+			restricted: []RestrictedField{
+				{Path: []string{"me", "email"}, Message: "field: email is restricted on type: User"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := checker.Check(&graphql.Request{Query: tc.query, Variables: []byte(tc.variables)}, tc.rules, false)
+			require.NoError(t, err)
+			assert.Equal(t, tc.restricted, result.Restricted)
+			assert.Nil(t, result.Request)
+		})
+	}
+
+	t.Run("remove restricted fields", func(t *testing.T) {
+		result, err := checker.Check(&graphql.Request{
+			Query:     `query Q($t: ID!) { orders(tenantId: $t) { id } me { email } }`,
+			Variables: []byte(`{"t":"t2"}`),
+		}, FieldAccessRules{
+			RestrictedTypes: tenantRule.RestrictedTypes,
+			ArgumentRules:   tenantRule.ArgumentRules,
+			AllowedTypes:    []graphql.Type{{Name: "Query", Fields: []string{"*"}}, {Name: "User", Fields: []string{"name"}}},
+		}, true)
+		require.NoError(t, err)
+
+		require.Len(t, result.Restricted, 2)
+		require.NotNil(t, result.Request)
+		assert.Equal(t, "query Q {me {__typename}}", result.Request.Query)
+	})
+}
@@ -110,6 +110,14 @@ func (g *GraphStatsExtractionVisitor) ExtractStats(rawRequest, response, schema
 	return stats, nil
 }
 
+// OperationName returns the operation name of the last request the stats were extracted from.
+func (g *GraphStatsExtractionVisitor) OperationName() string {
+	if g.gqlRequest == nil {
+		return ""
+	}
+	return g.gqlRequest.OperationName
+}
+
 func (g *GraphStatsExtractionVisitor) AnalyticsOperationTypes() analytics.GraphQLOperations {
 	if g.gqlRequest == nil {
 		return analytics.OperationUnknown
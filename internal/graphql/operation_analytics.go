@@ -0,0 +1,268 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	semconv "github.com/TykTechnologies/opentelemetry/semconv/v1.0.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/otel"
+)
+
+// OperationRecord is the analytics record of a GraphQL operation. It describes which operation was executed and
+// which fields it used, so unused fields can be found before they are deprecated.
+type OperationRecord struct {
+	APIID         string              `json:"api_id"`
+	OrgID         string              `json:"org_id"`
+	TimeStamp     time.Time           `json:"timestamp"`
+	OperationName string              `json:"operation_name"`
+	OperationType string              `json:"operation_type"`
+	RootFields    []string            `json:"root_fields"`
+	Types         map[string][]string `json:"types"`
+	ErrorCount    int                 `json:"error_count"`
+	DataSources   []DataSourceStats   `json:"data_sources"`
+}
+
+// DataSourceStats holds the calls a GraphQL operation made to one data source. Latency is the total time spent in
+// the calls, in milliseconds.
+type DataSourceStats struct {
+	Name    string `json:"name"`
+	Calls   int    `json:"calls"`
+	Errors  int    `json:"errors"`
+	Latency int64  `json:"latency"`
+}
+
+// NewOperationRecord returns the operation record of the extracted stats, with sorted fields so records of the same
+// operation are equal.
+func NewOperationRecord(operationName string, stats analytics.GraphQLStats) OperationRecord {
+	record := OperationRecord{
+		OperationName: operationName,
+		OperationType: printAnalyticsOperationType(stats.OperationType),
+		RootFields:    append([]string{}, stats.RootFields...),
+		Types:         make(map[string][]string, len(stats.Types)),
+		ErrorCount:    len(stats.Errors),
+	}
+	sort.Strings(record.RootFields)
+
+	for typeName, fields := range stats.Types {
+		record.Types[typeName] = append([]string{}, fields...)
+		sort.Strings(record.Types[typeName])
+	}
+
+	return record
+}
+
+func printAnalyticsOperationType(operationType analytics.GraphQLOperations) string {
+	switch operationType {
+	case analytics.OperationQuery:
+		return PrintOperationType(ast.OperationTypeQuery)
+	case analytics.OperationMutation:
+		return PrintOperationType(ast.OperationTypeMutation)
+	case analytics.OperationSubscription:
+		return PrintOperationType(ast.OperationTypeSubscription)
+	default:
+		return PrintOperationType(ast.OperationTypeUnknown)
+	}
+}
+
+type dataSourceURL struct {
+	prefix string
+	name   string
+}
+
+// DataSourceNames resolves the data source called by an upstream request of the execution engine from its URL.
+type DataSourceNames struct {
+	urls []dataSourceURL
+}
+
+// NewDataSourceNames returns the data source names of the API: the data sources of Universal Data Graph, the
+// subgraphs of a supergraph and the upstream of a proxy-only API.
+func NewDataSourceNames(def *apidef.APIDefinition) *DataSourceNames {
+	names := &DataSourceNames{}
+
+	switch def.GraphQL.ExecutionMode {
+	case apidef.GraphQLExecutionModeExecutionEngine:
+		for _, ds := range def.GraphQL.Engine.DataSources {
+			var conf struct {
+				URL string `json:"url"`
+			}
+			if err := json.Unmarshal(ds.Config, &conf); err == nil {
+				names.add(conf.URL, ds.Name)
+			}
+		}
+	case apidef.GraphQLExecutionModeSupergraph:
+		for _, subgraph := range def.GraphQL.Supergraph.Subgraphs {
+			name := subgraph.Name
+			if name == "" {
+				name = subgraph.APIID
+			}
+			names.add(subgraph.URL, name)
+		}
+	default:
+		names.add(def.Proxy.TargetURL, def.Name)
+	}
+
+	// the longest prefix is the most specific match
+	sort.SliceStable(names.urls, func(i, j int) bool {
+		return len(names.urls[i].prefix) > len(names.urls[j].prefix)
+	})
+
+	return names
+}
+
+func (n *DataSourceNames) add(rawURL, name string) {
+	// templated parts of the URL are only known at execution time
+	if i := strings.Index(rawURL, "{{"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	rawURL = strings.Replace(rawURL, "tyk://", "http://", 1)
+
+	if rawURL == "" || name == "" {
+		return
+	}
+	n.urls = append(n.urls, dataSourceURL{prefix: rawURL, name: name})
+}
+
+// Name returns the name of the data source serving the URL, or its host when no data source matches.
+func (n *DataSourceNames) Name(u *url.URL) string {
+	if n != nil {
+		rawURL := u.Scheme + "://" + u.Host + u.Path
+		for _, dsURL := range n.urls {
+			if strings.HasPrefix(rawURL, dsURL.prefix) {
+				return dsURL.name
+			}
+		}
+	}
+	return u.Host
+}
+
+// DataSourceTracker collects the data source calls of a GraphQL request.
+type DataSourceTracker struct {
+	names *DataSourceNames
+
+	mu    sync.Mutex
+	stats []DataSourceStats
+}
+
+// NewTracker returns a data source tracker resolving the data sources with the names.
+func (n *DataSourceNames) NewTracker() *DataSourceTracker {
+	return &DataSourceTracker{names: n}
+}
+
+// Track records a call to the named data source.
+func (t *DataSourceTracker) Track(name string, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := 0
+	for ; i < len(t.stats); i++ {
+		if t.stats[i].Name == name {
+			break
+		}
+	}
+	if i == len(t.stats) {
+		t.stats = append(t.stats, DataSourceStats{Name: name})
+	}
+
+	t.stats[i].Calls++
+	t.stats[i].Latency += latency.Milliseconds()
+	if failed {
+		t.stats[i].Errors++
+	}
+}
+
+// Stats returns the calls of the data sources in the order they were first called.
+func (t *DataSourceTracker) Stats() []DataSourceStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]DataSourceStats{}, t.stats...)
+}
+
+type dataSourceTrackerKey struct{}
+
+// ContextWithDataSourceTracker returns a context carrying the tracker to the data source calls of the request.
+func ContextWithDataSourceTracker(ctx context.Context, tracker *DataSourceTracker) context.Context {
+	return context.WithValue(ctx, dataSourceTrackerKey{}, tracker)
+}
+
+// DataSourceTrackerFromContext returns the tracker of the context, or nil.
+func DataSourceTrackerFromContext(ctx context.Context) *DataSourceTracker {
+	tracker, _ := ctx.Value(dataSourceTrackerKey{}).(*DataSourceTracker)
+	return tracker
+}
+
+// TrackDataSourceRequest records an upstream request of the execution engine which started at started, when its
+// context carries a tracker. The data source is resolved from the URL when name is empty.
+func TrackDataSourceRequest(req *http.Request, name string, started time.Time, res *http.Response, err error) {
+	tracker := DataSourceTrackerFromContext(req.Context())
+	if tracker == nil {
+		return
+	}
+
+	if name == "" {
+		name = tracker.names.Name(req.URL)
+	}
+
+	failed := err != nil || (res != nil && res.StatusCode >= http.StatusBadRequest)
+	tracker.Track(name, time.Since(started), failed)
+}
+
+type dataSourceTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+// NewDataSourceTransport returns a transport tracking the requests sent by the named data source.
+func NewDataSourceTransport(name string, next http.RoundTripper) http.RoundTripper {
+	return &dataSourceTransport{name: name, next: next}
+}
+
+func (t *dataSourceTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	started := time.Now()
+	defer func() {
+		TrackDataSourceRequest(req, t.name, started, res, err)
+	}()
+
+	return t.next.RoundTrip(req)
+}
+
+// TraceOperation adds the operation record to the span, as attributes of the span and an event per data source.
+func TraceOperation(span otel.Span, record OperationRecord) {
+	fields := make([]string, 0)
+	for _, typeName := range sortedKeys(record.Types) {
+		for _, field := range record.Types[typeName] {
+			fields = append(fields, typeName+"."+field)
+		}
+	}
+
+	span.SetAttributes(
+		semconv.GraphQLOperationName(record.OperationName),
+		semconv.GraphQLOperationType(record.OperationType),
+		attribute.StringSlice(semconv.GraphQLOperationPrefix+"root_fields", record.RootFields),
+		attribute.StringSlice(semconv.GraphQLOperationPrefix+"fields", fields),
+		attribute.Int(semconv.GraphQLOperationPrefix+"error_count", record.ErrorCount),
+	)
+
+	for _, ds := range record.DataSources {
+		span.AddEvent("graphql.datasource", trace.WithAttributes(
+			attribute.String(semconv.GraphQLPrefix+"datasource.name", ds.Name),
+			attribute.Int(semconv.GraphQLPrefix+"datasource.calls", ds.Calls),
+			attribute.Int(semconv.GraphQLPrefix+"datasource.errors", ds.Errors),
+			attribute.Int64(semconv.GraphQLPrefix+"datasource.latency_ms", ds.Latency),
+		))
+	}
+}
@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func TestNewOperationRecord(t *testing.T) {
+	extractor := NewGraphStatsExtractor()
+	stats, err := extractor.ExtractStats(
+		`{"operationName": "Characters", "query": "query Characters { characters { info { pages count } results { name } } }"}`,
+		`{"errors": [{"message": "first"}, {"message": "second"}]}`,
+		validSchema,
+	)
+	require.NoError(t, err)
+
+	record := NewOperationRecord(extractor.OperationName(), stats)
+	assert.Equal(t, "Characters", record.OperationName)
+	assert.Equal(t, "query", record.OperationType)
+	assert.Equal(t, []string{"characters"}, record.RootFields)
+	assert.Equal(t, map[string][]string{
+		"Characters": {"info", "results"},
+		"Info":       {"count", "pages"},
+		"Character":  {"name"},
+	}, record.Types)
+	assert.Equal(t, 2, record.ErrorCount)
+
+	assert.Equal(t, "unknown", NewOperationRecord("", analytics.GraphQLStats{}).OperationType)
+}
+
+func TestDataSourceNames(t *testing.T) {
+	parse := func(t *testing.T, rawURL string) *url.URL {
+		t.Helper()
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		return u
+	}
+
+	t.Run("universal data graph", func(t *testing.T) {
+		def := &apidef.APIDefinition{}
+		def.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeExecutionEngine
+		def.GraphQL.Engine.DataSources = []apidef.GraphQLEngineDataSource{
+			{Name: "users", Config: []byte(`{"url": "http://example.com/users/{{ .arguments.id }}"}`)},
+			{Name: "user-posts", Config: []byte(`{"url": "http://example.com/users/{{ .arguments.id }}/posts?limit=1"}`)},
+			{Name: "countries", Config: []byte(`{"url": "tyk://countries-api/graphql"}`)},
+		}
+		names := NewDataSourceNames(def)
+
+		assert.Equal(t, "users", names.Name(parse(t, "http://example.com/users/1")))
+		assert.Equal(t, "countries", names.Name(parse(t, "http://countries-api/graphql")))
+		assert.Equal(t, "other.com", names.Name(parse(t, "http://other.com/users")))
+	})
+
+	t.Run("supergraph", func(t *testing.T) {
+		def := &apidef.APIDefinition{}
+		def.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeSupergraph
+		def.GraphQL.Supergraph.Subgraphs = []apidef.GraphQLSubgraphEntity{
+			{Name: "accounts", URL: "http://accounts.service"},
+			{APIID: "reviews-api", URL: "http://reviews.service"},
+		}
+		names := NewDataSourceNames(def)
+
+		assert.Equal(t, "accounts", names.Name(parse(t, "http://accounts.service/query")))
+		assert.Equal(t, "reviews-api", names.Name(parse(t, "http://reviews.service")))
+	})
+
+	t.Run("proxy only", func(t *testing.T) {
+		def := &apidef.APIDefinition{Name: "countries"}
+		def.GraphQL.ExecutionMode = apidef.GraphQLExecutionModeProxyOnly
+		def.Proxy.TargetURL = "https://countries.trevorblades.com"
+
+		assert.Equal(t, "countries", NewDataSourceNames(def).Name(parse(t, "https://countries.trevorblades.com/")))
+	})
+}
+
+func TestTrackDataSourceRequest(t *testing.T) {
+	def := &apidef.APIDefinition{Name: "users"}
+	def.Proxy.TargetURL = "http://users.service"
+	tracker := NewDataSourceNames(def).NewTracker()
+
+	req, err := http.NewRequestWithContext(ContextWithDataSourceTracker(context.Background(), tracker), http.MethodPost, "http://users.service", nil)
+	require.NoError(t, err)
+
+	started := time.Now().Add(-10 * time.Millisecond)
+	TrackDataSourceRequest(req, "", started, &http.Response{StatusCode: http.StatusOK}, nil)
+	TrackDataSourceRequest(req, "", started, &http.Response{StatusCode: http.StatusBadGateway}, nil)
+	TrackDataSourceRequest(req, "sql", started, nil, errors.New("connection refused"))
+
+	stats := tracker.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "users", stats[0].Name)
+	assert.Equal(t, 2, stats[0].Calls)
+	assert.Equal(t, 1, stats[0].Errors)
+	assert.GreaterOrEqual(t, stats[0].Latency, int64(20))
+	assert.Equal(t, DataSourceStats{Name: "sql", Calls: 1, Errors: 1, Latency: stats[1].Latency}, stats[1])
+
+	// requests without a tracker aren't tracked
+	req, err = http.NewRequest(http.MethodPost, "http://users.service", nil)
+	require.NoError(t, err)
+	TrackDataSourceRequest(req, "", started, nil, nil)
+}
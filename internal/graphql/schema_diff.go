@@ -0,0 +1,555 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+)
+
+// SchemaChangeCriticality tells how a schema change affects the existing clients of an API.
+type SchemaChangeCriticality string
+
+const (
+	// SchemaChangeBreaking changes make operations that were valid fail.
+	SchemaChangeBreaking SchemaChangeCriticality = "BREAKING"
+	// SchemaChangeDangerous changes keep operations valid, but may change how clients handle the responses,
+	// e.g. a new enum value a client doesn't know about.
+	SchemaChangeDangerous SchemaChangeCriticality = "DANGEROUS"
+	// SchemaChangeSafe changes don't affect the existing clients.
+	SchemaChangeSafe SchemaChangeCriticality = "SAFE"
+)
+
+// Types of the schema changes.
+const (
+	SchemaChangeTypeAdded                 = "TYPE_ADDED"
+	SchemaChangeTypeRemoved               = "TYPE_REMOVED"
+	SchemaChangeTypeKindChanged           = "TYPE_KIND_CHANGED"
+	SchemaChangeFieldAdded                = "FIELD_ADDED"
+	SchemaChangeFieldRemoved              = "FIELD_REMOVED"
+	SchemaChangeFieldTypeChanged          = "FIELD_TYPE_CHANGED"
+	SchemaChangeFieldDeprecationAdded     = "FIELD_DEPRECATION_ADDED"
+	SchemaChangeFieldDeprecationRemoved   = "FIELD_DEPRECATION_REMOVED"
+	SchemaChangeArgumentAdded             = "FIELD_ARGUMENT_ADDED"
+	SchemaChangeArgumentRemoved           = "FIELD_ARGUMENT_REMOVED"
+	SchemaChangeArgumentTypeChanged       = "FIELD_ARGUMENT_TYPE_CHANGED"
+	SchemaChangeArgumentDefaultChanged    = "FIELD_ARGUMENT_DEFAULT_CHANGED"
+	SchemaChangeInputFieldAdded           = "INPUT_FIELD_ADDED"
+	SchemaChangeInputFieldRemoved         = "INPUT_FIELD_REMOVED"
+	SchemaChangeInputFieldTypeChanged     = "INPUT_FIELD_TYPE_CHANGED"
+	SchemaChangeInputFieldDefaultChanged  = "INPUT_FIELD_DEFAULT_VALUE_CHANGED"
+	SchemaChangeEnumValueAdded            = "ENUM_VALUE_ADDED"
+	SchemaChangeEnumValueRemoved          = "ENUM_VALUE_REMOVED"
+	SchemaChangeEnumValueDeprecationAdded = "ENUM_VALUE_DEPRECATION_ADDED"
+	SchemaChangeUnionMemberAdded          = "UNION_MEMBER_ADDED"
+	SchemaChangeUnionMemberRemoved        = "UNION_MEMBER_REMOVED"
+	SchemaChangeInterfaceAdded            = "OBJECT_TYPE_INTERFACE_ADDED"
+	SchemaChangeInterfaceRemoved          = "OBJECT_TYPE_INTERFACE_REMOVED"
+	SchemaChangeDirectiveAdded            = "DIRECTIVE_ADDED"
+	SchemaChangeDirectiveRemoved          = "DIRECTIVE_REMOVED"
+	SchemaChangeDirectiveArgumentAdded    = "DIRECTIVE_ARGUMENT_ADDED"
+	SchemaChangeDirectiveArgumentRemoved  = "DIRECTIVE_ARGUMENT_REMOVED"
+	SchemaChangeDirectiveLocationRemoved  = "DIRECTIVE_LOCATION_REMOVED"
+)
+
+const deprecatedDirective = "deprecated"
+
+// SchemaChange is a difference between two versions of a GraphQL schema.
+type SchemaChange struct {
+	Type        string                  `json:"type"`
+	Criticality SchemaChangeCriticality `json:"criticality"`
+	// Path is the coordinate of the changed schema element, e.g. Query.user.id for an argument.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// HasBreakingChanges reports whether any of the changes is breaking.
+func HasBreakingChanges(changes []SchemaChange) bool {
+	for _, change := range changes {
+		if change.Criticality == SchemaChangeBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSchemas compares two GraphQL schemas and classifies the changes between them. There are no changes
+// when the old schema is empty, as there are no clients depending on it.
+func DiffSchemas(oldSchema, newSchema string) ([]SchemaChange, error) {
+	changes := []SchemaChange{}
+	if strings.TrimSpace(oldSchema) == "" {
+		return changes, nil
+	}
+
+	oldModel, err := parseSchemaModel(oldSchema)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %w", err)
+	}
+
+	newModel, err := parseSchemaModel(newSchema)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %w", err)
+	}
+
+	d := &schemaDiff{changes: changes}
+	d.diffTypes(oldModel, newModel)
+	d.diffDirectives(oldModel, newModel)
+
+	return d.changes, nil
+}
+
+type schemaModel struct {
+	types      map[string]*schemaType
+	directives map[string]*schemaDirective
+}
+
+type schemaType struct {
+	kind       string
+	fields     map[string]*schemaField
+	values     map[string]bool
+	members    map[string]struct{}
+	interfaces map[string]struct{}
+}
+
+// schemaField is a field of an object or interface type, or a field of an input type.
+type schemaField struct {
+	typ        string
+	defaultVal *string
+	args       map[string]*schemaField
+	deprecated bool
+}
+
+type schemaDirective struct {
+	args      map[string]*schemaField
+	locations map[string]struct{}
+}
+
+func parseSchemaModel(sdl string) (*schemaModel, error) {
+	doc, report := astparser.ParseGraphqlDocumentString(sdl)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	model := &schemaModel{types: map[string]*schemaType{}, directives: map[string]*schemaDirective{}}
+	for _, node := range doc.RootNodes {
+		if node.Kind == ast.NodeKindDirectiveDefinition {
+			definition := doc.DirectiveDefinitions[node.Ref]
+			directive := &schemaDirective{
+				args:      inputFields(&doc, definition.ArgumentsDefinition.Refs),
+				locations: map[string]struct{}{},
+			}
+			iter := definition.DirectiveLocations.Iterable()
+			for iter.Next() {
+				directive.locations[iter.Value().LiteralString()] = struct{}{}
+			}
+			model.directives[doc.DirectiveDefinitionNameString(node.Ref)] = directive
+			continue
+		}
+
+		kind, ok := schemaTypeKind(node.Kind)
+		if !ok {
+			continue
+		}
+
+		name := doc.NodeNameString(node)
+		t, ok := model.types[name]
+		if !ok {
+			t = &schemaType{
+				kind:       kind,
+				fields:     map[string]*schemaField{},
+				values:     map[string]bool{},
+				members:    map[string]struct{}{},
+				interfaces: map[string]struct{}{},
+			}
+			model.types[name] = t
+		}
+
+		if parts, ok := fieldedTypeParts(&doc, node); ok {
+			for _, ref := range parts.implements.Refs {
+				t.interfaces[doc.ResolveTypeNameString(ref)] = struct{}{}
+			}
+			for _, ref := range parts.fields.Refs {
+				definition := doc.FieldDefinitions[ref]
+				t.fields[doc.FieldDefinitionNameString(ref)] = &schemaField{
+					typ:        printType(&doc, definition.Type),
+					args:       inputFields(&doc, definition.ArgumentsDefinition.Refs),
+					deprecated: definition.Directives.HasDirectiveByName(&doc, deprecatedDirective),
+				}
+			}
+			continue
+		}
+
+		switch node.Kind {
+		case ast.NodeKindInputObjectTypeDefinition:
+			mergeFields(t.fields, inputFields(&doc, doc.InputObjectTypeDefinitions[node.Ref].InputFieldsDefinition.Refs))
+		case ast.NodeKindInputObjectTypeExtension:
+			mergeFields(t.fields, inputFields(&doc, doc.InputObjectTypeExtensions[node.Ref].InputFieldsDefinition.Refs))
+		case ast.NodeKindEnumTypeDefinition:
+			enumValues(&doc, t, doc.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs)
+		case ast.NodeKindEnumTypeExtension:
+			enumValues(&doc, t, doc.EnumTypeExtensions[node.Ref].EnumValuesDefinition.Refs)
+		case ast.NodeKindUnionTypeDefinition:
+			unionMembers(&doc, t, doc.UnionTypeDefinitions[node.Ref].UnionMemberTypes.Refs)
+		case ast.NodeKindUnionTypeExtension:
+			unionMembers(&doc, t, doc.UnionTypeExtensions[node.Ref].UnionMemberTypes.Refs)
+		}
+	}
+
+	return model, nil
+}
+
+func schemaTypeKind(kind ast.NodeKind) (string, bool) {
+	switch kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindObjectTypeExtension:
+		return "OBJECT", true
+	case ast.NodeKindInterfaceTypeDefinition, ast.NodeKindInterfaceTypeExtension:
+		return "INTERFACE", true
+	case ast.NodeKindUnionTypeDefinition, ast.NodeKindUnionTypeExtension:
+		return "UNION", true
+	case ast.NodeKindEnumTypeDefinition, ast.NodeKindEnumTypeExtension:
+		return "ENUM", true
+	case ast.NodeKindInputObjectTypeDefinition, ast.NodeKindInputObjectTypeExtension:
+		return "INPUT_OBJECT", true
+	case ast.NodeKindScalarTypeDefinition, ast.NodeKindScalarTypeExtension:
+		return "SCALAR", true
+	}
+	return "", false
+}
+
+func inputFields(doc *ast.Document, refs []int) map[string]*schemaField {
+	fields := map[string]*schemaField{}
+	for _, ref := range refs {
+		definition := doc.InputValueDefinitions[ref]
+		field := &schemaField{typ: printType(doc, definition.Type)}
+		if definition.DefaultValue.IsDefined {
+			value, _ := doc.PrintValueBytes(definition.DefaultValue.Value, nil)
+			defaultVal := string(value)
+			field.defaultVal = &defaultVal
+		}
+		fields[doc.InputValueDefinitionNameString(ref)] = field
+	}
+	return fields
+}
+
+func mergeFields(fields, other map[string]*schemaField) {
+	for name, field := range other {
+		fields[name] = field
+	}
+}
+
+func enumValues(doc *ast.Document, t *schemaType, refs []int) {
+	for _, ref := range refs {
+		t.values[doc.EnumValueDefinitionNameString(ref)] = doc.EnumValueDefinitions[ref].Directives.HasDirectiveByName(doc, deprecatedDirective)
+	}
+}
+
+func unionMembers(doc *ast.Document, t *schemaType, refs []int) {
+	for _, ref := range refs {
+		t.members[doc.ResolveTypeNameString(ref)] = struct{}{}
+	}
+}
+
+func printType(doc *ast.Document, ref int) string {
+	typ, _ := doc.PrintTypeBytes(ref, nil)
+	return string(typ)
+}
+
+type schemaDiff struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiff) add(typ string, criticality SchemaChangeCriticality, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, SchemaChange{
+		Type:        typ,
+		Criticality: criticality,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *schemaDiff) diffTypes(oldModel, newModel *schemaModel) {
+	for _, name := range sortedKeys(oldModel.types) {
+		oldType := oldModel.types[name]
+		newType, ok := newModel.types[name]
+		if !ok {
+			d.add(SchemaChangeTypeRemoved, SchemaChangeBreaking, name, "Type '%s' was removed", name)
+			continue
+		}
+
+		if oldType.kind != newType.kind {
+			d.add(SchemaChangeTypeKindChanged, SchemaChangeBreaking, name, "Type '%s' changed from %s to %s", name, oldType.kind, newType.kind)
+			continue
+		}
+
+		switch oldType.kind {
+		case "OBJECT", "INTERFACE":
+			d.diffInterfaces(name, oldType, newType)
+			d.diffFields(name, oldType, newType)
+		case "INPUT_OBJECT":
+			d.diffInputFields(name, oldType, newType)
+		case "ENUM":
+			d.diffEnumValues(name, oldType, newType)
+		case "UNION":
+			d.diffUnionMembers(name, oldType, newType)
+		}
+	}
+
+	for _, name := range sortedKeys(newModel.types) {
+		if _, ok := oldModel.types[name]; !ok {
+			d.add(SchemaChangeTypeAdded, SchemaChangeSafe, name, "Type '%s' was added", name)
+		}
+	}
+}
+
+func (d *schemaDiff) diffInterfaces(typeName string, oldType, newType *schemaType) {
+	for _, name := range sortedKeys(oldType.interfaces) {
+		if _, ok := newType.interfaces[name]; !ok {
+			d.add(SchemaChangeInterfaceRemoved, SchemaChangeBreaking, typeName, "'%s' no longer implements interface '%s'", typeName, name)
+		}
+	}
+
+	for _, name := range sortedKeys(newType.interfaces) {
+		if _, ok := oldType.interfaces[name]; !ok {
+			d.add(SchemaChangeInterfaceAdded, SchemaChangeDangerous, typeName, "'%s' implements interface '%s'", typeName, name)
+		}
+	}
+}
+
+func (d *schemaDiff) diffFields(typeName string, oldType, newType *schemaType) {
+	for _, name := range sortedKeys(oldType.fields) {
+		oldField := oldType.fields[name]
+		path := typeName + "." + name
+
+		newField, ok := newType.fields[name]
+		if !ok {
+			d.add(SchemaChangeFieldRemoved, SchemaChangeBreaking, path, "Field '%s' was removed from type '%s'", name, typeName)
+			continue
+		}
+
+		if oldField.typ != newField.typ {
+			criticality := SchemaChangeBreaking
+			if isSafeOutputTypeChange(oldField.typ, newField.typ) {
+				criticality = SchemaChangeSafe
+			}
+			d.add(SchemaChangeFieldTypeChanged, criticality, path, "Field '%s' changed type from '%s' to '%s'", path, oldField.typ, newField.typ)
+		}
+
+		switch {
+		case !oldField.deprecated && newField.deprecated:
+			d.add(SchemaChangeFieldDeprecationAdded, SchemaChangeSafe, path, "Field '%s' is deprecated", path)
+		case oldField.deprecated && !newField.deprecated:
+			d.add(SchemaChangeFieldDeprecationRemoved, SchemaChangeSafe, path, "Field '%s' is no longer deprecated", path)
+		}
+
+		d.diffArguments(path, oldField.args, newField.args)
+	}
+
+	for _, name := range sortedKeys(newType.fields) {
+		if _, ok := oldType.fields[name]; !ok {
+			d.add(SchemaChangeFieldAdded, SchemaChangeSafe, typeName+"."+name, "Field '%s' was added to type '%s'", name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiff) diffArguments(fieldPath string, oldArgs, newArgs map[string]*schemaField) {
+	for _, name := range sortedKeys(oldArgs) {
+		oldArg := oldArgs[name]
+		path := fieldPath + "." + name
+
+		newArg, ok := newArgs[name]
+		if !ok {
+			d.add(SchemaChangeArgumentRemoved, SchemaChangeBreaking, path, "Argument '%s' was removed from field '%s'", name, fieldPath)
+			continue
+		}
+
+		if oldArg.typ != newArg.typ {
+			criticality := SchemaChangeBreaking
+			if isSafeInputTypeChange(oldArg.typ, newArg.typ) {
+				criticality = SchemaChangeSafe
+			}
+			d.add(SchemaChangeArgumentTypeChanged, criticality, path, "Type of argument '%s' on field '%s' changed from '%s' to '%s'", name, fieldPath, oldArg.typ, newArg.typ)
+		}
+
+		if !sameDefault(oldArg.defaultVal, newArg.defaultVal) {
+			d.add(SchemaChangeArgumentDefaultChanged, SchemaChangeDangerous, path, "Default value of argument '%s' on field '%s' changed", name, fieldPath)
+		}
+	}
+
+	for _, name := range sortedKeys(newArgs) {
+		if _, ok := oldArgs[name]; ok {
+			continue
+		}
+
+		path := fieldPath + "." + name
+		if newArgs[name].isRequired() {
+			d.add(SchemaChangeArgumentAdded, SchemaChangeBreaking, path, "Required argument '%s' was added to field '%s'", name, fieldPath)
+		} else {
+			d.add(SchemaChangeArgumentAdded, SchemaChangeDangerous, path, "Argument '%s' was added to field '%s'", name, fieldPath)
+		}
+	}
+}
+
+func (d *schemaDiff) diffInputFields(typeName string, oldType, newType *schemaType) {
+	for _, name := range sortedKeys(oldType.fields) {
+		oldField := oldType.fields[name]
+		path := typeName + "." + name
+
+		newField, ok := newType.fields[name]
+		if !ok {
+			d.add(SchemaChangeInputFieldRemoved, SchemaChangeBreaking, path, "Input field '%s' was removed from input type '%s'", name, typeName)
+			continue
+		}
+
+		if oldField.typ != newField.typ {
+			criticality := SchemaChangeBreaking
+			if isSafeInputTypeChange(oldField.typ, newField.typ) {
+				criticality = SchemaChangeSafe
+			}
+			d.add(SchemaChangeInputFieldTypeChanged, criticality, path, "Input field '%s' changed type from '%s' to '%s'", path, oldField.typ, newField.typ)
+		}
+
+		if !sameDefault(oldField.defaultVal, newField.defaultVal) {
+			d.add(SchemaChangeInputFieldDefaultChanged, SchemaChangeDangerous, path, "Default value of input field '%s' changed", path)
+		}
+	}
+
+	for _, name := range sortedKeys(newType.fields) {
+		if _, ok := oldType.fields[name]; ok {
+			continue
+		}
+
+		path := typeName + "." + name
+		if newType.fields[name].isRequired() {
+			d.add(SchemaChangeInputFieldAdded, SchemaChangeBreaking, path, "Required input field '%s' was added to input type '%s'", name, typeName)
+		} else {
+			d.add(SchemaChangeInputFieldAdded, SchemaChangeSafe, path, "Input field '%s' was added to input type '%s'", name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiff) diffEnumValues(typeName string, oldType, newType *schemaType) {
+	for _, name := range sortedKeys(oldType.values) {
+		path := typeName + "." + name
+
+		deprecated, ok := newType.values[name]
+		if !ok {
+			d.add(SchemaChangeEnumValueRemoved, SchemaChangeBreaking, path, "Enum value '%s' was removed from enum '%s'", name, typeName)
+			continue
+		}
+
+		if deprecated && !oldType.values[name] {
+			d.add(SchemaChangeEnumValueDeprecationAdded, SchemaChangeSafe, path, "Enum value '%s' is deprecated", path)
+		}
+	}
+
+	for _, name := range sortedKeys(newType.values) {
+		if _, ok := oldType.values[name]; !ok {
+			d.add(SchemaChangeEnumValueAdded, SchemaChangeDangerous, typeName+"."+name, "Enum value '%s' was added to enum '%s'", name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiff) diffUnionMembers(typeName string, oldType, newType *schemaType) {
+	for _, name := range sortedKeys(oldType.members) {
+		if _, ok := newType.members[name]; !ok {
+			d.add(SchemaChangeUnionMemberRemoved, SchemaChangeBreaking, typeName, "Member '%s' was removed from union '%s'", name, typeName)
+		}
+	}
+
+	for _, name := range sortedKeys(newType.members) {
+		if _, ok := oldType.members[name]; !ok {
+			d.add(SchemaChangeUnionMemberAdded, SchemaChangeDangerous, typeName, "Member '%s' was added to union '%s'", name, typeName)
+		}
+	}
+}
+
+func (d *schemaDiff) diffDirectives(oldModel, newModel *schemaModel) {
+	for _, name := range sortedKeys(oldModel.directives) {
+		oldDirective := oldModel.directives[name]
+		path := "@" + name
+
+		newDirective, ok := newModel.directives[name]
+		if !ok {
+			d.add(SchemaChangeDirectiveRemoved, SchemaChangeBreaking, path, "Directive '%s' was removed", path)
+			continue
+		}
+
+		for _, location := range sortedKeys(oldDirective.locations) {
+			if _, ok := newDirective.locations[location]; !ok {
+				d.add(SchemaChangeDirectiveLocationRemoved, SchemaChangeBreaking, path, "Location '%s' was removed from directive '%s'", location, path)
+			}
+		}
+
+		for _, arg := range sortedKeys(oldDirective.args) {
+			if _, ok := newDirective.args[arg]; !ok {
+				d.add(SchemaChangeDirectiveArgumentRemoved, SchemaChangeBreaking, path+"."+arg, "Argument '%s' was removed from directive '%s'", arg, path)
+			}
+		}
+
+		for _, arg := range sortedKeys(newDirective.args) {
+			if _, ok := oldDirective.args[arg]; ok {
+				continue
+			}
+
+			criticality := SchemaChangeSafe
+			if newDirective.args[arg].isRequired() {
+				criticality = SchemaChangeBreaking
+			}
+			d.add(SchemaChangeDirectiveArgumentAdded, criticality, path+"."+arg, "Argument '%s' was added to directive '%s'", arg, path)
+		}
+	}
+
+	for _, name := range sortedKeys(newModel.directives) {
+		if _, ok := oldModel.directives[name]; !ok {
+			d.add(SchemaChangeDirectiveAdded, SchemaChangeSafe, "@"+name, "Directive '@%s' was added", name)
+		}
+	}
+}
+
+// isRequired reports whether an argument or input field must be set by clients.
+func (f *schemaField) isRequired() bool {
+	return strings.HasSuffix(f.typ, "!") && f.defaultVal == nil
+}
+
+func sameDefault(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// isSafeOutputTypeChange reports whether clients reading a value of the old type can read a value of the new
+// type, which is the case when the new type only makes the old one non-null at any level.
+func isSafeOutputTypeChange(oldType, newType string) bool {
+	if oldType == newType {
+		return true
+	}
+
+	oldNonNull, newNonNull := strings.HasSuffix(oldType, "!"), strings.HasSuffix(newType, "!")
+	switch {
+	case newNonNull && !oldNonNull:
+		return isSafeOutputTypeChange(oldType, strings.TrimSuffix(newType, "!"))
+	case newNonNull && oldNonNull:
+		return isSafeOutputTypeChange(strings.TrimSuffix(oldType, "!"), strings.TrimSuffix(newType, "!"))
+	case strings.HasPrefix(oldType, "[") && strings.HasPrefix(newType, "["):
+		return isSafeOutputTypeChange(oldType[1:len(oldType)-1], newType[1:len(newType)-1])
+	}
+	return false
+}
+
+// isSafeInputTypeChange reports whether values clients sent for the old type are valid for the new type, which is
+// the case when the new type only makes the old one nullable at any level.
+func isSafeInputTypeChange(oldType, newType string) bool {
+	return isSafeOutputTypeChange(newType, oldType)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,261 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	t.Run("no previous schema", func(t *testing.T) {
+		changes, err := DiffSchemas("", `type Query { a: String }`)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := DiffSchemas(`type Query { a: String }`, `type Query {`)
+		assert.Error(t, err)
+	})
+
+	t.Run("identical schemas", func(t *testing.T) {
+		changes, err := DiffSchemas(`type Query { a: String }`, `type Query {
+			a: String
+		}`)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	testCases := []struct {
+		name        string
+		oldSchema   string
+		newSchema   string
+		changeType  string
+		criticality SchemaChangeCriticality
+		path        string
+	}{
+		{
+			name:        "type added",
+			oldSchema:   `type Query { a: String }`,
+			newSchema:   `type Query { a: String } type User { id: ID }`,
+			changeType:  SchemaChangeTypeAdded,
+			criticality: SchemaChangeSafe,
+			path:        "User",
+		},
+		{
+			name:        "type removed",
+			oldSchema:   `type Query { a: String } type User { id: ID }`,
+			newSchema:   `type Query { a: String }`,
+			changeType:  SchemaChangeTypeRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "User",
+		},
+		{
+			name:        "type kind changed",
+			oldSchema:   `type Query { a: String } type User { id: ID }`,
+			newSchema:   `type Query { a: String } interface User { id: ID }`,
+			changeType:  SchemaChangeTypeKindChanged,
+			criticality: SchemaChangeBreaking,
+			path:        "User",
+		},
+		{
+			name:        "field added",
+			oldSchema:   `type Query { a: String }`,
+			newSchema:   `type Query { a: String b: Int }`,
+			changeType:  SchemaChangeFieldAdded,
+			criticality: SchemaChangeSafe,
+			path:        "Query.b",
+		},
+		{
+			name:        "field added by extension",
+			oldSchema:   `type Query { a: String }`,
+			newSchema:   `type Query { a: String } extend type Query { b: Int }`,
+			changeType:  SchemaChangeFieldAdded,
+			criticality: SchemaChangeSafe,
+			path:        "Query.b",
+		},
+		{
+			name:        "field removed",
+			oldSchema:   `type Query { a: String b: Int }`,
+			newSchema:   `type Query { a: String }`,
+			changeType:  SchemaChangeFieldRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.b",
+		},
+		{
+			name:        "field made non-null",
+			oldSchema:   `type Query { a: [String] }`,
+			newSchema:   `type Query { a: [String!]! }`,
+			changeType:  SchemaChangeFieldTypeChanged,
+			criticality: SchemaChangeSafe,
+			path:        "Query.a",
+		},
+		{
+			name:        "field made nullable",
+			oldSchema:   `type Query { a: String! }`,
+			newSchema:   `type Query { a: String }`,
+			changeType:  SchemaChangeFieldTypeChanged,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.a",
+		},
+		{
+			name:        "field type changed",
+			oldSchema:   `type Query { a: String }`,
+			newSchema:   `type Query { a: Int }`,
+			changeType:  SchemaChangeFieldTypeChanged,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.a",
+		},
+		{
+			name:        "field deprecated",
+			oldSchema:   `type Query { a: String }`,
+			newSchema:   `type Query { a: String @deprecated(reason: "use b") }`,
+			changeType:  SchemaChangeFieldDeprecationAdded,
+			criticality: SchemaChangeSafe,
+			path:        "Query.a",
+		},
+		{
+			name:        "required argument added",
+			oldSchema:   `type Query { user: String }`,
+			newSchema:   `type Query { user(id: ID!): String }`,
+			changeType:  SchemaChangeArgumentAdded,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.user.id",
+		},
+		{
+			name:        "optional argument added",
+			oldSchema:   `type Query { users: String }`,
+			newSchema:   `type Query { users(first: Int! = 10): String }`,
+			changeType:  SchemaChangeArgumentAdded,
+			criticality: SchemaChangeDangerous,
+			path:        "Query.users.first",
+		},
+		{
+			name:        "argument removed",
+			oldSchema:   `type Query { user(id: ID): String }`,
+			newSchema:   `type Query { user: String }`,
+			changeType:  SchemaChangeArgumentRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.user.id",
+		},
+		{
+			name:        "argument made nullable",
+			oldSchema:   `type Query { user(id: ID!): String }`,
+			newSchema:   `type Query { user(id: ID): String }`,
+			changeType:  SchemaChangeArgumentTypeChanged,
+			criticality: SchemaChangeSafe,
+			path:        "Query.user.id",
+		},
+		{
+			name:        "argument made non-null",
+			oldSchema:   `type Query { user(id: ID): String }`,
+			newSchema:   `type Query { user(id: ID!): String }`,
+			changeType:  SchemaChangeArgumentTypeChanged,
+			criticality: SchemaChangeBreaking,
+			path:        "Query.user.id",
+		},
+		{
+			name:        "argument default changed",
+			oldSchema:   `type Query { users(first: Int = 10): String }`,
+			newSchema:   `type Query { users(first: Int = 20): String }`,
+			changeType:  SchemaChangeArgumentDefaultChanged,
+			criticality: SchemaChangeDangerous,
+			path:        "Query.users.first",
+		},
+		{
+			name:        "required input field added",
+			oldSchema:   `type Query { a(in: In): String } input In { a: String }`,
+			newSchema:   `type Query { a(in: In): String } input In { a: String b: String! }`,
+			changeType:  SchemaChangeInputFieldAdded,
+			criticality: SchemaChangeBreaking,
+			path:        "In.b",
+		},
+		{
+			name:        "optional input field added",
+			oldSchema:   `type Query { a(in: In): String } input In { a: String }`,
+			newSchema:   `type Query { a(in: In): String } input In { a: String b: String }`,
+			changeType:  SchemaChangeInputFieldAdded,
+			criticality: SchemaChangeSafe,
+			path:        "In.b",
+		},
+		{
+			name:        "input field removed",
+			oldSchema:   `type Query { a(in: In): String } input In { a: String b: String }`,
+			newSchema:   `type Query { a(in: In): String } input In { a: String }`,
+			changeType:  SchemaChangeInputFieldRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "In.b",
+		},
+		{
+			name:        "enum value added",
+			oldSchema:   `type Query { a: Color } enum Color { RED }`,
+			newSchema:   `type Query { a: Color } enum Color { RED BLUE }`,
+			changeType:  SchemaChangeEnumValueAdded,
+			criticality: SchemaChangeDangerous,
+			path:        "Color.BLUE",
+		},
+		{
+			name:        "enum value removed",
+			oldSchema:   `type Query { a: Color } enum Color { RED BLUE }`,
+			newSchema:   `type Query { a: Color } enum Color { RED }`,
+			changeType:  SchemaChangeEnumValueRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "Color.BLUE",
+		},
+		{
+			name:        "union member added",
+			oldSchema:   `type Query { a: Result } union Result = A type A { a: String } type B { b: String }`,
+			newSchema:   `type Query { a: Result } union Result = A | B type A { a: String } type B { b: String }`,
+			changeType:  SchemaChangeUnionMemberAdded,
+			criticality: SchemaChangeDangerous,
+			path:        "Result",
+		},
+		{
+			name:        "union member removed",
+			oldSchema:   `type Query { a: Result } union Result = A | B type A { a: String } type B { b: String }`,
+			newSchema:   `type Query { a: Result } union Result = A type A { a: String } type B { b: String }`,
+			changeType:  SchemaChangeUnionMemberRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "Result",
+		},
+		{
+			name:        "interface removed",
+			oldSchema:   `type Query { a: A } interface Node { id: ID } type A implements Node { id: ID }`,
+			newSchema:   `type Query { a: A } interface Node { id: ID } type A { id: ID }`,
+			changeType:  SchemaChangeInterfaceRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "A",
+		},
+		{
+			name:        "directive removed",
+			oldSchema:   `directive @auth on FIELD_DEFINITION type Query { a: String }`,
+			newSchema:   `type Query { a: String }`,
+			changeType:  SchemaChangeDirectiveRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "@auth",
+		},
+		{
+			name:        "directive location removed",
+			oldSchema:   `directive @cached on FIELD | QUERY type Query { a: String }`,
+			newSchema:   `directive @cached on FIELD type Query { a: String }`,
+			changeType:  SchemaChangeDirectiveLocationRemoved,
+			criticality: SchemaChangeBreaking,
+			path:        "@cached",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := DiffSchemas(tc.oldSchema, tc.newSchema)
+			require.NoError(t, err)
+			require.Len(t, changes, 1)
+
+			assert.Equal(t, tc.changeType, changes[0].Type)
+			assert.Equal(t, tc.criticality, changes[0].Criticality)
+			assert.Equal(t, tc.path, changes[0].Path)
+			assert.NotEmpty(t, changes[0].Message)
+			assert.Equal(t, tc.criticality == SchemaChangeBreaking, HasBreakingChanges(changes))
+		})
+	}
+}
@@ -60,6 +60,9 @@ func (t *Service) ClearSession(session *user.SessionState) error {
 
 		if policy.Partitions.Complexity || all {
 			session.MaxQueryDepth = 0
+			session.MaxQueryCost = 0
+			session.MaxSubscriptions = 0
+			session.MaxSubscriptionLifetime = 0
 		}
 	}
 
@@ -206,6 +209,9 @@ func (t *Service) Apply(session *user.SessionState) error {
 
 		if !applyState.didComplexity[k] {
 			v.Limit.MaxQueryDepth = session.MaxQueryDepth
+			v.Limit.MaxQueryCost = session.MaxQueryCost
+			v.Limit.MaxSubscriptions = session.MaxSubscriptions
+			v.Limit.MaxSubscriptionLifetime = session.MaxSubscriptionLifetime
 		}
 
 		if !applyState.didQuota[k] {
@@ -359,6 +365,8 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 		ar := rights[k]
 
 		if !usePartitions || policy.Partitions.Acl {
+			// an earlier policy granted the API, its rights are merged with the rights of this policy
+			merging := applyState.didAcl[k]
 			applyState.didAcl[k] = true
 
 			// Merge ACLs for the same API
@@ -419,6 +427,15 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 					}
 				}
 
+				if merging {
+					r.ResponseFields = mergeResponseFields(r.ResponseFields, v.ResponseFields)
+					r.FieldArgumentRules = mergeFieldArgumentRules(r.FieldArgumentRules, v.FieldArgumentRules)
+				} else {
+					r.ResponseFields = v.ResponseFields
+					r.FieldArgumentRules = v.FieldArgumentRules
+				}
+				r.MaskRestrictedFields = r.MaskRestrictedFields || v.MaskRestrictedFields
+
 				ar = r
 			}
 
@@ -476,6 +493,27 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 					session.MaxQueryDepth = policy.MaxQueryDepth
 				}
 			}
+
+			if greaterThanInt(policy.MaxQueryCost, ar.Limit.MaxQueryCost) {
+				ar.Limit.MaxQueryCost = policy.MaxQueryCost
+				if greaterThanInt(policy.MaxQueryCost, session.MaxQueryCost) {
+					session.MaxQueryCost = policy.MaxQueryCost
+				}
+			}
+
+			if greaterThanInt(policy.MaxSubscriptions, ar.Limit.MaxSubscriptions) {
+				ar.Limit.MaxSubscriptions = policy.MaxSubscriptions
+				if greaterThanInt(policy.MaxSubscriptions, session.MaxSubscriptions) {
+					session.MaxSubscriptions = policy.MaxSubscriptions
+				}
+			}
+
+			if greaterThanInt64(policy.MaxSubscriptionLifetime, ar.Limit.MaxSubscriptionLifetime) {
+				ar.Limit.MaxSubscriptionLifetime = policy.MaxSubscriptionLifetime
+				if greaterThanInt64(policy.MaxSubscriptionLifetime, session.MaxSubscriptionLifetime) {
+					session.MaxSubscriptionLifetime = policy.MaxSubscriptionLifetime
+				}
+			}
 		}
 
 		// Respect existing QuotaRenews
@@ -498,6 +536,9 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 
 		if !usePartitions || policy.Partitions.Complexity {
 			session.MaxQueryDepth = policy.MaxQueryDepth
+			session.MaxQueryCost = policy.MaxQueryCost
+			session.MaxSubscriptions = policy.MaxSubscriptions
+			session.MaxSubscriptionLifetime = policy.MaxSubscriptionLifetime
 		}
 
 		if !usePartitions || policy.Partitions.Quota {
@@ -536,6 +577,9 @@ func (t *Service) updateSessionRootVars(session *user.SessionState, rights map[s
 
 			if len(applyState.didComplexity) == 1 {
 				session.MaxQueryDepth = v.Limit.MaxQueryDepth
+				session.MaxQueryCost = v.Limit.MaxQueryCost
+				session.MaxSubscriptions = v.Limit.MaxSubscriptions
+				session.MaxSubscriptionLifetime = v.Limit.MaxSubscriptionLifetime
 			}
 		}
 	}
@@ -103,3 +103,38 @@ func greaterThanInt(first, second int) bool {
 
 	return first > second
 }
+
+// mergeResponseFields merges the response field rules of two policies, keeping the
+// most permissive combination: allowed fields are combined, while only the fields
+// denied or masked by both policies stay denied or masked. A policy without rules leaves
+// every field unfiltered.
+func mergeResponseFields(dest, src user.ResponseFieldRules) user.ResponseFieldRules {
+	if len(dest.Allowed) > 0 && len(src.Allowed) > 0 {
+		dest.Allowed = appendIfMissing(slices.Clone(dest.Allowed), src.Allowed...)
+	} else {
+		dest.Allowed = nil
+	}
+
+	dest.Denied = intersection(dest.Denied, src.Denied)
+	dest.Masked = intersection(dest.Masked, src.Masked)
+
+	return dest
+}
+
+// mergeFieldArgumentRules merges the GraphQL field argument rules of two policies, keeping the
+// most permissive combination: only arguments restricted by both policies stay restricted,
+// allowing the values of either policy. A policy without rules leaves every argument unrestricted.
+func mergeFieldArgumentRules(dest, src []user.FieldArgumentRule) []user.FieldArgumentRule {
+	var merged []user.FieldArgumentRule
+	for _, rule := range dest {
+		for _, srcRule := range src {
+			if rule.TypeName == srcRule.TypeName && rule.FieldName == srcRule.FieldName && rule.ArgumentName == srcRule.ArgumentName {
+				rule.AllowedValues = appendIfMissing(slices.Clone(rule.AllowedValues), srcRule.AllowedValues...)
+				merged = append(merged, rule)
+				break
+			}
+		}
+	}
+
+	return merged
+}
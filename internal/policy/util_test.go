@@ -62,3 +62,140 @@ func TestMergeAllowedURLs(t *testing.T) {
 
 	assert.Equal(t, want, session.AccessRights["a"].AllowedURLs)
 }
+
+func TestMergeResponseFields(t *testing.T) {
+	svc := &policy.Service{}
+
+	pol1 := user.Policy{
+		ID: "pol1",
+		AccessRights: map[string]user.AccessDefinition{
+			"a": {
+				ResponseFields: user.ResponseFieldRules{
+					Allowed: []string{"$.id"},
+					Denied:  []string{"$.internal_id", "$.supplier_id"},
+					Masked:  []string{"$.email"},
+				},
+			},
+		},
+	}
+	pol2 := user.Policy{
+		ID: "pol2",
+		AccessRights: map[string]user.AccessDefinition{
+			"a": {
+				ResponseFields: user.ResponseFieldRules{
+					Allowed: []string{"$.name"},
+					Denied:  []string{"$.internal_id"},
+				},
+			},
+		},
+	}
+
+	for _, policies := range [][]user.Policy{{pol1, pol2}, {pol2, pol1}} {
+		session := &user.SessionState{}
+		session.SetCustomPolicies(policies)
+
+		assert.NoError(t, svc.Apply(session))
+
+		got := session.AccessRights["a"].ResponseFields
+		assert.ElementsMatch(t, []string{"$.id", "$.name"}, got.Allowed)
+		assert.Equal(t, []string{"$.internal_id"}, got.Denied)
+		assert.Empty(t, got.Masked)
+	}
+}
+
+func TestMergeResponseFields_Unfiltered(t *testing.T) {
+	svc := &policy.Service{}
+
+	filtered := user.Policy{
+		ID: "filtered",
+		AccessRights: map[string]user.AccessDefinition{
+			"a": {
+				ResponseFields: user.ResponseFieldRules{
+					Allowed: []string{"$.id"},
+					Denied:  []string{"$.internal_id"},
+					Masked:  []string{"$.email"},
+				},
+			},
+		},
+	}
+	unfiltered := user.Policy{
+		ID:           "unfiltered",
+		AccessRights: map[string]user.AccessDefinition{"a": {}},
+	}
+
+	for _, policies := range [][]user.Policy{{filtered, unfiltered}, {unfiltered, filtered}} {
+		session := &user.SessionState{}
+		session.SetCustomPolicies(policies)
+
+		assert.NoError(t, svc.Apply(session))
+		assert.True(t, session.AccessRights["a"].ResponseFields.IsEmpty())
+	}
+}
+
+func TestMergeFieldArgumentRules(t *testing.T) {
+	svc := &policy.Service{}
+
+	session := &user.SessionState{}
+	policies := []user.Policy{
+		{
+			ID: "pol1",
+			AccessRights: map[string]user.AccessDefinition{
+				"a": {
+					FieldArgumentRules: []user.FieldArgumentRule{
+						{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"t1"}},
+						{TypeName: "Query", FieldName: "invoices", ArgumentName: "tenantId", AllowedValues: []string{"t1"}},
+					},
+				},
+			},
+		},
+		{
+			ID: "pol2",
+			AccessRights: map[string]user.AccessDefinition{
+				"a": {
+					FieldArgumentRules: []user.FieldArgumentRule{
+						{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"t2"}},
+					},
+					MaskRestrictedFields: true,
+				},
+			},
+		},
+	}
+
+	session.SetCustomPolicies(policies)
+
+	assert.NoError(t, svc.Apply(session))
+
+	want := []user.FieldArgumentRule{
+		{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"t1", "t2"}},
+	}
+
+	assert.Equal(t, want, session.AccessRights["a"].FieldArgumentRules)
+	assert.True(t, session.AccessRights["a"].MaskRestrictedFields)
+}
+
+func TestMergeFieldArgumentRules_Unrestricted(t *testing.T) {
+	svc := &policy.Service{}
+
+	restricted := user.Policy{
+		ID: "restricted",
+		AccessRights: map[string]user.AccessDefinition{
+			"a": {
+				FieldArgumentRules: []user.FieldArgumentRule{
+					{TypeName: "Query", FieldName: "orders", ArgumentName: "tenantId", AllowedValues: []string{"t1"}},
+				},
+			},
+		},
+	}
+	unrestricted := user.Policy{
+		ID:           "unrestricted",
+		AccessRights: map[string]user.AccessDefinition{"a": {}},
+	}
+
+	for _, policies := range [][]user.Policy{{restricted, unrestricted}, {unrestricted, restricted}} {
+		session := &user.SessionState{}
+		session.SetCustomPolicies(policies)
+
+		assert.NoError(t, svc.Apply(session))
+		assert.Empty(t, session.AccessRights["a"].FieldArgumentRules)
+	}
+}
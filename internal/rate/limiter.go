@@ -30,6 +30,22 @@ func Limiter(gwConfig *config.Config, redis redis.UniversalClient) limiter.Limit
 	return nil
 }
 
+// CostLimiter returns the rate limiter consuming the cost of requests from redis, matching the rate limiter configured
+// by gateway. The redis rolling and sentinel rate limiters count the cost in a sliding window.
+func CostLimiter(gwConfig *config.Config, redis redis.UniversalClient) limiter.CostLimiterFunc {
+	res := limiter.NewLimiter(redis)
+
+	name, _ := LimiterKind(gwConfig)
+	switch name {
+	case LimitLeakyBucket, LimitTokenBucket:
+		return res.TokenBucketCost
+	case LimitFixedWindow:
+		return res.FixedWindowCost
+	}
+
+	return res.SlidingWindowCost
+}
+
 // LimiterKey returns a redis key name based on passed parameters.
 // The key should be post-fixed if multiple keys are required (sentinel).
 func LimiterKey(currentSession *user.SessionState, rateScope string, key string, useCustomKey bool) string {
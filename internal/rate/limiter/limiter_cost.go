@@ -0,0 +1,83 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+)
+
+// CostLimiterFunc consumes cost from the allowance of a key. It returns ErrLimitExhausted when the remaining
+// allowance doesn't cover the cost, in which case no allowance is consumed.
+type CostLimiterFunc func(ctx context.Context, key string, cost int64, rate float64, per float64) error
+
+// The scripts consume the cost atomically, they return 1 when it was consumed and 0 when the limit is exhausted.
+var (
+	// fixedWindowCostScript counts the cost in a window that starts with the first request.
+	fixedWindowCostScript = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+if count > tonumber(ARGV[2]) then
+	redis.call("DECRBY", KEYS[1], ARGV[1])
+	return 0
+end
+return 1
+`)
+
+	// slidingWindowCostScript weighs the cost counted in the previous window by its overlap with the sliding window.
+	slidingWindowCostScript = redis.NewScript(`
+local now, per, rate, cost = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+local window = math.floor(now / per)
+local current = tonumber(redis.call("HGET", KEYS[1], tostring(window)) or 0)
+local previous = tonumber(redis.call("HGET", KEYS[1], tostring(window - 1)) or 0)
+if previous * (1 - (now % per) / per) + current + cost > rate then
+	return 0
+end
+redis.call("HINCRBY", KEYS[1], tostring(window), cost)
+redis.call("HDEL", KEYS[1], tostring(window - 2))
+redis.call("PEXPIRE", KEYS[1], per * 2)
+return 1
+`)
+
+	// tokenBucketCostScript implements the generic cell rate algorithm, which stores the time the bucket is full again.
+	tokenBucketCostScript = redis.NewScript(`
+local now, per, rate, cost = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+local full = math.max(tonumber(redis.call("GET", KEYS[1]) or now), now) + per / rate * cost
+if full - now > per then
+	return 0
+end
+redis.call("SET", KEYS[1], tostring(full), "PX", math.ceil(full - now))
+return 1
+`)
+)
+
+// FixedWindowCost counts the cost in fixed windows, like FixedWindow counts requests.
+func (l *Limiter) FixedWindowCost(ctx context.Context, key string, cost int64, rate float64, per float64) error {
+	ttl := time.Duration(per * float64(time.Second))
+	return l.runCostScript(ctx, fixedWindowCostScript, key, cost, int64(rate), ttl.Milliseconds())
+}
+
+// SlidingWindowCost counts the cost in a sliding window, like SlidingWindow counts requests.
+func (l *Limiter) SlidingWindowCost(ctx context.Context, key string, cost int64, rate float64, per float64) error {
+	return l.runCostScript(ctx, slidingWindowCostScript, key, l.clock.Now().UnixMilli(), per*1000, rate, cost)
+}
+
+// TokenBucketCost takes the cost from a bucket of rate tokens refilled over per seconds. It's used for the token
+// and the leaky bucket rate limiters.
+func (l *Limiter) TokenBucketCost(ctx context.Context, key string, cost int64, rate float64, per float64) error {
+	return l.runCostScript(ctx, tokenBucketCostScript, key, l.clock.Now().UnixMilli(), per*1000, rate, cost)
+}
+
+func (l *Limiter) runCostScript(ctx context.Context, script *redis.Script, key string, args ...interface{}) error {
+	consumed, err := script.Run(ctx, l.redis, []string{key}, args...).Int()
+	if err != nil {
+		return err
+	}
+
+	if consumed == 0 {
+		return ErrLimitExhausted
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package rate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/rate/limiter"
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// TestCostLimiter is an integration test that tests the cost counting of the limiters.
+func TestCostLimiter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	conf, err := config.New()
+	require.NoError(t, err)
+
+	conn, err := storage.NewConnector(storage.DefaultConn, *conf)
+	require.NoError(t, err)
+
+	var db redis.UniversalClient
+	require.True(t, conn.As(&db))
+
+	l := limiter.NewLimiter(db)
+	limiters := map[string]limiter.CostLimiterFunc{
+		"fixed window":   l.FixedWindowCost,
+		"sliding window": l.SlidingWindowCost,
+		"token bucket":   l.TokenBucketCost,
+		"configured":     rate.CostLimiter(conf, db),
+	}
+
+	for name, costLimiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			key := uuid.New()
+
+			assert.NoError(t, costLimiter(ctx, key, 4, 10, 60))
+			assert.NoError(t, costLimiter(ctx, key, 4, 10, 60))
+			// blocked requests don't consume the allowance
+			assert.ErrorIs(t, costLimiter(ctx, key, 4, 10, 60), rate.ErrLimitExhausted)
+			assert.NoError(t, costLimiter(ctx, key, 2, 10, 60))
+			assert.ErrorIs(t, costLimiter(ctx, key, 1, 10, 60), rate.ErrLimitExhausted)
+			assert.Greater(t, db.PTTL(ctx, key).Val(), time.Duration(0))
+
+			assert.ErrorIs(t, costLimiter(ctx, uuid.New(), 11, 10, 60), rate.ErrLimitExhausted)
+		})
+	}
+}
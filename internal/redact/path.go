@@ -0,0 +1,180 @@
+// Package redact removes and masks fields of decoded JSON documents.
+//
+// Fields are selected with a subset of JSONPath: the root `$`, child access by
+// name (`.name` or `['name']`), array indexes (`[0]`), wildcards (`.*` or `[*]`)
+// and recursive descent (`..name`).
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned when a path can't be parsed.
+var ErrInvalidPath = errors.New("invalid JSONPath")
+
+type segment struct {
+	name      string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	source   string
+	segments []segment
+}
+
+// String returns the expression the path was compiled from.
+func (p Path) String() string {
+	return p.source
+}
+
+// Compile parses a JSONPath expression.
+func Compile(source string) (Path, error) {
+	invalid := func(reason string) (Path, error) {
+		return Path{}, fmt.Errorf("%w %q: %s", ErrInvalidPath, source, reason)
+	}
+
+	if !strings.HasPrefix(source, "$") {
+		return invalid("must start with $")
+	}
+
+	var (
+		segments []segment
+		rest     = source[1:]
+	)
+
+	for len(rest) > 0 {
+		var seg segment
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			seg.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return invalid("empty field name")
+			}
+			if rest[:end] == "*" {
+				seg.wildcard = true
+			} else {
+				seg.name = rest[:end]
+			}
+			rest = rest[end:]
+			segments = append(segments, seg)
+			continue
+		}
+
+		if !strings.HasPrefix(rest, "[") {
+			return invalid(fmt.Sprintf("unexpected %q", rest))
+		}
+
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return invalid("missing closing bracket")
+		}
+
+		selector := rest[1:end]
+		rest = rest[end+1:]
+
+		switch {
+		case selector == "*":
+			seg.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			seg.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return invalid(fmt.Sprintf("unsupported selector %q", selector))
+			}
+			seg.index, seg.isIndex = index, true
+		}
+
+		segments = append(segments, seg)
+	}
+
+	return Path{source: source, segments: segments}, nil
+}
+
+// location is the concrete position of a matched value, made of object keys and array indexes.
+type location []interface{}
+
+// find returns the locations of all values matching the path in doc.
+func (p Path) find(doc interface{}) []location {
+	var found []location
+	p.match(doc, p.segments, nil, &found)
+	return found
+}
+
+func (p Path) match(node interface{}, segments []segment, at location, found *[]location) {
+	if len(segments) == 0 {
+		*found = append(*found, at)
+		return
+	}
+
+	seg := segments[0]
+	if seg.recursive {
+		// apply the segment to every descendant, including the node itself
+		p.match(node, append([]segment{{name: seg.name, index: seg.index, isIndex: seg.isIndex, wildcard: seg.wildcard}}, segments[1:]...), at, found)
+		forEachChild(node, func(key interface{}, child interface{}) {
+			p.match(child, segments, extend(at, key), found)
+		})
+		return
+	}
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if seg.wildcard {
+			for key, child := range v {
+				p.match(child, segments[1:], extend(at, key), found)
+			}
+			return
+		}
+		if child, ok := v[seg.name]; ok && !seg.isIndex {
+			p.match(child, segments[1:], extend(at, seg.name), found)
+		}
+	case []interface{}:
+		if seg.wildcard {
+			for i, child := range v {
+				p.match(child, segments[1:], extend(at, i), found)
+			}
+			return
+		}
+		if seg.isIndex && seg.index < len(v) {
+			p.match(v[seg.index], segments[1:], extend(at, seg.index), found)
+		}
+	}
+}
+
+func forEachChild(node interface{}, fn func(key interface{}, child interface{})) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			fn(key, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			fn(i, child)
+		}
+	}
+}
+
+func extend(at location, key interface{}) location {
+	next := make(location, len(at), len(at)+1)
+	copy(next, at)
+	return append(next, key)
+}
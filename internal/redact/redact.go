@@ -0,0 +1,213 @@
+package redact
+
+// MaskValue replaces the value of masked fields.
+const MaskValue = "****"
+
+// removed marks array elements and object fields pending removal.
+type removed struct{}
+
+// Rules selects the fields to keep, remove and mask in a document.
+type Rules struct {
+	// Allowed, when not empty, keeps only the fields matching these paths.
+	Allowed []Path
+	// Denied removes the fields matching these paths.
+	Denied []Path
+	// Masked replaces the values of the fields matching these paths with MaskValue.
+	Masked []Path
+}
+
+// CompileRules compiles the given allowed, denied and masked JSONPath expressions.
+func CompileRules(allowed, denied, masked []string) (Rules, error) {
+	var (
+		rules Rules
+		err   error
+	)
+
+	if rules.Allowed, err = compileAll(allowed); err != nil {
+		return Rules{}, err
+	}
+	if rules.Denied, err = compileAll(denied); err != nil {
+		return Rules{}, err
+	}
+	if rules.Masked, err = compileAll(masked); err != nil {
+		return Rules{}, err
+	}
+
+	return rules, nil
+}
+
+func compileAll(sources []string) ([]Path, error) {
+	paths := make([]Path, 0, len(sources))
+	for _, source := range sources {
+		path, err := Compile(source)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// IsEmpty returns true if the rules leave documents untouched.
+func (r Rules) IsEmpty() bool {
+	return len(r.Allowed) == 0 && len(r.Denied) == 0 && len(r.Masked) == 0
+}
+
+// Apply returns doc with the rules applied. Allowed paths are applied first,
+// then masked and denied paths, so a denied field is never sent masked. doc may
+// be modified in place.
+func (r Rules) Apply(doc interface{}) interface{} {
+	if len(r.Allowed) > 0 {
+		doc = keep(doc, r.Allowed)
+	}
+
+	for _, path := range r.Masked {
+		for _, at := range path.find(doc) {
+			if len(at) == 0 {
+				return MaskValue
+			}
+			set(doc, at, MaskValue)
+		}
+	}
+
+	for _, path := range r.Denied {
+		for _, at := range path.find(doc) {
+			if len(at) == 0 {
+				return nil
+			}
+			set(doc, at, removed{})
+		}
+	}
+
+	return compact(doc)
+}
+
+// keep builds a copy of doc containing only the values matching paths.
+func keep(doc interface{}, paths []Path) interface{} {
+	var kept interface{} = removed{}
+
+	for _, path := range paths {
+		for _, at := range path.find(doc) {
+			kept = copyAt(kept, doc, at)
+		}
+	}
+
+	if _, ok := kept.(removed); ok {
+		return emptyLike(doc)
+	}
+
+	return kept
+}
+
+// copyAt copies the value found at the given location of src into dst, creating
+// the containers along the way.
+func copyAt(dst, src interface{}, at location) interface{} {
+	if len(at) == 0 {
+		return src
+	}
+
+	switch key := at[0].(type) {
+	case string:
+		srcMap := src.(map[string]interface{})
+		dstMap, ok := dst.(map[string]interface{})
+		if !ok {
+			dstMap = map[string]interface{}{}
+		}
+		child, ok := dstMap[key]
+		if !ok {
+			child = removed{}
+		}
+		dstMap[key] = copyAt(child, srcMap[key], at[1:])
+		return dstMap
+	case int:
+		srcSlice := src.([]interface{})
+		dstSlice, ok := dst.([]interface{})
+		if !ok {
+			dstSlice = make([]interface{}, len(srcSlice))
+			for i := range dstSlice {
+				dstSlice[i] = removed{}
+			}
+		}
+		dstSlice[key] = copyAt(dstSlice[key], srcSlice[key], at[1:])
+		return dstSlice
+	}
+
+	return dst
+}
+
+// set replaces the value found at the given location. Locations are found before
+// any value is replaced, so a location below a value already removed or masked,
+// e.g. with nested matches of a recursive path, is skipped.
+func set(doc interface{}, at location, value interface{}) {
+	parent := doc
+	for _, key := range at[:len(at)-1] {
+		var ok bool
+		if parent, ok = child(parent, key); !ok {
+			return
+		}
+	}
+
+	switch k := at[len(at)-1].(type) {
+	case string:
+		if m, ok := parent.(map[string]interface{}); ok {
+			m[k] = value
+		}
+	case int:
+		if s, ok := parent.([]interface{}); ok && k < len(s) {
+			s[k] = value
+		}
+	}
+}
+
+// child returns the value of node at key, if node is a container holding it.
+func child(node interface{}, key interface{}) (interface{}, bool) {
+	switch k := key.(type) {
+	case string:
+		if m, ok := node.(map[string]interface{}); ok {
+			value, ok := m[k]
+			return value, ok
+		}
+	case int:
+		if s, ok := node.([]interface{}); ok && k < len(s) {
+			return s[k], true
+		}
+	}
+	return nil, false
+}
+
+// compact drops the values marked as removed.
+func compact(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, ok := child.(removed); ok {
+				delete(v, key)
+				continue
+			}
+			v[key] = compact(child)
+		}
+	case []interface{}:
+		kept := v[:0]
+		for _, child := range v {
+			if _, ok := child.(removed); ok {
+				continue
+			}
+			kept = append(kept, compact(child))
+		}
+		return kept
+	case removed:
+		return nil
+	}
+
+	return doc
+}
+
+func emptyLike(doc interface{}) interface{} {
+	switch doc.(type) {
+	case map[string]interface{}:
+		return map[string]interface{}{}
+	case []interface{}:
+		return []interface{}{}
+	}
+	return nil
+}
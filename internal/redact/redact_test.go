@@ -0,0 +1,129 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/redact"
+)
+
+const testDocument = `{
+	"id": "u-1",
+	"internal_id": 42,
+	"profile": {"name": "Tyk", "ssn": "123-45-6789", "address": {"city": "London", "ssn": "nested"}},
+	"orders": [
+		{"id": "o-1", "cost": 10, "supplier_id": "s-1"},
+		{"id": "o-2", "cost": 20, "supplier_id": "s-2"}
+	]
+}`
+
+func TestCompile(t *testing.T) {
+	for _, valid := range []string{"$", "$.a", "$.a.b", "$['a'].b", "$.a[0]", "$.a[*].b", "$.*", "$..b", "$..[0]"} {
+		_, err := redact.Compile(valid)
+		assert.NoError(t, err, valid)
+	}
+
+	for _, invalid := range []string{"", "a.b", "$.", "$.a[", "$.a[-1]", "$.a[?(@.b)]", "$a"} {
+		_, err := redact.Compile(invalid)
+		assert.ErrorIs(t, err, redact.ErrInvalidPath, invalid)
+	}
+}
+
+func TestRules_Apply(t *testing.T) {
+	testCases := []struct {
+		name    string
+		allowed []string
+		denied  []string
+		masked  []string
+		result  string
+	}{
+		{
+			name:   "no rules",
+			result: testDocument,
+		},
+		{
+			name:   "deny fields",
+			denied: []string{"$.internal_id", "$.orders[*].supplier_id", "$.missing"},
+			result: `{"id":"u-1","profile":{"name":"Tyk","ssn":"123-45-6789","address":{"city":"London","ssn":"nested"}},"orders":[{"id":"o-1","cost":10},{"id":"o-2","cost":20}]}`,
+		},
+		{
+			name:   "deny array element",
+			denied: []string{"$.orders[0]"},
+			result: `{"id":"u-1","internal_id":42,"profile":{"name":"Tyk","ssn":"123-45-6789","address":{"city":"London","ssn":"nested"}},"orders":[{"id":"o-2","cost":20,"supplier_id":"s-2"}]}`,
+		},
+		{
+			name:   "mask recursively",
+			masked: []string{"$..ssn"},
+			result: `{"id":"u-1","internal_id":42,"profile":{"name":"Tyk","ssn":"****","address":{"city":"London","ssn":"****"}},"orders":[{"id":"o-1","cost":10,"supplier_id":"s-1"},{"id":"o-2","cost":20,"supplier_id":"s-2"}]}`,
+		},
+		{
+			name:    "allow fields",
+			allowed: []string{"$.id", "$.profile.name", "$.orders[*].id"},
+			result:  `{"id":"u-1","profile":{"name":"Tyk"},"orders":[{"id":"o-1"},{"id":"o-2"}]}`,
+		},
+		{
+			name:    "allow then deny and mask",
+			allowed: []string{"$.profile", "$.orders"},
+			denied:  []string{"$.profile.address"},
+			masked:  []string{"$.orders[*].cost", "$.profile.address.city"},
+			result:  `{"profile":{"name":"Tyk","ssn":"123-45-6789"},"orders":[{"id":"o-1","cost":"****","supplier_id":"s-1"},{"id":"o-2","cost":"****","supplier_id":"s-2"}]}`,
+		},
+		{
+			name:    "allow nothing matching",
+			allowed: []string{"$.missing"},
+			result:  `{}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := redact.CompileRules(tc.allowed, tc.denied, tc.masked)
+			require.NoError(t, err)
+
+			var doc interface{}
+			require.NoError(t, json.Unmarshal([]byte(testDocument), &doc))
+
+			out, err := json.Marshal(rules.Apply(doc))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.result, string(out))
+		})
+	}
+
+	t.Run("nested matches", func(t *testing.T) {
+		const nested = `{"a":{"a":{"a":1},"b":2},"c":[{"a":3}]}`
+
+		for _, tc := range []struct {
+			name    string
+			allowed []string
+			denied  []string
+			masked  []string
+			result  string
+		}{
+			{name: "deny recursively", denied: []string{"$..a"}, result: `{"c":[{}]}`},
+			{name: "mask recursively", masked: []string{"$..a"}, result: `{"a":"****","c":[{"a":"****"}]}`},
+			{name: "allow recursively", allowed: []string{"$..a"}, result: `{"a":{"a":{"a":1},"b":2},"c":[{"a":3}]}`},
+			{name: "deny wildcards", denied: []string{"$.*.*"}, result: `{"a":{},"c":[]}`},
+			{name: "mask wildcards", masked: []string{"$.*.*", "$..a"}, result: `{"a":"****","c":["****"]}`},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				rules, err := redact.CompileRules(tc.allowed, tc.denied, tc.masked)
+				require.NoError(t, err)
+
+				var doc interface{}
+				require.NoError(t, json.Unmarshal([]byte(nested), &doc))
+
+				out, err := json.Marshal(rules.Apply(doc))
+				require.NoError(t, err)
+				assert.JSONEq(t, tc.result, string(out))
+			})
+		}
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		_, err := redact.CompileRules(nil, []string{"internal_id"}, nil)
+		assert.ErrorIs(t, err, redact.ErrInvalidPath)
+	})
+}
@@ -22,6 +22,9 @@ type Policy struct {
 	ThrottleInterval              float64                          `bson:"throttle_interval" json:"throttle_interval"`
 	ThrottleRetryLimit            int                              `bson:"throttle_retry_limit" json:"throttle_retry_limit"`
 	MaxQueryDepth                 int                              `bson:"max_query_depth" json:"max_query_depth"`
+	MaxQueryCost                  int                              `bson:"max_query_cost" json:"max_query_cost"`
+	MaxSubscriptions              int                              `bson:"max_subscriptions" json:"max_subscriptions"`
+	MaxSubscriptionLifetime       int64                            `bson:"max_subscription_lifetime" json:"max_subscription_lifetime"`
 	AccessRights                  map[string]AccessDefinition      `bson:"access_rights" json:"access_rights"`
 	HMACEnabled                   bool                             `bson:"hmac_enabled" json:"hmac_enabled"`
 	EnableHTTPSignatureValidation bool                             `json:"enable_http_signature_validation" msg:"enable_http_signature_validation"`
@@ -40,11 +43,14 @@ type Policy struct {
 
 func (p *Policy) APILimit() APILimit {
 	return APILimit{
-		QuotaMax:           p.QuotaMax,
-		QuotaRenewalRate:   p.QuotaRenewalRate,
-		ThrottleInterval:   p.ThrottleInterval,
-		ThrottleRetryLimit: p.ThrottleRetryLimit,
-		MaxQueryDepth:      p.MaxQueryDepth,
+		QuotaMax:                p.QuotaMax,
+		QuotaRenewalRate:        p.QuotaRenewalRate,
+		ThrottleInterval:        p.ThrottleInterval,
+		ThrottleRetryLimit:      p.ThrottleRetryLimit,
+		MaxQueryDepth:           p.MaxQueryDepth,
+		MaxQueryCost:            p.MaxQueryCost,
+		MaxSubscriptions:        p.MaxSubscriptions,
+		MaxSubscriptionLifetime: p.MaxSubscriptionLifetime,
 		RateLimit: RateLimit{
 			Rate:      p.Rate,
 			Per:       p.Per,
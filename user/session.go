@@ -51,14 +51,17 @@ type RateLimit struct {
 // APILimit stores quota and rate limit on ACL level (per API)
 type APILimit struct {
 	RateLimit
-	ThrottleInterval   float64 `json:"throttle_interval" msg:"throttle_interval"`
-	ThrottleRetryLimit int     `json:"throttle_retry_limit" msg:"throttle_retry_limit"`
-	MaxQueryDepth      int     `json:"max_query_depth" msg:"max_query_depth"`
-	QuotaMax           int64   `json:"quota_max" msg:"quota_max"`
-	QuotaRenews        int64   `json:"quota_renews" msg:"quota_renews"`
-	QuotaRemaining     int64   `json:"quota_remaining" msg:"quota_remaining"`
-	QuotaRenewalRate   int64   `json:"quota_renewal_rate" msg:"quota_renewal_rate"`
-	SetBy              string  `json:"-" msg:"-"`
+	ThrottleInterval        float64 `json:"throttle_interval" msg:"throttle_interval"`
+	ThrottleRetryLimit      int     `json:"throttle_retry_limit" msg:"throttle_retry_limit"`
+	MaxQueryDepth           int     `json:"max_query_depth" msg:"max_query_depth"`
+	MaxQueryCost            int     `json:"max_query_cost" msg:"max_query_cost"`
+	MaxSubscriptions        int     `json:"max_subscriptions" msg:"max_subscriptions"`
+	MaxSubscriptionLifetime int64   `json:"max_subscription_lifetime" msg:"max_subscription_lifetime"`
+	QuotaMax                int64   `json:"quota_max" msg:"quota_max"`
+	QuotaRenews             int64   `json:"quota_renews" msg:"quota_renews"`
+	QuotaRemaining          int64   `json:"quota_remaining" msg:"quota_remaining"`
+	QuotaRenewalRate        int64   `json:"quota_renewal_rate" msg:"quota_renewal_rate"`
+	SetBy                   string  `json:"-" msg:"-"`
 }
 
 // Clone does a deepcopy of APILimit.
@@ -75,14 +78,17 @@ func (a APILimit) Clone() *APILimit {
 			Per:       a.Per,
 			Smoothing: smoothingRef,
 		},
-		ThrottleInterval:   a.ThrottleInterval,
-		ThrottleRetryLimit: a.ThrottleRetryLimit,
-		MaxQueryDepth:      a.MaxQueryDepth,
-		QuotaMax:           a.QuotaMax,
-		QuotaRenews:        a.QuotaRenews,
-		QuotaRemaining:     a.QuotaRemaining,
-		QuotaRenewalRate:   a.QuotaRenewalRate,
-		SetBy:              a.SetBy,
+		ThrottleInterval:        a.ThrottleInterval,
+		ThrottleRetryLimit:      a.ThrottleRetryLimit,
+		MaxQueryDepth:           a.MaxQueryDepth,
+		MaxQueryCost:            a.MaxQueryCost,
+		MaxSubscriptions:        a.MaxSubscriptions,
+		MaxSubscriptionLifetime: a.MaxSubscriptionLifetime,
+		QuotaMax:                a.QuotaMax,
+		QuotaRenews:             a.QuotaRenews,
+		QuotaRemaining:          a.QuotaRemaining,
+		QuotaRenewalRate:        a.QuotaRenewalRate,
+		SetBy:                   a.SetBy,
 	}
 }
 
@@ -113,6 +119,32 @@ type AccessDefinition struct {
 	AllowanceScope string `json:"allowance_scope" msg:"allowance_scope"`
 
 	Endpoints Endpoints `json:"endpoints,omitempty" msg:"endpoints,omitempty"`
+
+	// ResponseFields filters the fields of JSON responses returned to the key.
+	ResponseFields ResponseFieldRules `json:"response_fields" msg:"response_fields"`
+
+	// FieldArgumentRules restrict GraphQL fields to the allowed values of their arguments.
+	FieldArgumentRules []FieldArgumentRule `json:"field_argument_rules,omitempty" msg:"field_argument_rules"`
+	// MaskRestrictedFields resolves restricted GraphQL fields to null with an error entry,
+	// instead of rejecting the whole operation.
+	MaskRestrictedFields bool `json:"mask_restricted_fields,omitempty" msg:"mask_restricted_fields"`
+}
+
+// ResponseFieldRules holds JSONPath expressions selecting the fields of upstream JSON
+// responses a key is allowed to see. Allowed paths are applied first, then masked and
+// denied paths.
+type ResponseFieldRules struct {
+	// Allowed, when not empty, keeps only the fields matching these paths.
+	Allowed []string `json:"allowed,omitempty" msg:"allowed"`
+	// Denied removes the fields matching these paths.
+	Denied []string `json:"denied,omitempty" msg:"denied"`
+	// Masked replaces the values of the fields matching these paths.
+	Masked []string `json:"masked,omitempty" msg:"masked"`
+}
+
+// IsEmpty returns true if no response field rules are set.
+func (r ResponseFieldRules) IsEmpty() bool {
+	return len(r.Allowed) == 0 && len(r.Denied) == 0 && len(r.Masked) == 0
 }
 
 // IsEmpty checks if APILimit is empty.
@@ -137,6 +169,18 @@ func (a APILimit) IsEmpty() bool {
 		return false
 	}
 
+	if a.MaxQueryCost != 0 {
+		return false
+	}
+
+	if a.MaxSubscriptions != 0 {
+		return false
+	}
+
+	if a.MaxSubscriptionLifetime != 0 {
+		return false
+	}
+
 	if a.QuotaMax != 0 {
 		return false
 	}
@@ -166,6 +210,15 @@ type FieldAccessDefinition struct {
 	Limits    FieldLimits `json:"limits" msg:"limits"`
 }
 
+// FieldArgumentRule restricts a GraphQL field to operations passing one of the allowed values
+// to the argument. Allowed values can use context variables, like $tyk_meta.tenant_id.
+type FieldArgumentRule struct {
+	TypeName      string   `json:"type_name" msg:"type_name"`
+	FieldName     string   `json:"field_name" msg:"field_name"`
+	ArgumentName  string   `json:"argument_name" msg:"argument_name"`
+	AllowedValues []string `json:"allowed_values" msg:"allowed_values"`
+}
+
 type FieldLimits struct {
 	MaxQueryDepth int `json:"max_query_depth" msg:"max_query_depth"`
 }
@@ -253,6 +306,9 @@ type SessionState struct {
 	ThrottleInterval              float64                     `json:"throttle_interval" msg:"throttle_interval"`
 	ThrottleRetryLimit            int                         `json:"throttle_retry_limit" msg:"throttle_retry_limit"`
 	MaxQueryDepth                 int                         `json:"max_query_depth" msg:"max_query_depth"`
+	MaxQueryCost                  int                         `json:"max_query_cost" msg:"max_query_cost"`
+	MaxSubscriptions              int                         `json:"max_subscriptions" msg:"max_subscriptions"`
+	MaxSubscriptionLifetime       int64                       `json:"max_subscription_lifetime" msg:"max_subscription_lifetime"`
 	DateCreated                   time.Time                   `json:"date_created" msg:"date_created"`
 	Expires                       int64                       `json:"expires" msg:"expires"`
 	QuotaMax                      int64                       `json:"quota_max" msg:"quota_max"`
@@ -310,12 +366,15 @@ func (s *SessionState) APILimit() APILimit {
 			Per:       s.Per,
 			Smoothing: s.Smoothing,
 		},
-		QuotaMax:           s.QuotaMax,
-		QuotaRenewalRate:   s.QuotaRenewalRate,
-		QuotaRenews:        s.QuotaRenews,
-		ThrottleInterval:   s.ThrottleInterval,
-		ThrottleRetryLimit: s.ThrottleRetryLimit,
-		MaxQueryDepth:      s.MaxQueryDepth,
+		QuotaMax:                s.QuotaMax,
+		QuotaRenewalRate:        s.QuotaRenewalRate,
+		QuotaRenews:             s.QuotaRenews,
+		ThrottleInterval:        s.ThrottleInterval,
+		ThrottleRetryLimit:      s.ThrottleRetryLimit,
+		MaxQueryDepth:           s.MaxQueryDepth,
+		MaxQueryCost:            s.MaxQueryCost,
+		MaxSubscriptions:        s.MaxSubscriptions,
+		MaxSubscriptionLifetime: s.MaxSubscriptionLifetime,
 	}
 }
 